@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -19,7 +20,8 @@ func main() {
 	// Command line flags
 	var (
 		pregaIndex   = flag.String("prega-index", "quay.io/prega/prega-operator-index:v4.21", "Prega operator index image to analyze")
-		outputFile   = flag.String("output", "", "Output file for release notes (default: auto-generated timestamp)")
+		outputFile   = flag.String("output", "", "Output file for release notes, '-' for text on stdout, or comma-separated files whose format is inferred from the extension (.txt, .html, .json, .md, .pdf, .xml for an Atom feed) (default: auto-generated timestamp)")
+		outputFormat = flag.String("format", "text", "Output format: 'text' for the release notes files selected by --output, or 'json' for a single JSON array with one object per repository (its notes, or its error when it failed)")
 		workDir      = flag.String("work-dir", "", "Temporary directory for cloning repositories")
 		verbose      = flag.Bool("verbose", false, "Enable verbose logging")
 		cursorAgent  = flag.Bool("cursor-agent", false, "Use cursor-agent vibe-tools for enhanced release notes")
@@ -27,7 +29,75 @@ func main() {
 		indexFile    = flag.String("index-file", "", "Path to index.json file")
 		serverMode   = flag.Bool("server", false, "Run in web server mode")
 		serverPort   = flag.Int("port", 8080, "Port for web server (default: 8080)")
+		bindHost     = flag.String("bind", "", "Address for the web server to bind to (default: all interfaces)")
+		noHTML       = flag.Bool("no-html", false, "Do not generate the companion HTML release notes file")
+		htmlOnly     = flag.Bool("html-only", false, "Generate only the HTML release notes file (no text file)")
+		noTimestamp  = flag.Bool("no-timestamp", false, "Omit generation timestamps so unchanged results produce identical files")
+		upstreams    = flag.String("upstream", "", "Comma-separated fork=upstream repository URL pairs to report how far each fork is behind/ahead of upstream")
+		notableFiles = flag.String("notable-files", "", "Comma-separated file globs (e.g. '**/role.yaml,Dockerfile,.github/workflows/*') whose changes are listed as notable")
+		notableFrom  = flag.String("notable-files-from", "", "File with notable file globs, one per line ('#' starts a comment)")
+		openPRs      = flag.Bool("open-prs", false, "Show the open pull/merge request count per repository (needs GITHUB_TOKEN or GITLAB_TOKEN)")
+		ignoreWS     = flag.Bool("ignore-whitespace", false, "Ignore whitespace-only changes when counting changed lines (slower)")
+		checkURLs    = flag.Bool("check-urls", false, "Only check that each repository URL is reachable (no clone or analysis), then exit")
+		rankBy       = flag.String("rank-by", "commits", "Rank contributors by 'commits' or by 'lines' changed")
+		dateMode     = flag.String("date-mode", "committer", "Commit date that places commits in the analysis window and is shown for them: 'committer' (when the commit landed) or 'author' (when the change was written)")
+		statsWorkers = flag.Int("stats-workers", pkg.DefaultStatsWorkers, "Number of commits whose line counts are computed in parallel within a repository")
+		concurrency  = flag.Int("concurrency", pkg.DefaultConcurrency, "Number of repositories cloned and analyzed in parallel")
+		regenIndex   = flag.Bool("regenerate-index", false, "Regenerate the index JSON with opm even when the index file exists")
+		noRegenIndex = flag.Bool("no-regenerate-index", false, "Fail instead of generating the index JSON when the index file is missing")
+		labelsFile   = flag.String("labels-file", "", "File mapping repository URLs to labels ('<url> label1,label2' per line); the report is grouped by label")
+		notifyHook   = flag.String("notify-webhook", "", "Incoming webhook URL (Slack, Teams, Discord, ...) that receives a summary of the run")
+		notifyFormat = flag.String("notify-format", "slack", "Payload of --notify-webhook: 'slack', 'teams', 'discord' or 'json' (the raw summary)")
+		stateFile    = flag.String("state-file", "", "JSON file remembering each repository's analyzed commit between runs, used to flag force-pushed branches")
+		reportURL    = flag.String("report-url", "", "URL where the full report is published, linked from the --notify-webhook summary")
+		htmlCommits  = flag.Int("html-max-commits", pkg.DefaultHTMLMaxCommits, "Number of commits listed in the web UI's HTML notes (the text notes keep their own limit)")
+		noProgress   = flag.Bool("no-clone-progress", false, "Do not log git's clone progress (it is only logged at debug level with --verbose)")
+		objectCache  = flag.String("object-cache", "", "Directory of a shared object store so forks of the same operator only fetch their common history once")
+		failOnEmpty  = flag.Bool("fail-on-empty-index", false, "Exit with an error when the index parses but no repository is extracted from it")
+		indexTTL     = flag.Duration("index-cache-ttl", pkg.DefaultIndexCacheTTL, "How long the server reuses the parsed index when the same index image is refreshed again (0 disables)")
+		idleTimeout  = flag.Duration("idle-timeout", pkg.DefaultIdleTimeout, "How long the web server keeps idle keep-alive connections open (0 keeps them indefinitely)")
+		h2Streams    = flag.Uint("http2-max-streams", pkg.DefaultHTTP2MaxConcurrentStreams, "Maximum concurrent requests on one HTTP/2 connection to the web server")
+		h2c          = flag.Bool("h2c", false, "Accept HTTP/2 without TLS (h2c), for running behind a proxy that terminates TLS")
+		lineEnding   = flag.String("line-ending", "", "Line ending of the text notes: 'lf' or 'crlf' (default: the host's convention)")
+		diffIndex    = flag.String("diff-index", "", "Compare this older index image with the index image given as argument and report added and removed repositories and changed head versions")
+		showRepos    = flag.Bool("show-repos", false, "Log the list of unique repositories found in the index (also logged with --verbose)")
+		subjectLen   = flag.Int("max-subject-length", 0, "Show only the first line of each commit message in the text notes, cut to this many characters (0 keeps full messages)")
+		identities   = flag.String("emit-identities", "", "Write the author identities seen in each repository's commits to this file as candidate .mailmap entries")
+		submodules   = flag.Bool("include-submodules", false, "Fetch each repository's submodules and list their commits in the analysis window (clones every submodule)")
+		tarballFall  = flag.Bool("tarball-fallback", false, "When cloning a GitHub repository fails, download its tarball from codeload.github.com and report its current state without history")
+		branches     = flag.String("branches", "", "Comma-separated branch names or globs (e.g. 'main,release-*') whose notes are generated from a single clone of each repository, one section per branch (default: main, else master)")
+		minCommits   = flag.Int("min-commits", 0, "Only list repositories with fewer commits than this in the analysis window in a low activity summary instead of a full section (0 shows every repository)")
+		staleDays    = flag.Int("stale-release-days", 0, "List repositories whose newest semver release tag is older than this many days in a stale releases summary (0 disables the check; repositories without tags are never listed)")
+		cloneDepth   = flag.Int("clone-depth", 0, "Clone only this many commits of each repository's history, which is much faster for short analysis windows; a warning is logged when the window reaches past it (0 clones full history)")
+		cloneLimit   = flag.Int("server-clone-concurrency", 0, "Maximum clones and analyses the web server runs at once across all requests; excess requests wait (see --server-clone-wait) (0 means unlimited)")
+		cloneWait    = flag.Duration("server-clone-wait", pkg.DefaultCloneQueueWait, "How long a web request waits for a free clone slot before getting a 503 'server busy' response")
+		cacheRepos   = flag.Int("server-cache-max-repos", pkg.DefaultRepoCacheMaxEntries, "Maximum repositories the web server keeps cloned under <work-dir>/cache; the least recently used are evicted (0 means unlimited)")
+		junitOutput  = flag.String("junit-output", "", "Also write a JUnit XML report to this file, with each repository as a test case that fails when its analysis failed")
+		overridesFile = flag.String("overrides-file", "", "File of per-repository settings that take precedence over the flags, one repository URL per line followed by days=N or since=YYYY-MM-DD [until=YYYY-MM-DD], and link=URL for bundles")
+		headOnly     = flag.Bool("head-only", false, "Only report what is currently shipping: the default channel head of each package, its version and the tagged commit of that version (no commit-window analysis), then exit")
+		upgradeGraph = flag.String("upgrade-graph", "", "Only print the catalog's upgrade edges (replaces, skips, skipRange) per channel as 'text' or 'dot', then exit")
+		heatmap      = flag.Bool("activity-heatmap", false, "Add a histogram of commits by weekday and time of day (in the local time zone, see TZ) to each repository")
+		avatars      = flag.Bool("avatars", false, "Show contributor avatars in HTML output for GitHub repositories (may call the GitHub API)")
+		asOf         = flag.String("as-of", "", "End the analysis window on this past date (YYYY-MM-DD) to reproduce a historical report")
+		days         = flag.Int("days", 0, "Number of days of history to analyze (default: ANALYSIS_DAYS or 7)")
+		sinceFlag    = flag.String("since", "", "Start the analysis window on this date (YYYY-MM-DD) or this long ago (e.g. 30d, 2w) instead of covering --days days")
+		untilFlag    = flag.String("until", "", "End the analysis window on this date (YYYY-MM-DD) or this long ago (e.g. 1w) instead of now")
+		maxRepoSize  = flag.Int64("max-repo-size", 0, "Skip repositories larger than this many MB before cloning (0 disables the check)")
+		skipChecksum = flag.Bool("skip-checksum", false, "Use a downloaded opm without verifying it against the mirror's sha256sum.txt, for air-gapped mirrors that don't publish checksums")
+		configFile   = flag.String("config", "", "YAML file of settings (pregaIndex, workDir, outputDir, concurrency, cloneDepth, format, serverPort, days) used where no flag or environment variable sets them")
 	)
+	var excludeExts listFlag
+	flag.Var(&excludeExts, "exclude-ext", "File extension (e.g. svg, min.js) whose lines are not counted as changed; repeatable or comma-separated")
+	var allowedHosts listFlag
+	flag.Var(&allowedHosts, "allowed-hosts", "Git hosts repositories may be cloned from (e.g. github.com,gitlab.cee.redhat.com); other repositories are skipped. Repeatable or comma-separated")
+	var vendorPaths listFlag
+	flag.Var(&vendorPaths, "vendor-paths", "File globs of vendored dependencies (default: vendor/**,third_party/**); commits only touching them are listed in a separate dependency vendoring section. Repeatable or comma-separated")
+	var onlyAuthors repeatedFlag
+	flag.Var(&onlyAuthors, "only-authors", "Only analyze commits by this author name or email (case-insensitive); repeatable")
+	var commitURLTemplates repeatedFlag
+	flag.Var(&commitURLTemplates, "commit-url-template", "Commit link template using {base}, {hash} and {shortHash}, optionally for one host ('host=template'); repeatable")
+	var linkHostRewrites repeatedFlag
+	flag.Var(&linkHostRewrites, "link-host-rewrite", "Point commit links at another host than the one cloned from ('from-host=to-host', e.g. mirror.internal=github.com); repeatable")
 	flag.Parse()
 
 	if *help {
@@ -35,8 +105,10 @@ func main() {
 		return
 	}
 
-	// Set up logging
+	// Set up logging. Diagnostics go to stderr (colored on a TTY) so stdout only carries
+	// results and can be piped.
 	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
 	if *verbose {
 		logger.SetLevel(logrus.DebugLevel)
 	} else {
@@ -45,16 +117,72 @@ func main() {
 	logger.SetFormatter(&logrus.TextFormatter{
 		FullTimestamp: true,
 	})
+	pkg.IndexLogger = logger
+	if credentials := pkg.GitCredentialsFromEnv(); credentials != nil && len(credentials.Hosts) == 0 {
+		logger.Warnf("%s is set but sent to no host; list the hosts it belongs to in %s", pkg.GitTokenEnv, pkg.GitTokenHostsEnv)
+	}
+
+	// Finds or downloads the opm that renders index images
+	deps := pkg.NewDependencyManager(".bin", logger)
+	deps.SkipChecksum = *skipChecksum
+
+	// Tracing is a no-op unless an OTLP endpoint is configured
+	ctx := context.Background()
+	shutdownTracing, err := pkg.InitTracing(ctx)
+	if err != nil {
+		logger.Warnf("Tracing disabled: %v", err)
+	}
+	flushTraces := func() {
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warnf("Failed to flush traces: %v", err)
+		}
+	}
+	defer flushTraces()
+	// Fatal errors exit without running deferred calls, so flush the spans recorded so far first
+	logger.ExitFunc = func(code int) {
+		flushTraces()
+		os.Exit(code)
+	}
+
+	// A --config file fills in the settings that neither a flag nor an environment variable
+	// sets: flags win over the environment, which wins over the file
+	explicitFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicitFlags[f.Name] = true })
+	config := &pkg.Config{}
+	if *configFile != "" {
+		if config, err = pkg.LoadConfig(*configFile); err != nil {
+			logger.Fatalf("Failed to load --config file: %v", err)
+		}
+	}
+	if config.PregaIndex != "" && !explicitFlags["prega-index"] {
+		*pregaIndex = config.PregaIndex
+	}
+	if config.Concurrency != 0 && !explicitFlags["concurrency"] {
+		*concurrency = config.Concurrency
+	}
+	if config.CloneDepth != 0 && !explicitFlags["clone-depth"] {
+		*cloneDepth = config.CloneDepth
+	}
+	if config.Format != "" && !explicitFlags["format"] {
+		*outputFormat = config.Format
+	}
 
 	// Check for environment variable overrides
 	if os.Getenv("SERVER_MODE") == "true" {
 		*serverMode = true
 	}
-	if portStr := os.Getenv("SERVER_PORT"); portStr != "" {
-		if port, err := strconv.Atoi(portStr); err == nil {
-			*serverPort = port
+	if !explicitFlags["port"] {
+		if portStr := os.Getenv("SERVER_PORT"); portStr != "" {
+			if port, err := strconv.Atoi(portStr); err == nil {
+				*serverPort = port
+			}
+		} else if config.ServerPort != 0 {
+			*serverPort = config.ServerPort
 		}
 	}
+	if host := os.Getenv("SERVER_HOST"); host != "" && *bindHost == "" {
+		*bindHost = host
+	}
 
 	// Configuration with environment variable support
 	indexJSONPath := getEnvOrDefault("INDEX_FILE", "prega-operator-index/index.json")
@@ -62,81 +190,432 @@ func main() {
 		indexJSONPath = *indexFile
 	}
 
-	defaultWorkDir := getEnvOrDefault("WORK_DIR", "temp-repos")
+	defaultWorkDir := getEnvOrDefault("WORK_DIR", firstNonEmpty(config.WorkDir, "temp-repos"))
 	if *workDir == "" {
 		*workDir = defaultWorkDir
 	}
 
-	outputDir := getEnvOrDefault("OUTPUT_DIR", ".")
+	// Catalog diff mode compares two index images and exits without analyzing any repository
+	if *diffIndex != "" {
+		if flag.NArg() != 1 {
+			logger.Fatalf("--diff-index needs the newer index image as argument: --diff-index <old> <new>")
+		}
+		diff, err := pkg.DiffIndexImages(ctx, deps, *diffIndex, flag.Arg(0), *workDir)
+		if err != nil {
+			logger.Fatalf("Failed to diff index images: %v", err)
+		}
+		fmt.Print(diff.FormatText())
+		return
+	}
+
+	analysisDays, err := resolveAnalysisDays(*days, getEnvOrDefault("ANALYSIS_DAYS", ""), config.Days)
+	if err != nil {
+		logger.Fatalf("Invalid analysis window: %v", err)
+	}
+	var analysisEnd time.Time
+	if *asOf != "" {
+		if *untilFlag != "" {
+			logger.Fatalf("--as-of and --until cannot be combined")
+		}
+		if analysisEnd, err = pkg.ParseAsOfDate(*asOf, time.Now()); err != nil {
+			logger.Fatalf("Invalid --as-of value: %v", err)
+		}
+	}
+	if *sinceFlag != "" && *days != 0 {
+		logger.Fatalf("--since and --days cannot be combined")
+	}
+	analysisStart, rangeEnd, err := pkg.ParseDateRange(*sinceFlag, *untilFlag, time.Now())
+	if err != nil {
+		logger.Fatalf("Invalid analysis window: %v", err)
+	}
+	if *untilFlag != "" {
+		analysisEnd = rangeEnd
+	}
+
+	contributorRanking, err := pkg.ParseRankBy(*rankBy)
+	if err != nil {
+		logger.Fatalf("Invalid --rank-by value: %v", err)
+	}
+	commitDateMode, err := pkg.ParseDateMode(*dateMode)
+	if err != nil {
+		logger.Fatalf("Invalid --date-mode value: %v", err)
+	}
+	textLineEnding, err := pkg.ParseLineEnding(*lineEnding)
+	if err != nil {
+		logger.Fatalf("Invalid --line-ending value: %v", err)
+	}
+
+	notificationFormat, err := pkg.ParseNotifyFormat(*notifyFormat)
+	if err != nil {
+		logger.Fatalf("Invalid --notify-format value: %v", err)
+	}
+
+	commitLinks, err := pkg.ParseCommitURLTemplates(commitURLTemplates)
+	if err != nil {
+		logger.Fatalf("Invalid --commit-url-template value: %v", err)
+	}
+	if commitLinks.HostRewrites, err = pkg.ParseLinkHostRewrites(linkHostRewrites); err != nil {
+		logger.Fatalf("Invalid --link-host-rewrite value: %v", err)
+	}
+
+	if *statsWorkers < 1 {
+		logger.Fatalf("--stats-workers must be at least 1, got %d", *statsWorkers)
+	}
+	if *concurrency < 1 {
+		logger.Fatalf("--concurrency must be at least 1, got %d", *concurrency)
+	}
+	if *subjectLen < 0 {
+		logger.Fatalf("--max-subject-length must not be negative, got %d", *subjectLen)
+	}
+	var graphFormat pkg.UpgradeGraphFormat
+	if *upgradeGraph != "" {
+		if graphFormat, err = pkg.ParseUpgradeGraphFormat(*upgradeGraph); err != nil {
+			logger.Fatalf("Invalid --upgrade-graph value: %v", err)
+		}
+	}
+	if *cloneLimit < 0 {
+		logger.Fatalf("--server-clone-concurrency must not be negative, got %d", *cloneLimit)
+	}
+	if *cacheRepos < 0 {
+		logger.Fatalf("--server-cache-max-repos must not be negative, got %d", *cacheRepos)
+	}
+	if *minCommits < 0 {
+		logger.Fatalf("--min-commits must not be negative, got %d", *minCommits)
+	}
+	if *cloneDepth < 0 {
+		logger.Fatalf("--clone-depth must not be negative, got %d", *cloneDepth)
+	}
+	if *staleDays < 0 {
+		logger.Fatalf("--stale-release-days must not be negative, got %d", *staleDays)
+	}
+	if *htmlCommits < 1 {
+		logger.Fatalf("--html-max-commits must be at least 1, got %d", *htmlCommits)
+	}
+
+	if *outputFormat != "text" && *outputFormat != "json" {
+		logger.Fatalf("Invalid --format value %q (expected text or json)", *outputFormat)
+	}
+	jsonArray := *outputFormat == "json"
+
+	outputDir := getEnvOrDefault("OUTPUT_DIR", firstNonEmpty(config.OutputDir, "."))
 	if *outputFile == "" {
-		timestamp := time.Now().Format("2006-01-02-15-04-05")
-		*outputFile = filepath.Join(outputDir, fmt.Sprintf("release-notes-%s.txt", timestamp))
+		extension := ".txt"
+		if jsonArray {
+			extension = ".json"
+		}
+		if *noTimestamp {
+			*outputFile = filepath.Join(outputDir, "release-notes"+extension)
+		} else {
+			timestamp := time.Now().Format("2006-01-02-15-04-05")
+			*outputFile = filepath.Join(outputDir, fmt.Sprintf("release-notes-%s%s", timestamp, extension))
+		}
+	}
+
+	if *regenIndex && *noRegenIndex {
+		logger.Fatalf("--regenerate-index and --no-regenerate-index cannot be used together")
+	}
+
+	if *noHTML && *htmlOnly {
+		logger.Fatalf("--no-html and --html-only cannot be used together")
+	}
+
+	// Several comma-separated files, or a single non-text file, select explicit output formats;
+	// a single text file keeps the text + companion HTML behaviour
+	var outputTargets []pkg.OutputTarget
+	if jsonArray {
+		if strings.Contains(*outputFile, ",") {
+			logger.Fatalf("--format=json writes a single file, got several in --output")
+		}
+		if *noHTML || *htmlOnly {
+			logger.Fatalf("--no-html and --html-only cannot be combined with --format=json")
+		}
+		outputTargets = []pkg.OutputTarget{{Path: *outputFile, Format: pkg.OutputFormatJSONArray}}
+	} else if format, err := pkg.OutputFormatForPath(*outputFile); strings.Contains(*outputFile, ",") || *outputFile == pkg.StdoutOutputPath || (err == nil && format != pkg.OutputFormatText) {
+		targets, err := pkg.ParseOutputTargets(*outputFile)
+		if err != nil {
+			logger.Fatalf("Invalid --output value: %v", err)
+		}
+		if *noHTML || *htmlOnly {
+			logger.Fatalf("--no-html and --html-only cannot be combined with explicit output formats in --output")
+		}
+		outputTargets = targets
 	}
 
 	// Handle server mode
+	notablePatterns := parseList(*notableFiles)
+	if *notableFrom != "" {
+		filePatterns, err := readPatternFile(*notableFrom)
+		if err != nil {
+			logger.Fatalf("Failed to read notable file patterns: %v", err)
+		}
+		notablePatterns = append(notablePatterns, filePatterns...)
+	}
+
 	if *serverMode {
-		runServerMode(*serverPort, *workDir, outputDir, *pregaIndex, logger)
+		server := pkg.NewServer(*serverPort, *workDir, outputDir, *pregaIndex, logger)
+		server.Host = *bindHost
+		server.NotablePatterns = notablePatterns
+		server.ReportOpenPRs = *openPRs
+		server.IgnoreWhitespace = *ignoreWS
+		server.ExcludeExtensions = excludeExts
+		server.OnlyAuthors = onlyAuthors
+		if len(vendorPaths) > 0 {
+			server.VendorPatterns = vendorPaths
+		}
+		server.IdleTimeout = *idleTimeout
+		server.HTTP2MaxConcurrentStreams = uint32(*h2Streams)
+		server.H2C = *h2c
+		server.RankBy = contributorRanking
+		server.DateMode = commitDateMode
+		server.StatsWorkers = *statsWorkers
+		server.ShowAvatars = *avatars
+		server.ActivityHeatmap = *heatmap
+		server.HTMLMaxCommits = *htmlCommits
+		server.CommitURLs = commitLinks
+		server.AllowedHosts = allowedHosts
+		server.IndexCacheTTL = *indexTTL
+		server.Deps = deps
+		server.LineEnding = textLineEnding
+		server.CloneConcurrency = *cloneLimit
+		server.CloneQueueWait = *cloneWait
+		server.RepoCacheMaxEntries = *cacheRepos
+		server.CloneDepth = *cloneDepth
+		server.MaxSubjectLength = *subjectLen
+		// Only an explicitly configured index file replaces the server's work-dir default
+		if *indexFile != "" || os.Getenv("INDEX_FILE") != "" {
+			server.IndexPath = indexJSONPath
+		}
+		runServerMode(server, logger)
 		return
 	}
 
+	ctx, runSpan := pkg.StartSpan(ctx, "release-notes.run")
+	defer runSpan.End()
+
 	logger.Infof("Configuration:")
 	logger.Infof("  Index file: %s", indexJSONPath)
 	logger.Infof("  Work directory: %s", *workDir)
 	logger.Infof("  Output file: %s", *outputFile)
+	if analysisStart.IsZero() {
+		logger.Infof("  Analysis window: %d days", analysisDays)
+	} else {
+		logger.Infof("  Analysis start: %s", analysisStart.Format("2006-01-02 15:04:05"))
+	}
+	if !analysisEnd.IsZero() {
+		logger.Infof("  Analysis end: %s", analysisEnd.Format("2006-01-02 15:04:05"))
+	}
 	logger.Infof("  Prega index: %s", *pregaIndex)
 
-	// Check if index.json exists, if not, generate it
-	if _, err := os.Stat(indexJSONPath); os.IsNotExist(err) {
-		logger.Infof("Index JSON file not found: %s", indexJSONPath)
+	// Generate index.json if it doesn't exist, or when regeneration is forced
+	_, statErr := os.Stat(indexJSONPath)
+	indexMissing := os.IsNotExist(statErr)
+	if indexMissing && *noRegenIndex {
+		logger.Fatalf("Index JSON file not found: %s (--no-regenerate-index is set)", indexJSONPath)
+	}
+	if indexMissing || *regenIndex {
+		if indexMissing {
+			logger.Infof("Index JSON file not found: %s", indexJSONPath)
+		} else {
+			logger.Infof("Regenerating existing index JSON: %s", indexJSONPath)
+		}
 		logger.Info("Generating index JSON from Prega operator index...")
 		
-		if err := generateIndexJSON(*pregaIndex, indexJSONPath, logger); err != nil {
-			logger.Fatalf("Failed to generate index JSON: %v", err)
+		_, renderSpan := pkg.StartSpan(ctx, "index.render")
+		err := generateIndexJSON(deps, *pregaIndex, indexJSONPath, logger)
+		pkg.EndSpan(renderSpan, err)
+		if err != nil {
+			logger.Fatalf("Failed to generate index JSON: %s", pkg.ErrorWithRemediation(err))
 		}
 		logger.Info("Index JSON generated successfully")
 	}
 
+	if graphFormat != "" {
+		packages, err := pkg.ParseCatalogPackages(indexJSONPath)
+		if err != nil {
+			logger.Fatalf("Failed to parse operator index: %v", err)
+		}
+		fmt.Print(pkg.FormatUpgradeGraphs(pkg.BuildUpgradeGraphs(packages), graphFormat))
+		return
+	}
+
 	logger.Info("Starting Prega Operator Analyzer")
 	logger.Infof("Reading index from: %s", indexJSONPath)
 
 	// Parse the operator index JSON
+	_, parseSpan := pkg.StartSpan(ctx, "index.parse")
 	repositories, err := pkg.ParseOperatorIndex(indexJSONPath)
+	pkg.EndSpan(parseSpan, err)
 	if err != nil {
 		logger.Fatalf("Failed to parse operator index: %v", err)
 	}
+	if len(repositories) == 0 {
+		if *failOnEmpty {
+			logger.Fatalf("No repositories extracted from %s", indexJSONPath)
+		}
+		logger.Warnf("No repositories extracted from %s; the report will be empty", indexJSONPath)
+	}
 
 	logger.Infof("Found %d repository entries", len(repositories))
 
 	// Remove duplicates
 	uniqueRepositories := pkg.RemoveDuplicates(repositories)
 	logger.Infof("Found %d unique repositories after deduplication", len(uniqueRepositories))
+	if len(allowedHosts) > 0 {
+		uniqueRepositories = pkg.FilterAllowedHosts(uniqueRepositories, allowedHosts, logger)
+		logger.Infof("%d repositories on allowed hosts (%s)", len(uniqueRepositories), allowedHosts.String())
+	}
 
-	// Display unique repositories
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Println("UNIQUE REPOSITORIES FOUND:")
-	fmt.Println(strings.Repeat("=", 80))
+	// List the unique repositories only when asked (--show-repos) or at debug level (--verbose);
+	// the count above is always logged
+	listLevel := logrus.DebugLevel
+	if *showRepos {
+		listLevel = logrus.InfoLevel
+	}
+	logger.Log(listLevel, "Unique repositories found:")
 	for i, repo := range uniqueRepositories {
-		fmt.Printf("%3d. %s\n", i+1, repo)
+		logger.Logf(listLevel, "%3d. %s", i+1, repo)
+	}
+
+	if *checkURLs {
+		if !runURLCheck(uniqueRepositories, logger) {
+			os.Exit(1)
+		}
+		return
 	}
-	fmt.Println(strings.Repeat("=", 80))
 
 	// Create work directory
 	if err := os.MkdirAll(*workDir, 0755); err != nil {
 		logger.Fatalf("Failed to create work directory: %v", err)
 	}
 
-	// Ensure output directory exists
-	outputDirPath := filepath.Dir(*outputFile)
-	if err := os.MkdirAll(outputDirPath, 0755); err != nil {
-		logger.Fatalf("Failed to create output directory: %v", err)
+	// Ensure output directories exist
+	outputPaths := []string{*outputFile}
+	if len(outputTargets) > 0 {
+		outputPaths = nil
+		for _, target := range outputTargets {
+			outputPaths = append(outputPaths, target.Path)
+		}
+	}
+	for _, path := range outputPaths {
+		if path == pkg.StdoutOutputPath {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			logger.Fatalf("Failed to create output directory: %v", err)
+		}
 	}
 
 	// Initialize VibeToolsManager with cursor-agent flag
 	vibeManager := pkg.NewVibeToolsManager(*workDir, *outputFile, *cursorAgent)
+	vibeManager.Logger.SetLevel(logger.GetLevel())
+	vibeManager.MaxRepoSizeMB = *maxRepoSize
+	vibeManager.GenerateHTML = !*noHTML
+	vibeManager.GenerateText = !*htmlOnly
+	vibeManager.Outputs = outputTargets
+	vibeManager.Formatter.OmitTimestamp = *noTimestamp
+	vibeManager.Formatter.LineEnding = textLineEnding
+	vibeManager.Formatter.MaxSubjectLength = *subjectLen
+	vibeManager.NotablePatterns = notablePatterns
+	vibeManager.ReportOpenPRs = *openPRs
+	vibeManager.IgnoreWhitespace = *ignoreWS
+	vibeManager.ExcludeExtensions = excludeExts
+	vibeManager.OnlyAuthors = onlyAuthors
+	if len(vendorPaths) > 0 {
+		vibeManager.VendorPatterns = vendorPaths
+	}
+	vibeManager.Days = analysisDays
+	vibeManager.Since = analysisStart
+	vibeManager.MinCommits = *minCommits
+	vibeManager.StaleReleaseDays = *staleDays
+	vibeManager.CloneDepth = *cloneDepth
+	vibeManager.AsOf = analysisEnd
+	vibeManager.RankBy = contributorRanking
+	vibeManager.DateMode = commitDateMode
+	vibeManager.StatsWorkers = *statsWorkers
+	vibeManager.Concurrency = *concurrency
+	vibeManager.ShowAvatars = *avatars
+	vibeManager.ActivityHeatmap = *heatmap
+	vibeManager.NotifyWebhook = *notifyHook
+	vibeManager.NotifyFormat = notificationFormat
+	vibeManager.ReportURL = *reportURL
+	vibeManager.CommitURLs = commitLinks
+	vibeManager.StateFile = *stateFile
+	vibeManager.IdentitiesFile = *identities
+	vibeManager.JUnitFile = *junitOutput
+	vibeManager.IncludeSubmodules = *submodules
+	vibeManager.AllowedHosts = allowedHosts
+	vibeManager.TarballFallback = *tarballFall
+	if branchPatterns := parseList(*branches); len(branchPatterns) > 0 {
+		if err := pkg.ValidateBranchPatterns(branchPatterns); err != nil {
+			logger.Fatalf("Invalid --branches value: %v", err)
+		}
+		vibeManager.Branches = branchPatterns
+	}
+	vibeManager.NoCloneProgress = *noProgress
+	vibeManager.ProgressFunc = pkg.LogProgress(logger)
+	vibeManager.ObjectCacheDir = *objectCache
+	if schemas, err := pkg.ParseCatalogSchemas(indexJSONPath); err == nil {
+		vibeManager.CatalogSchemas = schemas
+	} else {
+		logger.Debugf("Failed to read catalog schema: %v", err)
+	}
+	if infos, err := pkg.ParseOperatorIndexDetailed(indexJSONPath); err == nil {
+		vibeManager.CatalogInfo = make(map[string]pkg.ParserRepositoryInfo, len(infos))
+		for _, info := range infos {
+			vibeManager.CatalogInfo[info.URL] = info
+		}
+	} else {
+		logger.Debugf("Failed to read catalog metadata: %v", err)
+	}
+	if *upstreams != "" {
+		upstreamMap, err := parseKeyValuePairs(*upstreams)
+		if err != nil {
+			logger.Fatalf("Invalid --upstream value: %v", err)
+		}
+		vibeManager.Upstreams = upstreamMap
+	}
+	if *overridesFile != "" {
+		overrides, err := pkg.ParseOverridesFile(*overridesFile)
+		if err != nil {
+			logger.Fatalf("Invalid --overrides-file: %v", err)
+		}
+		vibeManager.Overrides = overrides
+		vibeManager.CommitURLs.Links = pkg.OverrideLinks(overrides)
+	}
+	if *labelsFile != "" {
+		labels, err := pkg.ParseLabelsFile(*labelsFile)
+		if err != nil {
+			logger.Fatalf("Invalid --labels-file: %v", err)
+		}
+		vibeManager.Labels = labels
+	}
+
+	if *headOnly {
+		heads, err := pkg.ParseShippingHeads(indexJSONPath)
+		if err != nil {
+			logger.Fatalf("Failed to parse operator index: %v", err)
+		}
+		selected := make(map[string]bool, len(uniqueRepositories))
+		for _, repo := range uniqueRepositories {
+			selected[repo] = true
+		}
+		var shipping []pkg.ShippingHead
+		for _, head := range heads {
+			if selected[head.Repository] {
+				shipping = append(shipping, head)
+			}
+		}
+		logger.Infof("Resolving the shipping version of %d repositories...", len(shipping))
+		fmt.Print(vibeManager.FormatShippingVersions(vibeManager.ResolveShippingVersions(ctx, shipping)))
+		if err := os.RemoveAll(*workDir); err != nil {
+			logger.Warnf("Failed to clean up work directory: %v", err)
+		}
+		return
+	}
 
 	// Process repositories and generate release notes
 	logger.Info("Starting release notes generation...")
-	if err := vibeManager.ProcessRepositories(uniqueRepositories); err != nil {
+	if _, err := vibeManager.ProcessRepositories(ctx, uniqueRepositories); err != nil {
 		logger.Fatalf("Failed to process repositories: %v", err)
 	}
 
@@ -154,8 +633,31 @@ func main() {
 		logger.Warnf("Failed to clean up work directory: %v", err)
 	}
 
-	logger.Infof("Release notes generated successfully: %s", *outputFile)
-	fmt.Printf("\nRelease notes saved to: %s\n", *outputFile)
+	resultFiles := []string{*outputFile}
+	if *htmlOnly {
+		resultFiles = []string{vibeManager.HTMLOutputFile}
+	}
+	if len(outputTargets) > 0 {
+		resultFiles = nil
+		for _, path := range outputPaths {
+			resultFiles = append(resultFiles, vibeManager.OutputPath(path))
+		}
+	}
+	var resultNames []string
+	for _, path := range resultFiles {
+		if path == pkg.StdoutOutputPath {
+			path = "standard output"
+		}
+		resultNames = append(resultNames, path)
+	}
+	logger.Infof("Release notes generated successfully: %s", strings.Join(resultNames, ", "))
+
+	// Stdout carries only the result: the notes themselves for "--output -", otherwise the file paths
+	for _, path := range resultFiles {
+		if path != pkg.StdoutOutputPath {
+			fmt.Println(path)
+		}
+	}
 }
 
 // getEnvOrDefault returns environment variable value or default if not set
@@ -166,19 +668,145 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// runURLCheck probes every repository URL without cloning and prints one result line per
+// URL to stdout. It returns false when any URL is unreachable.
+func runURLCheck(repositories []string, logger *logrus.Logger) bool {
+	logger.Infof("Checking reachability of %d repositories...", len(repositories))
+	results := pkg.CheckRepositoryURLs(repositories, 8)
+
+	counts := make(map[pkg.URLStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+		fmt.Println(result)
+	}
+
+	summary := fmt.Sprintf("Reachable: %d, Redirected: %d, Auth required: %d, Unreachable: %d",
+		counts[pkg.URLReachable], counts[pkg.URLRedirected], counts[pkg.URLAuthRequired], counts[pkg.URLUnreachable])
+	if counts[pkg.URLUnreachable] > 0 {
+		logger.Warn(summary)
+	} else {
+		logger.Info(summary)
+	}
+
+	return counts[pkg.URLUnreachable] == 0
+}
+
+// resolveAnalysisDays picks the analysis window: the --days flag wins over ANALYSIS_DAYS,
+// which wins over the --config file's days and then the built-in default. The flag and the
+// variable must be positive integers when set.
+func resolveAnalysisDays(flagValue int, envValue string, configValue int) (int, error) {
+	if flagValue != 0 {
+		if flagValue < 0 {
+			return 0, fmt.Errorf("--days must be a positive integer, got %d", flagValue)
+		}
+		return flagValue, nil
+	}
+	if envValue == "" {
+		if configValue > 0 {
+			return configValue, nil
+		}
+		return pkg.DefaultAnalysisDays, nil
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(envValue))
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("ANALYSIS_DAYS must be a positive integer, got %q", envValue)
+	}
+	return value, nil
+}
+
+// listFlag is a repeatable flag whose values may also be comma-separated
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, parseList(value)...)
+	return nil
+}
+
+// repeatedFlag is a repeatable flag whose values are kept whole, for values that may contain commas
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, " ")
+}
+
+func (r *repeatedFlag) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+// parseList splits a comma-separated flag value, dropping empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readPatternFile reads one pattern per line, ignoring blank lines and '#' comments
+func readPatternFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// parseKeyValuePairs parses a comma-separated list of key=value pairs
+func parseKeyValuePairs(value string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, val, found := strings.Cut(item, "=")
+		if !found || key == "" || val == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", item)
+		}
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return pairs, nil
+}
+
 // runServerMode starts the web server for interactive analysis
-func runServerMode(port int, workDir, outputDir, pregaIndex string, logger *logrus.Logger) {
+func runServerMode(server *pkg.Server, logger *logrus.Logger) {
 	logger.Info("Starting Prega Operator Analyzer in Web Server Mode")
-	logger.Infof("Port: %d", port)
-	logger.Infof("Work Directory: %s", workDir)
-	logger.Infof("Output Directory: %s", outputDir)
-	logger.Infof("Prega Index: %s", pregaIndex)
-
-	// Create the server
-	server := pkg.NewServer(port, workDir, outputDir, pregaIndex, logger)
+	if server.Host != "" {
+		logger.Infof("Bind Address: %s", server.Host)
+	}
+	logger.Infof("Port: %d", server.Port)
+	logger.Infof("Work Directory: %s", server.WorkDir)
+	logger.Infof("Output Directory: %s", server.OutputDir)
+	logger.Infof("Prega Index: %s", server.PregaIndex)
+	logger.Infof("Index JSON: %s", server.IndexJSONPath())
 
 	// Try to load repositories from existing index or generate new one
-	indexJSONPath := filepath.Join(workDir, "prega-operator-index", "index.json")
+	indexJSONPath := server.IndexJSONPath()
 	
 	if _, err := os.Stat(indexJSONPath); os.IsNotExist(err) {
 		logger.Info("Index JSON file not found, will generate on first refresh")
@@ -189,14 +817,18 @@ func runServerMode(port int, workDir, outputDir, pregaIndex string, logger *logr
 		if err != nil {
 			logger.Warnf("Failed to parse existing index: %v", err)
 		} else {
-			uniqueRepos := pkg.RemoveDuplicates(repositories)
+			uniqueRepos := pkg.FilterAllowedHosts(pkg.RemoveDuplicates(repositories), server.AllowedHosts, logger)
 			server.SetRepositories(uniqueRepos)
+			if infos, err := pkg.ParseOperatorIndexDetailed(indexJSONPath); err == nil {
+				server.SetRepositoryInfo(infos)
+			} else {
+				logger.Debugf("Failed to parse channel metadata: %v", err)
+			}
 			logger.Infof("Loaded %d unique repositories", len(uniqueRepos))
 		}
 	}
 
 	// Start the server
-	logger.Infof("Web interface available at: http://localhost:%d", port)
 	if err := server.Start(); err != nil {
 		logger.Fatalf("Server failed: %v", err)
 	}
@@ -220,31 +852,70 @@ func showHelp() {
 	fmt.Println("  INDEX_FILE    - Path to index.json file (default: prega-operator-index/index.json)")
 	fmt.Println("  WORK_DIR      - Temporary directory for cloning repositories (default: temp-repos)")
 	fmt.Println("  OUTPUT_DIR    - Directory for output files (default: current directory)")
+	fmt.Println("  ANALYSIS_DAYS - Number of days of history to analyze; --days overrides it (default: 7)")
 	fmt.Println("  SERVER_MODE   - Set to 'true' to run in web server mode")
-	fmt.Println("  SERVER_PORT   - Port for web server (default: 8080)")
+	fmt.Println("  SERVER_PORT   - Port for web server; --port overrides it (default: 8080)")
+	fmt.Println("  SERVER_HOST   - Address for the web server to bind to (default: all interfaces)")
+	fmt.Println("  CACHE_CLEAR_TOKEN - Bearer token required by the server's /api/cache/clear (disabled when unset)")
+	fmt.Println("  GITHUB_TOKEN  - GitHub API token used for --open-prs and repository size lookups")
+	fmt.Println("  GIT_TOKEN     - Token sent as HTTP basic auth when cloning private repositories")
+	fmt.Println("  GIT_TOKEN_HOSTS - Comma-separated hosts GIT_TOKEN is sent to (default: the host that issued a GitHub or GitLab token)")
+	fmt.Println("  GITLAB_TOKEN  - GitLab API token used for --open-prs")
+	fmt.Println("  GITLAB_HOSTS  - Comma-separated self-hosted GitLab hosts GITLAB_TOKEN is sent to besides gitlab.com")
+	fmt.Println("  OPM_VERSION   - OCP release whose opm is downloaded when opm is missing, or 'latest' (default: 4.17.21)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  # CLI Mode: Use default Prega index")
 	fmt.Println("  prega-operator-analyzer")
 	fmt.Println()
+	fmt.Println("  # CLI Mode: Take settings from a YAML file, overriding its days")
+	fmt.Println("  prega-operator-analyzer --config=analyzer.yaml --days=30")
+	fmt.Println()
 	fmt.Println("  # CLI Mode: Use custom Prega index")
 	fmt.Println("  prega-operator-analyzer --prega-index=quay.io/prega/prega-operator-index:v4.19.0")
 	fmt.Println()
+	fmt.Println("  # CLI Mode: Check that all repository URLs are reachable without analyzing them")
+	fmt.Println("  prega-operator-analyzer --check-urls")
+	fmt.Println()
 	fmt.Println("  # CLI Mode: Specify output file")
 	fmt.Println("  prega-operator-analyzer --output=my-release-notes.txt")
 	fmt.Println()
+	fmt.Println("  # CLI Mode: Write HTML, JSON and Markdown from a single run")
+	fmt.Println("  prega-operator-analyzer --output=notes.html,notes.json,notes.md")
+	fmt.Println()
+	fmt.Println("  # CLI Mode: Write the text release notes to stdout (logs go to stderr)")
+	fmt.Println("  prega-operator-analyzer --output=- > release-notes.txt")
+	fmt.Println()
+	fmt.Println("  # CLI Mode: Use a pre-supplied index and fail if it is missing (CI)")
+	fmt.Println("  prega-operator-analyzer --index-file=index.json --no-regenerate-index")
+	fmt.Println()
 	fmt.Println("  # CLI Mode: Enable verbose logging")
 	fmt.Println("  prega-operator-analyzer --verbose")
 	fmt.Println()
 	fmt.Println("  # CLI Mode: Use cursor-agent vibe-tools")
 	fmt.Println("  prega-operator-analyzer --cursor-agent")
 	fmt.Println()
+	fmt.Println("  # CLI Mode: Generate only the text release notes")
+	fmt.Println("  prega-operator-analyzer --no-html")
+	fmt.Println()
+	fmt.Println("  # CLI Mode: Compare a fork with its upstream")
+	fmt.Println("  prega-operator-analyzer --upstream=https://github.com/myorg/operator=https://github.com/upstream/operator")
+	fmt.Println()
+	fmt.Println("  # CLI Mode: Highlight commits touching RBAC, Dockerfiles and CI configs")
+	fmt.Println("  prega-operator-analyzer --notable-files='**/role.yaml,Dockerfile,.github/workflows/*'")
+	fmt.Println()
+	fmt.Println("  # CLI Mode: Skip repositories larger than 500 MB")
+	fmt.Println("  prega-operator-analyzer --max-repo-size=500")
+	fmt.Println()
 	fmt.Println("  # Web Server Mode: Start interactive web interface")
 	fmt.Println("  prega-operator-analyzer --server")
 	fmt.Println()
 	fmt.Println("  # Web Server Mode: Custom port")
 	fmt.Println("  prega-operator-analyzer --server --port=3000")
 	fmt.Println()
+	fmt.Println("  # Web Server Mode: Listen on localhost only (e.g. behind a local proxy)")
+	fmt.Println("  prega-operator-analyzer --server --bind=127.0.0.1")
+	fmt.Println()
 	fmt.Println("Docker Usage:")
 	fmt.Println("  # CLI Mode: Run with volume mounts")
 	fmt.Println("  podman run -v $(pwd)/output:/app/output:Z,rw \\")
@@ -265,8 +936,8 @@ func showHelp() {
 	fmt.Println("  - Rich HTML and plain text views")
 }
 
-// generateIndexJSON generates the index JSON file using opm render
-func generateIndexJSON(pregaIndex, outputPath string, logger *logrus.Logger) error {
+// generateIndexJSON generates the index JSON file using opm render, with the opm deps finds or downloads
+func generateIndexJSON(deps *pkg.DependencyManager, pregaIndex, outputPath string, logger *logrus.Logger) error {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -274,8 +945,7 @@ func generateIndexJSON(pregaIndex, outputPath string, logger *logrus.Logger) err
 	}
 
 	// Find or download opm
-	dm := pkg.NewDependencyManager(".bin", logger)
-	opmPath, err := dm.FindOrDownloadTool("opm")
+	opmPath, err := deps.FindOrDownloadTool("opm")
 	if err != nil {
 		return fmt.Errorf("opm command not found and could not be downloaded: %w", err)
 	}
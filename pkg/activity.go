@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// activityWeekdays labels the rows of a CommitActivity, Monday first
+var activityWeekdays = [7]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// activityHourBlock is the width in hours of each column of the rendered heatmap
+const activityHourBlock = 4
+
+// activityBarWidth is the length of the longest ASCII bar in the text histogram
+const activityBarWidth = 30
+
+// CommitActivity counts a window's commits by day of the week and hour of the day, bucketed
+// in one time zone so commits from authors in different zones line up
+type CommitActivity struct {
+	Timezone string     `json:"timezone"`
+	Counts   [7][24]int `json:"counts"` // [weekday][hour], weekday 0 is Monday
+}
+
+// newCommitActivity builds the histogram of the commit dates in loc; a nil loc uses the local
+// time zone (TZ)
+func newCommitActivity(commits []CommitDetail, loc *time.Location) *CommitActivity {
+	if loc == nil {
+		loc = time.Local
+	}
+	activity := &CommitActivity{Timezone: loc.String()}
+	for _, commit := range commits {
+		when := commit.Date.In(loc)
+		weekday := (int(when.Weekday()) + 6) % 7 // Shift Sunday from first to last
+		activity.Counts[weekday][when.Hour()]++
+	}
+	return activity
+}
+
+// weekdayTotals returns the number of commits on each day of the week
+func (a *CommitActivity) weekdayTotals() [7]int {
+	var totals [7]int
+	for day, hours := range a.Counts {
+		for _, count := range hours {
+			totals[day] += count
+		}
+	}
+	return totals
+}
+
+// hourBlockTotals returns the number of commits in each activityHourBlock-hour block of the day
+func (a *CommitActivity) hourBlockTotals() [24 / activityHourBlock]int {
+	var totals [24 / activityHourBlock]int
+	for _, hours := range a.Counts {
+		for hour, count := range hours {
+			totals[hour/activityHourBlock] += count
+		}
+	}
+	return totals
+}
+
+// blockCount returns the number of commits on a weekday within one hour block
+func (a *CommitActivity) blockCount(day, block int) int {
+	count := 0
+	for hour := block * activityHourBlock; hour < (block+1)*activityHourBlock; hour++ {
+		count += a.Counts[day][hour]
+	}
+	return count
+}
+
+// hourBlockLabel names an hour block, e.g. "08-12"
+func hourBlockLabel(block int) string {
+	return fmt.Sprintf("%02d-%02d", block*activityHourBlock, (block+1)*activityHourBlock)
+}
+
+// activityBar returns an ASCII bar for count scaled so busiest fills activityBarWidth
+func activityBar(count, busiest int) string {
+	if count == 0 || busiest == 0 {
+		return ""
+	}
+	width := count * activityBarWidth / busiest
+	if width == 0 {
+		width = 1 // Keep single commits visible next to busy days
+	}
+	return strings.Repeat("#", width)
+}
+
+// FormatText renders the histogram as ASCII bars by day of the week and by time of day
+func (a *CommitActivity) FormatText() string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("=== COMMIT ACTIVITY (%s) ===\n", a.Timezone))
+
+	weekdays := a.weekdayTotals()
+	busiest := 0
+	for _, count := range weekdays {
+		busiest = max(busiest, count)
+	}
+	for day, count := range weekdays {
+		output.WriteString(fmt.Sprintf("%-5s %3d %s\n", activityWeekdays[day], count, activityBar(count, busiest)))
+	}
+	output.WriteString("\n")
+
+	blocks := a.hourBlockTotals()
+	busiest = 0
+	for _, count := range blocks {
+		busiest = max(busiest, count)
+	}
+	for block, count := range blocks {
+		output.WriteString(fmt.Sprintf("%-5s %3d %s\n", hourBlockLabel(block), count, activityBar(count, busiest)))
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// FormatHTML renders the histogram as a compact weekday by time-of-day table whose cells are
+// shaded by commit count; callers wrap it in their own section markup
+func (a *CommitActivity) FormatHTML() string {
+	busiest := 0
+	for day := range a.Counts {
+		for block := 0; block < 24/activityHourBlock; block++ {
+			busiest = max(busiest, a.blockCount(day, block))
+		}
+	}
+
+	var table strings.Builder
+	table.WriteString(fmt.Sprintf(`<table class="activity-table">
+<caption>Commits by weekday and hour (%s)</caption>
+<tr><th></th>`, html.EscapeString(a.Timezone)))
+	for block := 0; block < 24/activityHourBlock; block++ {
+		table.WriteString(fmt.Sprintf("<th>%s</th>", hourBlockLabel(block)))
+	}
+	table.WriteString("<th>Total</th></tr>")
+
+	weekdays := a.weekdayTotals()
+	for day := range a.Counts {
+		table.WriteString(fmt.Sprintf("\n<tr><th>%s</th>", activityWeekdays[day]))
+		for block := 0; block < 24/activityHourBlock; block++ {
+			count := a.blockCount(day, block)
+			if count == 0 {
+				table.WriteString("<td></td>")
+				continue
+			}
+			table.WriteString(fmt.Sprintf(`<td style="background: rgba(255, 107, 53, %.2f)">%d</td>`, 0.15+0.85*float64(count)/float64(busiest), count))
+		}
+		table.WriteString(fmt.Sprintf("<td class=\"activity-total\">%d</td></tr>", weekdays[day]))
+	}
+	table.WriteString("\n</table>")
+	return table.String()
+}
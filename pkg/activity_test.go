@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewCommitActivity(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	commits := []CommitDetail{
+		// Sunday 23:30 UTC is Monday 00:30 in CET
+		{Hash: "a", Date: time.Date(2024, 3, 3, 23, 30, 0, 0, time.UTC)},
+		{Hash: "b", Date: time.Date(2024, 3, 4, 9, 0, 0, 0, berlin)},
+		{Hash: "c", Date: time.Date(2024, 3, 4, 10, 15, 0, 0, berlin)},
+		{Hash: "d", Date: time.Date(2024, 3, 10, 18, 0, 0, 0, berlin)},
+	}
+
+	activity := newCommitActivity(commits, berlin)
+	if activity.Timezone != "CET" {
+		t.Errorf("Expected timezone CET, got %s", activity.Timezone)
+	}
+
+	tests := []struct {
+		name     string
+		day      int
+		hour     int
+		expected int
+	}{
+		{name: "shifted into Monday", day: 0, hour: 0, expected: 1},
+		{name: "Monday morning", day: 0, hour: 9, expected: 1},
+		{name: "Sunday evening", day: 6, hour: 18, expected: 1},
+		{name: "Sunday late UTC", day: 6, hour: 23, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := activity.Counts[tt.day][tt.hour]; got != tt.expected {
+				t.Errorf("Expected %d commits, got %d", tt.expected, got)
+			}
+		})
+	}
+
+	if weekdays := activity.weekdayTotals(); weekdays[0] != 3 || weekdays[6] != 1 {
+		t.Errorf("Unexpected weekday totals: %v", weekdays)
+	}
+	if blocks := activity.hourBlockTotals(); blocks[0] != 1 || blocks[2] != 2 || blocks[4] != 1 {
+		t.Errorf("Unexpected hour block totals: %v", blocks)
+	}
+}
+
+func TestCommitActivityFormatting(t *testing.T) {
+	activity := newCommitActivity([]CommitDetail{
+		{Date: time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)},
+	}, time.UTC)
+
+	text := activity.FormatText()
+	for _, expected := range []string{
+		"=== COMMIT ACTIVITY (UTC) ===",
+		"Mon     2 " + strings.Repeat("#", activityBarWidth) + "\n",
+		"Tue     1 " + strings.Repeat("#", activityBarWidth/2) + "\n",
+		"Wed     0 \n",
+		"08-12   2 ",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected text histogram to contain %q, got:\n%s", expected, text)
+		}
+	}
+
+	table := activity.FormatHTML()
+	if !strings.Contains(table, "<th>Mon</th>") || !strings.Contains(table, `rgba(255, 107, 53, 1.00)">2</td>`) {
+		t.Errorf("Expected the busiest cell to be fully shaded, got:\n%s", table)
+	}
+}
@@ -0,0 +1,246 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// churnOptions controls which changes count towards a commit's changed lines. Files with an
+// excluded extension are dropped first; ignoreWhitespace then applies to the remaining files.
+type churnOptions struct {
+	ignoreWhitespace  bool
+	excludeExtensions []string // Lowercase suffixes with a leading dot, e.g. ".svg" or ".min.js"
+}
+
+// newChurnOptions builds churn options, accepting extensions as "svg", ".svg" or "*.svg"
+func newChurnOptions(ignoreWhitespace bool, excludeExtensions []string) churnOptions {
+	opts := churnOptions{ignoreWhitespace: ignoreWhitespace}
+	for _, ext := range excludeExtensions {
+		ext = strings.ToLower(strings.TrimLeft(strings.TrimSpace(ext), "*."))
+		if ext != "" {
+			opts.excludeExtensions = append(opts.excludeExtensions, "."+ext)
+		}
+	}
+	return opts
+}
+
+// excluded reports whether a file's lines are left out of the changed line count
+func (o churnOptions) excluded(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range o.excludeExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// commitChanges returns the number of lines changed and the files touched by a commit.
+// Excluded files are still listed but their lines are not counted, and with
+// ignoreWhitespace lines that differ only in whitespace are not counted either.
+// Some commits with very large diffs can cause panics in the diff library, so a panic
+// is recovered and reported as an error.
+func commitChanges(c *object.Commit, opts churnOptions) (lines int, files []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			lines, files = 0, nil
+			err = fmt.Errorf("panic recovered: %v", r)
+		}
+	}()
+
+	if opts.ignoreWhitespace {
+		return whitespaceInsensitiveChanges(c, opts)
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, stat := range stats {
+		if !opts.excluded(stat.Name) {
+			lines += stat.Addition + stat.Deletion
+		}
+		files = append(files, stat.Name)
+	}
+	return lines, files, nil
+}
+
+// DefaultStatsWorkers is the number of commits whose changes are computed concurrently
+// within one repository when no worker count is configured
+const DefaultStatsWorkers = 2
+
+// commitStats is the result of commitChanges for one commit
+type commitStats struct {
+	lines int
+	files []string
+	err   error
+}
+
+// computeCommitChanges runs commitChanges for every commit using up to workers goroutines
+// and returns the results in the order of the commits. go-git repositories are not safe
+// for concurrent use, so each worker opens its own handle on repoPath and loads the
+// commits from it; with a single worker the given commit objects are used directly.
+func computeCommitChanges(repoPath string, commits []*object.Commit, opts churnOptions, workers int) []commitStats {
+	results := make([]commitStats, len(commits))
+	if workers > len(commits) {
+		workers = len(commits)
+	}
+	if workers <= 1 {
+		for i, c := range commits {
+			results[i].lines, results[i].files, results[i].err = commitChanges(c, opts)
+		}
+		return results
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo, openErr := git.PlainOpen(repoPath)
+			for i := range jobs {
+				if openErr != nil {
+					results[i].err = openErr
+					continue
+				}
+				c, err := repo.CommitObject(commits[i].Hash)
+				if err != nil {
+					results[i].err = err
+					continue
+				}
+				results[i].lines, results[i].files, results[i].err = commitChanges(c, opts)
+			}
+		}()
+	}
+	for i := range commits {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// whitespaceInsensitiveChanges diffs each changed file against the commit's first parent
+// after stripping whitespace from every line, so a pure reformatting commit counts as
+// (nearly) no change. Binary files are listed but not counted, matching Stats().
+func whitespaceInsensitiveChanges(c *object.Commit, opts churnOptions) (int, []string, error) {
+	toTree, err := c.Tree()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var fromTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return 0, nil, err
+		}
+		if fromTree, err = parent.Tree(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lines := 0
+	var files []string
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+		if opts.excluded(name) {
+			continue
+		}
+
+		from, to, err := change.Files()
+		if err != nil {
+			return 0, nil, err
+		}
+		fromContent, fromBinary, err := normalizedContent(from)
+		if err != nil {
+			return 0, nil, err
+		}
+		toContent, toBinary, err := normalizedContent(to)
+		if err != nil {
+			return 0, nil, err
+		}
+		if fromBinary || toBinary {
+			continue
+		}
+
+		for _, d := range diff.Do(fromContent, toContent) {
+			if d.Type != diffmatchpatch.DiffEqual {
+				lines += strings.Count(d.Text, "\n")
+			}
+		}
+	}
+	return lines, files, nil
+}
+
+// normalizedContent returns the file's lines with all whitespace removed and blank lines
+// dropped. A nil file (added or deleted side of a change) has empty content.
+func normalizedContent(file *object.File) (string, bool, error) {
+	if file == nil {
+		return "", false, nil
+	}
+	if binary, err := file.IsBinary(); err != nil || binary {
+		return "", binary, err
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return "", false, err
+	}
+
+	var normalized strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		if stripped := strings.Join(strings.Fields(line), ""); stripped != "" {
+			normalized.WriteString(stripped)
+			normalized.WriteString("\n")
+		}
+	}
+	return normalized.String(), false, nil
+}
+
+// ParseAsOfDate parses an --as-of date (YYYY-MM-DD) into the end of that day in loc, so the
+// whole day is part of the analysis window. The date must not be in the future.
+func ParseAsOfDate(value string, now time.Time) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", value)
+	}
+	if date.After(now) {
+		return time.Time{}, fmt.Errorf("date %s is in the future", value)
+	}
+	return date.AddDate(0, 0, 1).Add(-time.Second), nil
+}
+
+// latestCommitAsOf returns the newest commit reachable from head that was committed at or
+// before end
+func latestCommitAsOf(repo *git.Repository, head plumbing.Hash, end time.Time) (*object.Commit, error) {
+	commits, err := logCommits(repo, head, nil, &end)
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+	commit, err := commits.Next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("no commits before %s", end.Format("2006-01-02"))
+	}
+	return commit, err
+}
@@ -0,0 +1,195 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCommitChangesIgnoreWhitespace(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	commitFile(t, repo, dir, "main.go", "func main() {\nfmt.Println(\"hi\")\n}\n")
+	reformat := commitFile(t, repo, dir, "main.go", "func main() {\n\tfmt.Println(\"hi\")\n}\n\n")
+	change := commitFile(t, repo, dir, "main.go", "func main() {\n\tfmt.Println(\"hello\")\n}\n\n")
+
+	tests := []struct {
+		name             string
+		commit           *object.Commit
+		ignoreWhitespace bool
+		expectedLines    int
+	}{
+		{name: "reformat counted by Stats", commit: reformat, ignoreWhitespace: false, expectedLines: 3},
+		{name: "reformat ignored", commit: reformat, ignoreWhitespace: true, expectedLines: 0},
+		{name: "real change counted", commit: change, ignoreWhitespace: true, expectedLines: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, files, err := commitChanges(tt.commit, churnOptions{ignoreWhitespace: tt.ignoreWhitespace})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if lines != tt.expectedLines {
+				t.Errorf("Expected %d changed lines, got %d", tt.expectedLines, lines)
+			}
+			if len(files) != 1 || files[0] != "main.go" {
+				t.Errorf("Expected files [main.go], got %v", files)
+			}
+		})
+	}
+}
+
+func TestComputeCommitChangesParallel(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	var commits []*object.Commit
+	content := ""
+	for i := 0; i < 6; i++ {
+		content += "line\n"
+		commits = append(commits, commitFile(t, repo, dir, "file.txt", content))
+	}
+
+	serial := computeCommitChanges(dir, commits, churnOptions{}, 1)
+	parallel := computeCommitChanges(dir, commits, churnOptions{}, 4)
+	if len(parallel) != len(commits) {
+		t.Fatalf("Expected %d results, got %d", len(commits), len(parallel))
+	}
+	for i := range commits {
+		if parallel[i].err != nil {
+			t.Fatalf("Unexpected error for commit %d: %v", i, parallel[i].err)
+		}
+		if parallel[i].lines != serial[i].lines || len(parallel[i].files) != len(serial[i].files) {
+			t.Errorf("Commit %d: parallel result %+v differs from serial %+v", i, parallel[i], serial[i])
+		}
+	}
+	// Each commit after the first appends one line
+	if parallel[5].lines != 1 {
+		t.Errorf("Expected 1 changed line in the last commit, got %d", parallel[5].lines)
+	}
+
+	failed := computeCommitChanges(filepath.Join(dir, "missing"), commits, churnOptions{}, 2)
+	for i, result := range failed {
+		if result.err == nil {
+			t.Errorf("Expected an error for commit %d when the repository cannot be opened", i)
+		}
+	}
+}
+
+func TestCommitChangesExcludeExtensions(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	commitFile(t, repo, dir, "main.go", "package main\n")
+	commit := commitFile(t, repo, dir, "logo.SVG", "<svg>\n<path/>\n</svg>\n")
+
+	tests := []struct {
+		name          string
+		opts          churnOptions
+		expectedLines int
+	}{
+		{name: "counted by default", opts: newChurnOptions(false, nil), expectedLines: 3},
+		{name: "excluded with glob", opts: newChurnOptions(false, []string{"*.svg"}), expectedLines: 0},
+		{name: "excluded with bare extension", opts: newChurnOptions(false, []string{"svg"}), expectedLines: 0},
+		{name: "excluded with ignore whitespace", opts: newChurnOptions(true, []string{".svg"}), expectedLines: 0},
+		{name: "other extension counted", opts: newChurnOptions(false, []string{"min.js"}), expectedLines: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, files, err := commitChanges(commit, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if lines != tt.expectedLines {
+				t.Errorf("Expected %d changed lines, got %d", tt.expectedLines, lines)
+			}
+			// Excluded files are still reported as touched
+			if len(files) != 1 || files[0] != "logo.SVG" {
+				t.Errorf("Expected files [logo.SVG], got %v", files)
+			}
+		})
+	}
+}
+
+func TestParseAsOfDate(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{value: "2024-03-01", expected: time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)},
+		{value: "2024-06-15", expected: time.Date(2024, 6, 15, 23, 59, 59, 0, time.UTC)},
+		{value: "2024-06-16", wantErr: true},
+		{value: "03/01/2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAsOfDate(tt.value, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAsOfDate(%q): expected an error", tt.value)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.expected) {
+			t.Errorf("ParseAsOfDate(%q) = %v, %v; expected %v", tt.value, got, err, tt.expected)
+		}
+	}
+}
+
+func TestReleaseNotesAsOf(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	for i, when := range []time.Time{
+		time.Date(2024, 2, 20, 10, 0, 0, 0, time.Local),
+		time.Date(2024, 2, 27, 10, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local),
+	} {
+		name := fmt.Sprintf("file%d.txt", i)
+		commitFile(t, repo, dir, name, name, withMessage("commit on "+when.Format("2006-01-02")), withDate(when))
+	}
+
+	asOf, err := ParseAsOfDate("2024-03-01", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.AsOf = asOf
+	notes, err := vtm.generateBasicReleaseNotes(context.Background(), dir, "https://github.com/test/repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	format := notes.Format
+	if !format.AnalysisEnd.Equal(asOf) {
+		t.Errorf("Expected the analysis to end at %v, got %v", asOf, format.AnalysisEnd)
+	}
+	if len(format.Commits) != 2 {
+		t.Fatalf("Expected the 2 commits of the week before the as-of date, got %+v", format.Commits)
+	}
+	if format.LatestCommit.Message != "commit on 2024-03-01" {
+		t.Errorf("Expected the latest commit as of the date, got %q", format.LatestCommit.Message)
+	}
+}
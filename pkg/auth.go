@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Environment variables holding the credentials sent when cloning over HTTP(S) and the
+// comma-separated hosts they are sent to
+const (
+	GitTokenEnv      = "GIT_TOKEN"
+	GitUsernameEnv   = "GIT_USERNAME"
+	GitTokenHostsEnv = "GIT_TOKEN_HOSTS"
+)
+
+// tokenHostPrefixes maps the prefixes of provider-issued tokens to the host that issued them,
+// the hosts GIT_TOKEN is sent to when GIT_TOKEN_HOSTS is not set
+var tokenHostPrefixes = []struct {
+	prefix string
+	host   string
+}{
+	{"github_pat_", "github.com"},
+	{"ghp_", "github.com"},
+	{"gho_", "github.com"},
+	{"ghu_", "github.com"},
+	{"ghs_", "github.com"},
+	{"glpat-", "gitlab.com"},
+}
+
+// GitCredentials are basic auth credentials for HTTP(S) clones together with the hosts they
+// may be sent to, so a repository URL on any other host never receives them
+type GitCredentials struct {
+	Auth  *githttp.BasicAuth
+	Hosts []string // Compared case-insensitively with the repository's host name
+}
+
+// GitTokenHeader carries a request's own access token to the server's GET endpoints, which
+// have no body to hold it
+const GitTokenHeader = "X-Git-Token"
+
+// defaultGitUsername is sent with a token when no user name is configured; GitHub and GitLab
+// accept any non-empty user name together with a personal access token
+const defaultGitUsername = "git"
+
+// GitCredentialsFromEnv returns the clone credentials set in GIT_TOKEN and GIT_USERNAME, or
+// nil when GIT_TOKEN is not set. They are sent to the hosts in GIT_TOKEN_HOSTS, or without it
+// to the host that issued the token when its prefix tells (github.com for ghp_..., gitlab.com
+// for glpat-...); a token of unknown origin without GIT_TOKEN_HOSTS is sent to no host.
+func GitCredentialsFromEnv() *GitCredentials {
+	auth := gitCredentials(os.Getenv(GitUsernameEnv), os.Getenv(GitTokenEnv))
+	if auth == nil {
+		return nil
+	}
+	var hosts []string
+	for _, host := range strings.Split(os.Getenv(GitTokenHostsEnv), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = tokenHosts(auth.Password)
+	}
+	return &GitCredentials{Auth: auth, Hosts: hosts}
+}
+
+// tokenHosts returns the host that issued a token, recognized by its prefix, or nil
+func tokenHosts(token string) []string {
+	for _, known := range tokenHostPrefixes {
+		if strings.HasPrefix(token, known.prefix) {
+			return []string{known.host}
+		}
+	}
+	return nil
+}
+
+// gitCredentials returns basic auth credentials for a token, or nil without a token. The
+// password is masked when the credentials are printed, so they are safe to log.
+func gitCredentials(username, token string) *githttp.BasicAuth {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil
+	}
+	if username = strings.TrimSpace(username); username == "" {
+		username = defaultGitUsername
+	}
+	return &githttp.BasicAuth{Username: username, Password: token}
+}
+
+// cloneAuth returns the credentials to send to repoURL. Only HTTP(S) remotes on one of the
+// credentials' hosts take basic auth, so SSH, local and bundle repositories and every other
+// host get none.
+func cloneAuth(repoURL string, credentials *GitCredentials) transport.AuthMethod {
+	if credentials == nil || credentials.Auth == nil {
+		return nil
+	}
+	parsed, err := url.Parse(repoURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil
+	}
+	host := repositoryHost(repoURL)
+	for _, allowed := range credentials.Hosts {
+		if host != "" && strings.EqualFold(strings.TrimSpace(allowed), host) {
+			return credentials.Auth
+		}
+	}
+	return nil
+}
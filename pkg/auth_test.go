@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitCredentials(t *testing.T) {
+	if credentials := gitCredentials("someone", " "); credentials != nil {
+		t.Errorf("Expected no credentials without a token, got %v", credentials)
+	}
+	credentials := gitCredentials("", "s3cret")
+	if credentials == nil || credentials.Username != defaultGitUsername || credentials.Password != "s3cret" {
+		t.Fatalf("Expected the token with the default user name, got %+v", credentials)
+	}
+	if strings.Contains(credentials.String(), "s3cret") {
+		t.Errorf("Expected the token to be masked when printed, got %q", credentials.String())
+	}
+
+	t.Setenv(GitTokenEnv, "env-token")
+	t.Setenv(GitUsernameEnv, "robot")
+	t.Setenv(GitTokenHostsEnv, "github.com, git.example.com")
+	fromEnv := GitCredentialsFromEnv()
+	if fromEnv == nil || fromEnv.Auth.Username != "robot" || fromEnv.Auth.Password != "env-token" || strings.Join(fromEnv.Hosts, ",") != "github.com,git.example.com" {
+		t.Fatalf("Expected the credentials from the environment, got %+v", fromEnv)
+	}
+
+	for repoURL, expected := range map[string]bool{
+		"https://github.com/example/private-operator": true,
+		"http://git.example.com/team/operator.git":    true,
+		"https://GitHub.com/example/private-operator": true,
+		"https://attacker.example/collect":            false,
+		"https://github.com.attacker.example/x/y":     false,
+		"git@github.com:example/private-operator.git": false,
+		"/srv/git/operator":                           false,
+		"operator.bundle":                             false,
+	} {
+		if auth := cloneAuth(repoURL, fromEnv); (auth != nil) != expected {
+			t.Errorf("%s: expected credentials to be sent: %v", repoURL, expected)
+		}
+	}
+	if auth := cloneAuth("https://github.com/example/operator", nil); auth != nil {
+		t.Errorf("Expected no auth method without credentials, got %v", auth)
+	}
+}
+
+func TestGitCredentialsDefaultHosts(t *testing.T) {
+	t.Setenv(GitTokenHostsEnv, "")
+	for token, expected := range map[string]string{
+		"ghp_abc":          "github.com",
+		"github_pat_abc":   "github.com",
+		"glpat-abc":        "gitlab.com",
+		"some-other-token": "",
+	} {
+		t.Setenv(GitTokenEnv, token)
+		credentials := GitCredentialsFromEnv()
+		if got := strings.Join(credentials.Hosts, ","); got != expected {
+			t.Errorf("Token %s: expected hosts %q, got %q", token, expected, got)
+		}
+	}
+
+	// A token of unknown origin goes nowhere until GIT_TOKEN_HOSTS names its host
+	if auth := cloneAuth("https://git.example.com/team/operator", GitCredentialsFromEnv()); auth != nil {
+		t.Errorf("Expected no credentials for an unlisted host, got %v", auth)
+	}
+}
+
+func TestCloneRepositorySendsToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); ok {
+			authorization = password
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	var logs bytes.Buffer
+	vtm.Logger.SetOutput(&logs)
+	vtm.Credentials = &GitCredentials{Auth: gitCredentials("", "s3cret-token"), Hosts: []string{"127.0.0.1"}}
+
+	err := vtm.cloneRepository(context.Background(), server.URL+"/org/private", filepath.Join(vtm.WorkDir, "private"))
+	if authorization != "s3cret-token" {
+		t.Errorf("Expected the token to be sent as the basic auth password, got %q", authorization)
+	}
+	if err == nil || !strings.Contains(err.Error(), "authentication required") {
+		t.Fatalf("Expected an authentication required error, got %v", err)
+	}
+	if strings.Contains(ErrorWithRemediation(err), "s3cret-token") || strings.Contains(logs.String(), "s3cret-token") {
+		t.Errorf("Expected the token to stay out of errors and logs")
+	}
+}
+
+func TestServerRequestCredentials(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Credentials = &GitCredentials{Auth: gitCredentials("robot", "server-token"), Hosts: []string{"github.com"}}
+
+	if credentials := s.requestCredentials("", "https://github.com/example/operator"); credentials != s.Credentials {
+		t.Errorf("Expected the server's credentials without a request token, got %+v", credentials)
+	}
+	credentials := s.requestCredentials("request-token", "https://git.example.com/team/operator")
+	if credentials.Auth.Username != "robot" || credentials.Auth.Password != "request-token" {
+		t.Errorf("Expected the request token with the server's user name, got %+v", credentials)
+	}
+	if cloneAuth("https://git.example.com/team/other", credentials) == nil || cloneAuth("https://attacker.example/team/operator", credentials) != nil {
+		t.Errorf("Expected the request token to be sent to its repository's host only")
+	}
+
+	s.Credentials = nil
+	if credentials := s.requestCredentials("request-token", "https://github.com/example/operator"); credentials.Auth.Username != defaultGitUsername {
+		t.Errorf("Expected the default user name, got %+v", credentials)
+	}
+}
+
+func TestForeignHostGetsNoCredentials(t *testing.T) {
+	var authorized bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); ok {
+			authorized = true
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+	s.Credentials = &GitCredentials{Auth: gitCredentials("robot", "server-token"), Hosts: []string{"github.com"}}
+
+	repoURL := server.URL + "/attacker/collect"
+	entry := s.lockCachedRepository(repoURL)
+	defer entry.mu.Unlock()
+	if _, err := s.syncCachedRepository(context.Background(), entry, repoURL, false); err == nil {
+		t.Fatalf("Expected the clone to fail")
+	}
+	if authorized {
+		t.Errorf("Expected no credentials to be sent to a host outside GIT_TOKEN_HOSTS")
+	}
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// normalizeIdentity folds an author name or email so "Jane  Doe" and "jane doe", or
+// "Jane@Example.com" and "jane@example.com", are the same person
+func normalizeIdentity(identity string) string {
+	return strings.ToLower(strings.Join(strings.Fields(identity), " "))
+}
+
+// authorMatcher matches commits against a list of author names or emails
+type authorMatcher map[string]bool
+
+// newAuthorMatcher builds a matcher for the given identities; it is nil when none are given
+func newAuthorMatcher(authors []string) authorMatcher {
+	var matcher authorMatcher
+	for _, author := range authors {
+		if identity := normalizeIdentity(author); identity != "" {
+			if matcher == nil {
+				matcher = make(authorMatcher)
+			}
+			matcher[identity] = true
+		}
+	}
+	return matcher
+}
+
+// matches reports whether the commit's author name or email is one of the identities
+func (m authorMatcher) matches(c *object.Commit) bool {
+	return m[normalizeIdentity(c.Author.Name)] || m[normalizeIdentity(c.Author.Email)]
+}
+
+// filterCommitsByAuthor keeps only the commits by one of the authors. With no authors every
+// commit is kept, so the filter is a no-op unless --only-authors is given.
+func filterCommitsByAuthor(commits []*object.Commit, authors []string) []*object.Commit {
+	matcher := newAuthorMatcher(authors)
+	if matcher == nil {
+		return commits
+	}
+	var filtered []*object.Commit
+	for _, c := range commits {
+		if matcher.matches(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// authorFilterNote describes the author filter in the notes header
+func authorFilterNote(authors []string) string {
+	return "only commits by " + strings.Join(authors, ", ")
+}
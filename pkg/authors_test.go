@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestFilterCommitsByAuthor(t *testing.T) {
+	commit := func(name, email string) *object.Commit {
+		return &object.Commit{Author: object.Signature{Name: name, Email: email}}
+	}
+	commits := []*object.Commit{
+		commit("Jane Doe", "jane@example.com"),
+		commit("John Smith", "John.Smith@Example.com"),
+		commit("jane  doe", "jdoe@users.noreply.github.com"),
+		commit("Bot", "bot@example.com"),
+	}
+
+	tests := []struct {
+		name     string
+		authors  []string
+		expected int
+	}{
+		{name: "no filter", authors: nil, expected: 4},
+		{name: "blank filter", authors: []string{" "}, expected: 4},
+		{name: "by name", authors: []string{"JANE DOE"}, expected: 2},
+		{name: "by email", authors: []string{"john.smith@example.com"}, expected: 1},
+		{name: "several authors", authors: []string{"Jane Doe", "bot@example.com"}, expected: 3},
+		{name: "unknown author", authors: []string{"nobody"}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterCommitsByAuthor(commits, tt.authors); len(got) != tt.expected {
+				t.Errorf("Expected %d commits, got %d", tt.expected, len(got))
+			}
+		})
+	}
+}
+
+func TestFormatReleaseNoteAuthorFilter(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	until := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	format := formatter.CreateStandardFormat("https://github.com/test/repo", until.AddDate(0, 0, -7), until,
+		CommitInfo{Hash: "a1b2c3d4", Message: "Add feature", Author: "Jane Doe", Date: until},
+		WeeklySummary{TotalCommits: 1, TotalLinesChanged: 3, ActiveContributors: 1}, nil, nil)
+
+	if strings.Contains(formatter.FormatReleaseNote(format), "Author Filter:") {
+		t.Errorf("Expected no author filter line for unfiltered notes")
+	}
+	format.OnlyAuthors = []string{"Jane Doe", "john@example.com"}
+	if result := formatter.FormatReleaseNote(format); !strings.Contains(result, "Author Filter: only commits by Jane Doe, john@example.com") {
+		t.Errorf("Expected the author filter in the header:\n%s", result)
+	}
+}
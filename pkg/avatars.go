@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// githubNoreplyPattern matches GitHub's private commit addresses, either
+// "user@users.noreply.github.com" or "12345+user@users.noreply.github.com"
+var githubNoreplyPattern = regexp.MustCompile(`^(?:\d+\+)?([A-Za-z0-9-]+)@users\.noreply\.github\.com$`)
+
+// githubLoginCache remembers the account behind each commit email so an author is looked up once per run
+var githubLoginCache = struct {
+	sync.Mutex
+	entries map[string]string
+}{entries: make(map[string]string)}
+
+// githubAvatarURL returns the avatar image for a GitHub account
+func githubAvatarURL(login string) string {
+	return fmt.Sprintf("https://github.com/%s.png?size=40", login)
+}
+
+// contributorAvatarHTML returns a small avatar image for a contributor, or nothing when
+// no avatar was resolved
+func contributorAvatarHTML(c Contributor) string {
+	if c.AvatarURL == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<img class="avatar" src="%s" alt="" width="24" height="24" loading="lazy">`, html.EscapeString(c.AvatarURL))
+}
+
+// githubLoginFromEmail extracts the username from a GitHub noreply commit address
+func githubLoginFromEmail(email string) (string, bool) {
+	match := githubNoreplyPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(email)))
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// resolveContributorAvatars sets AvatarURL on contributors of GitHub-hosted repositories.
+// Noreply addresses give the username directly; other authors are looked up through the
+// commits API using their latest commit. Contributors whose account cannot be determined
+// are left without an avatar.
+func resolveContributorAvatars(repoURL string, contributors []Contributor) {
+	owner, name, ok := parseGitHubRepo(repoURL)
+	if !ok {
+		return
+	}
+
+	for i := range contributors {
+		login, ok := githubLoginForContributor(owner, name, contributors[i])
+		if ok {
+			contributors[i].AvatarURL = githubAvatarURL(login)
+		}
+	}
+}
+
+// githubLoginForContributor finds the GitHub account for a contributor, caching the result
+// (including misses) by email
+func githubLoginForContributor(owner, name string, contributor Contributor) (string, bool) {
+	if login, ok := githubLoginFromEmail(contributor.Email); ok {
+		return login, true
+	}
+	if contributor.Email == "" || contributor.latestCommit == "" {
+		return "", false
+	}
+
+	githubLoginCache.Lock()
+	login, cached := githubLoginCache.entries[contributor.Email]
+	githubLoginCache.Unlock()
+	if !cached {
+		// Failed lookups are cached too so a rate-limited API isn't asked again for every repository
+		login, _ = fetchGitHubCommitAuthorLogin(owner, name, contributor.latestCommit)
+		githubLoginCache.Lock()
+		githubLoginCache.entries[contributor.Email] = login
+		githubLoginCache.Unlock()
+	}
+	return login, login != ""
+}
+
+// fetchGitHubCommitAuthorLogin returns the login of the GitHub account linked to a commit's author
+func fetchGitHubCommitAuthorLogin(owner, name, sha string) (string, error) {
+	req, err := newGitHubRequest(fmt.Sprintf("/repos/%s/%s/commits/%s", owner, name, sha))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := providerHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub API returned HTTP %d", resp.StatusCode)
+	}
+
+	var commit struct {
+		Author *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&commit); err != nil {
+		return "", fmt.Errorf("failed to decode GitHub API response: %w", err)
+	}
+	if commit.Author == nil || commit.Author.Login == "" {
+		return "", fmt.Errorf("commit %s is not linked to a GitHub account", sha)
+	}
+	return commit.Author.Login, nil
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGitHubLoginFromEmail(t *testing.T) {
+	tests := []struct {
+		email         string
+		expectedLogin string
+		expectedOK    bool
+	}{
+		{email: "octocat@users.noreply.github.com", expectedLogin: "octocat", expectedOK: true},
+		{email: "583231+octocat@users.noreply.github.com", expectedLogin: "octocat", expectedOK: true},
+		{email: "Jane-Doe@Users.Noreply.GitHub.com", expectedLogin: "jane-doe", expectedOK: true},
+		{email: "jane@example.com", expectedOK: false},
+		{email: "", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			login, ok := githubLoginFromEmail(tt.email)
+			if ok != tt.expectedOK || login != tt.expectedLogin {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.expectedLogin, tt.expectedOK, login, ok)
+			}
+		})
+	}
+}
+
+func TestResolveContributorAvatars(t *testing.T) {
+	contributors := []Contributor{
+		{Name: "Octo Cat", Email: "583231+octocat@users.noreply.github.com"},
+		{Name: "No Commit", Email: "someone@example.com"},
+	}
+	resolveContributorAvatars("https://github.com/test/repo", contributors)
+	if contributors[0].AvatarURL != "https://github.com/octocat.png?size=40" {
+		t.Errorf("Unexpected avatar URL: %s", contributors[0].AvatarURL)
+	}
+	// Without a known commit the API is not consulted and no avatar is set
+	if contributors[1].AvatarURL != "" {
+		t.Errorf("Expected no avatar, got %s", contributors[1].AvatarURL)
+	}
+
+	gitlab := []Contributor{{Name: "Octo Cat", Email: "octocat@users.noreply.github.com"}}
+	resolveContributorAvatars("https://gitlab.com/group/project", gitlab)
+	if gitlab[0].AvatarURL != "" {
+		t.Errorf("Expected no avatar for a non-GitHub repository, got %s", gitlab[0].AvatarURL)
+	}
+}
+
+func TestContributorAvatarHTML(t *testing.T) {
+	if got := contributorAvatarHTML(Contributor{Name: "Alice"}); got != "" {
+		t.Errorf("Expected no image without an avatar, got %q", got)
+	}
+	got := contributorAvatarHTML(Contributor{Name: "Alice", AvatarURL: `https://github.com/alice.png?size=40&x="y"`})
+	if !strings.Contains(got, `src="https://github.com/alice.png?size=40&amp;x=&#34;y&#34;"`) {
+		t.Errorf("Expected an escaped avatar image, got %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// BranchNotes are the release notes of one branch of a repository analyzed with several
+// branches selected
+type BranchNotes struct {
+	Branch       string             `json:"branch"`
+	ReleaseNotes *ReleaseNoteFormat `json:"releaseNotes"`
+	Text         string             `json:"text"`
+}
+
+// branchTip is a branch of a clone and the commit it points at
+type branchTip struct {
+	Name string
+	Hash plumbing.Hash
+}
+
+// ValidateBranchPatterns checks that every branch pattern is a valid glob
+func ValidateBranchPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchBranches returns the branches of a clone matching the patterns (names or globs such as
+// "release-*"), in pattern order and by name within a pattern. A fresh clone only has its
+// default branch locally, so origin's remote-tracking branches are matched as well.
+func matchBranches(repo *git.Repository, patterns []string) ([]branchTip, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[string]plumbing.Hash)
+	remotePrefix := "refs/remotes/" + git.DefaultRemoteName + "/"
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		switch name := ref.Name().String(); {
+		case ref.Name().IsBranch():
+			tips[ref.Name().Short()] = ref.Hash()
+		case strings.HasPrefix(name, remotePrefix) && name != remotePrefix+"HEAD":
+			branch := strings.TrimPrefix(name, remotePrefix)
+			if _, ok := tips[branch]; !ok {
+				tips[branch] = ref.Hash()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tips))
+	for name := range tips {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var matched []branchTip
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); ok && !seen[name] {
+				seen[name] = true
+				matched = append(matched, branchTip{Name: name, Hash: tips[name]})
+			}
+		}
+	}
+	return matched, nil
+}
+
+// branchNames lists the branches the notes were generated for
+func branchNames(branches []BranchNotes) []string {
+	names := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		names = append(names, branch.Branch)
+	}
+	return names
+}
+
+// formatTextBranches renders a repository's text section with the notes of each branch in turn
+func formatTextBranches(repoURL string, branches []BranchNotes) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("=== %s: %d BRANCHES (%s) ===\n", repoURL, len(branches), strings.Join(branchNames(branches), ", ")))
+	for _, branch := range branches {
+		output.WriteString(branch.Text)
+	}
+	return output.String()
+}
+
+// formatHTMLRepoBranches formats a repository analyzed on several branches as one card with a
+// section per branch
+func (vtm *VibeToolsManager) formatHTMLRepoBranches(repoURL string, branches []BranchNotes) string {
+	var sections strings.Builder
+	for _, branch := range branches {
+		sections.WriteString(fmt.Sprintf(`
+                <div class="section branch-section">
+                    <h3>🌿 %s</h3>%s%s%s
+                    <div class="notes-text">%s</div>
+                </div>`, html.EscapeString(branch.Branch), formatHTMLHistoryRewrite(branch.ReleaseNotes),
+			formatHTMLStatsUnavailable(branch.ReleaseNotes), formatHTMLRepoBreakingChanges(branch.ReleaseNotes),
+			html.EscapeString(strings.TrimSpace(branch.Text))))
+	}
+	return fmt.Sprintf(`
+        <div class="repo-card">
+            <div class="repo-header">
+                <h2>📦 %s</h2>
+                <div class="repo-url">%s</div>
+            </div>
+            <div class="repo-body">%s
+            </div>
+        </div>
+`, html.EscapeString(vtm.extractRepoName(repoURL)), html.EscapeString(repoURL), sections.String())
+}
+
+// writeMarkdownBranches writes the notes of each branch under its own heading, one level below
+// the repository's
+func writeMarkdownBranches(output *strings.Builder, branches []BranchNotes, heading string) {
+	for _, branch := range branches {
+		output.WriteString(fmt.Sprintf("%s Branch `%s`\n\n", heading, branch.Branch))
+		writeMarkdownNotes(output, branch.ReleaseNotes, heading+"#")
+	}
+}
@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// branchedRepository creates a repository whose master branch has one commit and whose other
+// branches each add one commit on top of it
+func branchedRepository(t *testing.T, branches ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "base.txt", "Add base")
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	for _, branch := range branches {
+		if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}); err != nil {
+			t.Fatalf("Failed to check out master: %v", err)
+		}
+		if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true}); err != nil {
+			t.Fatalf("Failed to create %s: %v", branch, err)
+		}
+		commitFile(t, repo, dir, branch+".txt", "Fix for "+branch)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}); err != nil {
+		t.Fatalf("Failed to check out master: %v", err)
+	}
+	return dir
+}
+
+func TestMatchBranches(t *testing.T) {
+	sourceDir := branchedRepository(t, "release-4.15", "release-4.14", "feature-x")
+	// A clone only has master locally; the other branches are remote-tracking refs
+	clone, err := git.PlainClone(t.TempDir(), false, &git.CloneOptions{URL: sourceDir})
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{"default and release branches", []string{"master", "release-*"}, []string{"master", "release-4.14", "release-4.15"}},
+		{"pattern order is kept", []string{"release-4.15", "master"}, []string{"release-4.15", "master"}},
+		{"overlapping patterns list a branch once", []string{"release-*", "release-4.14"}, []string{"release-4.14", "release-4.15"}},
+		{"no match", []string{"stable-*"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tips, err := matchBranches(clone, tt.patterns)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, tip := range tips {
+				names = append(names, tip.Name)
+				if tip.Hash.IsZero() {
+					t.Errorf("Expected a commit for %s", tip.Name)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected branches %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestValidateBranchPatterns(t *testing.T) {
+	if err := ValidateBranchPatterns([]string{"main", "release-*", "release-4.1?"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := ValidateBranchPatterns([]string{"main", "release-["}); err == nil {
+		t.Error("Expected an error for a malformed glob")
+	}
+}
+
+func TestProcessRepositoriesBranches(t *testing.T) {
+	sourceDir := branchedRepository(t, "release-4.14", "release-4.15", "feature-x")
+
+	var stdout bytes.Buffer
+	markdownPath := filepath.Join(t.TempDir(), "notes.md")
+	vtm := NewVibeToolsManager(t.TempDir(), "", false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = &stdout
+	vtm.Outputs = []OutputTarget{
+		{Path: StdoutOutputPath, Format: OutputFormatText},
+		{Path: markdownPath, Format: OutputFormatMarkdown},
+	}
+	vtm.Branches = []string{"master", "release-*"}
+
+	results, err := vtm.ProcessRepositories(context.Background(), []string{sourceDir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Expected release notes, got %+v", results)
+	}
+	branches := results[0].Branches
+	if names := strings.Join(branchNames(branches), ","); names != "master,release-4.14,release-4.15" {
+		t.Fatalf("Expected master and the release branches, got %s", names)
+	}
+	if results[0].ReleaseNotes != branches[0].ReleaseNotes {
+		t.Error("Expected the repository's notes to be the first branch's")
+	}
+
+	// Each branch only lists its own commits
+	for _, branch := range branches[1:] {
+		messages := make([]string, 0, len(branch.ReleaseNotes.Commits))
+		for _, commit := range branch.ReleaseNotes.Commits {
+			messages = append(messages, commit.Message)
+		}
+		if strings.Join(messages, ",") != "Fix for "+branch.Branch+",Add base" {
+			t.Errorf("Unexpected commits on %s: %v", branch.Branch, messages)
+		}
+		if branch.ReleaseNotes.Branch != branch.Branch {
+			t.Errorf("Expected the notes of %s to name their branch, got %q", branch.Branch, branch.ReleaseNotes.Branch)
+		}
+	}
+
+	text := stdout.String()
+	for _, expected := range []string{"3 BRANCHES (master, release-4.14, release-4.15)", "Branch: release-4.14", "Branch: release-4.15", "- Fix for release-4.15"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in the text notes", expected)
+		}
+	}
+	if strings.Contains(text, "feature-x") {
+		t.Error("Expected unselected branches to be left out")
+	}
+
+	markdown, err := os.ReadFile(markdownPath)
+	if err != nil {
+		t.Fatalf("Failed to read Markdown notes: %v", err)
+	}
+	for _, heading := range []string{"### Branch `master`", "### Branch `release-4.14`", "### Branch `release-4.15`"} {
+		if !strings.Contains(string(markdown), heading) {
+			t.Errorf("Expected heading %q in the Markdown notes", heading)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package pkg
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+)
+
+// maxBranchSegmentLength bounds the path segment of a branch, well below the 255-byte file name
+// limit of common file systems so prefixes and extensions still fit
+const maxBranchSegmentLength = 80
+
+// BranchPath pairs a branch with the path segment it is stored under. Paths and file names use
+// Segment; cloning, logs and the notes use the real Branch.
+type BranchPath struct {
+	Branch  string
+	Segment string
+}
+
+// NewBranchPath maps a branch name to a single, safe path segment. Characters other than ASCII
+// letters, digits, '.', '_' and '-' become '-' (feature/foo becomes feature-foo) and leading dots
+// are replaced so the segment is never hidden or "..". A name that had to be changed or cut to
+// maxBranchSegmentLength gets a short hash of the real name appended, so distinct branches such
+// as feature/foo and feature-foo never share a segment.
+func NewBranchPath(branch string) BranchPath {
+	var segment strings.Builder
+	leading := true
+	for _, r := range branch {
+		switch {
+		case r == '.' && leading:
+			segment.WriteByte('-')
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			segment.WriteRune(r)
+			leading = false
+		default:
+			segment.WriteByte('-')
+			leading = false
+		}
+	}
+
+	safe := segment.String()
+	if safe == branch && safe != "" && len(safe) <= maxBranchSegmentLength {
+		return BranchPath{Branch: branch, Segment: safe}
+	}
+	hash := fmt.Sprintf("%x", sha1.Sum([]byte(branch)))[:8]
+	if safe == "" {
+		return BranchPath{Branch: branch, Segment: hash}
+	}
+	// The sanitized name is ASCII, so it can be cut at any byte
+	if limit := maxBranchSegmentLength - len(hash) - 1; len(safe) > limit {
+		safe = safe[:limit]
+	}
+	return BranchPath{Branch: branch, Segment: safe + "-" + hash}
+}
@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// hashSuffix matches the short hash appended to changed branch names
+var hashSuffix = regexp.MustCompile(`-[0-9a-f]{8}$`)
+
+func TestNewBranchPath(t *testing.T) {
+	long := "feature/" + strings.Repeat("very-long-branch-name-", 20)
+	tests := []struct {
+		name          string
+		branch        string
+		expected      string // Exact segment; empty when only the prefix is checked
+		expectPrefix  string
+		expectChanged bool
+	}{
+		{name: "plain branch is kept", branch: "release-4.14", expected: "release-4.14"},
+		{name: "slash", branch: "feature/foo", expectPrefix: "feature-foo-", expectChanged: true},
+		{name: "nested slashes", branch: "users/alice/fix/crash", expectPrefix: "users-alice-fix-crash-", expectChanged: true},
+		{name: "leading dots", branch: "../escape", expectPrefix: "---escape-", expectChanged: true},
+		{name: "dot dot", branch: "..", expectPrefix: "---", expectChanged: true},
+		{name: "non-ASCII", branch: "fix-ünïcode", expectPrefix: "fix--n-code-", expectChanged: true},
+		{name: "overlong", branch: long, expectPrefix: "feature-very-long-branch-name-", expectChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := NewBranchPath(tt.branch)
+			if path.Branch != tt.branch {
+				t.Errorf("Expected the real branch %q to be kept, got %q", tt.branch, path.Branch)
+			}
+			if tt.expected != "" && path.Segment != tt.expected {
+				t.Errorf("Expected segment %q, got %q", tt.expected, path.Segment)
+			}
+			if !strings.HasPrefix(path.Segment, tt.expectPrefix) {
+				t.Errorf("Expected segment starting with %q, got %q", tt.expectPrefix, path.Segment)
+			}
+			if tt.expectChanged && !hashSuffix.MatchString(path.Segment) {
+				t.Errorf("Expected a hash suffix on %q", path.Segment)
+			}
+			if len(path.Segment) > maxBranchSegmentLength {
+				t.Errorf("Expected at most %d bytes, got %d", maxBranchSegmentLength, len(path.Segment))
+			}
+			if strings.ContainsAny(path.Segment, `/\`) || path.Segment == "." || path.Segment == ".." || strings.HasPrefix(path.Segment, ".") {
+				t.Errorf("Segment %q is not a safe file name", path.Segment)
+			}
+
+			// The segment is usable as a directory name on its own
+			dir := filepath.Join(t.TempDir(), path.Segment)
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Errorf("Failed to create directory for %q: %v", path.Segment, err)
+			}
+		})
+	}
+}
+
+func TestNewBranchPathDistinct(t *testing.T) {
+	prefix := strings.Repeat("a", maxBranchSegmentLength)
+	pairs := [][2]string{
+		{"feature/foo", "feature-foo"},
+		{"feature/foo", "feature:foo"},
+		{prefix + "-one", prefix + "-two"},
+	}
+	for _, pair := range pairs {
+		first, second := NewBranchPath(pair[0]), NewBranchPath(pair[1])
+		if first.Segment == second.Segment {
+			t.Errorf("Branches %q and %q share the segment %q", pair[0], pair[1], first.Segment)
+		}
+	}
+	if NewBranchPath("feature/foo").Segment != NewBranchPath("feature/foo").Segment {
+		t.Error("Expected the same branch to always map to the same segment")
+	}
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// breakingHeaderPattern matches a conventional-commit subject marked breaking with "!",
+// e.g. "feat(api)!: drop v1alpha1"
+var breakingHeaderPattern = regexp.MustCompile(`^[A-Za-z]+(\([^)]*\))?!:\s*(.+)$`)
+
+// breakingFooterPattern matches a "BREAKING CHANGE:" (or "BREAKING-CHANGE:") footer
+var breakingFooterPattern = regexp.MustCompile(`^BREAKING[ -]CHANGE:\s*(.*)$`)
+
+// trailerPattern matches the start of another git trailer, which ends a footer's description
+var trailerPattern = regexp.MustCompile(`^[A-Za-z-]+(: | #)`)
+
+// BreakingChange is a commit that declares a breaking change
+type BreakingChange struct {
+	Commit      CommitDetail `json:"commit"`
+	Description string       `json:"description"`
+}
+
+// breakingChangeDescription extracts the breaking change declared by a full commit message. A
+// BREAKING CHANGE footer describes the change and may continue over the following lines; a
+// subject marked with "!" and no footer uses the subject's description.
+func breakingChangeDescription(message string) (string, bool) {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	for i := 1; i < len(lines); i++ {
+		match := breakingFooterPattern.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if match == nil {
+			continue
+		}
+		description := []string{match[1]}
+		for _, line := range lines[i+1:] {
+			line = strings.TrimSpace(line)
+			if line == "" || trailerPattern.MatchString(line) {
+				break
+			}
+			description = append(description, line)
+		}
+		if text := strings.TrimSpace(strings.Join(description, " ")); text != "" {
+			return text, true
+		}
+	}
+
+	if match := breakingHeaderPattern.FindStringSubmatch(strings.TrimSpace(lines[0])); match != nil {
+		return strings.TrimSpace(match[2]), true
+	}
+	return "", false
+}
+
+// formatTextBreakingChanges renders the breaking changes section of the text notes
+func formatTextBreakingChanges(changes []BreakingChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString("=== ⚠️ BREAKING CHANGES ===\n")
+	for _, change := range changes {
+		output.WriteString(fmt.Sprintf("- %s (%s) by %s\n", change.Description, change.Commit.Hash, change.Commit.Author))
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// formatHTMLBreakingChanges renders the breaking changes as a list; callers wrap it in their
+// own section markup
+func formatHTMLBreakingChanges(changes []BreakingChange) string {
+	var list strings.Builder
+	list.WriteString(`<ul class="breaking-list">`)
+	for _, change := range changes {
+		list.WriteString(fmt.Sprintf(`
+<li><span class="breaking-description">%s</span> <code class="commit-hash">%s</code> <span class="breaking-author">👤 %s</span></li>`,
+			html.EscapeString(change.Description), change.Commit.Hash, html.EscapeString(change.Commit.Author)))
+	}
+	list.WriteString("\n</ul>")
+	return list.String()
+}
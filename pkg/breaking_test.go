@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBreakingChangeDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		expected    string
+		notBreaking bool
+	}{
+		{
+			name:     "footer",
+			message:  "feat: add v1 API\n\nBREAKING CHANGE: the v1alpha1 CRD is no longer served\n",
+			expected: "the v1alpha1 CRD is no longer served",
+		},
+		{
+			name:     "hyphenated footer continues until the next trailer",
+			message:  "refactor: rename flags\n\nBREAKING-CHANGE: --foo is now\n--bar\nSigned-off-by: Jane <jane@example.com>",
+			expected: "--foo is now --bar",
+		},
+		{
+			name:     "bang subject",
+			message:  "feat(api)!: drop v1alpha1",
+			expected: "drop v1alpha1",
+		},
+		{
+			name:     "footer wins over bang subject",
+			message:  "fix!: change defaults\n\nBREAKING CHANGE: replicas default to 3",
+			expected: "replicas default to 3",
+		},
+		{
+			name:        "footer text in the subject is not a footer",
+			message:     "BREAKING CHANGE: not a footer",
+			notBreaking: true,
+		},
+		{
+			name:        "regular commit",
+			message:     "fix: handle empty index\n\nMentions breaking change in prose.",
+			notBreaking: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, ok := breakingChangeDescription(tt.message)
+			if ok == tt.notBreaking {
+				t.Fatalf("Expected breaking=%v, got %v (%q)", !tt.notBreaking, ok, description)
+			}
+			if description != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, description)
+			}
+		})
+	}
+}
+
+func TestFormatReleaseNoteBreakingChanges(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	format := ReleaseNoteFormat{RepositoryInfo: RepositoryInfo{URL: "https://github.com/test/repo"}}
+	if strings.Contains(formatter.FormatReleaseNote(format), "BREAKING CHANGES") {
+		t.Error("Expected no breaking changes section without breaking changes")
+	}
+
+	format.BreakingChanges = []BreakingChange{{
+		Commit:      CommitDetail{Hash: "a1b2c3d4", Author: "Alice"},
+		Description: "the v1alpha1 CRD is no longer served",
+	}}
+	output := formatter.FormatReleaseNote(format)
+	section := strings.Index(output, "=== ⚠️ BREAKING CHANGES ===\n- the v1alpha1 CRD is no longer served (a1b2c3d4) by Alice")
+	if section < 0 {
+		t.Fatalf("Expected a breaking changes section, got:\n%s", output)
+	}
+	if section > strings.Index(output, "=== LATEST COMMIT INFORMATION ===") {
+		t.Error("Expected the breaking changes before the latest commit")
+	}
+}
@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+)
+
+// bundleExtension marks a repository given as a local git bundle instead of a remote URL
+const bundleExtension = ".bundle"
+
+// IsBundlePath reports whether a repository is a local git bundle file (a path, or a file://
+// URL, ending in .bundle) that is analyzed without any network access
+func IsBundlePath(repo string) bool {
+	if strings.HasPrefix(repo, "http://") || strings.HasPrefix(repo, "https://") || strings.HasPrefix(repo, "git@") {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(repo), bundleExtension)
+}
+
+// bundleFilePath returns the file system path of a bundle repository
+func bundleFilePath(repo string) string {
+	return strings.TrimPrefix(repo, "file://")
+}
+
+// gitBundle is the parsed header of a git bundle; the packfile follows it in the reader
+type gitBundle struct {
+	refs          map[plumbing.ReferenceName]plumbing.Hash
+	prerequisites []plumbing.Hash
+}
+
+// readBundleHeader parses the header of a v2 or v3 git bundle, leaving r at the packfile
+func readBundleHeader(r *bufio.Reader) (*gitBundle, error) {
+	signature, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle signature: %w", err)
+	}
+	signature = strings.TrimSuffix(signature, "\n")
+	if signature != "# v2 git bundle" && signature != "# v3 git bundle" {
+		return nil, fmt.Errorf("not a git bundle (signature %q)", signature)
+	}
+
+	bundle := &gitBundle{refs: make(map[plumbing.ReferenceName]plumbing.Hash)}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("truncated bundle header: %w", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return bundle, nil
+		case strings.HasPrefix(line, "@"):
+			// v3 capabilities; only SHA-1 bundles without an object filter can be cloned
+			if line != "@object-format=sha1" {
+				return nil, fmt.Errorf("unsupported bundle capability %q", line)
+			}
+		case strings.HasPrefix(line, "-"):
+			hash, _, _ := strings.Cut(line[1:], " ")
+			bundle.prerequisites = append(bundle.prerequisites, plumbing.NewHash(hash))
+		default:
+			hash, name, found := strings.Cut(line, " ")
+			if !found || len(hash) != 40 {
+				return nil, fmt.Errorf("invalid bundle reference line %q", line)
+			}
+			bundle.refs[plumbing.ReferenceName(name)] = plumbing.NewHash(hash)
+		}
+	}
+}
+
+// branches returns the bundle's branches by short name. Bundles made with --all from a clone
+// only hold remote-tracking refs, which are used when the bundle has no local branch.
+func (b *gitBundle) branches() map[string]plumbing.Hash {
+	branches := make(map[string]plumbing.Hash)
+	for name, hash := range b.refs {
+		if name.IsBranch() {
+			branches[name.Short()] = hash
+		}
+	}
+	if len(branches) > 0 {
+		return branches
+	}
+	for name, hash := range b.refs {
+		if name.IsRemote() {
+			_, branch, _ := strings.Cut(strings.TrimPrefix(name.String(), "refs/remotes/"), "/")
+			if branch != "HEAD" {
+				branches[branch] = hash
+			}
+		}
+	}
+	return branches
+}
+
+// cloneFromBundle clones a local git bundle into repoPath the way `git clone <bundle>` does:
+// the packfile is indexed into a new repository, the bundle's branches become origin's
+// remote-tracking refs and the default branch is checked out
+func cloneFromBundle(bundleRepo, repoPath string) error {
+	file, err := os.Open(bundleFilePath(bundleRepo))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	bundle, err := readBundleHeader(reader)
+	if err != nil {
+		return err
+	}
+	if len(bundle.prerequisites) > 0 {
+		return fmt.Errorf("bundle is incremental (needs %d prerequisite commits) and cannot be cloned on its own", len(bundle.prerequisites))
+	}
+	branches := bundle.branches()
+	if len(branches) == 0 {
+		return fmt.Errorf("no branches found in bundle %s", bundleRepo)
+	}
+
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		return err
+	}
+	if err := packfile.UpdateObjectStorage(repo.Storer, io.Reader(reader)); err != nil {
+		return fmt.Errorf("failed to read bundle packfile: %w", err)
+	}
+	for name, hash := range bundle.refs {
+		if name.IsTag() {
+			if err := repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+				return err
+			}
+		}
+	}
+	return checkoutBranches(repo, bundleRepo, branches)
+}
@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+)
+
+// writeBundle writes a v2 bundle of the repository's branch, like `git bundle create <path> <branch>`
+func writeBundle(t *testing.T, repo *git.Repository, path string, branch plumbing.ReferenceName) {
+	t.Helper()
+	ref, err := repo.Reference(branch, true)
+	if err != nil {
+		t.Fatalf("Failed to resolve %s: %v", branch, err)
+	}
+	objects, err := revlist.Objects(repo.Storer, []plumbing.Hash{ref.Hash()}, nil)
+	if err != nil {
+		t.Fatalf("Failed to list objects: %v", err)
+	}
+
+	var bundle bytes.Buffer
+	fmt.Fprintf(&bundle, "# v2 git bundle\n%s %s\n\n", ref.Hash(), branch)
+	if _, err := packfile.NewEncoder(&bundle, repo.Storer, false).Encode(objects, 10); err != nil {
+		t.Fatalf("Failed to encode packfile: %v", err)
+	}
+	if err := os.WriteFile(path, bundle.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+}
+
+func TestCloneFromBundle(t *testing.T) {
+	sourceDir := t.TempDir()
+	source, err := git.PlainInit(sourceDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, source, sourceDir, "a.txt", "first commit")
+	commitFile(t, source, sourceDir, "b.txt", "second commit")
+
+	bundlePath := filepath.Join(t.TempDir(), "example-operator.bundle")
+	writeBundle(t, source, bundlePath, plumbing.Master)
+
+	if !IsBundlePath(bundlePath) || !IsBundlePath("file://"+bundlePath) || IsBundlePath("https://example.com/repo.bundle") {
+		t.Error("Unexpected bundle path detection")
+	}
+	if name := extractRepoNameFromURL(bundlePath); name != "example-operator" {
+		t.Errorf("Expected repository name example-operator, got %s", name)
+	}
+
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	if err := cloneFromBundle(bundlePath, clonePath); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clone, err := git.PlainOpen(clonePath)
+	if err != nil {
+		t.Fatalf("Failed to open clone: %v", err)
+	}
+	head, err := clone.Head()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	sourceHead, _ := source.Head()
+	if head.Name() != plumbing.Master || head.Hash() != sourceHead.Hash() {
+		t.Errorf("Expected master at %s, got %s at %s", sourceHead.Hash(), head.Name(), head.Hash())
+	}
+	if _, err := clone.Reference(plumbing.NewRemoteReferenceName("origin", "master"), false); err != nil {
+		t.Errorf("Expected a remote-tracking ref for master: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(clonePath, "b.txt")); err != nil {
+		t.Errorf("Expected the default branch to be checked out: %v", err)
+	}
+}
+
+func TestReadBundleHeaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "not a bundle", content: "PACK", expected: "bundle signature"},
+		{name: "incremental bundle", content: "# v2 git bundle\n-" + strings.Repeat("a", 40) + " base\n" + strings.Repeat("b", 40) + " refs/heads/main\n\n", expected: "incremental"},
+		{name: "filtered bundle", content: "# v3 git bundle\n@filter=blob:none\n", expected: "unsupported bundle capability"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".bundle")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write bundle: %v", err)
+			}
+			err := cloneFromBundle(path, filepath.Join(dir, "clone-"+strings.ReplaceAll(tt.name, " ", "-")))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CatalogDiff is how the operators of a catalog changed from one index image to another
+type CatalogDiff struct {
+	OldIndex       string                 `json:"oldIndex"`
+	NewIndex       string                 `json:"newIndex"`
+	Added          []ParserRepositoryInfo `json:"added"`
+	Removed        []ParserRepositoryInfo `json:"removed"`
+	VersionChanges []VersionChange        `json:"versionChanges"`
+	Unchanged      int                    `json:"unchanged"` // Repositories in both catalogs without a known version change
+}
+
+// VersionChange is an operator whose default channel head moved to another version
+type VersionChange struct {
+	Repository string `json:"repository"`
+	Package    string `json:"package,omitempty"`
+	OldVersion string `json:"oldVersion"`
+	NewVersion string `json:"newVersion"`
+}
+
+// DiffCatalogs compares the repositories of two parsed catalogs. Repositories are matched by
+// URL; a version change is only reported when both catalogs tell the head version.
+func DiffCatalogs(oldIndex, newIndex string, oldInfos, newInfos []ParserRepositoryInfo) *CatalogDiff {
+	diff := &CatalogDiff{
+		OldIndex:       oldIndex,
+		NewIndex:       newIndex,
+		Added:          []ParserRepositoryInfo{},
+		Removed:        []ParserRepositoryInfo{},
+		VersionChanges: []VersionChange{},
+	}
+	previous := make(map[string]ParserRepositoryInfo, len(oldInfos))
+	for _, info := range oldInfos {
+		previous[info.URL] = info
+	}
+	current := make(map[string]bool, len(newInfos))
+
+	for _, info := range newInfos {
+		current[info.URL] = true
+		old, ok := previous[info.URL]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, info)
+		case old.HeadVersion != "" && info.HeadVersion != "" && old.HeadVersion != info.HeadVersion:
+			diff.VersionChanges = append(diff.VersionChanges, VersionChange{
+				Repository: info.URL,
+				Package:    info.Name,
+				OldVersion: old.HeadVersion,
+				NewVersion: info.HeadVersion,
+			})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for _, info := range oldInfos {
+		if !current[info.URL] {
+			diff.Removed = append(diff.Removed, info)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].URL < diff.Added[j].URL })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].URL < diff.Removed[j].URL })
+	sort.Slice(diff.VersionChanges, func(i, j int) bool { return diff.VersionChanges[i].Repository < diff.VersionChanges[j].Repository })
+	return diff
+}
+
+// FormatText renders the diff as a concise report, one line per changed operator
+func (d *CatalogDiff) FormatText() string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Catalog diff: %s -> %s\n", d.OldIndex, d.NewIndex))
+	output.WriteString(fmt.Sprintf("Added: %d, Removed: %d, Version changes: %d, Unchanged: %d\n",
+		len(d.Added), len(d.Removed), len(d.VersionChanges), d.Unchanged))
+
+	if len(d.Added) > 0 {
+		output.WriteString("\n=== ADDED ===\n")
+		for _, info := range d.Added {
+			output.WriteString("+ " + catalogEntryLine(info) + "\n")
+		}
+	}
+	if len(d.Removed) > 0 {
+		output.WriteString("\n=== REMOVED ===\n")
+		for _, info := range d.Removed {
+			output.WriteString("- " + catalogEntryLine(info) + "\n")
+		}
+	}
+	if len(d.VersionChanges) > 0 {
+		output.WriteString("\n=== VERSION CHANGES ===\n")
+		for _, change := range d.VersionChanges {
+			name := change.Package
+			if name == "" {
+				name = change.Repository
+			}
+			output.WriteString(fmt.Sprintf("~ %s: %s -> %s (%s)\n", name, change.OldVersion, change.NewVersion, change.Repository))
+		}
+	}
+	return output.String()
+}
+
+// catalogEntryLine describes an added or removed operator as "package version (repository)"
+func catalogEntryLine(info ParserRepositoryInfo) string {
+	var parts []string
+	if info.Name != "" {
+		parts = append(parts, info.Name)
+	}
+	if info.HeadVersion != "" {
+		parts = append(parts, info.HeadVersion)
+	}
+	if len(parts) == 0 {
+		return info.URL
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, " "), info.URL)
+}
+
+// validateImageReference rejects an index image reference opm would parse as a flag
+func validateImageReference(image string) error {
+	if strings.HasPrefix(image, "-") {
+		return fmt.Errorf("invalid image reference %q: must not start with '-'", image)
+	}
+	return nil
+}
+
+// RenderIndexImage writes the JSON render of an index image to outputPath using the opm dm
+// finds or downloads
+func RenderIndexImage(ctx context.Context, dm *DependencyManager, indexImage, outputPath string) (err error) {
+	_, span := StartSpan(ctx, "index.render", attribute.String("index.image", indexImage))
+	defer func() { EndSpan(span, err) }()
+
+	if err := validateImageReference(indexImage); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(outputPath)
+	os.MkdirAll(dir, 0755)
+
+	// Find or download opm
+	opmPath, err := dm.FindOrDownloadTool("opm")
+	if err != nil {
+		return fmt.Errorf("opm command not found and could not be downloaded: %w", err)
+	}
+	dm.Logger.Debugf("Using opm at: %s", opmPath)
+
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outputFile.Close()
+
+	cmd := exec.CommandContext(ctx, opmPath, "render", indexImage, "--output=json")
+	cmd.Stdout = outputFile
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute opm render: %w", err)
+	}
+
+	return nil
+}
+
+// DiffIndexImages renders both index images with the opm of dm in a scratch directory under
+// workDir, parses them and compares their catalogs
+func DiffIndexImages(ctx context.Context, dm *DependencyManager, oldImage, newImage, workDir string) (*CatalogDiff, error) {
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(workDir, "catalog-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var catalogs [2][]ParserRepositoryInfo
+	for i, image := range []string{oldImage, newImage} {
+		indexPath := filepath.Join(dir, fmt.Sprintf("index-%d.json", i))
+		if err := RenderIndexImage(ctx, dm, image, indexPath); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", image, err)
+		}
+		// The detailed parser also reads each operator's head version where the catalog declares it
+		if catalogs[i], err = ParseOperatorIndexDetailed(indexPath); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", image, err)
+		}
+	}
+	return DiffCatalogs(oldImage, newImage, catalogs[0], catalogs[1]), nil
+}
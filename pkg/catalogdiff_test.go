@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	oldInfos := []ParserRepositoryInfo{
+		{URL: "https://github.com/example/kept-operator", Name: "kept", HeadVersion: "1.0.0"},
+		{URL: "https://github.com/example/bumped-operator", Name: "bumped", HeadVersion: "1.2.0"},
+		{URL: "https://github.com/example/dropped-operator", Name: "dropped", HeadVersion: "0.9.0"},
+		{URL: "https://github.com/example/unversioned-operator", Name: "unversioned"},
+	}
+	newInfos := []ParserRepositoryInfo{
+		{URL: "https://github.com/example/kept-operator", Name: "kept", HeadVersion: "1.0.0"},
+		{URL: "https://github.com/example/bumped-operator", Name: "bumped", HeadVersion: "1.3.0"},
+		{URL: "https://github.com/example/unversioned-operator", Name: "unversioned", HeadVersion: "2.0.0"},
+		{URL: "https://github.com/example/new-operator", Name: "new", HeadVersion: "0.1.0"},
+	}
+
+	diff := DiffCatalogs("registry.example.com/index:v1", "registry.example.com/index:v2", oldInfos, newInfos)
+
+	if len(diff.Added) != 1 || diff.Added[0].Name != "new" {
+		t.Errorf("Expected only the new operator to be added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "dropped" {
+		t.Errorf("Expected only the dropped operator to be removed, got %+v", diff.Removed)
+	}
+	expectedChange := VersionChange{
+		Repository: "https://github.com/example/bumped-operator",
+		Package:    "bumped",
+		OldVersion: "1.2.0",
+		NewVersion: "1.3.0",
+	}
+	if len(diff.VersionChanges) != 1 || diff.VersionChanges[0] != expectedChange {
+		t.Errorf("Expected version change %+v, got %+v", expectedChange, diff.VersionChanges)
+	}
+	// A version that only one catalog tells is not a change
+	if diff.Unchanged != 2 {
+		t.Errorf("Expected 2 unchanged repositories, got %d", diff.Unchanged)
+	}
+
+	text := diff.FormatText()
+	for _, expected := range []string{
+		"Catalog diff: registry.example.com/index:v1 -> registry.example.com/index:v2\n",
+		"Added: 1, Removed: 1, Version changes: 1, Unchanged: 2\n",
+		"+ new 0.1.0 (https://github.com/example/new-operator)\n",
+		"- dropped 0.9.0 (https://github.com/example/dropped-operator)\n",
+		"~ bumped: 1.2.0 -> 1.3.0 (https://github.com/example/bumped-operator)\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected diff text to contain %q, got:\n%s", expected, text)
+		}
+	}
+}
+
+func TestDiffCatalogsIdentical(t *testing.T) {
+	infos := []ParserRepositoryInfo{{URL: "https://github.com/example/kept-operator", Name: "kept", HeadVersion: "1.0.0"}}
+	diff := DiffCatalogs("old", "new", infos, infos)
+
+	text := diff.FormatText()
+	if strings.Contains(text, "===") {
+		t.Errorf("Expected no sections for identical catalogs, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Added: 0, Removed: 0, Version changes: 0, Unchanged: 1") {
+		t.Errorf("Expected counts line for identical catalogs, got:\n%s", text)
+	}
+}
+
+func TestChannelHead(t *testing.T) {
+	tests := []struct {
+		name     string
+		edges    []channelEdge
+		expected string
+		ok       bool
+	}{
+		{
+			name: "replaces chain",
+			edges: []channelEdge{
+				{bundle: "op.v1.0.0"},
+				{bundle: "op.v1.1.0", supersedes: []string{"op.v1.0.0"}},
+				{bundle: "op.v1.2.0", supersedes: []string{"op.v1.1.0"}},
+			},
+			expected: "op.v1.2.0",
+			ok:       true,
+		},
+		{
+			name: "skips",
+			edges: []channelEdge{
+				{bundle: "op.v1.0.0"},
+				{bundle: "op.v1.1.0"},
+				{bundle: "op.v2.0.0", supersedes: []string{"op.v1.0.0", "op.v1.1.0"}},
+			},
+			expected: "op.v2.0.0",
+			ok:       true,
+		},
+		{
+			name: "ambiguous head",
+			edges: []channelEdge{
+				{bundle: "op.v1.0.0"},
+				{bundle: "op.v1.1.0", supersedes: []string{"op.v1.0.0"}},
+				{bundle: "op.v1.0.1", supersedes: []string{"op.v1.0.0"}},
+			},
+			ok: false,
+		},
+		{
+			name:  "empty channel",
+			edges: nil,
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, ok := channelHead(tt.edges)
+			if ok != tt.ok || head != tt.expected {
+				t.Errorf("Expected head %q (%v), got %q (%v)", tt.expected, tt.ok, head, ok)
+			}
+		})
+	}
+}
+
+func TestHandleCatalogDiffValidation(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.CloneConcurrency = 1
+	s.CloneQueueWait = 10 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	s.handleCatalogDiff(rec, httptest.NewRequest(http.MethodGet, "/api/catalog-diff?old=--help&new=quay.io/example/index:v2", nil))
+	if !strings.Contains(rec.Body.String(), "must not start with '-'") {
+		t.Errorf("Expected an image reference starting with '-' to be rejected, got %s", rec.Body.String())
+	}
+
+	release, err := s.acquireCloneSlot(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer release()
+	rec = httptest.NewRecorder()
+	s.handleCatalogDiff(rec, httptest.NewRequest(http.MethodGet, "/api/catalog-diff?old=quay.io/example/index:v1&new=quay.io/example/index:v2", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while every clone slot is taken, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultCloneQueueWait is how long a request waits for a clone slot before the server reports
+// that it is busy
+const DefaultCloneQueueWait = 30 * time.Second
+
+// ErrServerBusy reports that every clone slot stayed in use for the server's queue wait
+var ErrServerBusy = errors.New("server busy")
+
+// acquireCloneSlot takes one of the CloneConcurrency slots shared by every request that clones
+// and analyzes a repository, waiting up to CloneQueueWait for one to free up. It returns the
+// function that gives the slot back; without a limit it returns at once.
+func (s *Server) acquireCloneSlot(ctx context.Context) (func(), error) {
+	if s.CloneConcurrency <= 0 {
+		return func() {}, nil
+	}
+	s.cloneSlotsOnce.Do(func() {
+		s.cloneSlots = make(chan struct{}, s.CloneConcurrency)
+	})
+	release := func() { <-s.cloneSlots }
+
+	select {
+	case s.cloneSlots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	s.Logger.Infof("All %d clone slots are in use, queuing request for up to %s", s.CloneConcurrency, s.CloneQueueWait)
+	timer := time.NewTimer(s.CloneQueueWait)
+	defer timer.Stop()
+	select {
+	case s.cloneSlots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: %d repositories are already being cloned, try again shortly", ErrServerBusy, s.CloneConcurrency)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// writeBusyStatus answers 503 Service Unavailable with a Retry-After hint when err is
+// ErrServerBusy; the caller then writes the usual JSON error body
+func (s *Server) writeBusyStatus(w http.ResponseWriter, err error) {
+	if !errors.Is(err, ErrServerBusy) {
+		return
+	}
+	retry := s.CloneQueueWait
+	if retry < time.Second {
+		retry = time.Second
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds())))
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
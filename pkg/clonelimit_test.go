@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAcquireCloneSlot(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.CloneConcurrency = 1
+	s.CloneQueueWait = 20 * time.Millisecond
+
+	release, err := s.acquireCloneSlot(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the first clone to get a slot, got %v", err)
+	}
+
+	if _, err := s.acquireCloneSlot(context.Background()); !errors.Is(err, ErrServerBusy) {
+		t.Fatalf("Expected ErrServerBusy while the only slot is taken, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.CloneQueueWait = time.Minute
+	if _, err := s.acquireCloneSlot(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancelled request to stop waiting, got %v", err)
+	}
+
+	// A queued request gets the slot as soon as it is released
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	release, err = s.acquireCloneSlot(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the queued clone to get the released slot, got %v", err)
+	}
+	release()
+}
+
+func TestAcquireCloneSlotUnlimited(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	for i := 0; i < 10; i++ {
+		if _, err := s.acquireCloneSlot(context.Background()); err != nil {
+			t.Fatalf("Expected no limit by default, got %v", err)
+		}
+	}
+}
+
+func TestWriteBusyStatus(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.CloneQueueWait = 30 * time.Second
+
+	recorder := httptest.NewRecorder()
+	s.writeBusyStatus(recorder, errors.New("clone failed"))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Retry-After") != "" {
+		t.Errorf("Expected other errors to leave the response alone, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	s.writeBusyStatus(recorder, ErrServerBusy)
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", recorder.Code)
+	}
+	if retry := recorder.Header().Get("Retry-After"); retry != "30" {
+		t.Errorf("Expected Retry-After: 30, got %q", retry)
+	}
+}
@@ -0,0 +1,129 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/idxfile"
+	"github.com/sirupsen/logrus"
+)
+
+// CloneStats describes how much data a clone pulled from the remote
+type CloneStats struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// Add accumulates another clone's stats into the totals
+func (cs *CloneStats) Add(other CloneStats) {
+	cs.Objects += other.Objects
+	cs.Bytes += other.Bytes
+}
+
+// String returns a human-readable summary such as "cloned 1200 objects (3.4 MB)"
+func (cs CloneStats) String() string {
+	return fmt.Sprintf("cloned %d objects (%.1f MB)", cs.Objects, float64(cs.Bytes)/(1024*1024))
+}
+
+// collectCloneStats measures a fresh clone from the packfiles it received, since a clone
+// stores everything it transferred as packs and their indexes hold the object counts
+func collectCloneStats(repoPath string) (CloneStats, error) {
+	return collectPackStats(filepath.Join(repoPath, ".git", "objects", "pack"))
+}
+
+// collectPackStats sums the objects and bytes of the packfiles in a pack directory
+func collectPackStats(packDir string) (CloneStats, error) {
+	var stats CloneStats
+
+	packs, err := filepath.Glob(filepath.Join(packDir, "*.pack"))
+	if err != nil {
+		return stats, err
+	}
+
+	for _, pack := range packs {
+		info, err := os.Stat(pack)
+		if err != nil {
+			return stats, fmt.Errorf("failed to stat packfile %s: %w", pack, err)
+		}
+		stats.Bytes += info.Size()
+
+		count, err := countIndexObjects(pack[:len(pack)-len(".pack")] + ".idx")
+		if err != nil {
+			return stats, err
+		}
+		stats.Objects += count
+	}
+
+	return stats, nil
+}
+
+// countIndexObjects returns the number of objects recorded in a pack index file
+func countIndexObjects(idxPath string) (int64, error) {
+	f, err := os.Open(idxPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open pack index %s: %w", idxPath, err)
+	}
+	defer f.Close()
+
+	idx := idxfile.NewMemoryIndex()
+	if err := idxfile.NewDecoder(f).Decode(idx); err != nil {
+		return 0, fmt.Errorf("failed to decode pack index %s: %w", idxPath, err)
+	}
+	return idx.Count()
+}
+
+// logCloneStats reports the transfer size of a clone at debug level. The packs are only
+// inspected when verbose logging is enabled so normal runs pay nothing for it.
+func logCloneStats(logger *logrus.Logger, repoURL, repoPath string) (CloneStats, bool) {
+	if !logger.IsLevelEnabled(logrus.DebugLevel) {
+		return CloneStats{}, false
+	}
+
+	stats, err := collectCloneStats(repoPath)
+	if err != nil {
+		logger.Debugf("Failed to collect clone stats for %s: %v", repoURL, err)
+		return CloneStats{}, false
+	}
+	logger.Debugf("Repository %s: %s", repoURL, stats)
+	return stats, true
+}
+
+// cloneProgress returns where a clone reports git's transfer progress: the logger at debug
+// level when verbose logging is enabled, otherwise nowhere. Progress never goes to stdout,
+// which may carry the results.
+func cloneProgress(logger *logrus.Logger, disabled bool) io.Writer {
+	if disabled || !logger.IsLevelEnabled(logrus.DebugLevel) {
+		return nil
+	}
+	return &progressLogWriter{logger: logger}
+}
+
+// progressLogWriter logs each completed line of git's progress output. Intermediate updates
+// that git redraws in place with '\r' are dropped so only the final counts are logged.
+type progressLogWriter struct {
+	logger  *logrus.Logger
+	pending []byte
+}
+
+func (w *progressLogWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.pending[:end]), "\r")
+		w.pending = w.pending[end+1:]
+		if redraw := strings.LastIndexByte(line, '\r'); redraw >= 0 {
+			line = line[redraw+1:]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			w.logger.Debugf("git: %s", line)
+		}
+	}
+	return len(p), nil
+}
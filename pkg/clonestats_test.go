@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/sirupsen/logrus"
+)
+
+func TestCollectCloneStats(t *testing.T) {
+	upstreamDir := t.TempDir()
+	upstreamRepo, err := git.PlainInit(upstreamDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, upstreamRepo, upstreamDir, "a.txt", "first commit")
+	commitFile(t, upstreamRepo, upstreamDir, "b.txt", "second commit")
+
+	cloneDir := t.TempDir()
+	if _, err := git.PlainClone(cloneDir, false, &git.CloneOptions{URL: upstreamDir}); err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	stats, err := collectCloneStats(cloneDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Two commits, two trees and two blobs
+	if stats.Objects != 6 {
+		t.Errorf("Expected 6 objects, got %d", stats.Objects)
+	}
+	if stats.Bytes <= 0 {
+		t.Errorf("Expected a positive pack size, got %d", stats.Bytes)
+	}
+}
+
+func TestCloneStatsString(t *testing.T) {
+	stats := CloneStats{Objects: 1200, Bytes: 3 * 1024 * 1024}
+	expected := "cloned 1200 objects (3.0 MB)"
+	if stats.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, stats.String())
+	}
+}
+
+func TestCloneProgress(t *testing.T) {
+	logger := logrus.New()
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+
+	if cloneProgress(logger, false) != nil {
+		t.Error("Expected no clone progress without verbose logging")
+	}
+	logger.SetLevel(logrus.DebugLevel)
+	if cloneProgress(logger, true) != nil {
+		t.Error("Expected no clone progress when disabled")
+	}
+
+	progress := cloneProgress(logger, false)
+	if progress == nil {
+		t.Fatal("Expected clone progress with verbose logging")
+	}
+	progress.Write([]byte("Counting objects:  50% (1/2)\rCounting objects: 100% (2/2)"))
+	progress.Write([]byte(", done.\nCompressing objects:  10% (1/10)\r"))
+
+	output := logs.String()
+	if !strings.Contains(output, "git: Counting objects: 100% (2/2), done.") {
+		t.Errorf("Expected the completed progress line to be logged, got %q", output)
+	}
+	if strings.Contains(output, "50%") || strings.Contains(output, "Compressing") {
+		t.Errorf("Expected intermediate progress updates to be dropped, got %q", output)
+	}
+}
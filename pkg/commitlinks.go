@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Built-in commit links, used when no template is configured for a host
+const (
+	defaultCommitURLTemplate   = "{base}/commit/{hash}" // GitHub, and hosts that are not recognized
+	gitlabCommitURLTemplate    = "{base}/-/commit/{hash}"
+	bitbucketCommitURLTemplate = "{base}/commits/{hash}"
+)
+
+// hostCommitURLTemplate returns the built-in commit link layout of the repository's host:
+// GitLab for gitlab.com and hosts named like a self-hosted GitLab (gitlab.example.com),
+// Bitbucket for bitbucket.org, and the GitHub layout otherwise
+func hostCommitURLTemplate(repoURL string) string {
+	host := repositoryHost(repoURL)
+	switch {
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return gitlabCommitURLTemplate
+	case host == "bitbucket.org":
+		return bitbucketCommitURLTemplate
+	}
+	return defaultCommitURLTemplate
+}
+
+// CommitURLTemplates builds commit links for hosts whose URL layout is not built in. Templates
+// use {base} (the repository URL without ".git"), {hash} (the full commit hash) and
+// {shortHash} (the abbreviated hash shown in the notes).
+type CommitURLTemplates struct {
+	Default      string            // Applies to every host without its own template; empty keeps the built-in links
+	ByHost       map[string]string // Lowercase host name -> template
+	HostRewrites map[string]string // Lowercase host name cloned from -> host the links point to, e.g. a mirror -> github.com
+	Links        map[string]string // Repository (see labelKey) -> web URL its links are built from, e.g. for a bundle
+}
+
+// ParseCommitURLTemplates parses --commit-url-template values. A value is either a template for
+// all hosts, e.g. "{base}/commit/{hash}", or a template for one host written as
+// "host=template", e.g. "gerrit.example.com={base}/+/{hash}".
+func ParseCommitURLTemplates(values []string) (CommitURLTemplates, error) {
+	var templates CommitURLTemplates
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		host, template, found := strings.Cut(value, "=")
+		if !found || strings.ContainsAny(host, "{}/:?") {
+			// The "=" belongs to the template itself (e.g. a query string), not a host prefix
+			host, template = "", value
+		}
+		if !strings.Contains(template, "{hash}") && !strings.Contains(template, "{shortHash}") {
+			return CommitURLTemplates{}, fmt.Errorf("commit URL template %q must contain {hash} or {shortHash}", value)
+		}
+
+		if host == "" {
+			templates.Default = template
+			continue
+		}
+		if templates.ByHost == nil {
+			templates.ByHost = make(map[string]string)
+		}
+		templates.ByHost[strings.ToLower(host)] = template
+	}
+	return templates, nil
+}
+
+// ParseLinkHostRewrites parses --link-host-rewrite values written as "from=to", e.g.
+// "mirror.internal=github.com". Host names are compared case-insensitively.
+func ParseLinkHostRewrites(values []string) (map[string]string, error) {
+	rewrites := make(map[string]string)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		from, to, found := strings.Cut(value, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !found || from == "" || to == "" || strings.ContainsAny(from+to, "/?#@ ") {
+			return nil, fmt.Errorf("link host rewrite %q must be written as from-host=to-host", value)
+		}
+		rewrites[strings.ToLower(from)] = to
+	}
+	return rewrites, nil
+}
+
+// linkBase returns the repository URL links are built from: its entry in Links, else the clone
+// URL, with its host replaced when a HostRewrites rule matches. Only HTTP(S) URLs are rewritten.
+func (t CommitURLTemplates) linkBase(repoURL string) string {
+	if link, ok := t.Links[labelKey(repoURL)]; ok {
+		return link
+	}
+	if len(t.HostRewrites) == 0 {
+		return repoURL
+	}
+	parsed, err := url.Parse(repoURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return repoURL
+	}
+	to, ok := t.HostRewrites[strings.ToLower(parsed.Hostname())]
+	if !ok {
+		return repoURL
+	}
+	parsed.Host = to
+	parsed.User = nil
+	return parsed.String()
+}
+
+// CommitURL returns the link to a commit of the repository. fullHash may be empty, in which
+// case {hash} falls back to the short hash. A HostRewrites rule for the repository's host
+// applies first, so the template is chosen for the host the link points to. Configured
+// templates take priority over the host's built-in layout.
+func (t CommitURLTemplates) CommitURL(repoURL, fullHash, shortHash string) string {
+	repoURL = t.linkBase(repoURL)
+	template := hostCommitURLTemplate(repoURL)
+	if byHost, ok := t.ByHost[repositoryHost(repoURL)]; ok {
+		template = byHost
+	} else if t.Default != "" {
+		template = t.Default
+	}
+
+	if fullHash == "" {
+		fullHash = shortHash
+	}
+	return strings.NewReplacer(
+		"{base}", strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git"),
+		"{hash}", fullHash,
+		"{shortHash}", shortHash,
+	).Replace(template)
+}
+
+// CommitLinkLabel returns the text of a link made by CommitURL: "View on GitHub →", GitLab or
+// Bitbucket for those hosts' built-in layouts, else the host-neutral "View commit →"
+func (t CommitURLTemplates) CommitLinkLabel(repoURL string) string {
+	repoURL = t.linkBase(repoURL)
+	host := repositoryHost(repoURL)
+	if _, ok := t.ByHost[host]; ok || t.Default != "" {
+		return "View commit →"
+	}
+	switch {
+	case host == "github.com":
+		return "View on GitHub →"
+	case hostCommitURLTemplate(repoURL) == gitlabCommitURLTemplate:
+		return "View on GitLab →"
+	case hostCommitURLTemplate(repoURL) == bitbucketCommitURLTemplate:
+		return "View on Bitbucket →"
+	}
+	return "View commit →"
+}
+
+// repositoryHost returns the lowercase host name of an HTTP(S) or scp-like SSH repository URL
+func repositoryHost(repoURL string) string {
+	if parsed, err := url.Parse(repoURL); err == nil && parsed.Host != "" {
+		return strings.ToLower(parsed.Hostname())
+	}
+	if at := strings.Index(repoURL, "@"); at >= 0 {
+		if host, _, found := strings.Cut(repoURL[at+1:], ":"); found {
+			return strings.ToLower(host)
+		}
+	}
+	return ""
+}
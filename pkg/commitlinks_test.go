@@ -0,0 +1,206 @@
+package pkg
+
+import "testing"
+
+func TestParseCommitURLTemplates(t *testing.T) {
+	templates, err := ParseCommitURLTemplates([]string{
+		"{base}/commits/{hash}",
+		"Gerrit.Example.com={base}/+/{hash}",
+		"{base}/show?rev={shortHash}",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if templates.Default != "{base}/show?rev={shortHash}" {
+		t.Errorf("Expected the last host-less template to be the default, got %q", templates.Default)
+	}
+	if templates.ByHost["gerrit.example.com"] != "{base}/+/{hash}" {
+		t.Errorf("Expected a template for gerrit.example.com, got %v", templates.ByHost)
+	}
+
+	if _, err := ParseCommitURLTemplates([]string{"{base}/commit"}); err == nil {
+		t.Error("Expected an error for a template without a hash placeholder")
+	}
+}
+
+func TestCommitURL(t *testing.T) {
+	const fullHash = "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+	templates := CommitURLTemplates{ByHost: map[string]string{"gerrit.example.com": "{base}/+/{hash}"}}
+
+	tests := []struct {
+		name      string
+		templates CommitURLTemplates
+		repoURL   string
+		fullHash  string
+		expected  string
+	}{
+		{
+			name:     "default keeps the built-in layout",
+			repoURL:  "https://github.com/example/operator.git",
+			fullHash: fullHash,
+			expected: "https://github.com/example/operator/commit/" + fullHash,
+		},
+		{
+			name:     "missing full hash falls back to the short hash",
+			repoURL:  "https://github.com/example/operator",
+			expected: "https://github.com/example/operator/commit/a1b2c3d4",
+		},
+		{
+			name:      "host template",
+			templates: templates,
+			repoURL:   "https://Gerrit.example.com/operator/",
+			fullHash:  fullHash,
+			expected:  "https://Gerrit.example.com/operator/+/" + fullHash,
+		},
+		{
+			name:      "bundle with a link base from the overrides file",
+			templates: CommitURLTemplates{Links: map[string]string{"/srv/bundles/operator.bundle": "https://gitlab.com/example/operator"}},
+			repoURL:   "/srv/bundles/operator.bundle",
+			fullHash:  fullHash,
+			expected:  "https://gitlab.com/example/operator/-/commit/" + fullHash,
+		},
+		{
+			name:      "unmatched host keeps the built-in layout",
+			templates: templates,
+			repoURL:   "https://github.com/example/operator",
+			fullHash:  fullHash,
+			expected:  "https://github.com/example/operator/commit/" + fullHash,
+		},
+		{
+			name:      "host rewrite",
+			templates: CommitURLTemplates{HostRewrites: map[string]string{"mirror.internal": "github.com"}},
+			repoURL:   "https://token@Mirror.Internal:8443/example/operator.git",
+			fullHash:  fullHash,
+			expected:  "https://github.com/example/operator/commit/" + fullHash,
+		},
+		{
+			name: "host rewrite picks the rewritten host's template",
+			templates: CommitURLTemplates{
+				ByHost:       map[string]string{"gitlab.com": "{base}/-/commit/{hash}", "mirror.internal": "{base}/+/{hash}"},
+				HostRewrites: map[string]string{"mirror.internal": "gitlab.com"},
+			},
+			repoURL:  "https://mirror.internal/group/operator",
+			fullHash: fullHash,
+			expected: "https://gitlab.com/group/operator/-/commit/" + fullHash,
+		},
+		{
+			name:      "host rewrite leaves other hosts alone",
+			templates: CommitURLTemplates{HostRewrites: map[string]string{"mirror.internal": "github.com"}},
+			repoURL:   "https://git.example.com/group/operator",
+			fullHash:  fullHash,
+			expected:  "https://git.example.com/group/operator/commit/" + fullHash,
+		},
+		{
+			name:     "gitlab.com",
+			repoURL:  "https://gitlab.com/group/operator.git",
+			fullHash: fullHash,
+			expected: "https://gitlab.com/group/operator/-/commit/" + fullHash,
+		},
+		{
+			name:     "bitbucket.org",
+			repoURL:  "https://bitbucket.org/team/operator",
+			fullHash: fullHash,
+			expected: "https://bitbucket.org/team/operator/commits/" + fullHash,
+		},
+		{
+			name:      "configured template takes priority over the host layout",
+			templates: CommitURLTemplates{ByHost: map[string]string{"gitlab.com": "{base}/commit/{shortHash}"}},
+			repoURL:   "https://gitlab.com/group/operator",
+			fullHash:  fullHash,
+			expected:  "https://gitlab.com/group/operator/commit/a1b2c3d4",
+		},
+		{
+			name:      "default template with short hash",
+			templates: CommitURLTemplates{Default: "{base}/-/commit/{shortHash}"},
+			repoURL:   "https://gitlab.example.com/group/operator.git",
+			fullHash:  fullHash,
+			expected:  "https://gitlab.example.com/group/operator/-/commit/a1b2c3d4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.templates.CommitURL(tt.repoURL, tt.fullHash, "a1b2c3d4"); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseLinkHostRewrites(t *testing.T) {
+	rewrites, err := ParseLinkHostRewrites([]string{"Mirror.Internal=github.com", " ghe.example.com = gitlab.com "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rewrites["mirror.internal"] != "github.com" || rewrites["ghe.example.com"] != "gitlab.com" {
+		t.Errorf("Unexpected rewrites %v", rewrites)
+	}
+
+	for _, value := range []string{"mirror.internal", "=github.com", "mirror.internal=", "https://mirror.internal=github.com"} {
+		if _, err := ParseLinkHostRewrites([]string{value}); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestHostCommitURLTemplate(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/example/operator":          defaultCommitURLTemplate,
+		"https://github.com/example/operator.git":      defaultCommitURLTemplate,
+		"https://gitlab.com/group/operator":            gitlabCommitURLTemplate,
+		"https://GitLab.com/group/sub/operator.git":    gitlabCommitURLTemplate,
+		"https://gitlab.example.com/group/operator":    gitlabCommitURLTemplate,
+		"git@gitlab.example.com:group/operator.git":    gitlabCommitURLTemplate,
+		"https://bitbucket.org/team/operator":          bitbucketCommitURLTemplate,
+		"https://bitbucket.org/team/operator.git":      bitbucketCommitURLTemplate,
+		"https://git.example.com/team/operator.git":    defaultCommitURLTemplate,
+		"https://notgitlab.example.com/group/operator": defaultCommitURLTemplate,
+	}
+	for repoURL, expected := range tests {
+		if got := hostCommitURLTemplate(repoURL); got != expected {
+			t.Errorf("hostCommitURLTemplate(%q) = %q, expected %q", repoURL, got, expected)
+		}
+	}
+}
+
+func TestRepositoryHost(t *testing.T) {
+	tests := map[string]string{
+		"https://GitHub.com/example/operator.git": "github.com",
+		"git@gitlab.example.com:group/repo.git":   "gitlab.example.com",
+		"ssh://git@host.example.com:2222/repo":    "host.example.com",
+		"not a url":                               "",
+	}
+	for repoURL, expected := range tests {
+		if got := repositoryHost(repoURL); got != expected {
+			t.Errorf("repositoryHost(%q) = %q, expected %q", repoURL, got, expected)
+		}
+	}
+}
+
+func TestCommitLinkLabel(t *testing.T) {
+	tests := []struct {
+		templates CommitURLTemplates
+		repoURL   string
+		expected  string
+	}{
+		{repoURL: "https://github.com/example/operator", expected: "View on GitHub →"},
+		{repoURL: "https://gitlab.example.com/team/operator", expected: "View on GitLab →"},
+		{repoURL: "https://bitbucket.org/team/operator", expected: "View on Bitbucket →"},
+		{repoURL: "https://git.example.com/team/operator", expected: "View commit →"},
+		{
+			templates: CommitURLTemplates{ByHost: map[string]string{"github.com": "{base}/+/{hash}"}},
+			repoURL:   "https://github.com/example/operator",
+			expected:  "View commit →",
+		},
+		{
+			templates: CommitURLTemplates{HostRewrites: map[string]string{"mirror.internal": "gitlab.com"}},
+			repoURL:   "https://mirror.internal/team/operator",
+			expected:  "View on GitLab →",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.templates.CommitLinkLabel(tt.repoURL); got != tt.expected {
+			t.Errorf("CommitLinkLabel(%q) = %q, expected %q", tt.repoURL, got, tt.expected)
+		}
+	}
+}
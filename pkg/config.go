@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings a --config file may give, e.g.
+//
+//	pregaIndex: quay.io/prega/prega-operator-index:v4.21
+//	workDir: /app/temp-repos
+//	outputDir: /app/output
+//	concurrency: 8
+//	cloneDepth: 500
+//	format: json
+//	serverPort: 8080
+//	days: 14
+//
+// A zero or empty value leaves the setting to its default. Flags and environment variables
+// take precedence over the file.
+type Config struct {
+	PregaIndex  string `yaml:"pregaIndex"`
+	WorkDir     string `yaml:"workDir"`
+	OutputDir   string `yaml:"outputDir"`
+	Concurrency int    `yaml:"concurrency"`
+	CloneDepth  int    `yaml:"cloneDepth"`
+	Format      string `yaml:"format"`
+	ServerPort  int    `yaml:"serverPort"`
+	Days        int    `yaml:"days"`
+}
+
+// LoadConfig reads a YAML config file. Unknown keys and invalid values are ErrorTypeValidation
+// errors, so a misspelled setting is reported rather than silently ignored.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, WrapError(err, ErrorTypeFileSystem, "failed to read config file", map[string]interface{}{
+			"path": path,
+		})
+	}
+
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, WrapError(err, ErrorTypeValidation, "invalid config file", map[string]interface{}{
+			"path":        path,
+			"remediation": "check the YAML syntax and the setting names (pregaIndex, workDir, outputDir, concurrency, cloneDepth, format, serverPort, days)",
+		})
+	}
+	if err := config.validate(); err != nil {
+		return nil, WrapError(err, ErrorTypeValidation, "invalid config file", map[string]interface{}{
+			"path": path,
+		})
+	}
+	return config, nil
+}
+
+// validate checks the values the flags they stand in for would also reject
+func (c *Config) validate() error {
+	switch {
+	case c.Concurrency < 0:
+		return fmt.Errorf("concurrency must not be negative, got %d", c.Concurrency)
+	case c.CloneDepth < 0:
+		return fmt.Errorf("cloneDepth must not be negative, got %d", c.CloneDepth)
+	case c.ServerPort < 0 || c.ServerPort > 65535:
+		return fmt.Errorf("serverPort must be between 1 and 65535, got %d", c.ServerPort)
+	case c.Days < 0:
+		return fmt.Errorf("days must be a positive integer, got %d", c.Days)
+	case c.Format != "" && c.Format != "text" && c.Format != "json":
+		return fmt.Errorf("unknown format %q (expected text or json)", c.Format)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    Config
+		expectError string
+	}{
+		{
+			name: "all settings",
+			content: `pregaIndex: quay.io/prega/prega-operator-index:v4.21
+workDir: /app/temp-repos
+outputDir: /app/output
+concurrency: 8
+cloneDepth: 500
+format: json
+serverPort: 9090
+days: 14
+`,
+			expected: Config{
+				PregaIndex:  "quay.io/prega/prega-operator-index:v4.21",
+				WorkDir:     "/app/temp-repos",
+				OutputDir:   "/app/output",
+				Concurrency: 8,
+				CloneDepth:  500,
+				Format:      "json",
+				ServerPort:  9090,
+				Days:        14,
+			},
+		},
+		{name: "some settings", content: "# Weekly run\ndays: 7\n", expected: Config{Days: 7}},
+		{name: "empty file", content: ""},
+		{name: "unknown key", content: "dayz: 7\n", expectError: "field dayz not found"},
+		{name: "wrong type", content: "concurrency: many\n", expectError: "invalid config file"},
+		{name: "invalid yaml", content: "days: [7\n", expectError: "invalid config file"},
+		{name: "negative value", content: "concurrency: -1\n", expectError: "concurrency must not be negative"},
+		{name: "unknown format", content: "format: yaml\n", expectError: `unknown format "yaml"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadConfig(path)
+			if tt.expectError != "" {
+				var analyzerErr *AnalyzerError
+				if !errors.As(err, &analyzerErr) || analyzerErr.Type != ErrorTypeValidation || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected a validation error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if *config != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *config)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	var analyzerErr *AnalyzerError
+	if !errors.As(err, &analyzerErr) || analyzerErr.Type != ErrorTypeFileSystem || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a filesystem error for a missing file, got %v", err)
+	}
+}
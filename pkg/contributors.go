@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RankBy selects the metric used to order the contributors ranking
+type RankBy string
+
+const (
+	RankByCommits RankBy = "commits"
+	RankByLines   RankBy = "lines"
+)
+
+// ParseRankBy validates a --rank-by value; an empty value ranks by commit count
+func ParseRankBy(value string) (RankBy, error) {
+	switch RankBy(strings.ToLower(strings.TrimSpace(value))) {
+	case "", RankByCommits:
+		return RankByCommits, nil
+	case RankByLines:
+		return RankByLines, nil
+	}
+	return "", fmt.Errorf("unknown ranking %q (expected lines or commits)", value)
+}
+
+// contributorTally accumulates commit and line counts per author while walking a commit log,
+// keyed by contributorKey so one person committing under several spellings is counted once
+type contributorTally map[string]*Contributor
+
+// contributorKey identifies the author of a commit: their normalized email, or their
+// normalized name when the commit has no email
+func contributorKey(author object.Signature) string {
+	if email := normalizeIdentity(author.Email); email != "" {
+		return email
+	}
+	return normalizeIdentity(author.Name)
+}
+
+// add attributes one commit and its changed lines to its author. Commits are walked newest
+// first, so the first commit seen for an author is their most recent one. The author is shown
+// under the name they committed with most often, the most recent one on a tie.
+func (t contributorTally) add(c *object.Commit, lines int) {
+	key := contributorKey(c.Author)
+	contributor, ok := t[key]
+	if !ok {
+		contributor = &Contributor{Name: c.Author.Name, Email: c.Author.Email, latestCommit: c.Hash.String(), names: make(map[string]int)}
+		t[key] = contributor
+	}
+	contributor.names[c.Author.Name]++
+	if contributor.names[c.Author.Name] > contributor.names[contributor.Name] {
+		contributor.Name = c.Author.Name
+	}
+	contributor.CommitCount++
+	contributor.LinesChanged += lines
+}
+
+// ranked returns the contributors ordered by the chosen metric, using the other metric
+// and then the name to break ties so the ranking is stable between runs
+func (t contributorTally) ranked(rankBy RankBy) []Contributor {
+	contributors := make([]Contributor, 0, len(t))
+	for _, contributor := range t {
+		contributors = append(contributors, *contributor)
+	}
+
+	sort.Slice(contributors, func(i, j int) bool {
+		a, b := contributors[i], contributors[j]
+		primaryA, primaryB, secondaryA, secondaryB := a.CommitCount, b.CommitCount, a.LinesChanged, b.LinesChanged
+		if rankBy == RankByLines {
+			primaryA, primaryB, secondaryA, secondaryB = secondaryA, secondaryB, primaryA, primaryB
+		}
+		if primaryA != primaryB {
+			return primaryA > primaryB
+		}
+		if secondaryA != secondaryB {
+			return secondaryA > secondaryB
+		}
+		return a.Name < b.Name
+	})
+
+	for i := range contributors {
+		contributors[i].Rank = i + 1
+	}
+	return contributors
+}
+
+// Activity describes a contributor's activity with the ranking metric first,
+// e.g. "12 commits" or "840 lines changed, 3 commits"
+func (c Contributor) Activity(rankBy RankBy) string {
+	if rankBy == RankByLines {
+		return fmt.Sprintf("%d lines changed, %d commits", c.LinesChanged, c.CommitCount)
+	}
+	return fmt.Sprintf("%d commits", c.CommitCount)
+}
@@ -0,0 +1,129 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// authorCommit returns a commit authored by name for tallying
+func authorCommit(name string) *object.Commit {
+	return &object.Commit{Author: object.Signature{Name: name, Email: name + "@example.com"}}
+}
+
+func TestContributorTallyRanked(t *testing.T) {
+	tally := make(contributorTally)
+	// Alice makes many small commits, Bob one large one, Carol ties Bob on lines
+	for i := 0; i < 3; i++ {
+		tally.add(authorCommit("Alice"), 2)
+	}
+	tally.add(authorCommit("Bob"), 500)
+	tally.add(authorCommit("Carol"), 500)
+
+	tests := []struct {
+		name     string
+		rankBy   RankBy
+		expected []string
+	}{
+		{name: "by commits", rankBy: RankByCommits, expected: []string{"Alice", "Bob", "Carol"}},
+		{name: "by lines", rankBy: RankByLines, expected: []string{"Bob", "Carol", "Alice"}},
+		{name: "default", rankBy: "", expected: []string{"Alice", "Bob", "Carol"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := tally.ranked(tt.rankBy)
+			if len(ranked) != len(tt.expected) {
+				t.Fatalf("Expected %d contributors, got %d", len(tt.expected), len(ranked))
+			}
+			for i, name := range tt.expected {
+				if ranked[i].Name != name || ranked[i].Rank != i+1 {
+					t.Errorf("Expected #%d %s, got #%d %s", i+1, name, ranked[i].Rank, ranked[i].Name)
+				}
+			}
+		})
+	}
+
+	alice := tally[contributorKey(authorCommit("Alice").Author)]
+	if alice.CommitCount != 3 || alice.LinesChanged != 6 {
+		t.Errorf("Expected Alice to have 3 commits and 6 lines, got %d and %d", alice.CommitCount, alice.LinesChanged)
+	}
+}
+
+func TestContributorTallyMergesIdentities(t *testing.T) {
+	commit := func(name, email string) *object.Commit {
+		return &object.Commit{Author: object.Signature{Name: name, Email: email}}
+	}
+
+	tally := make(contributorTally)
+	// Jane commits under three spellings of her name and two casings of one email
+	tally.add(commit("jane", "Jane.Doe@Example.com"), 1)
+	tally.add(commit("Jane Doe", "jane.doe@example.com"), 1)
+	tally.add(commit("Jane Doe", "JANE.DOE@example.com"), 1)
+	tally.add(commit("JANE DOE", "jane.doe@example.com"), 1)
+	// Bob has no email, so his name identifies him
+	tally.add(commit("Bob Smith", ""), 10)
+	tally.add(commit("bob  smith", ""), 10)
+	tally.add(commit("Bob Smith", ""), 10)
+	// Carol shares Jane's name but not her email, so she is someone else
+	tally.add(commit("Jane Doe", "carol@example.com"), 1)
+
+	ranked := tally.ranked(RankByCommits)
+	expected := []struct {
+		name    string
+		email   string
+		commits int
+	}{
+		{name: "Jane Doe", email: "Jane.Doe@Example.com", commits: 4},
+		{name: "Bob Smith", commits: 3},
+		{name: "Jane Doe", email: "carol@example.com", commits: 1},
+	}
+	if len(ranked) != len(expected) {
+		t.Fatalf("Expected %d contributors, got %d: %+v", len(expected), len(ranked), ranked)
+	}
+	for i, want := range expected {
+		got := ranked[i]
+		if got.Name != want.name || got.Email != want.email || got.CommitCount != want.commits || got.Rank != i+1 {
+			t.Errorf("Expected #%d %s <%s> with %d commits, got #%d %s <%s> with %d commits",
+				i+1, want.name, want.email, want.commits, got.Rank, got.Name, got.Email, got.CommitCount)
+		}
+	}
+}
+
+func TestParseRankBy(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    RankBy
+		expectError bool
+	}{
+		{value: "", expected: RankByCommits},
+		{value: "commits", expected: RankByCommits},
+		{value: "Lines", expected: RankByLines},
+		{value: "authors", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rankBy, err := ParseRankBy(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.value)
+				}
+				return
+			}
+			if err != nil || rankBy != tt.expected {
+				t.Errorf("Expected %s, got %s (err: %v)", tt.expected, rankBy, err)
+			}
+		})
+	}
+}
+
+func TestContributorActivity(t *testing.T) {
+	contributor := Contributor{Name: "Alice", CommitCount: 3, LinesChanged: 840}
+	if got := contributor.Activity(RankByCommits); got != "3 commits" {
+		t.Errorf("Unexpected commit activity: %s", got)
+	}
+	if got := contributor.Activity(RankByLines); got != "840 lines changed, 3 commits" {
+		t.Errorf("Unexpected lines activity: %s", got)
+	}
+}
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DateMode selects which date of a commit places it in the analysis window and is shown for
+// it in the notes. The two differ for rebased, cherry-picked and amended commits: their author
+// date stays when the change was first written, possibly months earlier, while the committer
+// date is when it was applied to the branch.
+type DateMode string
+
+const (
+	DateModeCommitter DateMode = "committer" // When the commit landed on the branch
+	DateModeAuthor    DateMode = "author"    // When the change was first written
+)
+
+// ParseDateMode validates a --date-mode value; an empty value selects the committer date
+func ParseDateMode(value string) (DateMode, error) {
+	switch DateMode(strings.ToLower(strings.TrimSpace(value))) {
+	case "", DateModeCommitter:
+		return DateModeCommitter, nil
+	case DateModeAuthor:
+		return DateModeAuthor, nil
+	}
+	return "", fmt.Errorf("unknown date mode %q (expected committer or author)", value)
+}
+
+// commitDate returns the date of c the mode selects; the zero mode selects the committer date
+func (m DateMode) commitDate(c *object.Commit) time.Time {
+	if m == DateModeAuthor {
+		return c.Author.When
+	}
+	return c.Committer.When
+}
+
+// logWindowCommits returns the commits reachable from from, newest first, whose date under mode
+// lies within since..until. The dates are compared here rather than through the limits of
+// git.LogOptions, whose choice of date has not been the same across go-git versions.
+func logWindowCommits(repo *git.Repository, from plumbing.Hash, since, until time.Time, mode DateMode) ([]*object.Commit, error) {
+	iter, err := logCommits(repo, from, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var commits []*object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if date := mode.commitDate(c); !date.Before(since) && !date.After(until) {
+			commits = append(commits, c)
+		}
+		return nil
+	})
+	return commits, err
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseDateMode(t *testing.T) {
+	for value, expected := range map[string]DateMode{"": DateModeCommitter, "committer": DateModeCommitter, " Author ": DateModeAuthor} {
+		if mode, err := ParseDateMode(value); err != nil || mode != expected {
+			t.Errorf("ParseDateMode(%q) = %q, %v; expected %q", value, mode, err, expected)
+		}
+	}
+	if _, err := ParseDateMode("commit"); err == nil {
+		t.Error("Expected an unknown date mode to be rejected")
+	}
+}
+
+func TestLogWindowCommits(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	now := time.Now()
+	// A change written months ago and cherry-picked yesterday, then one written and landed today
+	for _, c := range []struct {
+		name              string
+		authored, applied time.Time
+	}{
+		{name: "picked.txt", authored: now.AddDate(0, -3, 0), applied: now.AddDate(0, 0, -1)},
+		{name: "fresh.txt", authored: now, applied: now},
+	} {
+		if err := os.WriteFile(filepath.Join(dir, c.name), []byte(c.name), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, err := wt.Add(c.name); err != nil {
+			t.Fatalf("Failed to add file: %v", err)
+		}
+		if _, err := wt.Commit("Add "+c.name, &git.CommitOptions{
+			Author:    &object.Signature{Name: "Test Author", Email: "test@example.com", When: c.authored},
+			Committer: &object.Signature{Name: "Test Committer", Email: "committer@example.com", When: c.applied},
+		}); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+
+	since, until := now.AddDate(0, 0, -7), now.Add(time.Minute)
+	for mode, expected := range map[DateMode]int{"": 2, DateModeCommitter: 2, DateModeAuthor: 1} {
+		commits, err := logWindowCommits(repo, head.Hash(), since, until, mode)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(commits) != expected {
+			t.Errorf("Mode %q: expected %d commits in the window, got %d", mode, expected, len(commits))
+		}
+	}
+
+	commits, _ := logWindowCommits(repo, head.Hash(), since, until, DateModeCommitter)
+	picked := commits[1]
+	if !DateModeCommitter.commitDate(picked).Equal(picked.Committer.When) || !DateModeAuthor.commitDate(picked).Equal(picked.Author.When) {
+		t.Errorf("Expected each mode to select its own date")
+	}
+}
+
+func TestLatestCommitDateMode(t *testing.T) {
+	now := time.Now()
+	authored, applied := now.AddDate(0, 0, -3), now.AddDate(0, 0, -1)
+	for mode, expected := range map[DateMode]time.Time{DateModeCommitter: applied, DateModeAuthor: authored} {
+		// Each run removes its clone, so every mode gets a fresh repository
+		dir := t.TempDir()
+		repo, err := git.PlainInit(dir, false)
+		if err != nil {
+			t.Fatalf("Failed to init repository: %v", err)
+		}
+		wt, err := repo.Worktree()
+		if err != nil {
+			t.Fatalf("Failed to get worktree: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "picked.txt"), []byte("picked"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, err := wt.Add("picked.txt"); err != nil {
+			t.Fatalf("Failed to add file: %v", err)
+		}
+		if _, err := wt.Commit("Add picked.txt", &git.CommitOptions{
+			Author:    &object.Signature{Name: "Test Author", Email: "test@example.com", When: authored},
+			Committer: &object.Signature{Name: "Test Committer", Email: "committer@example.com", When: applied},
+		}); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+
+		vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+		vtm.DateMode = mode
+		notes, err := vtm.generateBasicReleaseNotes(context.Background(), dir, "https://github.com/test/repo")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !notes.Format.LatestCommit.Date.Equal(expected.Truncate(time.Second)) {
+			t.Errorf("Mode %q: expected the latest commit dated %v, got %v", mode, expected, notes.Format.LatestCommit.Date)
+		}
+	}
+}
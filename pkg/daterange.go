@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeDatePattern matches a number of days or weeks before now, e.g. "30d" or "2w"
+var relativeDatePattern = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// parseRangeDate parses a --since or --until value: a YYYY-MM-DD date, at the start of that
+// day, or a relative value such as "30d" or "2w", counted back from now. isDate tells which.
+func parseRangeDate(value string, now time.Time) (date time.Time, isDate bool, err error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if match := relativeDatePattern.FindStringSubmatch(value); match != nil {
+		count, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid relative date %q", value)
+		}
+		if match[2] == "w" {
+			count *= 7
+		}
+		return now.AddDate(0, 0, -count), false, nil
+	}
+	date, err = time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q (expected YYYY-MM-DD, or a number of days or weeks ago such as 30d or 2w)", value)
+	}
+	return date, true, nil
+}
+
+// ParseDateRange resolves the --since and --until values into an analysis window. An empty
+// since is returned as the zero time, for a window of the usual number of days. until
+// defaults to now and includes the whole of a YYYY-MM-DD day; since must precede it and until
+// must not be in the future. Errors are ErrorTypeValidation errors.
+func ParseDateRange(sinceValue, untilValue string, now time.Time) (since, until time.Time, err error) {
+	invalid := func(flag, value string, err error) error {
+		return WrapError(err, ErrorTypeValidation, fmt.Sprintf("invalid %s value", flag), map[string]interface{}{
+			flag:          value,
+			"remediation": "use a YYYY-MM-DD date or a relative value such as 30d or 2w",
+		})
+	}
+
+	if strings.TrimSpace(sinceValue) != "" {
+		if since, _, err = parseRangeDate(sinceValue, now); err != nil {
+			return time.Time{}, time.Time{}, invalid("since", sinceValue, err)
+		}
+	}
+
+	until = now
+	if strings.TrimSpace(untilValue) != "" {
+		var isDate bool
+		if until, isDate, err = parseRangeDate(untilValue, now); err != nil {
+			return time.Time{}, time.Time{}, invalid("until", untilValue, err)
+		}
+		if isDate {
+			if until.After(now) {
+				return time.Time{}, time.Time{}, WrapError(nil, ErrorTypeValidation, fmt.Sprintf("until date %s is in the future", untilValue), nil)
+			}
+			// Include the whole end day
+			until = until.AddDate(0, 0, 1).Add(-time.Second)
+		}
+	}
+
+	if !since.IsZero() && !since.Before(until) {
+		return time.Time{}, time.Time{}, WrapError(nil, ErrorTypeValidation, fmt.Sprintf("since %s must be before until %s", since.Format("2006-01-02"), until.Format("2006-01-02")), map[string]interface{}{
+			"since": sinceValue,
+			"until": untilValue,
+		})
+	}
+	return since, until, nil
+}
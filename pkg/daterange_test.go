@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestParseDateRange(t *testing.T) {
+	now := time.Date(2024, 6, 15, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name         string
+		since, until string
+		expectSince  time.Time
+		expectUntil  time.Time
+		expectError  string
+	}{
+		{name: "since only", since: "2024-06-01", expectSince: time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local), expectUntil: now},
+		{name: "both dates", since: "2024-05-01", until: "2024-05-31", expectSince: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), expectUntil: time.Date(2024, 5, 31, 23, 59, 59, 0, time.Local)},
+		{name: "relative days", since: "30d", expectSince: now.AddDate(0, 0, -30), expectUntil: now},
+		{name: "relative weeks", since: "4W", until: "2w", expectSince: now.AddDate(0, 0, -28), expectUntil: now.AddDate(0, 0, -14)},
+		{name: "until only", until: "2024-06-01", expectUntil: time.Date(2024, 6, 1, 23, 59, 59, 0, time.Local)},
+		{name: "invalid since", since: "June 1st", expectError: "invalid since value"},
+		{name: "invalid until", since: "2024-06-01", until: "3m", expectError: "invalid until value"},
+		{name: "since after until", since: "2024-06-10", until: "2024-06-01", expectError: "since 2024-06-10 must be before until 2024-06-01"},
+		{name: "future until", since: "2024-06-01", until: "2024-07-01", expectError: "until date 2024-07-01 is in the future"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, err := ParseDateRange(tt.since, tt.until, now)
+			if tt.expectError != "" {
+				var analyzerErr *AnalyzerError
+				if !errors.As(err, &analyzerErr) || analyzerErr.Type != ErrorTypeValidation || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected a validation error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !since.Equal(tt.expectSince) || !until.Equal(tt.expectUntil) {
+				t.Errorf("Expected %s..%s, got %s..%s", tt.expectSince, tt.expectUntil, since, until)
+			}
+		})
+	}
+}
+
+func TestProcessRepositoriesSince(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "old.txt", "old.txt", withMessage("Add old.txt"), withDate(now.AddDate(0, 0, -40)))
+	commitFile(t, repo, dir, "recent.txt", "recent.txt", withMessage("Add recent.txt"), withDate(now.AddDate(0, 0, -20)))
+
+	output := filepath.Join(t.TempDir(), "notes.txt")
+	vtm := NewVibeToolsManager(t.TempDir(), output, false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+	vtm.Logger.SetOutput(io.Discard)
+	vtm.NoCloneProgress = true
+	vtm.Since = now.AddDate(0, 0, -30)
+
+	results, err := vtm.ProcessRepositories(context.Background(), []string{dir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	notes := results[0].ReleaseNotes
+	if notes == nil || notes.WeeklySummary.TotalCommits != 1 || !notes.AbsoluteRange || notes.AnalysisDays != 31 {
+		t.Fatalf("Expected the commit of the last 30 days over a 31-day range, got %+v", notes)
+	}
+	text, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read notes: %v", err)
+	}
+	expected := "Date Range: " + vtm.Since.Format("2006-01-02") + ".." + now.Format("2006-01-02") + "\n"
+	if !strings.Contains(string(text), expected) {
+		t.Errorf("Expected the report header to show the range %q, got:\n%s", expected, text)
+	}
+}
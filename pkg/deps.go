@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"compress/gzip"
 	"fmt"
 	"io"
@@ -15,10 +16,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// defaultMaxExtractSize caps the total uncompressed size of an archive to guard against decompression bombs
+const defaultMaxExtractSize int64 = 512 * 1024 * 1024
+
 // DependencyManager handles downloading and managing external dependencies
 type DependencyManager struct {
-	BinDir string
-	Logger *logrus.Logger
+	BinDir         string
+	Logger         *logrus.Logger
+	MaxExtractSize int64  // Maximum total uncompressed bytes extracted from an archive
+	OPMVersion     string // OCP release whose opm is downloaded, or OPMVersionLatest; empty means DefaultOPMVersion
+	SkipChecksum   bool   // Use a downloaded opm without checking it against the mirror's sha256sum.txt
+
+	mirrorURL string // Base URL of the OpenShift mirror, replaced in tests
 }
 
 // NewDependencyManager creates a new dependency manager
@@ -27,9 +36,16 @@ func NewDependencyManager(binDir string, logger *logrus.Logger) *DependencyManag
 		logger = logrus.New()
 		logger.SetLevel(logrus.InfoLevel)
 	}
+	opmVersion := os.Getenv("OPM_VERSION")
+	if opmVersion == "" {
+		opmVersion = DefaultOPMVersion
+	}
 	return &DependencyManager{
-		BinDir: binDir,
-		Logger:  logger,
+		BinDir:         binDir,
+		Logger:         logger,
+		MaxExtractSize: defaultMaxExtractSize,
+		OPMVersion:     opmVersion,
+		mirrorURL:      opmMirrorURL,
 	}
 }
 
@@ -95,9 +111,11 @@ func (dm *DependencyManager) downloadOPM(binPath string) (string, error) {
 		return "", fmt.Errorf("unsupported architecture: %s", arch)
 	}
 
-	// OPM version - using a recent stable version
-	version := "4.17.21"
-	
+	version, err := dm.resolveOPMVersion(opmArch)
+	if err != nil {
+		return "", err
+	}
+
 	// Determine OS-specific file name
 	var osName, fileExt string
 	switch goos {
@@ -116,8 +134,9 @@ func (dm *DependencyManager) downloadOPM(binPath string) (string, error) {
 
 	// Construct download URL
 	// OPM is available from OpenShift mirror
-	url := fmt.Sprintf("https://mirror.openshift.com/pub/openshift-v4/%s/clients/ocp/%s/opm-%s-%s.%s",
-		opmArch, version, osName, version, fileExt)
+	releaseURL := fmt.Sprintf("%s/%s", dm.mirrorClientsURL(opmArch), version)
+	archiveName := fmt.Sprintf("opm-%s-%s.%s", osName, version, fileExt)
+	url := releaseURL + "/" + archiveName
 
 	dm.Logger.Infof("Downloading OPM from: %s", url)
 
@@ -128,6 +147,9 @@ func (dm *DependencyManager) downloadOPM(binPath string) (string, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("OPM version %s was not found on the mirror (HTTP 404 for %s); set OPM_VERSION to a published OCP release or %q", version, url, OPMVersionLatest)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("failed to download OPM: HTTP %d", resp.StatusCode)
 	}
@@ -148,32 +170,31 @@ func (dm *DependencyManager) downloadOPM(binPath string) (string, error) {
 	}
 	out.Close()
 
+	// Only extract an archive that matches the checksum the mirror publishes for it
+	if dm.SkipChecksum {
+		dm.Logger.Warnf("Skipping the checksum verification of %s", url)
+	} else if err := dm.verifyOPMDownload(releaseURL, archiveName, tmpFile); err != nil {
+		return "", err
+	}
+
 	// Extract based on file type
-	if fileExt == "tar.gz" {
-		if err := dm.extractTarGz(tmpFile, dm.BinDir); err != nil {
-			os.Remove(tmpFile)
-			return "", fmt.Errorf("failed to extract OPM: %w", err)
-		}
-	} else {
-		return "", fmt.Errorf("zip extraction not yet implemented for Windows")
+	extract := dm.extractTarGz
+	if fileExt == "zip" {
+		extract = dm.extractZip
+	}
+	if err := extract(tmpFile, dm.BinDir); err != nil {
+		os.Remove(tmpFile)
+		return "", fmt.Errorf("failed to extract OPM: %w", err)
 	}
 
 	// Remove temp file
 	os.Remove(tmpFile)
 
 	// Find the extracted opm binary
-	var opmBinaryName string
-	switch goos {
-	case "linux":
-		opmBinaryName = "opm-rhel8"
-	case "darwin":
-		opmBinaryName = "opm-darwin"
-	}
-
-	extractedPath := filepath.Join(dm.BinDir, opmBinaryName)
+	extractedPath := filepath.Join(dm.BinDir, opmBinaryName(goos))
 	if _, err := os.Stat(extractedPath); err != nil {
 		// Try alternative names
-		altNames := []string{"opm", "opm-linux", "opm-mac"}
+		altNames := []string{"opm", "opm-linux", "opm-mac", "opm.exe"}
 		found := false
 		for _, altName := range altNames {
 			altPath := filepath.Join(dm.BinDir, altName)
@@ -206,12 +227,27 @@ func (dm *DependencyManager) downloadOPM(binPath string) (string, error) {
 	return binPath, nil
 }
 
+// opmBinaryName returns the name of the opm binary in the mirror's archive for goos
+func opmBinaryName(goos string) string {
+	switch goos {
+	case "linux":
+		return "opm-rhel8"
+	case "darwin":
+		return "opm-darwin"
+	case "windows":
+		return "opm.exe"
+	}
+	return "opm"
+}
+
 // downloadVibeTools downloads vibe-tools (placeholder - implementation depends on availability)
 func (dm *DependencyManager) downloadVibeTools(binPath string) (string, error) {
 	return "", fmt.Errorf("vibe-tools auto-download not yet implemented")
 }
 
-// extractTarGz extracts a tar.gz file to the destination directory
+// extractTarGz extracts the regular files of a tar.gz file into the destination directory.
+// Entries are flattened to their base name; entries whose path escapes the destination are
+// rejected, links are skipped, and extraction stops once MaxExtractSize bytes are written.
 func (dm *DependencyManager) extractTarGz(src, dst string) error {
 	file, err := os.Open(src)
 	if err != nil {
@@ -227,6 +263,11 @@ func (dm *DependencyManager) extractTarGz(src, dst string) error {
 
 	tr := tar.NewReader(gzr)
 
+	remaining := dm.MaxExtractSize
+	if remaining <= 0 {
+		remaining = defaultMaxExtractSize
+	}
+
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -236,11 +277,26 @@ func (dm *DependencyManager) extractTarGz(src, dst string) error {
 			return err
 		}
 
+		// Refuse entries that would escape the destination if extracted as named
+		if !isWithinDir(dst, header.Name) {
+			return fmt.Errorf("archive entry %q escapes destination directory", header.Name)
+		}
+
+		// Links could point outside the destination, so never recreate them
+		if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
+			dm.Logger.Warnf("Skipping link %s -> %s in archive %s", header.Name, header.Linkname, src)
+			continue
+		}
+
 		// Skip if not a regular file
 		if header.Typeflag != tar.TypeReg {
 			continue
 		}
 
+		if header.Size > remaining {
+			return fmt.Errorf("archive entry %q exceeds the maximum extract size of %d bytes", header.Name, dm.MaxExtractSize)
+		}
+
 		// Extract to destination
 		target := filepath.Join(dst, filepath.Base(header.Name))
 
@@ -255,12 +311,18 @@ func (dm *DependencyManager) extractTarGz(src, dst string) error {
 			return err
 		}
 
-		// Copy file contents
-		if _, err := io.Copy(outFile, tr); err != nil {
-			outFile.Close()
+		// Copy file contents without trusting the header size
+		written, err := io.CopyN(outFile, tr, remaining+1)
+		outFile.Close()
+		if err != nil && err != io.EOF {
+			os.Remove(target)
 			return err
 		}
-		outFile.Close()
+		if written > remaining {
+			os.Remove(target)
+			return fmt.Errorf("archive entry %q exceeds the maximum extract size of %d bytes", header.Name, dm.MaxExtractSize)
+		}
+		remaining -= written
 
 		// Make executable if it's a binary
 		if strings.Contains(header.Name, "opm") {
@@ -271,6 +333,101 @@ func (dm *DependencyManager) extractTarGz(src, dst string) error {
 	return nil
 }
 
+// extractZip extracts the regular files of a zip file into the destination directory, with the
+// same safeguards as extractTarGz: entries are flattened to their base name, entries whose path
+// escapes the destination are rejected, links are skipped, and extraction stops once
+// MaxExtractSize bytes are written.
+func (dm *DependencyManager) extractZip(src, dst string) error {
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	remaining := dm.MaxExtractSize
+	if remaining <= 0 {
+		remaining = defaultMaxExtractSize
+	}
+
+	for _, entry := range reader.File {
+		// Refuse entries that would escape the destination if extracted as named
+		if !isWithinDir(dst, entry.Name) {
+			return fmt.Errorf("archive entry %q escapes destination directory", entry.Name)
+		}
+
+		// Links could point outside the destination, so never recreate them
+		if entry.Mode()&os.ModeSymlink != 0 {
+			dm.Logger.Warnf("Skipping link %s in archive %s", entry.Name, src)
+			continue
+		}
+
+		// Skip directories and other non-regular entries
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		if entry.UncompressedSize64 > uint64(remaining) {
+			return fmt.Errorf("archive entry %q exceeds the maximum extract size of %d bytes", entry.Name, dm.MaxExtractSize)
+		}
+
+		written, err := dm.extractZipEntry(entry, filepath.Join(dst, filepath.Base(entry.Name)), remaining)
+		if err != nil {
+			return err
+		}
+		remaining -= written
+	}
+
+	return nil
+}
+
+// extractZipEntry writes one zip entry to target, failing once more than limit bytes come out of
+// it whatever size its header claims
+func (dm *DependencyManager) extractZipEntry(entry *zip.File, target string, limit int64) (int64, error) {
+	in, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, err
+	}
+	outFile, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := io.CopyN(outFile, in, limit+1)
+	outFile.Close()
+	if err != nil && err != io.EOF {
+		os.Remove(target)
+		return 0, err
+	}
+	if written > limit {
+		os.Remove(target)
+		return 0, fmt.Errorf("archive entry %q exceeds the maximum extract size of %d bytes", entry.Name, dm.MaxExtractSize)
+	}
+
+	// Make executable if it's a binary
+	if strings.Contains(entry.Name, "opm") {
+		os.Chmod(target, 0755)
+	}
+	return written, nil
+}
+
+// isWithinDir reports whether the archive entry name stays inside dir once joined and cleaned
+func isWithinDir(dir, name string) bool {
+	if filepath.IsAbs(name) {
+		return false
+	}
+	target := filepath.Join(dir, name)
+	rel, err := filepath.Rel(filepath.Clean(dir), target)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // copyFile copies a file from src to dst
 func (dm *DependencyManager) copyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
@@ -0,0 +1,245 @@
+package pkg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tarEntry describes a single member of a test archive
+type tarEntry struct {
+	name     string
+	body     string
+	typeflag byte
+	linkname string
+}
+
+// writeTarGz creates a tar.gz archive with the given entries and returns its path
+func writeTarGz(t *testing.T, entries []tarEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "archive.tar.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	gzw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gzw)
+	for _, entry := range entries {
+		typeflag := entry.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		header := &tar.Header{
+			Name:     entry.name,
+			Mode:     0644,
+			Size:     int64(len(entry.body)),
+			Typeflag: typeflag,
+			Linkname: entry.linkname,
+		}
+		if typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write header: %v", err)
+		}
+		if typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(entry.body)); err != nil {
+				t.Fatalf("Failed to write body: %v", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	return path
+}
+
+func TestExtractTarGz(t *testing.T) {
+	archive := writeTarGz(t, []tarEntry{
+		{name: "opm-bundle/opm-rhel8", body: "binary"},
+		{name: "opm-bundle/link", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"},
+	})
+
+	dst := t.TempDir()
+	dm := NewDependencyManager(dst, nil)
+	if err := dm.extractTarGz(archive, dst); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "opm-rhel8"))
+	if err != nil {
+		t.Fatalf("Expected extracted file: %v", err)
+	}
+	if string(data) != "binary" {
+		t.Errorf("Expected file content 'binary', got %q", string(data))
+	}
+	if _, err := os.Lstat(filepath.Join(dst, "link")); !os.IsNotExist(err) {
+		t.Errorf("Expected symlink to be skipped")
+	}
+}
+
+func TestExtractTarGzMalicious(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []tarEntry
+		maxSize     int64
+		expectedErr string
+	}{
+		{
+			name:        "parent directory traversal",
+			entries:     []tarEntry{{name: "../../evil", body: "pwned"}},
+			expectedErr: "escapes destination",
+		},
+		{
+			name:        "nested traversal",
+			entries:     []tarEntry{{name: "bin/../../evil", body: "pwned"}},
+			expectedErr: "escapes destination",
+		},
+		{
+			name:        "absolute path",
+			entries:     []tarEntry{{name: "/tmp/evil", body: "pwned"}},
+			expectedErr: "escapes destination",
+		},
+		{
+			name:        "oversized member",
+			entries:     []tarEntry{{name: "opm", body: strings.Repeat("x", 2048)}},
+			maxSize:     1024,
+			expectedErr: "exceeds the maximum extract size",
+		},
+		{
+			name: "oversized total",
+			entries: []tarEntry{
+				{name: "a", body: strings.Repeat("x", 600)},
+				{name: "b", body: strings.Repeat("x", 600)},
+			},
+			maxSize:     1024,
+			expectedErr: "exceeds the maximum extract size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := writeTarGz(t, tt.entries)
+			dst := filepath.Join(t.TempDir(), "dst")
+			dm := NewDependencyManager(dst, nil)
+			if tt.maxSize > 0 {
+				dm.MaxExtractSize = tt.maxSize
+			}
+
+			err := dm.extractTarGz(archive, dst)
+			if err == nil {
+				t.Fatalf("Expected error containing %q", tt.expectedErr)
+			}
+			if !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(dst), "evil")); !os.IsNotExist(err) {
+				t.Errorf("Expected no file written outside the destination")
+			}
+		})
+	}
+}
+
+// writeZip builds a zip archive in memory with the given entries, where a name ending in "/"
+// is a directory, and returns the path it was saved to
+func writeZip(t *testing.T, entries []tarEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", entry.name, err)
+		}
+		if _, err := w.Write([]byte(entry.body)); err != nil {
+			t.Fatalf("Failed to write body: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip writer: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "archive.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to save archive: %v", err)
+	}
+	return path
+}
+
+func TestExtractZip(t *testing.T) {
+	archive := writeZip(t, []tarEntry{
+		{name: "opm-windows/"},
+		{name: "opm-windows/opm.exe", body: "binary"},
+	})
+
+	dst := t.TempDir()
+	dm := NewDependencyManager(dst, nil)
+	if err := dm.extractZip(archive, dst); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	target := filepath.Join(dst, opmBinaryName("windows"))
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Expected extracted binary at %s: %v", target, err)
+	}
+	if string(data) != "binary" {
+		t.Errorf("Expected file content 'binary', got %q", string(data))
+	}
+	if info, err := os.Stat(target); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected the binary to be executable, got %v (%v)", info.Mode(), err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "opm-windows")); !os.IsNotExist(err) {
+		t.Errorf("Expected directory entries to be skipped")
+	}
+}
+
+func TestExtractZipMalicious(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []tarEntry
+		maxSize     int64
+		expectedErr string
+	}{
+		{
+			name:        "parent directory traversal",
+			entries:     []tarEntry{{name: "../../evil", body: "pwned"}},
+			expectedErr: "escapes destination",
+		},
+		{
+			name:        "oversized total",
+			entries:     []tarEntry{{name: "a", body: strings.Repeat("x", 600)}, {name: "b", body: strings.Repeat("x", 600)}},
+			maxSize:     1024,
+			expectedErr: "exceeds the maximum extract size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := writeZip(t, tt.entries)
+			dst := filepath.Join(t.TempDir(), "dst")
+			dm := NewDependencyManager(dst, nil)
+			if tt.maxSize > 0 {
+				dm.MaxExtractSize = tt.maxSize
+			}
+
+			err := dm.extractZip(archive, dst)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(dst), "evil")); !os.IsNotExist(err) {
+				t.Errorf("Expected no file written outside the destination")
+			}
+		})
+	}
+}
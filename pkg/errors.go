@@ -1,8 +1,16 @@
 package pkg
 
 import (
+	"compress/zlib"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/format/idxfile"
+	"github.com/go-git/go-git/v5/plumbing/format/objfile"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 // ErrorType represents different types of errors that can occur
@@ -15,6 +23,7 @@ const (
 	ErrorTypeFileSystem  ErrorType = "FILESYSTEM_ERROR"
 	ErrorTypeValidation  ErrorType = "VALIDATION_ERROR"
 	ErrorTypeTimeout     ErrorType = "TIMEOUT_ERROR"
+	ErrorTypeCorruptRepo ErrorType = "CORRUPT_REPOSITORY_ERROR"
 	ErrorTypeUnknown     ErrorType = "UNKNOWN_ERROR"
 )
 
@@ -87,6 +96,7 @@ func (e *AnalyzerError) GetRetryDelay() time.Duration {
 // ErrorHandler handles errors with retry logic and logging
 type ErrorHandler struct {
 	MaxRetries int
+	Sleep      func(time.Duration) // Waits between attempts; tests can replace it to retry without waiting
 	Logger     interface {
 		Errorf(format string, args ...interface{})
 		Warnf(format string, args ...interface{})
@@ -102,6 +112,7 @@ func NewErrorHandler(maxRetries int, logger interface {
 }) *ErrorHandler {
 	return &ErrorHandler{
 		MaxRetries: maxRetries,
+		Sleep:      time.Sleep,
 		Logger:     logger,
 	}
 }
@@ -138,7 +149,11 @@ func (eh *ErrorHandler) HandleWithRetry(operation func() error, operationName st
 		eh.Logger.Warnf("Operation '%s' failed (attempt %d/%d): %v. Retrying in %v...", 
 			operationName, attempt+1, eh.MaxRetries+1, err, delay)
 		
-		time.Sleep(delay)
+		if eh.Sleep != nil {
+			eh.Sleep(delay)
+		} else {
+			time.Sleep(delay)
+		}
 	}
 	
 	// Log final error
@@ -153,4 +168,120 @@ func WrapError(err error, errorType ErrorType, message string, context map[strin
 		analyzerErr.WithContext(k, v)
 	}
 	return analyzerErr
-}
\ No newline at end of file
+}
+
+// corruptObjectMessages are fragments of go-git errors that do not support errors.Is
+// but indicate a damaged object database
+var corruptObjectMessages = []string{
+	"invalid git object",
+	"zlib reading error",
+	"malformed pack file signature",
+	"empty packfile",
+}
+
+// IsCorruptObjectError reports whether an error from reading a cloned repository points to a
+// corrupt object database rather than a problem with the repository itself. Only checksum and
+// zlib or packfile decode errors count; a missing object (a shallow boundary, a truncated
+// fetch) is not corruption.
+func IsCorruptObjectError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var analyzerErr *AnalyzerError
+	if errors.As(err, &analyzerErr) && analyzerErr.Type == ErrorTypeCorruptRepo {
+		return true
+	}
+
+	for _, target := range []error{
+		packfile.ErrReferenceDeltaNotFound,
+		packfile.ErrInvalidDelta,
+		packfile.ErrDeltaCmd,
+		objfile.ErrHeader,
+		objfile.ErrNegativeSize,
+		idxfile.ErrMalformedIdxFile,
+		zlib.ErrChecksum,
+		zlib.ErrHeader,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	message := err.Error()
+	for _, fragment := range corruptObjectMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// authFailureMessages are fragments of clone errors that mean the credentials were missing
+// or rejected, for transports whose errors do not support errors.Is
+var authFailureMessages = []string{
+	"authentication required",
+	"authorization failed",
+	"unable to authenticate",
+	"401 unauthorized",
+	"403 forbidden",
+}
+
+// IsAuthError reports whether a clone or fetch failed because the repository needs
+// credentials (HTTP 401/403 or an SSH authentication failure)
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, fragment := range authFailureMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAuthRequiredError classifies a clone failure caused by missing or rejected credentials
+// as a git error with a clear "authentication required" message instead of go-git's. Retrying
+// with the same credentials cannot succeed, so unlike other clone failures it is not retried;
+// the way to provide credentials is recorded as its remediation.
+func NewAuthRequiredError(repoURL string, err error) *AnalyzerError {
+	return WrapError(err, ErrorTypeGit, "authentication required to clone repository", map[string]interface{}{
+		"repository":  repoURL,
+		"remediation": authRemediation(repoURL),
+	})
+}
+
+// authRemediation explains how to provide credentials for the repository's host
+func authRemediation(repoURL string) string {
+	host := repositoryHost(repoURL)
+	if host == "" {
+		host = "the repository host"
+	}
+	return fmt.Sprintf("set GIT_TOKEN (and GIT_USERNAME if needed) or add a machine entry for %s to ~/.netrc", host)
+}
+
+// ErrorRemediation returns the suggested fix recorded on an error, or an empty string when
+// there is none
+func ErrorRemediation(err error) string {
+	var analyzerErr *AnalyzerError
+	if errors.As(err, &analyzerErr) {
+		if remediation, ok := analyzerErr.Context["remediation"].(string); ok {
+			return remediation
+		}
+	}
+	return ""
+}
+
+// ErrorWithRemediation returns the error message followed by its remediation, if any
+func ErrorWithRemediation(err error) string {
+	if remediation := ErrorRemediation(err); remediation != "" {
+		return fmt.Sprintf("%v (%s)", err, remediation)
+	}
+	return err.Error()
+}
@@ -1,9 +1,21 @@
 package pkg
 
 import (
+	"compress/zlib"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 func TestAnalyzerError(t *testing.T) {
@@ -120,6 +132,58 @@ func TestWrapError(t *testing.T) {
 	}
 }
 
+func TestIsCorruptObjectError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "missing object wrapped by analysis",
+			err:      WrapError(fmt.Errorf("commit 1a2b3c4d: %w", plumbing.ErrObjectNotFound), ErrorTypeGit, "failed to get commit log", nil),
+			expected: false,
+		},
+		{
+			name:     "truncated read",
+			err:      fmt.Errorf("reading packfile: %w", io.ErrUnexpectedEOF),
+			expected: false,
+		},
+		{
+			name:     "zlib checksum",
+			err:      fmt.Errorf("reading object: %w", zlib.ErrChecksum),
+			expected: true,
+		},
+		{
+			name:     "packfile zlib error",
+			err:      packfile.ErrZLib.AddDetails("unexpected EOF"),
+			expected: true,
+		},
+		{
+			name:     "already classified",
+			err:      WrapError(nil, ErrorTypeCorruptRepo, "object database still corrupt after a fresh clone", nil),
+			expected: true,
+		},
+		{
+			name:     "missing branch reference",
+			err:      WrapError(plumbing.ErrReferenceNotFound, ErrorTypeGit, "failed to get main/master branch reference", nil),
+			expected: false,
+		},
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsCorruptObjectError(tt.err); result != tt.expected {
+				t.Errorf("Expected %v for %v, got %v", tt.expected, tt.err, result)
+			}
+		})
+	}
+}
+
 func TestErrorHandler(t *testing.T) {
 	// Mock logger
 	mockLogger := &mockLogger{}
@@ -163,6 +227,72 @@ func TestErrorHandler(t *testing.T) {
 	}
 }
 
+func TestErrorHandlerRetriesWithInjectedSleep(t *testing.T) {
+	tests := []struct {
+		name           string
+		errorType      ErrorType
+		failures       int
+		expectAttempts int
+		expectDelays   []time.Duration
+		expectSuccess  bool
+	}{
+		{
+			name:           "network error retried the full count",
+			errorType:      ErrorTypeNetwork,
+			failures:       10,
+			expectAttempts: 4,
+			expectDelays:   []time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:           "timeout recovers on the last retry",
+			errorType:      ErrorTypeTimeout,
+			failures:       3,
+			expectAttempts: 4,
+			expectDelays:   []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second},
+			expectSuccess:  true,
+		},
+		{
+			name:           "git clone failure recovers after one retry",
+			errorType:      ErrorTypeGit,
+			failures:       1,
+			expectAttempts: 2,
+			expectDelays:   []time.Duration{3 * time.Second},
+			expectSuccess:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &mockLogger{}
+			var delays []time.Duration
+			handler := NewErrorHandler(3, logger)
+			handler.Sleep = func(d time.Duration) { delays = append(delays, d) }
+
+			attempts := 0
+			err := handler.HandleWithRetry(func() error {
+				attempts++
+				if attempts <= tt.failures {
+					return NewAnalyzerError(tt.errorType, "failed to clone repository", errors.New("connection reset"))
+				}
+				return nil
+			}, tt.name)
+
+			if (err == nil) != tt.expectSuccess {
+				t.Errorf("Expected success %v, got error %v", tt.expectSuccess, err)
+			}
+			if attempts != tt.expectAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectAttempts, attempts)
+			}
+			if fmt.Sprint(delays) != fmt.Sprint(tt.expectDelays) {
+				t.Errorf("Expected delays %v, got %v", tt.expectDelays, delays)
+			}
+			if logger.retryCount != len(tt.expectDelays) {
+				t.Errorf("Expected %d retry warnings, got %d", len(tt.expectDelays), logger.retryCount)
+			}
+		})
+	}
+}
+
 // Mock logger for testing
 type mockLogger struct {
 	retryCount int
@@ -177,3 +307,74 @@ func (m *mockLogger) Infof(format string, args ...interface{}) {}
 func (m *mockLogger) reset() {
 	m.retryCount = 0
 }
+
+func TestIsAuthError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "http 401", err: fmt.Errorf("clone: %w", transport.ErrAuthenticationRequired), expected: true},
+		{name: "http 403", err: transport.ErrAuthorizationFailed, expected: true},
+		{name: "ssh handshake", err: errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey]"), expected: true},
+		{name: "repository not found", err: transport.ErrRepositoryNotFound, expected: false},
+		{name: "network error", err: errors.New("dial tcp: connection refused"), expected: false},
+		{name: "nil error", err: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsAuthError(tt.err); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNewAuthRequiredError(t *testing.T) {
+	tests := []struct {
+		repoURL      string
+		expectedHost string
+	}{
+		{repoURL: "https://github.com/example/private-operator", expectedHost: "github.com"},
+		{repoURL: "git@gitlab.example.com:team/operator.git", expectedHost: "gitlab.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoURL, func(t *testing.T) {
+			err := NewAuthRequiredError(tt.repoURL, transport.ErrAuthenticationRequired)
+			if err.Type != ErrorTypeGit || err.IsRetryable() {
+				t.Errorf("Expected a non-retryable git error, got %s (retryable: %v)", err.Type, err.IsRetryable())
+			}
+			if !strings.HasPrefix(err.Error(), "[GIT_ERROR] authentication required") {
+				t.Errorf("Expected an authentication required message, got %q", err.Error())
+			}
+			remediation := ErrorRemediation(fmt.Errorf("process: %w", err))
+			if !strings.Contains(remediation, "GIT_TOKEN") || !strings.Contains(remediation, tt.expectedHost) {
+				t.Errorf("Expected remediation naming GIT_TOKEN and %s, got %q", tt.expectedHost, remediation)
+			}
+			if section := NewReleaseNoteFormatter().FormatErrorSection(tt.repoURL, err); !strings.Contains(section, "Remediation: "+remediation) {
+				t.Errorf("Expected the error section to show the remediation, got:\n%s", section)
+			}
+		})
+	}
+
+	if remediation := ErrorRemediation(errors.New("clone failed")); remediation != "" {
+		t.Errorf("Expected no remediation for an unclassified error, got %q", remediation)
+	}
+}
+
+func TestCloneRepositoryAuthRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	err := vtm.cloneRepository(context.Background(), server.URL+"/org/private", filepath.Join(vtm.WorkDir, "private"))
+
+	var analyzerErr *AnalyzerError
+	if !errors.As(err, &analyzerErr) || analyzerErr.Type != ErrorTypeGit || analyzerErr.Message != "authentication required to clone repository" {
+		t.Fatalf("Expected an authentication required git error, got %v", err)
+	}
+}
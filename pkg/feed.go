@@ -0,0 +1,179 @@
+package pkg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// atomNamespace is the XML namespace of Atom 1.0 (RFC 4287)
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// feedID identifies the feeds of release activity; a feed published at ReportURL uses that instead
+const feedID = "urn:prega-operator-analyzer:activity"
+
+// atomFeed is an Atom feed whose entries are the latest activity of each repository
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomEntry is one repository's latest activity
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  *atomPerson `xml:"author,omitempty"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Summary string      `xml:"summary"`
+
+	updated time.Time
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// repositoryFeedEntry builds the entry of a repository whose latest commit is commit. The entry
+// links to the commit on HTTP(S) repositories; other entries are identified by the commit hash.
+func repositoryFeedEntry(repoURL, name string, commit CommitInfo, updated time.Time, summary string, links CommitURLTemplates) atomEntry {
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	entry := atomEntry{
+		Title:   fmt.Sprintf("%s: %s", name, strings.TrimSpace(subject)),
+		Updated: updated.UTC().Format(time.RFC3339),
+		Summary: summary,
+		updated: updated,
+	}
+	if commit.Author != "" {
+		entry.Author = &atomPerson{Name: commit.Author}
+	}
+
+	hash := commit.fullHash
+	if hash == "" {
+		hash = commit.Hash
+	}
+	entry.ID = "urn:git:" + hash
+	if strings.HasPrefix(repoURL, "https://") || strings.HasPrefix(repoURL, "http://") {
+		href := links.CommitURL(repoURL, commit.fullHash, commit.Hash)
+		entry.ID = href
+		entry.Link = &atomLink{Href: href, Rel: "alternate"}
+	}
+	return entry
+}
+
+// marshalAtomFeed renders the entries, most recent first, as an Atom document. The feed is as
+// recent as its newest entry, or updated when it has none. Text is escaped by encoding/xml,
+// which also replaces characters XML cannot carry, so any commit message yields a valid feed.
+func marshalAtomFeed(title, id, link string, updated time.Time, entries []atomEntry) ([]byte, error) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].updated.After(entries[j].updated)
+	})
+	if len(entries) > 0 {
+		updated = entries[0].updated
+	}
+	feed := atomFeed{
+		Xmlns:   atomNamespace,
+		ID:      id,
+		Title:   title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: "Prega Operator Analyzer"},
+		Entries: entries,
+	}
+	if link != "" {
+		feed.Link = &atomLink{Href: link, Rel: "alternate"}
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// feedRepositoryName is the title of a repository in the feed: the catalog's display name when
+// known, else the repository name
+func feedRepositoryName(repoURL string, info RepositoryInfo) string {
+	if info.DisplayName != "" {
+		return info.DisplayName
+	}
+	return extractRepoNameFromURL(repoURL)
+}
+
+// notesFeedSummary describes a repository's analysis window in its feed entry
+func notesFeedSummary(format *ReleaseNoteFormat) string {
+	summary := format.WeeklySummary
+	return fmt.Sprintf("%d commits, %d lines changed by %d contributors (%s). Latest commit %s by %s.",
+		summary.TotalCommits, summary.TotalLinesChanged, summary.ActiveContributors, format.AnalysisPeriod,
+		format.LatestCommit.Hash, format.LatestCommit.Author)
+}
+
+// renderAtomReport renders the latest activity of each successfully analyzed repository as an
+// Atom feed. Repositories without a commit history, such as tarball snapshots, are left out.
+func (vtm *VibeToolsManager) renderAtomReport(report *RunReport) ([]byte, error) {
+	var entries []atomEntry
+	for _, repo := range report.Repositories {
+		format := repo.ReleaseNotes
+		if repo.Status != RepositoryStatusSuccess || format == nil || format.Snapshot != nil || format.LatestCommit.Hash == "" {
+			continue
+		}
+		entries = append(entries, repositoryFeedEntry(repo.Repository, feedRepositoryName(repo.Repository, format.RepositoryInfo),
+			format.LatestCommit, format.LatestCommit.Date, notesFeedSummary(format), vtm.CommitURLs))
+	}
+
+	id := feedID
+	if vtm.ReportURL != "" {
+		id = vtm.ReportURL
+	}
+	updated := vtm.analysisEnd()
+	if report.GeneratedAt != nil {
+		updated = *report.GeneratedAt
+	}
+	return marshalAtomFeed("Prega operator release notes", id, vtm.ReportURL, updated, entries)
+}
+
+// handleFeed serves the latest activity of every repository as an Atom feed. Each entry is the
+// tip of the repository's default branch, found with the same cached lookups as activity=true.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	repos := s.Repositories
+	repoInfo := s.RepositoryInfo
+	s.mu.Unlock()
+
+	activity := s.repositoryActivity(r.Context(), repos)
+	var entries []atomEntry
+	for _, repo := range repos {
+		when, ok := activity[repo]
+		if !ok {
+			continue
+		}
+		commit := s.latestCommit(repo)
+		info := RepositoryInfo{}
+		if catalog, ok := repoInfo[repo]; ok {
+			info.applyCatalog(catalog)
+		}
+		summary := fmt.Sprintf("Latest commit %s by %s on %s.", commit.Hash, commit.Author, s.defaultBranch(repo))
+		entries = append(entries, repositoryFeedEntry(repo, feedRepositoryName(repo, info), commit, when, summary, s.CommitURLs))
+	}
+
+	data, err := marshalAtomFeed("Prega operator activity", feedID, "", time.Now(), entries)
+	if err != nil {
+		http.Error(w, "Failed to render feed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write(data)
+}
@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestRenderAtomReport(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.Formatter.OmitTimestamp = true
+	vtm.ReportURL = "https://reports.example.com/weekly.html"
+
+	end := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	latest := CommitInfo{
+		Hash:     "a1b2c3d4",
+		Message:  "Escape <script> & \"quotes\"\x01 in CRDs\n\nLonger body",
+		Author:   "Alice",
+		Date:     end,
+		fullHash: "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+	}
+	format := vtm.Formatter.CreateStandardFormat(
+		"https://github.com/test/repo",
+		end.AddDate(0, 0, -7),
+		end,
+		latest,
+		WeeklySummary{TotalCommits: 4, TotalLinesChanged: 120, ActiveContributors: 2},
+		nil,
+		nil,
+	)
+	format.RepositoryInfo.DisplayName = "Test & Repo Operator"
+	cloneErr := errors.New("clone failed")
+	report := &RunReport{
+		Total:      2,
+		Successful: 1,
+		Failed:     1,
+		Repositories: []RepositoryReport{
+			{Repository: "https://github.com/test/repo", Status: RepositoryStatusSuccess, ReleaseNotes: &format},
+			{Repository: "https://github.com/test/broken", Status: RepositoryStatusFailed, Error: cloneErr.Error(), err: cloneErr},
+		},
+	}
+
+	data, err := vtm.renderAtomReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Error("Expected an XML declaration")
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("Feed is not well-formed: %v\n%s", err, data)
+	}
+	if feed.ID != vtm.ReportURL || feed.Link == nil || feed.Link.Href != vtm.ReportURL {
+		t.Errorf("Expected the report URL as the feed's id and link, got %q", feed.ID)
+	}
+	if feed.Updated != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected the feed to be as recent as its entry, got %s", feed.Updated)
+	}
+	if len(feed.Entries) != 1 {
+		t.Fatalf("Expected only the analyzed repository in the feed, got %d entries", len(feed.Entries))
+	}
+
+	entry := feed.Entries[0]
+	if entry.Title != "Test & Repo Operator: Escape <script> & \"quotes\"� in CRDs" {
+		t.Errorf("Unexpected entry title %q", entry.Title)
+	}
+	commitURL := "https://github.com/test/repo/commit/a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+	if entry.ID != commitURL || entry.Link == nil || entry.Link.Href != commitURL {
+		t.Errorf("Expected the entry to link to %s, got id %q", commitURL, entry.ID)
+	}
+	if entry.Author == nil || entry.Author.Name != "Alice" {
+		t.Errorf("Expected the commit author as the entry's author, got %+v", entry.Author)
+	}
+	if !strings.HasPrefix(entry.Summary, "4 commits, 120 lines changed by 2 contributors") {
+		t.Errorf("Unexpected entry summary %q", entry.Summary)
+	}
+}
+
+func TestHandleFeed(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "Add <feature> & docs")
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.SetRepositories([]string{dir})
+
+	recorder := httptest.NewRecorder()
+	s.handleFeed(recorder, httptest.NewRequest(http.MethodGet, "/api/feed.xml", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/atom+xml") {
+		t.Errorf("Expected an Atom content type, got %s", contentType)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("Feed is not well-formed: %v", err)
+	}
+	if len(feed.Entries) != 1 {
+		t.Fatalf("Expected one entry, got %d", len(feed.Entries))
+	}
+	entry := feed.Entries[0]
+	if !strings.HasSuffix(entry.Title, ": Add <feature> & docs") {
+		t.Errorf("Unexpected entry title %q", entry.Title)
+	}
+	// Local repositories have no web page, so the entry is identified by its commit
+	if entry.ID != "urn:git:"+head.Hash().String() || entry.Link != nil {
+		t.Errorf("Expected a commit URN without link, got id %q", entry.ID)
+	}
+	if !strings.Contains(entry.Summary, "on master") {
+		t.Errorf("Expected the default branch in the summary, got %q", entry.Summary)
+	}
+}
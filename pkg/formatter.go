@@ -2,68 +2,195 @@ package pkg
 
 import (
 	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // ReleaseNoteFormat defines the structure for consistent release notes
 type ReleaseNoteFormat struct {
-	Header           string
-	RepositoryInfo   RepositoryInfo
-	AnalysisPeriod   string
-	AnalysisDays     int
-	AnalysisStart    time.Time
-	AnalysisEnd      time.Time
-	LatestCommit     CommitInfo
-	WeeklySummary    WeeklySummary
-	Contributors     []Contributor
-	Commits          []CommitDetail
-	Footer           string
+	Header         string              `json:"header"`
+	RepositoryInfo RepositoryInfo      `json:"repositoryInfo"`
+	Branch         string              `json:"branch,omitempty"` // Branch analyzed, set when several branches of the repository are selected
+	AnalysisPeriod string              `json:"analysisPeriod"`
+	AnalysisDays   int                 `json:"analysisDays"`
+	AbsoluteRange  bool                `json:"absoluteRange,omitempty"` // The window was given as explicit dates rather than "last N days"
+	FromTag        string              `json:"fromTag,omitempty"` // With ToTag, the notes cover the commits of ToTag that are not in FromTag
+	ToTag          string              `json:"toTag,omitempty"`
+	AnalysisStart  time.Time           `json:"analysisStart"`
+	AnalysisEnd    time.Time           `json:"analysisEnd"`
+	LatestCommit   CommitInfo          `json:"latestCommit"`
+	WeeklySummary  WeeklySummary       `json:"weeklySummary"`
+	Contributors   []Contributor       `json:"contributors"`
+	RankBy         RankBy              `json:"rankBy,omitempty"` // Metric the contributors are ranked by; empty means commits
+	OnlyAuthors    []string            `json:"onlyAuthors,omitempty"` // Authors the commits and stats were restricted to, if any
+	Commits        []CommitDetail      `json:"commits"`
+	VendoredCommits []CommitDetail     `json:"vendoredCommits,omitempty"` // Commits that only touch vendored dependencies, kept out of Commits
+	Submodules     []SubmoduleChanges  `json:"submodules,omitempty"` // Commits of each submodule in the window, when submodules are analyzed
+	Snapshot       *TarballSnapshot    `json:"snapshot,omitempty"` // Set when the repository could not be cloned and only its tarball was read
+	Upstream       *UpstreamComparison `json:"upstream,omitempty"`
+	HistoryRewrite *HistoryRewrite     `json:"historyRewrite,omitempty"` // Set when the branch was force-pushed since the previous run
+	LatestRelease  *ReleaseTag         `json:"latestRelease,omitempty"` // Newest semver tag as of the window's end, looked up when stale releases are checked
+	NotableChanges []NotableChange     `json:"notableChanges,omitempty"`
+	BreakingChanges []BreakingChange   `json:"breakingChanges,omitempty"` // Commits declaring a BREAKING CHANGE footer or a "!" subject
+	MergedPullRequests []MergedPullRequest `json:"mergedPullRequests,omitempty"` // Requests whose merge commit is in the window
+	Activity       *CommitActivity     `json:"activity,omitempty"` // Commit histogram, only computed when the heatmap is enabled
+	Footer         string              `json:"footer,omitempty"`
 }
 
 // RepositoryInfo contains basic repository information
 type RepositoryInfo struct {
-	URL         string
-	Name        string
-	Description string
+	URL              string `json:"url"`
+	Name             string `json:"name,omitempty"`
+	Description      string `json:"description,omitempty"`
+	DisplayName      string `json:"displayName,omitempty"`  // Operator display name from the catalog's CSV metadata
+	Capabilities     string `json:"capabilities,omitempty"` // OLM capability level from the catalog's CSV metadata
+	OpenPullRequests *int   `json:"openPullRequests,omitempty"` // Open PRs/MRs reported by the provider API, when known
+}
+
+// applyCatalog fills in the package name, description, display name and capability level the
+// catalog declares for the repository
+func (r *RepositoryInfo) applyCatalog(info ParserRepositoryInfo) {
+	r.Name = info.Name
+	r.Description = info.Description
+	r.DisplayName = info.DisplayName
+	r.Capabilities = info.Capabilities
 }
 
 // CommitInfo contains latest commit information
 type CommitInfo struct {
-	Hash    string
-	Message string
-	Author  string
-	Date    time.Time
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+
+	fullHash string // Unabbreviated hash, used to build commit links
 }
 
 // WeeklySummary contains weekly activity statistics
 type WeeklySummary struct {
-	TotalCommits     int
-	TotalLinesChanged int
-	ActiveContributors int
-	AnalysisStart    time.Time
-	AnalysisEnd      time.Time
+	TotalCommits       int       `json:"totalCommits"`
+	TotalLinesChanged  int       `json:"totalLinesChanged"`
+	ActiveContributors int       `json:"activeContributors"`
+	AnalysisStart      time.Time `json:"analysisStart"`
+	AnalysisEnd        time.Time `json:"analysisEnd"`
+	StatsSkipped       int       `json:"statsSkipped,omitempty"` // Commits whose changed lines could not be computed
+	MergedPRs          int       `json:"mergedPRs"`              // Merge commits of pull/merge requests, approximating PR throughput
+}
+
+// statsUnavailableFraction is the share of commits whose stats failed above which the
+// repository's line counts are reported as unavailable rather than as a misleading total
+const statsUnavailableFraction = 0.5
+
+// StatsUnavailable reports whether too many commits failed for TotalLinesChanged to be meaningful
+func (w WeeklySummary) StatsUnavailable() bool {
+	return w.StatsSkipped > 0 && float64(w.StatsSkipped) >= statsUnavailableFraction*float64(w.TotalCommits)
+}
+
+// statsUnavailableNote is the warning shown in place of the line counts of such a repository
+func (w WeeklySummary) statsUnavailableNote() string {
+	return fmt.Sprintf("line-change statistics unavailable for this repository (%d of %d commits could not be diffed)", w.StatsSkipped, w.TotalCommits)
+}
+
+// linesChangedValue is the line count to display, or "unavailable"
+func (w WeeklySummary) linesChangedValue() string {
+	if w.StatsUnavailable() {
+		return "unavailable"
+	}
+	return strconv.Itoa(w.TotalLinesChanged)
 }
 
 // Contributor represents a contributor with their activity
 type Contributor struct {
-	Name        string
-	CommitCount int
-	Rank        int
+	Name         string `json:"name"`
+	Email        string `json:"email,omitempty"`
+	CommitCount  int    `json:"commitCount"`
+	LinesChanged int    `json:"linesChanged"`
+	Rank         int    `json:"rank"`
+	AvatarURL    string `json:"avatarUrl,omitempty"` // Provider avatar, resolved only when avatars are enabled
+	latestCommit string         // Full hash of the contributor's most recent commit, used to look up their account
+	names        map[string]int // Commits per name the contributor committed under, to pick the name shown
 }
 
 // CommitDetail represents a detailed commit entry
 type CommitDetail struct {
-	Hash    string
-	Message string
-	Author  string
-	Date    time.Time
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Files   []string  `json:"files,omitempty"` // Paths changed by the commit, when known
+
+	fullHash string // Unabbreviated hash, used to build commit links
+}
+
+// CommitPage is one page of a list of Total commits, PageSize commits long; Page counts from 1
+type CommitPage struct {
+	Page     int
+	PageSize int
+	Total    int
+}
+
+// NewCommitPage returns a page of a list of total commits. A page before the first or past
+// the last is moved to the nearest page of the list.
+func NewCommitPage(page, pageSize, total int) CommitPage {
+	p := CommitPage{PageSize: max(pageSize, 1), Total: total}
+	p.Page = min(max(page, 1), p.TotalPages())
+	return p
+}
+
+// TotalPages is the number of pages of the list; an empty list still has one, empty, page
+func (p CommitPage) TotalPages() int {
+	if p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.PageSize - 1) / p.PageSize
+}
+
+// bounds returns the indexes of the page's first commit and the one after its last
+func (p CommitPage) bounds() (start, end int) {
+	start = (p.Page - 1) * p.PageSize
+	return start, min(start+p.PageSize, p.Total)
 }
 
 // ReleaseNoteFormatter handles consistent formatting of release notes
 type ReleaseNoteFormatter struct {
 	MaxContributors int
 	MaxCommits      int
+	OmitTimestamp   bool // Leave out generation timestamps so unchanged content produces identical output
+	LineEnding      string // LineEndingLF or LineEndingCRLF; empty uses LF
+	MaxSubjectLength int   // Show only the first line of commit messages, cut to this many characters; 0 keeps full messages
+}
+
+// Line endings of the text notes
+const (
+	LineEndingLF   = "lf"
+	LineEndingCRLF = "crlf"
+)
+
+// ParseLineEnding validates a --line-ending value; an empty value selects the host's
+// convention, CRLF on Windows and LF elsewhere
+func ParseLineEnding(value string) (string, error) {
+	switch ending := strings.ToLower(strings.TrimSpace(value)); ending {
+	case "":
+		if runtime.GOOS == "windows" {
+			return LineEndingCRLF, nil
+		}
+		return LineEndingLF, nil
+	case LineEndingLF, LineEndingCRLF:
+		return ending, nil
+	}
+	return "", fmt.Errorf("unknown line ending %q (expected lf or crlf)", value)
+}
+
+// applyLineEnding converts text to the line ending. Existing CRLF endings are normalized
+// first, so text that embeds already converted notes is not converted twice.
+func applyLineEnding(text, ending string) string {
+	if ending != LineEndingCRLF {
+		return text
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
 }
 
 // NewReleaseNoteFormatter creates a new formatter with default settings
@@ -76,6 +203,23 @@ func NewReleaseNoteFormatter() *ReleaseNoteFormatter {
 
 // FormatReleaseNote creates a consistently formatted release note
 func (rnf *ReleaseNoteFormatter) FormatReleaseNote(format ReleaseNoteFormat) string {
+	return rnf.formatReleaseNote(format, nil)
+}
+
+// FormatReleaseNotePage formats the release note listing only one page of its commits, page
+// counting from 1 and pageSize defaulting to MaxCommits. The activity summary still covers
+// every commit of the window.
+func (rnf *ReleaseNoteFormatter) FormatReleaseNotePage(format ReleaseNoteFormat, page, pageSize int) string {
+	if pageSize <= 0 {
+		pageSize = rnf.MaxCommits
+	}
+	commitPage := NewCommitPage(page, pageSize, len(format.Commits))
+	return rnf.formatReleaseNote(format, &commitPage)
+}
+
+// formatReleaseNote formats the release note listing the commits of page, or the first
+// MaxCommits commits without one
+func (rnf *ReleaseNoteFormatter) formatReleaseNote(format ReleaseNoteFormat, page *CommitPage) string {
 	var output strings.Builder
 	
 	// Header
@@ -90,18 +234,57 @@ func (rnf *ReleaseNoteFormatter) FormatReleaseNote(format ReleaseNoteFormat) str
 	if format.RepositoryInfo.Description != "" {
 		output.WriteString(fmt.Sprintf("Description: %s\n", format.RepositoryInfo.Description))
 	}
+	if format.RepositoryInfo.DisplayName != "" {
+		output.WriteString(fmt.Sprintf("Display Name: %s\n", format.RepositoryInfo.DisplayName))
+	}
+	if format.RepositoryInfo.Capabilities != "" {
+		output.WriteString(fmt.Sprintf("Capability Level: %s\n", format.RepositoryInfo.Capabilities))
+	}
+	if format.Branch != "" {
+		output.WriteString(fmt.Sprintf("Branch: %s\n", format.Branch))
+	}
+	if format.RepositoryInfo.OpenPullRequests != nil {
+		output.WriteString(fmt.Sprintf("Open Pull Requests: %d\n", *format.RepositoryInfo.OpenPullRequests))
+	}
 	output.WriteString(strings.Repeat("-", 80))
 	output.WriteString("\n")
 	
+	// A rewritten history explains commits that vanished since the previous digest
+	if format.HistoryRewrite != nil {
+		output.WriteString(fmt.Sprintf("⚠️ WARNING: %s\n\n", format.HistoryRewrite.Message()))
+	}
+	if format.WeeklySummary.StatsUnavailable() {
+		output.WriteString(fmt.Sprintf("⚠️ WARNING: %s\n\n", format.WeeklySummary.statsUnavailableNote()))
+	}
+	
+	// Breaking changes lead the notes so upgraders see them first
+	output.WriteString(formatTextBreakingChanges(format.BreakingChanges))
+	
 	// Analysis Period
 	output.WriteString(fmt.Sprintf("Analysis Period: %s\n", format.AnalysisPeriod))
-	output.WriteString(fmt.Sprintf("Analysis Start: %s\n", format.AnalysisStart.Format("2006-01-02 15:04:05")))
-	output.WriteString(fmt.Sprintf("Analysis End: %s\n\n", format.AnalysisEnd.Format("2006-01-02 15:04:05")))
+	output.WriteString(fmt.Sprintf("Analysis Start: %s\n", format.AnalysisStart.Format(rnf.analysisLayout())))
+	output.WriteString(fmt.Sprintf("Analysis End: %s\n", format.AnalysisEnd.Format(rnf.analysisLayout())))
+	if len(format.OnlyAuthors) > 0 {
+		output.WriteString(fmt.Sprintf("Author Filter: %s\n", authorFilterNote(format.OnlyAuthors)))
+	}
+	output.WriteString("\n")
+
+	// Without history only the current state can be reported; the activity sections would be empty
+	if format.Snapshot != nil {
+		output.WriteString(fmt.Sprintf("⚠️ WARNING: %s\n\n", format.Snapshot.Note()))
+		output.WriteString(formatTextSnapshot(format.Snapshot))
+		if format.Footer != "" {
+			output.WriteString("\n")
+			output.WriteString(format.Footer)
+		}
+		output.WriteString("\n\n")
+		return applyLineEnding(output.String(), rnf.LineEnding)
+	}
 	
 	// Latest Commit Information
 	output.WriteString("=== LATEST COMMIT INFORMATION ===\n")
 	output.WriteString(fmt.Sprintf("Hash: %s\n", format.LatestCommit.Hash))
-	output.WriteString(fmt.Sprintf("Message: %s\n", format.LatestCommit.Message))
+	output.WriteString(fmt.Sprintf("Message: %s\n", latestCommitMessage(format.LatestCommit.Message)))
 	output.WriteString(fmt.Sprintf("Author: %s\n", format.LatestCommit.Author))
 	output.WriteString(fmt.Sprintf("Date: %s\n\n", format.LatestCommit.Date.Format("2006-01-02 15:04:05")))
 	
@@ -109,39 +292,97 @@ func (rnf *ReleaseNoteFormatter) FormatReleaseNote(format ReleaseNoteFormat) str
 	periodLabel := getPeriodLabel(format.AnalysisDays)
 	output.WriteString(fmt.Sprintf("=== %s ACTIVITY SUMMARY ===\n", strings.ToUpper(periodLabel)))
 	output.WriteString(fmt.Sprintf("Total Commits: %d\n", format.WeeklySummary.TotalCommits))
-	output.WriteString(fmt.Sprintf("Total Lines Changed: %d\n", format.WeeklySummary.TotalLinesChanged))
+	output.WriteString(fmt.Sprintf("Merged PRs: %d\n", format.WeeklySummary.MergedPRs))
+	output.WriteString(fmt.Sprintf("Total Lines Changed: %s\n", format.WeeklySummary.linesChangedValue()))
 	output.WriteString(fmt.Sprintf("Active Contributors: %d\n\n", format.WeeklySummary.ActiveContributors))
+
+	output.WriteString(formatTextMergedPullRequests(format.MergedPullRequests))
+
+	// Upstream Comparison
+	if format.Upstream != nil {
+		output.WriteString("=== UPSTREAM COMPARISON ===\n")
+		output.WriteString(fmt.Sprintf("Upstream: %s (%s)\n", format.Upstream.UpstreamURL, format.Upstream.Branch))
+		output.WriteString(fmt.Sprintf("Status: %s\n", format.Upstream.Status()))
+		if format.Upstream.MergeBase != "" {
+			output.WriteString(fmt.Sprintf("Merge Base: %s\n", format.Upstream.MergeBase))
+		}
+		output.WriteString("\n")
+	}
+
+	// Notable Changes
+	if len(format.NotableChanges) > 0 {
+		output.WriteString("=== NOTABLE CHANGES ===\n")
+		for _, change := range format.NotableChanges {
+			output.WriteString(fmt.Sprintf("- %s (%s) by %s matched %s: %s\n",
+				rnf.truncateSubject(firstLine(change.Commit.Message)),
+				change.Commit.Hash,
+				change.Commit.Author,
+				change.Pattern,
+				strings.Join(change.Files, ", ")))
+		}
+		output.WriteString("\n")
+	}
 	
 	// Top Contributors
 	if len(format.Contributors) > 0 {
-		output.WriteString(fmt.Sprintf("=== TOP CONTRIBUTORS (LAST %d DAYS) ===\n", format.AnalysisDays))
+		output.WriteString(fmt.Sprintf("=== TOP CONTRIBUTORS (%s) ===\n", strings.ToUpper(format.windowDescription())))
 		for _, contributor := range format.Contributors {
-			output.WriteString(fmt.Sprintf("%d. %s (%d commits)\n", 
-				contributor.Rank, contributor.Name, contributor.CommitCount))
+			output.WriteString(fmt.Sprintf("%d. %s (%s)\n", 
+				contributor.Rank, contributor.Name, contributor.Activity(format.RankBy)))
 		}
 		output.WriteString("\n")
 	}
 	
+	// Commit activity histogram
+	if format.Activity != nil {
+		output.WriteString(format.Activity.FormatText())
+	}
+	
+	commits := format.Commits
+	if page != nil {
+		start, end := page.bounds()
+		commits = commits[start:end]
+	} else if len(commits) > rnf.MaxCommits {
+		commits = commits[:rnf.MaxCommits]
+	}
+	output.WriteString(rnf.formatTextChangesByType(commits))
+
 	// Recent Commits
 	if len(format.Commits) > 0 {
-		output.WriteString(fmt.Sprintf("=== COMMITS FROM LAST %d DAYS ===\n", format.AnalysisDays))
-		commitCount := len(format.Commits)
-		if commitCount > rnf.MaxCommits {
-			output.WriteString(fmt.Sprintf("(Showing first %d of %d commits)\n", rnf.MaxCommits, commitCount))
-			commitCount = rnf.MaxCommits
+		output.WriteString(fmt.Sprintf("=== COMMITS FROM %s ===\n", strings.ToUpper(format.windowDescription())))
+		if page != nil && page.TotalPages() > 1 {
+			start, end := page.bounds()
+			output.WriteString(fmt.Sprintf("(Showing commits %d-%d of %d, page %d of %d)\n", start+1, end, page.Total, page.Page, page.TotalPages()))
+		} else if len(commits) < len(format.Commits) {
+			output.WriteString(fmt.Sprintf("(Showing first %d of %d commits)\n", len(commits), len(format.Commits)))
 		}
 		
-		for i := 0; i < commitCount; i++ {
-			commit := format.Commits[i]
+		for _, commit := range commits {
 			output.WriteString(fmt.Sprintf("- %s (%s) by %s on %s\n",
-				strings.TrimSpace(commit.Message),
+				rnf.commitMessage(commit.Message),
 				commit.Hash,
 				commit.Author,
 				commit.Date.Format("2006-01-02 15:04:05")))
 		}
 	} else {
-		output.WriteString(fmt.Sprintf("=== NO COMMITS IN LAST %d DAYS ===\n", format.AnalysisDays))
-		output.WriteString(fmt.Sprintf("No commits found in the branch during the last %d days.\n", format.AnalysisDays))
+		output.WriteString(fmt.Sprintf("=== NO COMMITS IN %s ===\n", strings.ToUpper(format.windowDescription())))
+		during := "the " + format.windowDescription()
+		if format.AbsoluteRange || format.FromTag != "" {
+			during = format.windowDescription()
+		}
+		if len(format.VendoredCommits) > 0 {
+			output.WriteString(fmt.Sprintf("No commits outside vendored dependencies found in the branch during %s.\n", during))
+		} else {
+			output.WriteString(fmt.Sprintf("No commits found in the branch during %s.\n", during))
+		}
+	}
+	if len(format.VendoredCommits) > 0 {
+		output.WriteString("\n")
+		output.WriteString(rnf.formatTextVendoredCommits(format.VendoredCommits))
+	}
+	if len(format.Submodules) > 0 {
+		output.WriteString("\n")
+		output.WriteString(rnf.formatTextSubmoduleChanges(format.Submodules))
 	}
 	
 	// Footer
@@ -151,7 +392,7 @@ func (rnf *ReleaseNoteFormatter) FormatReleaseNote(format ReleaseNoteFormat) str
 	}
 	
 	output.WriteString("\n\n")
-	return output.String()
+	return applyLineEnding(output.String(), rnf.LineEnding)
 }
 
 // CreateStandardFormat creates a standard release note format structure
@@ -191,10 +432,15 @@ func (rnf *ReleaseNoteFormatter) CreateStandardFormatWithDays(
 	}
 	
 	// Calculate analysis period with dynamic days
-	period := fmt.Sprintf("Last %d days (since %s)", days, analysisStart.Format("2006-01-02 15:04:05"))
+	period := fmt.Sprintf("Last %d days (since %s)", days, analysisStart.Format(rnf.analysisLayout()))
+
+	header := fmt.Sprintf("Release Notes Generated on: %s", time.Now().Format("2006-01-02 15:04:05"))
+	if rnf.OmitTimestamp {
+		header = fmt.Sprintf("Release Notes for period ending: %s", analysisEnd.Format("2006-01-02"))
+	}
 	
 	return ReleaseNoteFormat{
-		Header: fmt.Sprintf("Release Notes Generated on: %s", time.Now().Format("2006-01-02 15:04:05")),
+		Header: header,
 		RepositoryInfo: RepositoryInfo{
 			URL: repoURL,
 		},
@@ -210,6 +456,185 @@ func (rnf *ReleaseNoteFormatter) CreateStandardFormatWithDays(
 	}
 }
 
+// SetAbsoluteRange marks the format as covering an explicit date range, so the period
+// is shown as the two dates instead of "last N days"
+func (rnf *ReleaseNoteFormatter) SetAbsoluteRange(format *ReleaseNoteFormat) {
+	format.AbsoluteRange = true
+	format.AnalysisPeriod = fmt.Sprintf("%s (%d days)", format.windowDescription(), format.AnalysisDays)
+}
+
+// SetTagRange marks the format as covering the commits between two tags, so the period is
+// shown as the tags and their dates instead of "last N days"
+func (rnf *ReleaseNoteFormatter) SetTagRange(format *ReleaseNoteFormat, fromTag, toTag string) {
+	format.FromTag = fromTag
+	format.ToTag = toTag
+	format.AnalysisPeriod = fmt.Sprintf("%s (%s to %s)", format.windowDescription(),
+		format.AnalysisStart.Format("2006-01-02"), format.AnalysisEnd.Format("2006-01-02"))
+}
+
+// windowDescription describes the analysis window for headings and messages,
+// e.g. "last 7 days", "2024-01-01 to 2024-02-01" or "v1.0.0..v1.1.0"
+func (format ReleaseNoteFormat) windowDescription() string {
+	if format.FromTag != "" {
+		return fmt.Sprintf("%s..%s", format.FromTag, format.ToTag)
+	}
+	if format.AbsoluteRange {
+		return fmt.Sprintf("%s to %s", format.AnalysisStart.Format("2006-01-02"), format.AnalysisEnd.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("last %d days", format.AnalysisDays)
+}
+
+// analysisLayout returns the time layout for the analysis window, dropping the time of day
+// when timestamps are omitted so reruns on the same day produce identical output
+func (rnf *ReleaseNoteFormatter) analysisLayout() string {
+	if rnf.OmitTimestamp {
+		return "2006-01-02"
+	}
+	return "2006-01-02 15:04:05"
+}
+
+// firstLine returns the first line of a commit message
+func firstLine(message string) string {
+	return strings.Split(strings.TrimSpace(message), "\n")[0]
+}
+
+// Commit categories of the changes-by-type section, in the order they are listed
+const (
+	CommitCategoryFeatures    = "Features"
+	CommitCategoryFixes       = "Fixes"
+	CommitCategoryPerformance = "Performance"
+	CommitCategoryRefactoring = "Refactoring"
+	CommitCategoryDocs        = "Docs"
+	CommitCategoryTests       = "Tests"
+	CommitCategoryBuild       = "Build"
+	CommitCategoryCI          = "CI"
+	CommitCategoryStyle       = "Style"
+	CommitCategoryReverts     = "Reverts"
+	CommitCategoryChores      = "Chores"
+	CommitCategoryOther       = "Other"
+)
+
+var commitCategoryOrder = []string{
+	CommitCategoryFeatures, CommitCategoryFixes, CommitCategoryPerformance, CommitCategoryRefactoring,
+	CommitCategoryDocs, CommitCategoryTests, CommitCategoryBuild, CommitCategoryCI, CommitCategoryStyle,
+	CommitCategoryReverts, CommitCategoryChores, CommitCategoryOther,
+}
+
+// commitTypeCategories maps conventional-commit types to their category
+var commitTypeCategories = map[string]string{
+	"feat":     CommitCategoryFeatures,
+	"feature":  CommitCategoryFeatures,
+	"fix":      CommitCategoryFixes,
+	"bugfix":   CommitCategoryFixes,
+	"perf":     CommitCategoryPerformance,
+	"refactor": CommitCategoryRefactoring,
+	"docs":     CommitCategoryDocs,
+	"doc":      CommitCategoryDocs,
+	"test":     CommitCategoryTests,
+	"tests":    CommitCategoryTests,
+	"build":    CommitCategoryBuild,
+	"deps":     CommitCategoryBuild,
+	"ci":       CommitCategoryCI,
+	"style":    CommitCategoryStyle,
+	"revert":   CommitCategoryReverts,
+	"chore":    CommitCategoryChores,
+}
+
+// commitTypePattern matches a conventional-commit subject prefix with an optional scope and
+// breaking marker, e.g. "feat(api)!: "
+var commitTypePattern = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?!?:`)
+
+// commitCategory returns the category of a commit from the conventional-commit type of its
+// subject, or CommitCategoryOther when the subject has no known type
+func commitCategory(message string) string {
+	match := commitTypePattern.FindStringSubmatch(firstLine(message))
+	if match == nil {
+		return CommitCategoryOther
+	}
+	if category, ok := commitTypeCategories[strings.ToLower(match[1])]; ok {
+		return category
+	}
+	return CommitCategoryOther
+}
+
+// CommitTypeGroup is the commits of one category
+type CommitTypeGroup struct {
+	Category string
+	Commits  []CommitDetail
+}
+
+// groupCommitsByType groups commits by category in commitCategoryOrder, keeping their order
+// within a category and leaving out empty categories
+func groupCommitsByType(commits []CommitDetail) []CommitTypeGroup {
+	byCategory := make(map[string][]CommitDetail)
+	for _, commit := range commits {
+		category := commitCategory(commit.Message)
+		byCategory[category] = append(byCategory[category], commit)
+	}
+	var groups []CommitTypeGroup
+	for _, category := range commitCategoryOrder {
+		if len(byCategory[category]) > 0 {
+			groups = append(groups, CommitTypeGroup{Category: category, Commits: byCategory[category]})
+		}
+	}
+	return groups
+}
+
+// formatTextChangesByType renders the changes-by-type section of the text notes, listing the
+// subject of each commit under its category; nothing without commits
+func (rnf *ReleaseNoteFormatter) formatTextChangesByType(commits []CommitDetail) string {
+	groups := groupCommitsByType(commits)
+	if len(groups) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString("=== CHANGES BY TYPE ===\n")
+	for _, group := range groups {
+		output.WriteString(fmt.Sprintf("%s (%d):\n", group.Category, len(group.Commits)))
+		for _, commit := range group.Commits {
+			output.WriteString(fmt.Sprintf("- %s (%s) by %s\n", rnf.truncateSubject(firstLine(commit.Message)), commit.Hash, commit.Author))
+		}
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// commitMessage is a commit message as the text notes list it: the whole message, or only
+// its subject cut to MaxSubjectLength when a maximum is set
+func (rnf *ReleaseNoteFormatter) commitMessage(message string) string {
+	if rnf.MaxSubjectLength <= 0 {
+		return strings.TrimSpace(message)
+	}
+	return rnf.truncateSubject(firstLine(message))
+}
+
+// latestCommitMessage is the latest commit's full message in the text notes, kept whatever
+// MaxSubjectLength says about the commit list: the subject, then the body indented below it
+func latestCommitMessage(message string) string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(lines[i], " \t\r"); line != "" {
+			lines[i] = "  " + line
+		} else {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateSubject cuts a subject to MaxSubjectLength characters, ending it with an ellipsis
+// when it was cut
+func (rnf *ReleaseNoteFormatter) truncateSubject(subject string) string {
+	runes := []rune(subject)
+	if rnf.MaxSubjectLength <= 0 || len(runes) <= rnf.MaxSubjectLength {
+		return subject
+	}
+	if rnf.MaxSubjectLength == 1 {
+		return "…"
+	}
+	return strings.TrimRight(string(runes[:rnf.MaxSubjectLength-1]), " ") + "…"
+}
+
 // getPeriodLabel returns a human-readable label for the analysis period
 func getPeriodLabel(days int) string {
 	switch {
@@ -237,9 +662,27 @@ func (rnf *ReleaseNoteFormatter) FormatErrorSection(repoURL string, err error) s
 	output.WriteString("\n")
 	output.WriteString("=== ERROR PROCESSING REPOSITORY ===\n")
 	output.WriteString(fmt.Sprintf("Error: %v\n", err))
-	output.WriteString(fmt.Sprintf("Timestamp: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	if !rnf.OmitTimestamp {
+		output.WriteString(fmt.Sprintf("Timestamp: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	}
 	output.WriteString("This repository could not be processed successfully.\n")
-	output.WriteString("Please check the repository URL and network connectivity.\n\n")
+	if remediation := ErrorRemediation(err); remediation != "" {
+		output.WriteString(fmt.Sprintf("Remediation: %s\n\n", remediation))
+	} else {
+		output.WriteString("Please check the repository URL and network connectivity.\n\n")
+	}
 	
 	return output.String()
-}
\ No newline at end of file
+}
+// FormatSkippedSection formats a repository that was intentionally not processed
+func (rnf *ReleaseNoteFormatter) FormatSkippedSection(repoURL, reason string) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("Repository: %s\n", repoURL))
+	output.WriteString(strings.Repeat("-", 80))
+	output.WriteString("\n")
+	output.WriteString("=== REPOSITORY SKIPPED ===\n")
+	output.WriteString(fmt.Sprintf("Reason: %s\n\n", reason))
+
+	return output.String()
+}
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -139,6 +140,120 @@ func TestFormatReleaseNoteWithNoCommits(t *testing.T) {
 	}
 }
 
+func TestFormatReleaseNoteAbsoluteRange(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 23, 59, 59, 0, time.UTC)
+
+	format := formatter.CreateStandardFormatWithDays(
+		"https://github.com/test/repo",
+		32,
+		since,
+		until,
+		CommitInfo{Hash: "a1b2c3d4", Message: "Test commit message", Author: "Test Author", Date: until},
+		WeeklySummary{AnalysisStart: since, AnalysisEnd: until},
+		nil,
+		nil,
+	)
+	formatter.SetAbsoluteRange(&format)
+
+	if format.AnalysisPeriod != "2024-01-01 to 2024-02-01 (32 days)" {
+		t.Errorf("Unexpected analysis period: %s", format.AnalysisPeriod)
+	}
+
+	result := formatter.FormatReleaseNote(format)
+	expected := []string{
+		"=== NO COMMITS IN 2024-01-01 TO 2024-02-01 ===",
+		"No commits found in the branch during 2024-01-01 to 2024-02-01.",
+	}
+	for _, text := range expected {
+		if !strings.Contains(result, text) {
+			t.Errorf("Expected %q in formatted output", text)
+		}
+	}
+}
+
+func TestFormatReleaseNoteCatalogMetadata(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	now := time.Now()
+	newFormat := func() ReleaseNoteFormat {
+		return formatter.CreateStandardFormatWithDays(
+			"https://github.com/test/repo",
+			7,
+			now.AddDate(0, 0, -7),
+			now,
+			CommitInfo{Hash: "a1b2c3d4", Message: "Test commit message", Author: "Test Author", Date: now},
+			WeeklySummary{},
+			nil,
+			nil,
+		)
+	}
+
+	format := newFormat()
+	format.RepositoryInfo.applyCatalog(ParserRepositoryInfo{
+		URL:          "https://github.com/test/repo",
+		Name:         "test-operator",
+		DisplayName:  "Test Operator",
+		Capabilities: "Full Lifecycle",
+	})
+	result := formatter.FormatReleaseNote(format)
+	for _, text := range []string{"Name: test-operator", "Display Name: Test Operator", "Capability Level: Full Lifecycle"} {
+		if !strings.Contains(result, text) {
+			t.Errorf("Expected %q in formatted output", text)
+		}
+	}
+
+	result = formatter.FormatReleaseNote(newFormat())
+	for _, text := range []string{"Display Name:", "Capability Level:"} {
+		if strings.Contains(result, text) {
+			t.Errorf("Expected no %q without catalog metadata", text)
+		}
+	}
+}
+
+func TestFormatReleaseNoteStatsUnavailable(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name        string
+		skipped     int
+		unavailable bool
+	}{
+		{name: "all stats computed", skipped: 0, unavailable: false},
+		{name: "a few commits skipped", skipped: 1, unavailable: false},
+		{name: "most commits skipped", skipped: 3, unavailable: true},
+		{name: "every commit skipped", skipped: 4, unavailable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := WeeklySummary{TotalCommits: 4, TotalLinesChanged: 12, StatsSkipped: tt.skipped, AnalysisStart: since, AnalysisEnd: until}
+			if summary.StatsUnavailable() != tt.unavailable {
+				t.Fatalf("StatsUnavailable() = %v, expected %v", summary.StatsUnavailable(), tt.unavailable)
+			}
+
+			format := formatter.CreateStandardFormatWithDays("https://github.com/test/repo", 7, since, until,
+				CommitInfo{Hash: "a1b2c3d4", Message: "Test commit message", Author: "Test Author", Date: until},
+				summary, nil, nil)
+			result := formatter.FormatReleaseNote(format)
+
+			warning := "line-change statistics unavailable for this repository"
+			if strings.Contains(result, warning) != tt.unavailable {
+				t.Errorf("Expected warning present = %v in:\n%s", tt.unavailable, result)
+			}
+			lines := "Total Lines Changed: 12"
+			if tt.unavailable {
+				lines = "Total Lines Changed: unavailable"
+			}
+			if !strings.Contains(result, lines) {
+				t.Errorf("Expected %q in:\n%s", lines, result)
+			}
+		})
+	}
+}
+
 func TestCreateStandardFormat(t *testing.T) {
 	formatter := NewReleaseNoteFormatter()
 
@@ -295,3 +410,280 @@ func TestFormatErrorSection(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatSkippedSection(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+
+	result := formatter.FormatSkippedSection("https://github.com/test/repo", "skipped: too large (900 MB exceeds limit of 500 MB)")
+
+	expectedSections := []string{
+		"Repository: https://github.com/test/repo",
+		"=== REPOSITORY SKIPPED ===",
+		"Reason: skipped: too large (900 MB exceeds limit of 500 MB)",
+	}
+
+	for _, section := range expectedSections {
+		if !strings.Contains(result, section) {
+			t.Errorf("Expected section '%s' not found in skipped format", section)
+		}
+	}
+}
+
+func TestCreateStandardFormatOmitTimestamp(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	formatter.OmitTimestamp = true
+
+	analysisEnd := time.Date(2024, 3, 1, 14, 30, 25, 0, time.UTC)
+	analysisStart := analysisEnd.AddDate(0, 0, -7)
+
+	format := formatter.CreateStandardFormatWithDays(
+		"https://github.com/test/repo",
+		7,
+		analysisStart,
+		analysisEnd,
+		CommitInfo{Hash: "a1b2c3d4", Message: "Test commit", Author: "Test Author", Date: analysisEnd},
+		WeeklySummary{TotalCommits: 1, AnalysisStart: analysisStart, AnalysisEnd: analysisEnd},
+		nil,
+		nil,
+	)
+
+	if format.Header != "Release Notes for period ending: 2024-03-01" {
+		t.Errorf("Expected stable header, got %s", format.Header)
+	}
+
+	result := formatter.FormatReleaseNote(format)
+	if strings.Contains(result, "Generated on") {
+		t.Errorf("Expected no generation timestamp in output")
+	}
+	if !strings.Contains(result, "Analysis End: 2024-03-01\n") {
+		t.Errorf("Expected date-only analysis end in output")
+	}
+
+	errorSection := formatter.FormatErrorSection("https://github.com/test/repo", WrapError(nil, ErrorTypeNetwork, "connection failed", nil))
+	if strings.Contains(errorSection, "Timestamp:") {
+		t.Errorf("Expected no timestamp in error section")
+	}
+}
+
+func TestParseLineEnding(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "lf", expected: LineEndingLF},
+		{value: "CRLF", expected: LineEndingCRLF},
+		{value: " crlf ", expected: LineEndingCRLF},
+		{value: "cr", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ending, err := ParseLineEnding(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLineEnding(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if ending != tt.expected {
+				t.Errorf("ParseLineEnding(%q) = %q, expected %q", tt.value, ending, tt.expected)
+			}
+		})
+	}
+
+	if ending, err := ParseLineEnding(""); err != nil || (ending != LineEndingLF && ending != LineEndingCRLF) {
+		t.Errorf("Expected the host default for an empty value, got %q (err: %v)", ending, err)
+	}
+}
+
+func TestFormatReleaseNoteCRLF(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	formatter.LineEnding = LineEndingCRLF
+	until := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	format := formatter.CreateStandardFormat("https://github.com/test/repo", until.AddDate(0, 0, -7), until,
+		CommitInfo{Hash: "a1b2c3d4", Message: "Add feature\n\nWith a body", Author: "Alice", Date: until},
+		WeeklySummary{TotalCommits: 1, TotalLinesChanged: 3, ActiveContributors: 1},
+		[]Contributor{{Name: "Alice", CommitCount: 1, Rank: 1}},
+		[]CommitDetail{{Hash: "a1b2c3d4", Message: "Add feature", Author: "Alice", Date: until}})
+	result := formatter.FormatReleaseNote(format)
+
+	if !strings.Contains(result, "\r\n") {
+		t.Fatalf("Expected CRLF line endings in:\n%q", result)
+	}
+	if strings.Contains(strings.ReplaceAll(result, "\r\n", ""), "\n") {
+		t.Errorf("Expected every line feed to be preceded by a carriage return in:\n%q", result)
+	}
+	if strings.Contains(result, "\r\r") {
+		t.Errorf("Expected no doubled carriage returns in:\n%q", result)
+	}
+
+	formatter.LineEnding = LineEndingLF
+	if strings.Contains(formatter.FormatReleaseNote(format), "\r") {
+		t.Errorf("Expected LF line endings to be left untouched")
+	}
+}
+
+func TestTruncateSubject(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxLength int
+		subject   string
+		expected  string
+	}{
+		{name: "no maximum", maxLength: 0, subject: "Add a long feature description", expected: "Add a long feature description"},
+		{name: "short subject", maxLength: 20, subject: "Fix typo", expected: "Fix typo"},
+		{name: "exact length", maxLength: 8, subject: "Fix typo", expected: "Fix typo"},
+		{name: "cut with ellipsis", maxLength: 10, subject: "Add a long feature description", expected: "Add a lon…"},
+		{name: "trailing space dropped", maxLength: 7, subject: "Add a long feature", expected: "Add a…"},
+		{name: "multi-byte characters", maxLength: 5, subject: "Übersetzung ergänzt", expected: "Über…"},
+		{name: "single character", maxLength: 1, subject: "Fix typo", expected: "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := &ReleaseNoteFormatter{MaxSubjectLength: tt.maxLength}
+			if result := formatter.truncateSubject(tt.subject); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatReleaseNoteMaxSubjectLength(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	until := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	message := "Refactor the reconciler to requeue on conflicts\n\nThe body explains why."
+	format := formatter.CreateStandardFormat("https://github.com/test/repo", until.AddDate(0, 0, -7), until,
+		CommitInfo{Hash: "a1b2c3d4", Message: message, Author: "Alice", Date: until},
+		WeeklySummary{TotalCommits: 1, TotalLinesChanged: 3, ActiveContributors: 1},
+		[]Contributor{{Name: "Alice", CommitCount: 1, Rank: 1}},
+		[]CommitDetail{{Hash: "a1b2c3d4", Message: message, Author: "Alice", Date: until}})
+
+	// Without a maximum the full message is kept, as before
+	if result := formatter.FormatReleaseNote(format); !strings.Contains(result, "The body explains why.") {
+		t.Errorf("Expected the full commit message without a maximum, got:\n%s", result)
+	}
+
+	formatter.MaxSubjectLength = 20
+	result := formatter.FormatReleaseNote(format)
+	for _, expected := range []string{
+		// The latest commit keeps its full message, body indented, whatever the list shows
+		"Message: Refactor the reconciler to requeue on conflicts\n\n  The body explains why.\nAuthor: Alice\n",
+		"- Refactor the reconc… (a1b2c3d4) by Alice",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected result to contain %q, got:\n%s", expected, result)
+		}
+	}
+	if strings.Count(result, "The body explains why.") != 1 {
+		t.Errorf("Expected only the latest commit to show its body, got:\n%s", result)
+	}
+}
+
+func TestCommitCategory(t *testing.T) {
+	tests := map[string]string{
+		"feat: add channel filter":             CommitCategoryFeatures,
+		"feat(api): add v1 CRD":                CommitCategoryFeatures,
+		"fix(controller)!: requeue on error":   CommitCategoryFixes,
+		"Docs: update README":                  CommitCategoryDocs,
+		"chore(deps): bump go-git":             CommitCategoryChores,
+		"refactor:split parser":                CommitCategoryRefactoring,
+		"ci: run tests on arm64\n\nfeat: no":   CommitCategoryCI,
+		"Update README":                        CommitCategoryOther,
+		"wip: half done":                       CommitCategoryOther,
+		"feat add filter":                      CommitCategoryOther,
+		"Merge pull request #12 from a/fix: x": CommitCategoryOther,
+	}
+	for message, expected := range tests {
+		if category := commitCategory(message); category != expected {
+			t.Errorf("%q: expected %s, got %s", message, expected, category)
+		}
+	}
+}
+
+func TestFormatReleaseNoteChangesByType(t *testing.T) {
+	now := time.Now()
+	format := ReleaseNoteFormat{
+		AnalysisDays: 7,
+		Commits: []CommitDetail{
+			{Hash: "aaaaaaaa", Message: "Update README", Author: "Alice", Date: now},
+			{Hash: "bbbbbbbb", Message: "fix: handle empty index\n\nDetails.", Author: "Bob", Date: now},
+			{Hash: "cccccccc", Message: "feat(api): add v1 CRD", Author: "Alice", Date: now},
+			{Hash: "dddddddd", Message: "fix(cli): reject negative days", Author: "Carol", Date: now},
+		},
+	}
+	output := NewReleaseNoteFormatter().FormatReleaseNote(format)
+
+	expected := "=== CHANGES BY TYPE ===\n" +
+		"Features (1):\n- feat(api): add v1 CRD (cccccccc) by Alice\n" +
+		"Fixes (2):\n- fix: handle empty index (bbbbbbbb) by Bob\n- fix(cli): reject negative days (dddddddd) by Carol\n" +
+		"Other (1):\n- Update README (aaaaaaaa) by Alice\n\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected the changes grouped by type, got:\n%s", output)
+	}
+	if strings.Index(output, "=== CHANGES BY TYPE ===") > strings.Index(output, "=== COMMITS FROM") {
+		t.Errorf("Expected the changes by type before the commit list")
+	}
+
+	format.Commits = nil
+	if output := NewReleaseNoteFormatter().FormatReleaseNote(format); strings.Contains(output, "CHANGES BY TYPE") {
+		t.Errorf("Expected no changes by type without commits")
+	}
+}
+
+func TestFormatReleaseNotePage(t *testing.T) {
+	now := time.Now()
+	format := ReleaseNoteFormat{
+		AnalysisDays:  7,
+		WeeklySummary: WeeklySummary{TotalCommits: 5},
+	}
+	for i := 1; i <= 5; i++ {
+		format.Commits = append(format.Commits, CommitDetail{Hash: fmt.Sprintf("%08d", i), Message: fmt.Sprintf("fix: change %d", i), Author: "Alice", Date: now})
+	}
+	formatter := NewReleaseNoteFormatter()
+
+	output := formatter.FormatReleaseNotePage(format, 2, 2)
+	if !strings.Contains(output, "(Showing commits 3-4 of 5, page 2 of 3)\n") {
+		t.Errorf("Expected the page note, got:\n%s", output)
+	}
+	for i := 1; i <= 5; i++ {
+		listed := strings.Contains(output, fmt.Sprintf("- fix: change %d (", i))
+		if listed != (i == 3 || i == 4) {
+			t.Errorf("Commit %d: expected listed %v", i, !listed)
+		}
+	}
+	if !strings.Contains(output, "Fixes (2):\n") {
+		t.Errorf("Expected the changes by type to cover the page only, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Total Commits: 5\n") {
+		t.Errorf("Expected the summary to cover the whole window, got:\n%s", output)
+	}
+
+	// A page past the end shows the last page
+	if output := formatter.FormatReleaseNotePage(format, 9, 2); !strings.Contains(output, "(Showing commits 5-5 of 5, page 3 of 3)\n") {
+		t.Errorf("Expected the last page, got:\n%s", output)
+	}
+	// A single page needs no note
+	if output := formatter.FormatReleaseNotePage(format, 1, 0); strings.Contains(output, "Showing") {
+		t.Errorf("Expected no page note, got:\n%s", output)
+	}
+}
+
+func TestNewCommitPage(t *testing.T) {
+	tests := []struct {
+		page, pageSize, total int
+		expected              CommitPage
+		pages, start, end     int
+	}{
+		{page: 0, pageSize: 10, total: 25, expected: CommitPage{Page: 1, PageSize: 10, Total: 25}, pages: 3, start: 0, end: 10},
+		{page: 3, pageSize: 10, total: 25, expected: CommitPage{Page: 3, PageSize: 10, Total: 25}, pages: 3, start: 20, end: 25},
+		{page: 4, pageSize: 10, total: 20, expected: CommitPage{Page: 2, PageSize: 10, Total: 20}, pages: 2, start: 10, end: 20},
+		{page: 2, pageSize: 10, total: 0, expected: CommitPage{Page: 1, PageSize: 10, Total: 0}, pages: 1, start: 0, end: 0},
+	}
+	for _, tt := range tests {
+		page := NewCommitPage(tt.page, tt.pageSize, tt.total)
+		start, end := page.bounds()
+		if page != tt.expected || page.TotalPages() != tt.pages || start != tt.start || end != tt.end {
+			t.Errorf("NewCommitPage(%d, %d, %d) = %+v (%d pages, %d-%d)", tt.page, tt.pageSize, tt.total, page, page.TotalPages(), start, end)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// testCommit holds the optional settings of a commit made by commitFile
+type testCommit struct {
+	message string
+	when    time.Time
+}
+
+// testCommitOption changes a setting of a commit made by commitFile
+type testCommitOption func(*testCommit)
+
+// withMessage sets the commit message, which defaults to the file content
+func withMessage(message string) testCommitOption {
+	return func(c *testCommit) { c.message = message }
+}
+
+// withDate sets the author and committer date, which default to now
+func withDate(when time.Time) testCommitOption {
+	return func(c *testCommit) { c.when = when }
+}
+
+// commitFile writes content to a file in the worktree and commits it as the test author,
+// returning the new commit
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content string, options ...testCommitOption) *object.Commit {
+	t.Helper()
+	settings := testCommit{message: content, when: time.Now()}
+	for _, option := range options {
+		option(&settings)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	signature := &object.Signature{Name: "Test Author", Email: "test@example.com", When: settings.when}
+	hash, err := wt.Commit(settings.message, &git.CommitOptions{Author: signature, Committer: signature})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatalf("Failed to load commit: %v", err)
+	}
+	return commit
+}
@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ShippingHead is the bundle at the head of a package's default channel, i.e. what a new
+// subscription installs today, and the repository it is built from
+type ShippingHead struct {
+	Package    string `json:"package"`
+	Channel    string `json:"channel"`
+	Bundle     string `json:"bundle,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository"`
+}
+
+// ShippingVersion ties a shipping head to the tagged commit of its version
+type ShippingVersion struct {
+	ShippingHead
+	Tag    string      `json:"tag,omitempty"`
+	Commit *CommitInfo `json:"commit,omitempty"`
+	Note   string      `json:"note,omitempty"` // Why the version could not be tied to a commit
+}
+
+// ParseShippingHeads returns the default channel head of every repository in the index. The
+// head bundle is the channel's currentCSV or, in a file-based catalog, the only entry no other
+// entry replaces or skips. Packages built from the same repository share its entry.
+func ParseShippingHeads(filePath string) ([]ShippingHead, error) {
+	infos, err := ParseOperatorIndexDetailed(filePath)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := ParseCatalogPackages(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := make(map[string]string) // package -> head bundle of its default channel
+	for _, pkg := range packages {
+		for _, channel := range pkg.Channels {
+			if channel.Name != pkg.DefaultChannel {
+				continue
+			}
+			if channel.CurrentCSV != "" {
+				bundles[pkg.Name] = channel.CurrentCSV
+				continue
+			}
+			graphs := BuildUpgradeGraphs([]Package{{Name: pkg.Name, Channels: []Channel{channel}}})
+			if len(graphs) == 1 && len(graphs[0].Heads) == 1 {
+				bundles[pkg.Name] = graphs[0].Heads[0]
+			}
+		}
+	}
+
+	heads := make([]ShippingHead, 0, len(infos))
+	for _, info := range infos {
+		heads = append(heads, ShippingHead{
+			Package:    info.Name,
+			Channel:    info.DefaultChannel,
+			Bundle:     bundles[info.Name],
+			Version:    info.HeadVersion,
+			Repository: info.URL,
+		})
+	}
+	return heads, nil
+}
+
+// versionTagCandidates lists the tag names a release of version is commonly published under,
+// most likely first
+func versionTagCandidates(packageName, version string) []string {
+	version = strings.TrimPrefix(version, "v")
+	candidates := []string{"v" + version, version}
+	if packageName != "" {
+		candidates = append(candidates, packageName+"-v"+version, packageName+"-"+version)
+	}
+	return candidates
+}
+
+// taggedCommit resolves the first existing candidate tag to its commit, peeling annotated tags.
+// A tag whose commit a shallow clone lacks is an error saying so, rather than a missing tag.
+func taggedCommit(repo *git.Repository, candidates []string) (string, *object.Commit, error) {
+	for _, name := range candidates {
+		ref, err := repo.Reference(plumbing.NewTagReferenceName(name), true)
+		if err != nil {
+			continue
+		}
+		hash := ref.Hash()
+		var commit *object.Commit
+		if tag, tagErr := repo.TagObject(hash); tagErr == nil {
+			commit, err = tag.Commit()
+		} else {
+			commit, err = repo.CommitObject(hash)
+		}
+		if outsideShallowHistory(repo, err) {
+			return "", nil, fmt.Errorf("tag %s points outside the history of the shallow clone (raise --clone-depth or set it to 0 to resolve it)", name)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("tag %s does not point to a commit: %w", name, err)
+		}
+		return name, commit, nil
+	}
+	return "", nil, nil
+}
+
+// ResolveShippingVersions clones the repository of each head and looks up the commit tagged
+// with its version, without any commit-window analysis. Heads that cannot be tied to a commit,
+// because the catalog has no version, the clone fails or no tag matches, carry a Note instead.
+func (vtm *VibeToolsManager) ResolveShippingVersions(ctx context.Context, heads []ShippingHead) []ShippingVersion {
+	versions := make([]ShippingVersion, 0, len(heads))
+	for _, head := range heads {
+		versions = append(versions, vtm.resolveShippingVersion(ctx, head))
+	}
+	return versions
+}
+
+func (vtm *VibeToolsManager) resolveShippingVersion(ctx context.Context, head ShippingHead) ShippingVersion {
+	version := ShippingVersion{ShippingHead: head}
+	if head.Version == "" {
+		version.Note = "the catalog does not declare the head version"
+		return version
+	}
+
+	repoPath := filepath.Join(vtm.WorkDir, vtm.extractRepoName(head.Repository))
+	defer os.RemoveAll(repoPath)
+	if err := vtm.cloneRepository(ctx, head.Repository, repoPath); err != nil {
+		vtm.Logger.Warnf("Failed to clone %s: %v", head.Repository, err)
+		version.Note = "failed to clone repository: " + ErrorWithRemediation(err)
+		return version
+	}
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		version.Note = "failed to open repository: " + err.Error()
+		return version
+	}
+
+	candidates := versionTagCandidates(head.Package, head.Version)
+	tag, commit, err := taggedCommit(repo, candidates)
+	switch {
+	case err != nil:
+		version.Note = err.Error()
+	case commit == nil:
+		version.Note = fmt.Sprintf("no tag for version %s (tried %s)", head.Version, strings.Join(candidates, ", "))
+	default:
+		version.Tag = tag
+		version.Commit = &CommitInfo{
+			Hash:     commit.Hash.String()[:8],
+			Message:  strings.TrimSpace(commit.Message),
+			Author:   commit.Author.Name,
+			Date:     vtm.DateMode.commitDate(commit),
+			fullHash: commit.Hash.String(),
+		}
+	}
+	return version
+}
+
+// FormatShippingVersions renders the "what's currently shipping" snapshot, one block per
+// repository
+func (vtm *VibeToolsManager) FormatShippingVersions(versions []ShippingVersion) string {
+	var sb strings.Builder
+	for i, version := range versions {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		title := version.Package
+		if version.Channel != "" {
+			title += " (" + version.Channel + ")"
+		}
+		sb.WriteString(fmt.Sprintf("=== %s ===\n", title))
+		if version.Bundle != "" {
+			sb.WriteString(fmt.Sprintf("Head bundle: %s\n", version.Bundle))
+		}
+		if version.Version != "" {
+			sb.WriteString(fmt.Sprintf("Version: %s\n", version.Version))
+		}
+		sb.WriteString(fmt.Sprintf("Repository: %s\n", version.Repository))
+		if version.Commit == nil {
+			sb.WriteString(fmt.Sprintf("Commit: unknown, %s\n", version.Note))
+			continue
+		}
+		commit := version.Commit
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		sb.WriteString(fmt.Sprintf("Tag: %s\n", version.Tag))
+		sb.WriteString(fmt.Sprintf("Commit: %s (%s) by %s: %s\n", commit.Hash, commit.Date.Format("2006-01-02"), commit.Author, strings.TrimSpace(subject)))
+		if strings.HasPrefix(version.Repository, "https://") || strings.HasPrefix(version.Repository, "http://") {
+			sb.WriteString(fmt.Sprintf("Link: %s\n", vtm.CommitURLs.CommitURL(version.Repository, commit.fullHash, commit.Hash)))
+		}
+	}
+	return sb.String()
+}
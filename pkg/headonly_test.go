@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseShippingHeads(t *testing.T) {
+	heads, err := ParseShippingHeads(filepath.Join("..", "testdata", "sample_index.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var compliance *ShippingHead
+	for i := range heads {
+		if heads[i].Repository == "https://github.com/ComplianceAsCode/compliance-operator" {
+			compliance = &heads[i]
+		}
+	}
+	if compliance == nil {
+		t.Fatalf("Expected a head for the compliance operator, got %+v", heads)
+	}
+	expected := ShippingHead{
+		Package:    "compliance-operator",
+		Channel:    "stable",
+		Bundle:     "compliance-operator.v1.0.0",
+		Version:    "1.0.0",
+		Repository: "https://github.com/ComplianceAsCode/compliance-operator",
+	}
+	if *compliance != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *compliance)
+	}
+}
+
+func TestResolveShippingVersions(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "Release 1.2.0\n\nDetails")
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	if _, err := repo.CreateTag("v1.2.0", head.Hash(), &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "Test Author", Email: "test@example.com", When: time.Now()},
+		Message: "Release 1.2.0",
+	}); err != nil {
+		t.Fatalf("Failed to tag: %v", err)
+	}
+	commitFile(t, repo, dir, "b.txt", "Work after the release")
+
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.NoCloneProgress = true
+	versions := vtm.ResolveShippingVersions(context.Background(), []ShippingHead{
+		{Package: "foo", Channel: "stable", Bundle: "foo.v1.2.0", Version: "1.2.0", Repository: dir},
+		{Package: "foo", Channel: "stable", Version: "1.3.0", Repository: dir},
+		{Package: "bar", Channel: "stable", Repository: dir},
+	})
+	if len(versions) != 3 {
+		t.Fatalf("Expected 3 versions, got %d", len(versions))
+	}
+
+	tagged := versions[0]
+	if tagged.Tag != "v1.2.0" || tagged.Commit == nil {
+		t.Fatalf("Expected the annotated tag v1.2.0 to resolve, got %+v", tagged)
+	}
+	if tagged.Commit.fullHash != head.Hash().String() {
+		t.Errorf("Expected the tagged commit %s, got %s", head.Hash(), tagged.Commit.fullHash)
+	}
+	if !strings.Contains(versions[1].Note, "no tag for version 1.3.0") || versions[1].Commit != nil {
+		t.Errorf("Expected a note about the missing tag, got %+v", versions[1])
+	}
+	if versions[2].Note == "" || versions[2].Commit != nil {
+		t.Errorf("Expected a note about the missing version, got %+v", versions[2])
+	}
+
+	text := vtm.FormatShippingVersions(versions)
+	for _, expected := range []string{
+		"=== foo (stable) ===\nHead bundle: foo.v1.2.0\nVersion: 1.2.0\n",
+		"Tag: v1.2.0\nCommit: " + head.Hash().String()[:8],
+		": Release 1.2.0\n",
+		"Commit: unknown, no tag for version 1.3.0",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected snapshot to contain %q, got:\n%s", expected, text)
+		}
+	}
+}
+
+func TestVersionTagCandidates(t *testing.T) {
+	got := strings.Join(versionTagCandidates("foo", "v2.0.1"), ",")
+	if got != "v2.0.1,2.0.1,foo-v2.0.1,foo-2.0.1" {
+		t.Errorf("Unexpected candidates %s", got)
+	}
+}
@@ -0,0 +1,148 @@
+package pkg
+
+import "strings"
+
+// channelEdge is an entry of an upgrade channel and the bundles it supersedes
+type channelEdge struct {
+	bundle     string
+	supersedes []string
+}
+
+// catalogHeadVersions returns the version of the head bundle of each package's default
+// channel in a file-based catalog, i.e. the version a new subscription installs. The channel
+// graph comes from olm.channel blobs or, in sqlite-based renders, from the bundles' olm.channel
+// properties. Packages whose head is ambiguous or unversioned are left out.
+func catalogHeadVersions(entries []map[string]interface{}) map[string]string {
+	defaultChannels := make(map[string]string)
+	graphs := make(map[string][]channelEdge) // "package/channel" -> entries
+	versions := make(map[string]string)      // bundle name -> version
+
+	for _, entry := range entries {
+		schema, _ := entry["schema"].(string)
+		name, _ := entry["name"].(string)
+		packageName, _ := entry["package"].(string)
+		switch schema {
+		case "olm.package":
+			defaultChannels[name], _ = entry["defaultChannel"].(string)
+		case "olm.channel":
+			channelEntries, _ := entry["entries"].([]interface{})
+			for _, ce := range channelEntries {
+				if ceMap, ok := ce.(map[string]interface{}); ok {
+					graphs[packageName+"/"+name] = append(graphs[packageName+"/"+name], channelEdgeFrom(ceMap))
+				}
+			}
+		case "olm.bundle":
+			propsArray, _ := entry["properties"].([]interface{})
+			for _, prop := range propsArray {
+				propMap, _ := prop.(map[string]interface{})
+				valueMap, _ := propMap["value"].(map[string]interface{})
+				switch propMap["type"] {
+				case "olm.package":
+					if version, ok := valueMap["version"].(string); ok && version != "" {
+						versions[name] = version
+					}
+				case "olm.channel":
+					if channel, ok := valueMap["name"].(string); ok && channel != "" {
+						edge := channelEdgeFrom(valueMap)
+						edge.bundle = name
+						graphs[packageName+"/"+channel] = append(graphs[packageName+"/"+channel], edge)
+					}
+				}
+			}
+		}
+	}
+
+	heads := make(map[string]string)
+	for packageName, channel := range defaultChannels {
+		head, ok := channelHead(graphs[packageName+"/"+channel])
+		if !ok {
+			continue
+		}
+		if version := bundleVersion(packageName, head, versions); version != "" {
+			heads[packageName] = version
+		}
+	}
+	return heads
+}
+
+// channelEdgeFrom reads a channel entry's name, replaces and skips
+func channelEdgeFrom(entry map[string]interface{}) channelEdge {
+	edge := channelEdge{}
+	edge.bundle, _ = entry["name"].(string)
+	if replaces, ok := entry["replaces"].(string); ok && replaces != "" {
+		edge.supersedes = append(edge.supersedes, replaces)
+	}
+	skips, _ := entry["skips"].([]interface{})
+	for _, skip := range skips {
+		if name, ok := skip.(string); ok {
+			edge.supersedes = append(edge.supersedes, name)
+		}
+	}
+	return edge
+}
+
+// channelHead returns the only entry of a channel that no other entry replaces or skips
+func channelHead(edges []channelEdge) (string, bool) {
+	superseded := make(map[string]bool)
+	for _, edge := range edges {
+		for _, name := range edge.supersedes {
+			superseded[name] = true
+		}
+	}
+	head := ""
+	for _, edge := range edges {
+		if edge.bundle == "" || superseded[edge.bundle] || edge.bundle == head {
+			continue
+		}
+		if head != "" {
+			return "", false
+		}
+		head = edge.bundle
+	}
+	return head, head != ""
+}
+
+// bundleVersion returns a bundle's declared version, falling back to the version in the
+// conventional "<package>.v<version>" bundle name
+func bundleVersion(packageName, bundle string, versions map[string]string) string {
+	if version, ok := versions[bundle]; ok {
+		return version
+	}
+	if rest, ok := strings.CutPrefix(bundle, packageName+"."); ok {
+		return strings.TrimPrefix(rest, "v")
+	}
+	return ""
+}
+
+// packageHeadVersions returns the version of each package's default channel head in a
+// structured index, where the channel names its head as currentCSV
+func packageHeadVersions(packages []Package) map[string]string {
+	heads := make(map[string]string)
+	for _, pkg := range packages {
+		for _, channel := range pkg.Channels {
+			if channel.Name != pkg.DefaultChannel || channel.CurrentCSV == "" {
+				continue
+			}
+			versions := make(map[string]string)
+			for _, entry := range channel.Entries {
+				for _, prop := range entry.Properties {
+					valueMap, _ := prop.Value.(map[string]interface{})
+					if version, ok := valueMap["version"].(string); ok && prop.Type == "olm.package" && version != "" {
+						versions[entry.Name] = version
+					}
+				}
+			}
+			if version := bundleVersion(pkg.Name, channel.CurrentCSV, versions); version != "" {
+				heads[pkg.Name] = version
+			}
+		}
+	}
+	return heads
+}
+
+// setHeadVersions records the head version of each repository's package
+func setHeadVersions(infos []ParserRepositoryInfo, heads map[string]string) {
+	for i := range infos {
+		infos[i].HeadVersion = heads[infos[i].Name]
+	}
+}
@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+)
+
+// ReadinessResponse is the body of /api/ready: the overall status and the result of each
+// check, "ok" or what failed
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// handleHealth reports that the server is up. It checks nothing else, so liveness probes stay
+// fast and never fail because of a missing tool.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleReady reports whether the server can serve requests: opm must be resolvable and the
+// work directory writable. It answers 503 with the failed checks otherwise.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	response := ReadinessResponse{Status: "ready", Checks: make(map[string]string)}
+	for name, check := range map[string]func() error{
+		"opm":     checkOPMResolvable,
+		"workDir": func() error { return ensureWritableDir(s.WorkDir) },
+	} {
+		response.Checks[name] = "ok"
+		if err := check(); err != nil {
+			response.Checks[name] = err.Error()
+			response.Status = "not ready"
+		}
+	}
+
+	if response.Status != "ready" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkOPMResolvable looks opm up where FindOrDownloadTool does, in PATH and then in .bin/,
+// without downloading it
+func checkOPMResolvable() error {
+	if _, err := exec.LookPath("opm"); err == nil {
+		return nil
+	}
+	if _, err := exec.LookPath(filepath.Join(".bin", "opm")); err == nil {
+		return nil
+	}
+	return fmt.Errorf("opm not found in PATH or .bin/")
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestHandleHealth(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	recorder := httptest.NewRecorder()
+	s.handleHealth(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "{\"status\":\"ok\"}\n" {
+		t.Errorf("Expected 200 with status ok, got %d %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestHandleReady(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in opm is a shell script")
+	}
+	ready := func(s *Server) (int, ReadinessResponse) {
+		recorder := httptest.NewRecorder()
+		s.handleReady(recorder, httptest.NewRequest(http.MethodGet, "/api/ready", nil))
+		var response ReadinessResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return recorder.Code, response
+	}
+
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "opm"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	s := NewServer(0, filepath.Join(t.TempDir(), "work"), t.TempDir(), "", nil)
+	if code, response := ready(s); code != http.StatusOK || response.Status != "ready" || response.Checks["opm"] != "ok" || response.Checks["workDir"] != "ok" {
+		t.Errorf("Expected the server to be ready, got %d %+v", code, response)
+	}
+
+	// Without opm and with a work directory below a regular file
+	t.Setenv("PATH", t.TempDir())
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	s.WorkDir = filepath.Join(file, "work")
+	code, response := ready(s)
+	if code != http.StatusServiceUnavailable || response.Status != "not ready" {
+		t.Errorf("Expected 503, got %d %+v", code, response)
+	}
+	if response.Checks["opm"] == "ok" || response.Checks["workDir"] == "ok" {
+		t.Errorf("Expected both checks to fail, got %+v", response.Checks)
+	}
+}
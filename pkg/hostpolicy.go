@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HostAllowed reports whether a repository URL points at one of the allowed git hosts. Hosts
+// are compared case-insensitively; an empty allowlist allows every host.
+func HostAllowed(allowedHosts []string, repoURL string) bool {
+	if len(allowedHosts) == 0 {
+		return true
+	}
+	host := repositoryHost(repoURL)
+	for _, allowed := range allowedHosts {
+		if host != "" && strings.EqualFold(strings.TrimSpace(allowed), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterAllowedHosts drops the repositories whose host is not in the allowlist, logging each
+// one as a policy violation so a compromised or mistaken catalog can't make the analyzer
+// reach out to unexpected remotes. Local bundles are kept: they are never fetched from a host,
+// and command-line runs analyze them offline.
+func FilterAllowedHosts(repoURLs []string, allowedHosts []string, logger *logrus.Logger) []string {
+	if len(allowedHosts) == 0 {
+		return repoURLs
+	}
+	allowed := make([]string, 0, len(repoURLs))
+	for _, repoURL := range repoURLs {
+		if IsBundlePath(repoURL) || HostAllowed(allowedHosts, repoURL) {
+			allowed = append(allowed, repoURL)
+			continue
+		}
+		logger.WithFields(logrus.Fields{
+			"repository": repoURL,
+			"host":       repositoryHost(repoURL),
+		}).Warn("Policy violation: repository host is not in --allowed-hosts, skipping")
+	}
+	return allowed
+}
+
+// serverRepositoryError returns why the server refuses a repository named by a request: a host
+// outside the allowlist, or a local bundle, which only command-line runs analyze so that requests
+// can't make the server read its own files
+func serverRepositoryError(allowedHosts []string, repoURL string) error {
+	if IsBundlePath(repoURL) {
+		return errors.New("local bundle repositories can only be analyzed from the command line")
+	}
+	if !HostAllowed(allowedHosts, repoURL) {
+		return fmt.Errorf("repository host %q is not in the allowed hosts", repositoryHost(repoURL))
+	}
+	return nil
+}
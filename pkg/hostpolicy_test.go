@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestHostAllowed(t *testing.T) {
+	allowed := []string{"github.com", "GitLab.cee.redhat.com"}
+	tests := []struct {
+		repoURL  string
+		expected bool
+	}{
+		{"https://github.com/openshift/compliance-operator", true},
+		{"https://GitHub.com/openshift/compliance-operator.git", true},
+		{"git@gitlab.cee.redhat.com:team/operator.git", true},
+		{"https://github.com.evil.example/openshift/operator", false},
+		{"https://gitlab.com/team/operator", false},
+		{"not a url", false},
+	}
+
+	for _, tt := range tests {
+		if got := HostAllowed(allowed, tt.repoURL); got != tt.expected {
+			t.Errorf("HostAllowed(%q) = %v, expected %v", tt.repoURL, got, tt.expected)
+		}
+	}
+	if !HostAllowed(nil, "https://anywhere.example/repo") {
+		t.Error("Expected an empty allowlist to allow every host")
+	}
+}
+
+func TestServerRepositoryError(t *testing.T) {
+	if err := serverRepositoryError(nil, "https://github.com/example/operator"); err != nil {
+		t.Errorf("Expected a remote repository to be accepted, got %v", err)
+	}
+	if err := serverRepositoryError([]string{"github.com"}, "https://gitlab.com/example/operator"); err == nil {
+		t.Error("Expected a host outside the allowlist to be rejected")
+	}
+	for _, bundle := range []string{"/srv/bundles/operator.bundle", "file:///srv/bundles/operator.bundle"} {
+		if err := serverRepositoryError(nil, bundle); err == nil {
+			t.Errorf("Expected the server to reject the local bundle %s", bundle)
+		}
+	}
+}
+
+func TestFilterAllowedHosts(t *testing.T) {
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&logs)
+
+	repos := []string{
+		"https://github.com/openshift/compliance-operator",
+		"https://attacker.example/openshift/compliance-operator",
+		"/srv/bundles/operator.bundle",
+	}
+	filtered := FilterAllowedHosts(repos, []string{"github.com"}, logger)
+	if len(filtered) != 2 || filtered[0] != repos[0] || filtered[1] != repos[2] {
+		t.Errorf("Expected the github.com repository and the bundle, got %v", filtered)
+	}
+	if !strings.Contains(logs.String(), "Policy violation") || !strings.Contains(logs.String(), "attacker.example") {
+		t.Errorf("Expected the rejection to be logged as a policy violation, got %q", logs.String())
+	}
+
+	if unfiltered := FilterAllowedHosts(repos, nil, logger); len(unfiltered) != len(repos) {
+		t.Errorf("Expected every repository without an allowlist, got %v", unfiltered)
+	}
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// DefaultIdleTimeout is how long an idle keep-alive connection is kept open for the browser's
+// next request, so loading the UI and its API calls reuses one connection
+const DefaultIdleTimeout = 120 * time.Second
+
+// DefaultHTTP2MaxConcurrentStreams bounds the requests in flight on one HTTP/2 connection
+const DefaultHTTP2MaxConcurrentStreams = 250
+
+// newHTTPServer builds the web server around handler. HTTP/2 is configured on the server so it
+// is negotiated automatically when served over TLS; with H2C enabled it is also accepted in
+// cleartext, for deployments behind a proxy that terminates TLS and speaks HTTP/2 upstream.
+func (s *Server) newHTTPServer(addr string, handler http.Handler) (*http.Server, error) {
+	h2 := &http2.Server{
+		MaxConcurrentStreams: s.HTTP2MaxConcurrentStreams,
+		IdleTimeout:          s.IdleTimeout,
+	}
+	if s.H2C {
+		handler = h2c.NewHandler(handler, h2)
+	}
+
+	server := &http.Server{
+		Addr:        addr,
+		Handler:     handler,
+		IdleTimeout: s.IdleTimeout,
+	}
+	if err := http2.ConfigureServer(server, h2); err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP/2: %w", err)
+	}
+	return server, nil
+}
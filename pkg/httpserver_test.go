@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name     string
+		h2c      bool
+		accepted bool
+	}{
+		{name: "cleartext HTTP/2 disabled", h2c: false, accepted: false},
+		{name: "cleartext HTTP/2 enabled", h2c: true, accepted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+			s.H2C = tt.h2c
+			server, err := s.newHTTPServer("", ok)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if server.IdleTimeout != DefaultIdleTimeout {
+				t.Errorf("Expected idle timeout %v, got %v", DefaultIdleTimeout, server.IdleTimeout)
+			}
+
+			ts := httptest.NewServer(server.Handler)
+			defer ts.Close()
+
+			// A prior-knowledge h2c client, as a proxy speaking HTTP/2 upstream would connect
+			client := &http.Client{
+				Timeout: 5 * time.Second,
+				Transport: &http2.Transport{
+					AllowHTTP: true,
+					DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+						return (&net.Dialer{}).DialContext(ctx, network, addr)
+					},
+				},
+			}
+			resp, err := client.Get(ts.URL)
+			if !tt.accepted {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("Expected cleartext HTTP/2 to be refused")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.ProtoMajor != 2 {
+				t.Errorf("Expected an HTTP/2 response, got %s", resp.Proto)
+			}
+		})
+	}
+}
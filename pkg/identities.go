@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Identity is one distinct author name and email pair seen in a repository's commits
+type Identity struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Commits int    `json:"commits"`
+}
+
+// String formats the identity the way git and .mailmap write it
+func (i Identity) String() string {
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+// collectIdentities returns every distinct author name and email pair of the commits with the
+// number of commits made under it
+func collectIdentities(commits []*object.Commit) []Identity {
+	counts := make(map[Identity]int)
+	for _, c := range commits {
+		counts[Identity{Name: c.Author.Name, Email: c.Author.Email}]++
+	}
+	identities := make([]Identity, 0, len(counts))
+	for identity, count := range counts {
+		identity.Commits = count
+		identities = append(identities, identity)
+	}
+	return identities
+}
+
+// groupIdentities groups the identities that are likely the same person: those whose names or
+// emails are equal once normalized the way --only-authors matches them. Each group starts
+// with its most used identity, which .mailmap maps the others to.
+func groupIdentities(identities []Identity) [][]Identity {
+	parent := make([]int, len(identities))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	seen := make(map[string]int) // "name:" or "email:" + normalized value -> first identity
+	for i, identity := range identities {
+		for _, key := range []string{"name:" + normalizeIdentity(identity.Name), "email:" + normalizeIdentity(identity.Email)} {
+			if key == "name:" || key == "email:" {
+				continue
+			}
+			if j, ok := seen[key]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				seen[key] = i
+			}
+		}
+	}
+
+	members := make(map[int][]Identity)
+	for i, identity := range identities {
+		root := find(i)
+		members[root] = append(members[root], identity)
+	}
+	groups := make([][]Identity, 0, len(members))
+	for _, group := range members {
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Commits != group[j].Commits {
+				return group[i].Commits > group[j].Commits
+			}
+			return group[i].String() < group[j].String()
+		})
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i][0].String()) < strings.ToLower(groups[j][0].String())
+	})
+	return groups
+}
+
+// formatMailmap renders the identities of each repository as a candidate .mailmap: one
+// commented section per repository, where every person gets a line with their most used
+// identity followed by a line mapping each other variant to it
+func formatMailmap(repositories []string, identities map[string][]Identity) string {
+	var output strings.Builder
+	output.WriteString("# Candidate .mailmap entries generated by Prega Operator Analyzer.\n")
+	output.WriteString("# Identities that share a name or an email are grouped as one person; review the\n")
+	output.WriteString("# groups before copying a repository's section into its .mailmap.\n")
+	for _, repo := range repositories {
+		repoIdentities, ok := identities[repo]
+		if !ok {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("\n# %s\n", repo))
+		for _, group := range groupIdentities(repoIdentities) {
+			canonical := group[0]
+			output.WriteString(canonical.String() + "\n")
+			for _, variant := range group[1:] {
+				output.WriteString(fmt.Sprintf("%s %s\n", canonical, variant))
+			}
+		}
+	}
+	return output.String()
+}
+
+// writeIdentities writes the candidate .mailmap of the run to IdentitiesFile
+func (vtm *VibeToolsManager) writeIdentities(repositories []string) error {
+	if err := os.MkdirAll(filepath.Dir(vtm.IdentitiesFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(vtm.IdentitiesFile, []byte(formatMailmap(repositories, vtm.identities)), 0644)
+}
@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestGroupIdentities(t *testing.T) {
+	commit := func(name, email string) *object.Commit {
+		return &object.Commit{Author: object.Signature{Name: name, Email: email}}
+	}
+	commits := []*object.Commit{
+		commit("Jane Doe", "jane@example.com"),
+		commit("Jane Doe", "jane@example.com"),
+		commit("jane  doe", "jdoe@users.noreply.github.com"),
+		commit("J. Doe", "Jane@Example.com"),
+		commit("John Smith", "john@example.com"),
+		commit("Bot", "bot@example.com"),
+	}
+
+	groups := groupIdentities(collectIdentities(commits))
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 people, got %d: %v", len(groups), groups)
+	}
+
+	// Groups are sorted by their canonical identity
+	expected := [][]string{
+		{"Bot <bot@example.com>"},
+		{"Jane Doe <jane@example.com>", "J. Doe <Jane@Example.com>", "jane  doe <jdoe@users.noreply.github.com>"},
+		{"John Smith <john@example.com>"},
+	}
+	for i, group := range groups {
+		var names []string
+		for _, identity := range group {
+			names = append(names, identity.String())
+		}
+		if strings.Join(names, "|") != strings.Join(expected[i], "|") {
+			t.Errorf("Expected group %v, got %v", expected[i], names)
+		}
+	}
+	if groups[1][0].Commits != 2 {
+		t.Errorf("Expected the canonical identity to have 2 commits, got %d", groups[1][0].Commits)
+	}
+}
+
+func TestWriteIdentities(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.IdentitiesFile = filepath.Join(t.TempDir(), "identities", "mailmap")
+	vtm.identities = map[string][]Identity{
+		"https://github.com/test/operator": {
+			{Name: "Jane Doe", Email: "jane@example.com", Commits: 3},
+			{Name: "jane doe", Email: "jdoe@users.noreply.github.com", Commits: 1},
+		},
+		"https://github.com/test/other": {
+			{Name: "John Smith", Email: "john@example.com", Commits: 1},
+		},
+	}
+
+	repositories := []string{"https://github.com/test/operator", "https://github.com/test/failed", "https://github.com/test/other"}
+	if err := vtm.writeIdentities(repositories); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(vtm.IdentitiesFile)
+	if err != nil {
+		t.Fatalf("Failed to read identities file: %v", err)
+	}
+
+	expected := "\n# https://github.com/test/operator\n" +
+		"Jane Doe <jane@example.com>\n" +
+		"Jane Doe <jane@example.com> jane doe <jdoe@users.noreply.github.com>\n" +
+		"\n# https://github.com/test/other\n" +
+		"John Smith <john@example.com>\n"
+	if !strings.HasSuffix(string(data), expected) {
+		t.Errorf("Expected mailmap sections:\n%s\ngot:\n%s", expected, data)
+	}
+	if strings.Contains(string(data), "test/failed") {
+		t.Errorf("Expected no section for a repository without identities")
+	}
+}
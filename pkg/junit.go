@@ -0,0 +1,136 @@
+package pkg
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// junitSuiteName names the test suite of a run in the JUnit report
+const junitSuiteName = "prega-operator-analyzer"
+
+// junitTestSuites is the root of a JUnit XML report, as read by CI systems
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is one repository's analysis
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitSeconds formats a duration the way JUnit reports carry time, in seconds
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitErrorType is the error type of a failed repository, e.g. GIT_ERROR
+func junitErrorType(err error) string {
+	var analyzerErr *AnalyzerError
+	if errors.As(err, &analyzerErr) {
+		return string(analyzerErr.Type)
+	}
+	return string(ErrorTypeUnknown)
+}
+
+// renderJUnitReport renders the run as a JUnit XML report with one test case per repository:
+// failed repositories carry their error type and message as a failure, skipped ones are skipped.
+// Test cases are grouped under the catalog package of their repository when known.
+func (vtm *VibeToolsManager) renderJUnitReport(report *RunReport) ([]byte, error) {
+	suite := junitTestSuite{
+		Name:     junitSuiteName,
+		Tests:    len(report.Repositories),
+		Failures: report.Failed,
+		Skipped:  report.Skipped,
+		Time:     junitSeconds(report.duration),
+	}
+	if !report.startedAt.IsZero() {
+		suite.Timestamp = report.startedAt.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	for _, repo := range report.Repositories {
+		testCase := junitTestCase{
+			Name:      repo.Repository,
+			ClassName: junitSuiteName,
+			Time:      junitSeconds(repo.duration),
+		}
+		if info, ok := vtm.CatalogInfo[repo.Repository]; ok && info.Name != "" {
+			testCase.ClassName = info.Name
+		}
+		switch repo.Status {
+		case RepositoryStatusFailed:
+			text := repo.Error
+			if repo.Remediation != "" {
+				text += "\n" + repo.Remediation
+			}
+			testCase.Failure = &junitFailure{
+				Message: repo.Error,
+				Type:    junitErrorType(repo.err),
+				Text:    text,
+			}
+		case RepositoryStatusSkipped:
+			testCase.Skipped = &junitSkipped{Message: repo.SkipReason}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suites := junitTestSuites{
+		Name:     junitSuiteName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// writeJUnitReport writes the JUnit report of the run to JUnitFile
+func (vtm *VibeToolsManager) writeJUnitReport(report *RunReport) error {
+	data, err := vtm.renderJUnitReport(report)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(vtm.JUnitFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(vtm.JUnitFile, data, 0644)
+}
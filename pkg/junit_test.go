@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestRenderJUnitReport(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.CatalogInfo = map[string]ParserRepositoryInfo{
+		"https://github.com/test/ok": {URL: "https://github.com/test/ok", Name: "ok-operator"},
+	}
+	cloneErr := WrapError(nil, ErrorTypeGit, "failed to clone repository", nil)
+	report := &RunReport{
+		Total:      3,
+		Successful: 1,
+		Failed:     1,
+		Skipped:    1,
+		Repositories: []RepositoryReport{
+			{Repository: "https://github.com/test/ok", Status: RepositoryStatusSuccess, duration: 1500 * time.Millisecond},
+			{Repository: "https://github.com/test/broken", Status: RepositoryStatusFailed, Error: cloneErr.Error(), Remediation: "Check the URL & credentials", err: cloneErr},
+			{Repository: "https://github.com/test/huge", Status: RepositoryStatusSkipped, SkipReason: "repository is 900 MB"},
+		},
+		startedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		duration:  2 * time.Second,
+	}
+
+	data, err := vtm.renderJUnitReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("Report is not well-formed: %v\n%s", err, data)
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 || suites.Time != "2.000" {
+		t.Errorf("Unexpected totals %+v", suites)
+	}
+	if len(suites.Suites) != 1 || len(suites.Suites[0].Cases) != 3 {
+		t.Fatalf("Expected one suite with 3 test cases, got %+v", suites.Suites)
+	}
+	suite := suites.Suites[0]
+	if suite.Timestamp != "2024-03-01T12:00:00" {
+		t.Errorf("Expected the run's start as timestamp, got %q", suite.Timestamp)
+	}
+
+	ok, broken, huge := suite.Cases[0], suite.Cases[1], suite.Cases[2]
+	if ok.Name != "https://github.com/test/ok" || ok.ClassName != "ok-operator" || ok.Time != "1.500" || ok.Failure != nil || ok.Skipped != nil {
+		t.Errorf("Unexpected successful test case %+v", ok)
+	}
+	if broken.Failure == nil || broken.Failure.Type != "GIT_ERROR" || broken.Failure.Message != cloneErr.Error() {
+		t.Fatalf("Expected a GIT_ERROR failure, got %+v", broken.Failure)
+	}
+	if !strings.Contains(broken.Failure.Text, "Check the URL & credentials") {
+		t.Errorf("Expected the remediation in the failure text, got %q", broken.Failure.Text)
+	}
+	if broken.ClassName != junitSuiteName {
+		t.Errorf("Expected the suite name as class of repositories outside the catalog, got %q", broken.ClassName)
+	}
+	if huge.Skipped == nil || huge.Skipped.Message != "repository is 900 MB" {
+		t.Errorf("Expected a skipped test case, got %+v", huge)
+	}
+}
+
+func TestProcessRepositoriesWritesJUnit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "Initial commit")
+
+	out := t.TempDir()
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(out, "notes.txt"), false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+	vtm.Logger.SetOutput(io.Discard)
+	vtm.NoCloneProgress = true
+	vtm.JUnitFile = filepath.Join(out, "ci", "junit.xml")
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err := vtm.ProcessRepositories(context.Background(), []string{dir, missing}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(vtm.JUnitFile)
+	if err != nil {
+		t.Fatalf("Expected the JUnit report to be written: %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("Report is not well-formed: %v", err)
+	}
+	if suites.Tests != 2 || suites.Failures != 1 {
+		t.Errorf("Expected 2 tests with 1 failure, got %+v", suites)
+	}
+	cases := suites.Suites[0].Cases
+	if cases[0].Name != dir || cases[0].Failure != nil {
+		t.Errorf("Expected the analyzed repository to pass, got %+v", cases[0])
+	}
+	if cases[1].Name != missing || cases[1].Failure == nil || cases[1].Failure.Type != string(ErrorTypeGit) {
+		t.Errorf("Expected the missing repository to fail with a git error, got %+v", cases[1])
+	}
+}
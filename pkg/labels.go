@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// UncategorizedLabel groups repositories that have no label when a report is grouped by label
+const UncategorizedLabel = "Uncategorized"
+
+// ParseLabelsFile reads a labels file mapping repository URLs to report labels. Each line holds
+// a repository URL followed by a comma-separated list of labels, e.g.
+//
+//	https://github.com/example/operator security,observability
+//
+// Blank lines and lines starting with '#' are ignored. A repository listed on several lines
+// collects the labels of all of them.
+func ParseLabelsFile(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string][]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a repository URL followed by labels, got %q", i+1, line)
+		}
+		key := labelKey(fields[0])
+		for _, label := range parseLabelList(strings.Join(fields[1:], " ")) {
+			labels[key] = appendUnique(labels[key], label)
+		}
+		if len(labels[key]) == 0 {
+			return nil, fmt.Errorf("line %d: no labels given for %s", i+1, fields[0])
+		}
+	}
+	return labels, nil
+}
+
+// parseLabelList splits a comma-separated label list, trimming each label
+func parseLabelList(value string) []string {
+	var labels []string
+	for _, label := range strings.Split(value, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// appendUnique appends value unless the slice already contains it
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// labelKey normalizes a repository URL so "https://host/org/repo.git/" and
+// "https://host/org/repo" share their labels
+func labelKey(repoURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(repoURL), "/"), ".git")
+}
+
+// repositoryLabels returns the configured labels of a repository
+func (vtm *VibeToolsManager) repositoryLabels(repoURL string) []string {
+	return vtm.Labels[labelKey(repoURL)]
+}
+
+// labelGroup is a report section holding every repository carrying one label
+type labelGroup struct {
+	Label        string
+	Repositories []RepositoryReport
+}
+
+// labelGroups groups the repositories by label in alphabetical order, with unlabeled
+// repositories in a final Uncategorized group. A repository with several labels appears
+// in each of their groups. It returns nil when no repository has a label so the report
+// keeps its flat layout.
+func (r *RunReport) labelGroups() []labelGroup {
+	byLabel := make(map[string][]RepositoryReport)
+	var uncategorized []RepositoryReport
+	for _, repo := range r.Repositories {
+		if len(repo.Labels) == 0 {
+			uncategorized = append(uncategorized, repo)
+			continue
+		}
+		for _, label := range repo.Labels {
+			byLabel[label] = append(byLabel[label], repo)
+		}
+	}
+	if len(byLabel) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(byLabel))
+	for label := range byLabel {
+		names = append(names, label)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := strings.ToLower(names[i]), strings.ToLower(names[j])
+		if a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+
+	groups := make([]labelGroup, 0, len(names)+1)
+	for _, label := range names {
+		groups = append(groups, labelGroup{Label: label, Repositories: byLabel[label]})
+	}
+	if len(uncategorized) > 0 {
+		groups = append(groups, labelGroup{Label: UncategorizedLabel, Repositories: uncategorized})
+	}
+	return groups
+}
+
+// labelAnchor returns the HTML id used to link to a label's section from the index
+func labelAnchor(label string) string {
+	var anchor strings.Builder
+	anchor.WriteString("label-")
+	for _, r := range strings.ToLower(label) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			anchor.WriteRune(r)
+		} else {
+			anchor.WriteRune('-')
+		}
+	}
+	return anchor.String()
+}
@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabelsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.txt")
+	content := `# operator domains
+https://github.com/example/auth-operator.git security, observability
+https://github.com/example/storage-operator/ storage
+
+https://github.com/example/auth-operator security,networking
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write labels file: %v", err)
+	}
+
+	labels, err := ParseLabelsFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string][]string{
+		"https://github.com/example/auth-operator":    {"security", "observability", "networking"},
+		"https://github.com/example/storage-operator": {"storage"},
+	}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Expected %v, got %v", expected, labels)
+	}
+
+	if err := os.WriteFile(path, []byte("https://github.com/example/operator\n"), 0644); err != nil {
+		t.Fatalf("Failed to write labels file: %v", err)
+	}
+	if _, err := ParseLabelsFile(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected error naming line 1, got %v", err)
+	}
+}
+
+func TestLabelGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		repos    []RepositoryReport
+		expected map[string][]string // label -> repositories, in group order below
+		order    []string
+	}{
+		{
+			name: "no labels keeps flat layout",
+			repos: []RepositoryReport{
+				{Repository: "a"},
+				{Repository: "b"},
+			},
+		},
+		{
+			name: "multiple labels and uncategorized",
+			repos: []RepositoryReport{
+				{Repository: "a", Labels: []string{"storage", "Security"}},
+				{Repository: "b"},
+				{Repository: "c", Labels: []string{"observability"}},
+				{Repository: "d", Labels: []string{"storage"}},
+			},
+			order: []string{"observability", "Security", "storage", UncategorizedLabel},
+			expected: map[string][]string{
+				"observability":    {"c"},
+				"Security":         {"a"},
+				"storage":          {"a", "d"},
+				UncategorizedLabel: {"b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := (&RunReport{Repositories: tt.repos}).labelGroups()
+			if len(groups) != len(tt.order) {
+				t.Fatalf("Expected %d groups, got %+v", len(tt.order), groups)
+			}
+			for i, group := range groups {
+				if group.Label != tt.order[i] {
+					t.Errorf("Expected group %d to be %q, got %q", i, tt.order[i], group.Label)
+				}
+				var names []string
+				for _, repo := range group.Repositories {
+					names = append(names, repo.Repository)
+				}
+				if !reflect.DeepEqual(names, tt.expected[group.Label]) {
+					t.Errorf("Expected %q to contain %v, got %v", group.Label, tt.expected[group.Label], names)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderReportsGroupedByLabel(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.Formatter.OmitTimestamp = true
+	report := &RunReport{Total: 2, Failed: 2, Repositories: []RepositoryReport{
+		{Repository: "https://github.com/test/auth", Status: RepositoryStatusFailed, Error: "clone failed", Labels: []string{"security"}},
+		{Repository: "https://github.com/test/misc", Status: RepositoryStatusFailed, Error: "clone failed"},
+	}}
+
+	expected := map[string][]string{
+		OutputFormatText:     {"=== INDEX ===", "security (1): auth", "# SECURITY (1)", "# UNCATEGORIZED (1)"},
+		OutputFormatHTML:     {`<a href="#label-security">security</a>`, `id="label-uncategorized"`},
+		OutputFormatMarkdown: {"- [security](#label-security) (1): auth", "### auth", `## <a id="label-uncategorized"></a>Uncategorized`},
+	}
+	for format, fragments := range expected {
+		data, err := reportRenderers[format](vtm, report)
+		if err != nil {
+			t.Fatalf("Failed to render %s: %v", format, err)
+		}
+		for _, fragment := range fragments {
+			if !strings.Contains(string(data), fragment) {
+				t.Errorf("Expected %s report to contain %q", format, fragment)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// isLowActivity reports whether notes have fewer commits in the window than MinCommits. Notes
+// without a commit history (from vibe-tools or a tarball snapshot) are never low activity.
+func (vtm *VibeToolsManager) isLowActivity(format *ReleaseNoteFormat) bool {
+	if vtm.MinCommits <= 0 || format == nil || format.Snapshot != nil {
+		return false
+	}
+	return format.WeeklySummary.TotalCommits < vtm.MinCommits
+}
+
+// splitLowActivity returns a copy of the report without the low-activity repositories, whose
+// full sections are left out of the rendered notes, together with those repositories. The
+// counts of the copy still cover every processed repository.
+func (r *RunReport) splitLowActivity() (*RunReport, []RepositoryReport) {
+	var shown, low []RepositoryReport
+	for _, repo := range r.Repositories {
+		if repo.LowActivity {
+			low = append(low, repo)
+		} else {
+			shown = append(shown, repo)
+		}
+	}
+	if len(low) == 0 {
+		return r, nil
+	}
+	copied := *r
+	copied.Repositories = shown
+	return &copied, low
+}
+
+// lowActivityCommits describes the in-window commits of a low-activity repository
+func lowActivityCommits(repo RepositoryReport) string {
+	commits := repo.ReleaseNotes.WeeklySummary.TotalCommits
+	if commits == 1 {
+		return "1 commit"
+	}
+	return fmt.Sprintf("%d commits", commits)
+}
+
+// formatTextLowActivity lists the repositories left out for having fewer than minCommits commits
+func (vtm *VibeToolsManager) formatTextLowActivity(repos []RepositoryReport, minCommits int) string {
+	if len(repos) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n=== LOW ACTIVITY (FEWER THAN %d COMMITS) ===\n", minCommits))
+	for _, repo := range repos {
+		output.WriteString(fmt.Sprintf("- %s (%s) %s\n", vtm.extractRepoName(repo.Repository), lowActivityCommits(repo), repo.Repository))
+	}
+	return output.String()
+}
+
+// formatHTMLLowActivity lists the low-activity repositories in a card of the HTML report
+func (vtm *VibeToolsManager) formatHTMLLowActivity(repos []RepositoryReport, minCommits int) string {
+	if len(repos) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	for _, repo := range repos {
+		list.WriteString(fmt.Sprintf(`
+                    <li><strong>%s</strong> (%s) <span class="repo-url">%s</span></li>`,
+			html.EscapeString(vtm.extractRepoName(repo.Repository)), lowActivityCommits(repo), html.EscapeString(repo.Repository)))
+	}
+	return fmt.Sprintf(`
+        <div class="repo-card low-activity">
+            <div class="repo-header">
+                <h2>💤 Low Activity</h2>
+                <div class="repo-url">Fewer than %d commits in the analysis window</div>
+            </div>
+            <div class="repo-body">
+                <ul>%s
+                </ul>
+            </div>
+        </div>
+`, minCommits, list.String())
+}
+
+// writeMarkdownLowActivity lists the low-activity repositories in the Markdown report
+func (vtm *VibeToolsManager) writeMarkdownLowActivity(output *strings.Builder, repos []RepositoryReport, minCommits int) {
+	if len(repos) == 0 {
+		return
+	}
+	output.WriteString("## Low Activity\n\n")
+	output.WriteString(fmt.Sprintf("Fewer than %d commits in the analysis window:\n\n", minCommits))
+	for _, repo := range repos {
+		output.WriteString(fmt.Sprintf("- %s (%s): %s\n", markdownEscape(vtm.extractRepoName(repo.Repository)), lowActivityCommits(repo), repo.Repository))
+	}
+	output.WriteString("\n")
+}
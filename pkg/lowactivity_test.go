@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsLowActivity(t *testing.T) {
+	tests := []struct {
+		name       string
+		minCommits int
+		format     *ReleaseNoteFormat
+		expected   bool
+	}{
+		{"threshold disabled", 0, &ReleaseNoteFormat{}, false},
+		{"below threshold", 3, &ReleaseNoteFormat{WeeklySummary: WeeklySummary{TotalCommits: 2}}, true},
+		{"at threshold", 3, &ReleaseNoteFormat{WeeklySummary: WeeklySummary{TotalCommits: 3}}, false},
+		{"no structured notes", 3, nil, false},
+		{"tarball snapshot", 3, &ReleaseNoteFormat{Snapshot: &TarballSnapshot{}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+			vtm.MinCommits = tt.minCommits
+			if got := vtm.isLowActivity(tt.format); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderReportsLowActivity(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.Formatter.OmitTimestamp = true
+
+	end := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	notes := func(repoURL, message string, commits int) RepositoryReport {
+		format := vtm.Formatter.CreateStandardFormat(
+			repoURL,
+			end.AddDate(0, 0, -7),
+			end,
+			CommitInfo{Hash: "a1b2c3d4", Message: message, Author: "Alice", Date: end},
+			WeeklySummary{TotalCommits: commits, ActiveContributors: 1},
+			[]Contributor{{Name: "Alice", CommitCount: commits, Rank: 1}},
+			[]CommitDetail{{Hash: "a1b2c3d4", Message: message, Author: "Alice", Date: end}},
+		)
+		return RepositoryReport{
+			Repository:   repoURL,
+			Status:       RepositoryStatusSuccess,
+			ReleaseNotes: &format,
+			Text:         vtm.Formatter.FormatReleaseNote(format),
+			LowActivity:  commits < 3,
+		}
+	}
+	report := &RunReport{
+		Total:      2,
+		Successful: 2,
+		MinCommits: 3,
+		Repositories: []RepositoryReport{
+			notes("https://github.com/test/busy-operator", "Rework reconciler", 5),
+			notes("https://github.com/test/quiet-operator", "Fix typo", 1),
+		},
+	}
+
+	text, err := vtm.renderTextReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"Repository: https://github.com/test/busy-operator",
+		"=== LOW ACTIVITY (FEWER THAN 3 COMMITS) ===",
+		"- quiet-operator (1 commit) https://github.com/test/quiet-operator",
+		"Total Repositories: 2",
+		"Successfully Processed: 2",
+	} {
+		if !strings.Contains(string(text), expected) {
+			t.Errorf("Expected %q in text output", expected)
+		}
+	}
+	if strings.Contains(string(text), "Fix typo") {
+		t.Error("Expected the low-activity repository's section to be left out")
+	}
+
+	markdown, err := vtm.renderMarkdownReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"## busy-operator", "## Low Activity", "- quiet-operator (1 commit): https://github.com/test/quiet-operator", "| Successfully Processed | 2 |"} {
+		if !strings.Contains(string(markdown), expected) {
+			t.Errorf("Expected %q in Markdown output", expected)
+		}
+	}
+	if strings.Contains(string(markdown), "## quiet-operator") {
+		t.Error("Expected no Markdown section for the low-activity repository")
+	}
+
+	page, err := vtm.renderHTMLReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(page), "Low Activity") || strings.Contains(string(page), "Fix typo") {
+		t.Error("Expected the HTML report to list the low-activity repository without its notes")
+	}
+
+	// The report itself keeps every repository for the JSON output and the returned results
+	if len(report.Repositories) != 2 || len(report.Results()) != 2 {
+		t.Error("Expected rendering to leave the report's repositories untouched")
+	}
+}
@@ -0,0 +1,151 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// MergedPullRequest is a pull or merge request whose merge commit falls in the analysis window
+type MergedPullRequest struct {
+	Number int          `json:"number,omitempty"` // 0 for a "Merge branch" commit that names no request
+	Title  string       `json:"title"`
+	URL    string       `json:"url,omitempty"`
+	Commit CommitDetail `json:"commit"`
+}
+
+var (
+	// githubMergePattern matches GitHub's "Merge pull request #123 from owner/branch"
+	githubMergePattern = regexp.MustCompile(`^Merge pull request #(\d+) from \S+`)
+	// bitbucketMergePattern matches Bitbucket's "Merged in branch (pull request #12)"
+	bitbucketMergePattern = regexp.MustCompile(`^Merged in \S+ \(pull request #(\d+)\)`)
+	// branchMergePattern matches git's and GitLab's "Merge branch 'feature' into 'main'"
+	branchMergePattern = regexp.MustCompile(`^Merge (remote-tracking )?branch '[^']+'`)
+	// mergeRequestPattern matches the "See merge request group/project!42" line GitLab adds
+	mergeRequestPattern = regexp.MustCompile(`(?m)^See merge request \S*!(\d+)\s*$`)
+)
+
+// parseMergeMessage recognizes the message of a pull request merge commit and returns the
+// request number (0 when the message names none) and the request's title. GitHub and GitLab
+// put the title on the first line of the body; a plain branch merge uses its subject.
+func parseMergeMessage(message string) (number int, title string, ok bool) {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = strings.TrimSpace(subject)
+	title = subject
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			title = line
+			break
+		}
+	}
+
+	switch {
+	case githubMergePattern.MatchString(subject):
+		number, _ = strconv.Atoi(githubMergePattern.FindStringSubmatch(subject)[1])
+	case bitbucketMergePattern.MatchString(subject):
+		number, _ = strconv.Atoi(bitbucketMergePattern.FindStringSubmatch(subject)[1])
+		title = subject
+	case branchMergePattern.MatchString(subject):
+		match := mergeRequestPattern.FindStringSubmatch(body)
+		if match == nil {
+			return 0, subject, true
+		}
+		number, _ = strconv.Atoi(match[1])
+	default:
+		return 0, "", false
+	}
+	if strings.HasPrefix(title, "See merge request ") {
+		title = subject
+	}
+	return number, title, true
+}
+
+// mergedPullRequest returns the pull request merged by a commit, linked through the repository's
+// link base (see CommitURLTemplates.linkBase). Only merge commits (two or more parents) count, so
+// a squashed or rebased commit quoting a merge message is ignored.
+func mergedPullRequest(c *object.Commit, repoURL string, detail CommitDetail, links CommitURLTemplates) (MergedPullRequest, bool) {
+	if c.NumParents() < 2 {
+		return MergedPullRequest{}, false
+	}
+	number, title, ok := parseMergeMessage(c.Message)
+	if !ok {
+		return MergedPullRequest{}, false
+	}
+	return MergedPullRequest{
+		Number: number,
+		Title:  title,
+		URL:    pullRequestURL(links.linkBase(repoURL), number),
+		Commit: detail,
+	}, true
+}
+
+// pullRequestURL links to a pull request on GitHub, GitLab or Bitbucket; it is empty for
+// other hosts and for merges that name no request
+func pullRequestURL(repoURL string, number int) string {
+	if number <= 0 {
+		return ""
+	}
+	if owner, name, ok := parseGitHubRepo(repoURL); ok {
+		return fmt.Sprintf("https://github.com/%s/%s/pull/%d", owner, name, number)
+	}
+	if host, project, ok := parseGitLabRepo(repoURL); ok {
+		return fmt.Sprintf("https://%s/%s/-/merge_requests/%d", host, project, number)
+	}
+	if strings.Contains(repositoryHost(repoURL), "bitbucket") && !IsBundlePath(repoURL) {
+		base := strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+		if strings.HasPrefix(base, "http://") || strings.HasPrefix(base, "https://") {
+			return fmt.Sprintf("%s/pull-requests/%d", base, number)
+		}
+	}
+	return ""
+}
+
+// label names the request the way its host does, e.g. "#123", or "branch merge"
+func (pr MergedPullRequest) label() string {
+	if pr.Number == 0 {
+		return "branch merge"
+	}
+	if strings.Contains(pr.URL, "/-/merge_requests/") {
+		return fmt.Sprintf("!%d", pr.Number)
+	}
+	return fmt.Sprintf("#%d", pr.Number)
+}
+
+// formatTextMergedPullRequests renders the merged pull requests section of the text notes
+func formatTextMergedPullRequests(prs []MergedPullRequest) string {
+	if len(prs) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString("=== MERGED PULL REQUESTS ===\n")
+	for _, pr := range prs {
+		output.WriteString(fmt.Sprintf("- %s %s (%s) by %s", pr.label(), pr.Title, pr.Commit.Hash, pr.Commit.Author))
+		if pr.URL != "" {
+			output.WriteString(" " + pr.URL)
+		}
+		output.WriteString("\n")
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// formatHTMLMergedPullRequests renders the merged pull requests as a list linking to each one
+func formatHTMLMergedPullRequests(prs []MergedPullRequest) string {
+	var list strings.Builder
+	list.WriteString(`<ul class="merged-pr-list">`)
+	for _, pr := range prs {
+		label := html.EscapeString(pr.label())
+		if pr.URL != "" {
+			label = fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`, html.EscapeString(pr.URL), label)
+		}
+		list.WriteString(fmt.Sprintf(`
+<li><span class="merged-pr-number">%s</span> %s <code class="commit-hash">%s</code> <span class="merged-pr-author">👤 %s</span></li>`,
+			label, html.EscapeString(pr.Title), pr.Commit.Hash, html.EscapeString(pr.Commit.Author)))
+	}
+	list.WriteString("\n</ul>")
+	return list.String()
+}
@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseMergeMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		number   int
+		title    string
+		expected bool
+	}{
+		{
+			name:     "GitHub pull request",
+			message:  "Merge pull request #123 from alice/fix-scan\n\nFix the scan timeout",
+			number:   123,
+			title:    "Fix the scan timeout",
+			expected: true,
+		},
+		{
+			name:     "GitLab merge request",
+			message:  "Merge branch 'fix-scan' into 'main'\n\nFix the scan timeout\n\nSee merge request team/operator!42",
+			number:   42,
+			title:    "Fix the scan timeout",
+			expected: true,
+		},
+		{
+			name:     "GitLab merge request without description",
+			message:  "Merge branch 'fix-scan' into 'main'\n\nSee merge request team/operator!42",
+			number:   42,
+			title:    "Merge branch 'fix-scan' into 'main'",
+			expected: true,
+		},
+		{
+			name:     "Bitbucket pull request",
+			message:  "Merged in fix-scan (pull request #7)\n\nFix the scan timeout",
+			number:   7,
+			title:    "Merged in fix-scan (pull request #7)",
+			expected: true,
+		},
+		{
+			name:     "plain branch merge",
+			message:  "Merge branch 'release-4.16'",
+			number:   0,
+			title:    "Merge branch 'release-4.16'",
+			expected: true,
+		},
+		{
+			name:     "regular commit",
+			message:  "Fix the scan timeout",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, title, ok := parseMergeMessage(tt.message)
+			if ok != tt.expected || number != tt.number || title != tt.title {
+				t.Errorf("parseMergeMessage() = %d, %q, %v; expected %d, %q, %v", number, title, ok, tt.number, tt.title, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPullRequestURL(t *testing.T) {
+	tests := []struct {
+		repoURL  string
+		number   int
+		expected string
+	}{
+		{"https://github.com/openshift/compliance-operator.git", 12, "https://github.com/openshift/compliance-operator/pull/12"},
+		{"https://gitlab.cee.redhat.com/team/operator", 42, "https://gitlab.cee.redhat.com/team/operator/-/merge_requests/42"},
+		{"https://bitbucket.org/team/operator", 7, "https://bitbucket.org/team/operator/pull-requests/7"},
+		{"https://git.example.com/team/operator", 3, ""},
+		{"https://github.com/openshift/compliance-operator", 0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := pullRequestURL(tt.repoURL, tt.number); got != tt.expected {
+			t.Errorf("pullRequestURL(%q, %d) = %q, expected %q", tt.repoURL, tt.number, got, tt.expected)
+		}
+	}
+}
+
+func TestMergedPullRequestNeedsMergeCommit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	base := commitFile(t, repo, dir, "a.txt", "base")
+	feature := commitFile(t, repo, dir, "b.txt", "feature")
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	signature := &object.Signature{Name: "Test Author", Email: "test@example.com", When: time.Now()}
+	message := "Merge pull request #5 from alice/feature\n\nAdd the feature"
+	mergeHash, err := wt.Commit(message, &git.CommitOptions{
+		Author:            signature,
+		Parents:           []plumbing.Hash{base.Hash, feature.Hash},
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	merge, err := repo.CommitObject(mergeHash)
+	if err != nil {
+		t.Fatalf("Failed to read merge commit: %v", err)
+	}
+
+	repoURL := "https://github.com/openshift/compliance-operator"
+	pr, ok := mergedPullRequest(merge, repoURL, CommitDetail{Hash: mergeHash.String()[:8], Author: "Test Author"}, CommitURLTemplates{})
+	if !ok || pr.Number != 5 || pr.Title != "Add the feature" || pr.URL != repoURL+"/pull/5" {
+		t.Errorf("Unexpected merged pull request %+v, %v", pr, ok)
+	}
+
+	// Links follow the same host rewrites and link bases as commit links
+	rewrites := CommitURLTemplates{HostRewrites: map[string]string{"mirror.internal": "github.com"}}
+	if mirrored, _ := mergedPullRequest(merge, "https://mirror.internal/openshift/compliance-operator.git", CommitDetail{}, rewrites); mirrored.URL != repoURL+"/pull/5" {
+		t.Errorf("Expected the mirror's pull request to link to github.com, got %q", mirrored.URL)
+	}
+	bundle := CommitURLTemplates{Links: map[string]string{"/srv/bundles/operator.bundle": repoURL}}
+	if bundled, _ := mergedPullRequest(merge, "/srv/bundles/operator.bundle", CommitDetail{}, bundle); bundled.URL != repoURL+"/pull/5" {
+		t.Errorf("Expected the bundle's pull request to link through its link base, got %q", bundled.URL)
+	}
+
+	// A squashed commit quoting the merge message has a single parent and is not counted
+	feature.Message = message
+	if _, ok := mergedPullRequest(feature, repoURL, CommitDetail{}, CommitURLTemplates{}); ok {
+		t.Error("Expected a single-parent commit not to count as a merged pull request")
+	}
+
+	text := formatTextMergedPullRequests([]MergedPullRequest{pr})
+	if !strings.Contains(text, "=== MERGED PULL REQUESTS ===") || !strings.Contains(text, "#5 Add the feature") {
+		t.Errorf("Unexpected text section:\n%s", text)
+	}
+}
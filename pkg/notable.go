@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"path"
+	"strings"
+)
+
+// NotableChange is a commit that touched files matching one of the configured notable patterns
+type NotableChange struct {
+	Commit  CommitDetail `json:"commit"`
+	Pattern string       `json:"pattern"`
+	Files   []string     `json:"files"`
+}
+
+// findNotableChanges returns one entry per commit and pattern for commits whose changed files
+// match any of the patterns, preserving commit order and pattern order
+func findNotableChanges(commits []CommitDetail, patterns []string) []NotableChange {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	var changes []NotableChange
+	for _, commit := range commits {
+		for _, pattern := range patterns {
+			var matched []string
+			for _, file := range commit.Files {
+				if matchFilePattern(pattern, file) {
+					matched = append(matched, file)
+				}
+			}
+			if len(matched) > 0 {
+				changes = append(changes, NotableChange{
+					Commit:  commit,
+					Pattern: pattern,
+					Files:   matched,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+// matchFilePattern reports whether a slash-separated file path matches a glob pattern.
+// A "**" segment matches any number of directories, and a pattern without a slash
+// matches the file name in any directory (so "Dockerfile" matches "build/Dockerfile").
+func matchFilePattern(pattern, file string) bool {
+	pattern = strings.Trim(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+// matchSegments matches pattern segments against path segments, expanding "**" recursively
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchFilePattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		file     string
+		expected bool
+	}{
+		{
+			name:     "double star matches nested file",
+			pattern:  "**/role.yaml",
+			file:     "config/rbac/role.yaml",
+			expected: true,
+		},
+		{
+			name:     "double star matches top-level file",
+			pattern:  "**/role.yaml",
+			file:     "role.yaml",
+			expected: true,
+		},
+		{
+			name:     "bare name matches in any directory",
+			pattern:  "Dockerfile",
+			file:     "build/Dockerfile",
+			expected: true,
+		},
+		{
+			name:     "single star stays within directory",
+			pattern:  ".github/workflows/*",
+			file:     ".github/workflows/ci.yaml",
+			expected: true,
+		},
+		{
+			name:     "single star does not cross directories",
+			pattern:  ".github/workflows/*",
+			file:     ".github/workflows/sub/ci.yaml",
+			expected: false,
+		},
+		{
+			name:     "non-matching file",
+			pattern:  "**/role.yaml",
+			file:     "config/rbac/role_binding.yaml",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matchFilePattern(tt.pattern, tt.file)
+			if result != tt.expected {
+				t.Errorf("Expected %v for pattern %s and file %s, got %v", tt.expected, tt.pattern, tt.file, result)
+			}
+		})
+	}
+}
+
+func TestFindNotableChanges(t *testing.T) {
+	commits := []CommitDetail{
+		{Hash: "a1b2c3d4", Message: "Update RBAC", Author: "Alice", Files: []string{"config/rbac/role.yaml", "main.go"}},
+		{Hash: "e5f6g7h8", Message: "Fix typo", Author: "Bob", Files: []string{"README.md"}},
+		{Hash: "i9j0k1l2", Message: "Bump base image", Author: "Carol", Files: []string{"Dockerfile", ".github/workflows/build.yaml"}},
+	}
+	patterns := []string{"**/role.yaml", "Dockerfile", ".github/workflows/*"}
+
+	changes := findNotableChanges(commits, patterns)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 notable changes, got %d", len(changes))
+	}
+	if changes[0].Commit.Hash != "a1b2c3d4" || changes[0].Pattern != "**/role.yaml" {
+		t.Errorf("Unexpected first change: %+v", changes[0])
+	}
+	if changes[2].Pattern != ".github/workflows/*" || changes[2].Files[0] != ".github/workflows/build.yaml" {
+		t.Errorf("Unexpected last change: %+v", changes[2])
+	}
+
+	if findNotableChanges(commits, nil) != nil {
+		t.Errorf("Expected no notable changes without patterns")
+	}
+
+	formatter := NewReleaseNoteFormatter()
+	result := formatter.FormatReleaseNote(ReleaseNoteFormat{
+		RepositoryInfo: RepositoryInfo{URL: "https://github.com/test/repo"},
+		AnalysisDays:   7,
+		NotableChanges: changes,
+	})
+	if !strings.Contains(result, "=== NOTABLE CHANGES ===") {
+		t.Errorf("Expected notable changes section in output")
+	}
+	if !strings.Contains(result, "- Update RBAC (a1b2c3d4) by Alice matched **/role.yaml: config/rbac/role.yaml") {
+		t.Errorf("Expected notable change line in output, got:\n%s", result)
+	}
+}
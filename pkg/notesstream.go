@@ -0,0 +1,41 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// handleReleaseNotesStream generates release notes like handleReleaseNotes, streaming the
+// stages as Server-Sent Events: a "progress" event with {"stage": ...} as each stage starts,
+// then a "result" event carrying the ReleaseNotesResponse, successful or not
+func (s *Server) handleReleaseNotesStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep reverse proxies from holding events back
+
+	send := func(event string, data interface{}) {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			s.Logger.Errorf("Failed to encode %s event: %v", event, err)
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		flusher.Flush()
+	}
+
+	req, err := s.decodeReleaseNotesRequest(r)
+	if err != nil {
+		send("result", ReleaseNotesResponse{Success: false, ErrorMessage: err.Error()})
+		return
+	}
+	response, _ := s.releaseNotesResponse(r.Context(), req, func(event ProgressEvent) {
+		send("progress", map[string]string{"stage": event.Stage})
+	})
+	send("result", response)
+}
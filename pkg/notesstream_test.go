@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// readEvents splits a Server-Sent Events body into its event names and data
+func readEvents(t *testing.T, body io.Reader) (names, data []string) {
+	t.Helper()
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			names = append(names, name)
+		} else if payload, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			data = append(data, payload)
+		}
+	}
+	if len(names) != len(data) {
+		t.Fatalf("Expected one data line per event, got %d events and %d data lines", len(names), len(data))
+	}
+	return names, data
+}
+
+func TestHandleReleaseNotesStream(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "first")
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(fmt.Sprintf(`{"repository": %q, "branch": "master"}`, dir))
+	s.handleReleaseNotesStream(recorder, httptest.NewRequest(http.MethodPost, "/api/release-notes/stream", body))
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Expected an event stream, got %q", contentType)
+	}
+
+	names, data := readEvents(t, recorder.Body)
+	expected := "progress,progress,progress,result"
+	if strings.Join(names, ",") != expected {
+		t.Fatalf("Expected the events %s, got %v", expected, names)
+	}
+	for i, stage := range []string{ProgressCloning, ProgressAnalyzing, ProgressFormatting} {
+		if data[i] != fmt.Sprintf(`{"stage":%q}`, stage) {
+			t.Errorf("Expected the %s stage, got %s", stage, data[i])
+		}
+	}
+	var response ReleaseNotesResponse
+	if err := json.Unmarshal([]byte(data[3]), &response); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if !response.Success || !strings.Contains(response.Text, "Total Commits: 1\n") || response.HTML == "" {
+		t.Errorf("Expected the notes in the result, got %+v", response)
+	}
+}
+
+func TestHandleReleaseNotesStreamInvalidRequest(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	recorder := httptest.NewRecorder()
+	s.handleReleaseNotesStream(recorder, httptest.NewRequest(http.MethodPost, "/api/release-notes/stream", strings.NewReader(`{}`)))
+	names, data := readEvents(t, recorder.Body)
+	if len(names) != 1 || names[0] != "result" {
+		t.Fatalf("Expected only a result event, got %v", names)
+	}
+	var response ReleaseNotesResponse
+	if err := json.Unmarshal([]byte(data[0]), &response); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if response.Success || response.ErrorMessage != "repository is required" {
+		t.Errorf("Expected a validation error, got %+v", response)
+	}
+}
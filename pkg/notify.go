@@ -0,0 +1,148 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NotifyFormat selects the JSON payload posted to the notification webhook
+type NotifyFormat string
+
+const (
+	NotifyFormatSlack   NotifyFormat = "slack"   // {"text": ...}, also accepted by Mattermost and Rocket.Chat
+	NotifyFormatTeams   NotifyFormat = "teams"   // {"text": ...} for a Microsoft Teams incoming webhook
+	NotifyFormatDiscord NotifyFormat = "discord" // {"content": ...}
+	NotifyFormatJSON    NotifyFormat = "json"    // The RunSummary itself, for custom receivers
+)
+
+// notifyTopRepositories is the number of most active repositories listed in a notification
+const notifyTopRepositories = 5
+
+// notifyHTTPClient posts notifications; a slow webhook must not hold up the end of a run
+var notifyHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// ParseNotifyFormat validates a --notify-format value; an empty value selects Slack
+func ParseNotifyFormat(value string) (NotifyFormat, error) {
+	switch format := NotifyFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return NotifyFormatSlack, nil
+	case NotifyFormatSlack, NotifyFormatTeams, NotifyFormatDiscord, NotifyFormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown notification format %q (expected slack, teams, discord or json)", value)
+}
+
+// RepositoryActivity is one repository's commit activity in a run summary
+type RepositoryActivity struct {
+	Repository   string `json:"repository"`
+	Commits      int    `json:"commits"`
+	LinesChanged int    `json:"linesChanged"`
+}
+
+// RunSummary is the concise outcome of a run sent to the notification webhook
+type RunSummary struct {
+	Total           int                  `json:"total"`
+	Successful      int                  `json:"successful"`
+	Failed          int                  `json:"failed"`
+	Skipped         int                  `json:"skipped"`
+	SuccessRate     float64              `json:"successRate"`
+	TopRepositories []RepositoryActivity `json:"topRepositories,omitempty"`
+	ReportURL       string               `json:"reportUrl,omitempty"`
+}
+
+// newRunSummary summarizes a run, listing the repositories with the most commits first
+func newRunSummary(report *RunReport, reportURL string) RunSummary {
+	summary := RunSummary{
+		Total:       report.Total,
+		Successful:  report.Successful,
+		Failed:      report.Failed,
+		Skipped:     report.Skipped,
+		SuccessRate: report.SuccessRate(),
+		ReportURL:   reportURL,
+	}
+
+	for _, repo := range report.Repositories {
+		if repo.ReleaseNotes == nil || repo.ReleaseNotes.WeeklySummary.TotalCommits == 0 {
+			continue
+		}
+		summary.TopRepositories = append(summary.TopRepositories, RepositoryActivity{
+			Repository:   repo.Repository,
+			Commits:      repo.ReleaseNotes.WeeklySummary.TotalCommits,
+			LinesChanged: repo.ReleaseNotes.WeeklySummary.TotalLinesChanged,
+		})
+	}
+	sort.SliceStable(summary.TopRepositories, func(i, j int) bool {
+		return summary.TopRepositories[i].Commits > summary.TopRepositories[j].Commits
+	})
+	if len(summary.TopRepositories) > notifyTopRepositories {
+		summary.TopRepositories = summary.TopRepositories[:notifyTopRepositories]
+	}
+	return summary
+}
+
+// Text renders the summary as a short chat message
+func (s RunSummary) Text() string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Prega operator release notes: %d repositories processed (%d succeeded, %d failed, %d skipped, %.1f%% success rate)",
+		s.Total, s.Successful, s.Failed, s.Skipped, s.SuccessRate))
+	if len(s.TopRepositories) > 0 {
+		text.WriteString("\nMost active operators:")
+		for i, repo := range s.TopRepositories {
+			text.WriteString(fmt.Sprintf("\n%d. %s (%d commits, %d lines changed)", i+1, extractRepoNameFromURL(repo.Repository), repo.Commits, repo.LinesChanged))
+		}
+	}
+	if s.ReportURL != "" {
+		text.WriteString("\nFull report: " + s.ReportURL)
+	}
+	return text.String()
+}
+
+// notifyPayload encodes the summary in the body expected by the webhook format
+func notifyPayload(format NotifyFormat, summary RunSummary) ([]byte, error) {
+	switch format {
+	case NotifyFormatJSON:
+		return json.Marshal(summary)
+	case NotifyFormatDiscord:
+		return json.Marshal(map[string]string{"content": summary.Text()})
+	default:
+		return json.Marshal(map[string]string{"text": summary.Text()})
+	}
+}
+
+// postNotification posts the run summary to an incoming webhook. Webhook URLs embed their
+// credentials, so errors never include the URL.
+func postNotification(ctx context.Context, webhookURL string, format NotifyFormat, summary RunSummary) error {
+	payload, err := notifyPayload(format, summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return errors.New("invalid notification webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := notifyHTTPClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewRunSummary(t *testing.T) {
+	notes := func(commits int) *ReleaseNoteFormat {
+		return &ReleaseNoteFormat{WeeklySummary: WeeklySummary{TotalCommits: commits, TotalLinesChanged: commits * 10}}
+	}
+	report := &RunReport{Total: 4, Successful: 3, Failed: 1, Repositories: []RepositoryReport{
+		{Repository: "https://github.com/test/quiet", Status: RepositoryStatusSuccess, ReleaseNotes: notes(0)},
+		{Repository: "https://github.com/test/busy", Status: RepositoryStatusSuccess, ReleaseNotes: notes(12)},
+		{Repository: "https://github.com/test/broken", Status: RepositoryStatusFailed, Error: "clone failed"},
+		{Repository: "https://github.com/test/steady", Status: RepositoryStatusSuccess, ReleaseNotes: notes(3)},
+	}}
+
+	summary := newRunSummary(report, "https://reports.example.com/latest.html")
+	if summary.SuccessRate != 75 {
+		t.Errorf("Expected a 75%% success rate, got %.1f", summary.SuccessRate)
+	}
+	if len(summary.TopRepositories) != 2 || summary.TopRepositories[0].Repository != "https://github.com/test/busy" {
+		t.Fatalf("Expected busy then steady, got %+v", summary.TopRepositories)
+	}
+
+	text := summary.Text()
+	for _, fragment := range []string{"4 repositories processed", "1. busy (12 commits, 120 lines changed)", "Full report: https://reports.example.com/latest.html"} {
+		if !strings.Contains(text, fragment) {
+			t.Errorf("Expected summary text to contain %q, got:\n%s", fragment, text)
+		}
+	}
+}
+
+func TestPostNotification(t *testing.T) {
+	summary := RunSummary{Total: 1, Successful: 1, SuccessRate: 100}
+	tests := []struct {
+		format NotifyFormat
+		field  string
+	}{
+		{NotifyFormatSlack, "text"},
+		{NotifyFormatTeams, "text"},
+		{NotifyFormatDiscord, "content"},
+		{NotifyFormatJSON, "successRate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("Expected a JSON content type, got %q", r.Header.Get("Content-Type"))
+				}
+				data, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(data, &body); err != nil {
+					t.Errorf("Invalid JSON payload %q: %v", data, err)
+				}
+			}))
+			defer server.Close()
+
+			if err := postNotification(context.Background(), server.URL, tt.format, summary); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, ok := body[tt.field]; !ok {
+				t.Errorf("Expected payload field %q, got %v", tt.field, body)
+			}
+		})
+	}
+}
+
+func TestPostNotificationFailureHidesURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	webhook := server.URL + "/services/T000/B000/secret-token"
+	err := postNotification(context.Background(), webhook, NotifyFormatSlack, RunSummary{})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Expected a 403 error, got %v", err)
+	}
+
+	server.Close()
+	err = postNotification(context.Background(), webhook, NotifyFormatSlack, RunSummary{})
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("Expected an error without the webhook URL, got %v", err)
+	}
+}
+
+func TestParseNotifyFormat(t *testing.T) {
+	if format, err := ParseNotifyFormat(""); err != nil || format != NotifyFormatSlack {
+		t.Errorf("Expected slack by default, got %q, %v", format, err)
+	}
+	if format, err := ParseNotifyFormat("Discord"); err != nil || format != NotifyFormatDiscord {
+		t.Errorf("Expected discord, got %q, %v", format, err)
+	}
+	if _, err := ParseNotifyFormat("irc"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
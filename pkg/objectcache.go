@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// ObjectCache is a bare repository shared by the clones of a run. Every repository is fetched
+// into it under its own remote, so history that forks of the same operator share is
+// transferred once: the fetch offers the commits already cached for the other forks. The
+// working clones then borrow the cached objects through objects/info/alternates instead of
+// copying them. The cache is not safe for concurrent use by several processes.
+type ObjectCache struct {
+	Dir  string
+	repo *git.Repository
+	mu   sync.Mutex // Fetches into the shared repository run one at a time
+}
+
+// OpenObjectCache opens the shared object store in dir, creating it on first use
+func OpenObjectCache(dir string) (*ObjectCache, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err = git.PlainInit(dir, true)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object cache %s: %w", dir, err)
+	}
+	return &ObjectCache{Dir: dir, repo: repo}, nil
+}
+
+// objectCacheRemote names the cache remote of a repository; URLs are hashed since they
+// contain characters that are not valid in remote names
+func objectCacheRemote(repoURL string) string {
+	return fmt.Sprintf("repo-%x", sha1.Sum([]byte(labelKey(repoURL))))[:21]
+}
+
+// fetch brings the cache up to date with every branch of repoURL, authenticating with auth when
+// set, and returns the branch heads together with the data the fetch transferred
+func (c *ObjectCache) fetch(ctx context.Context, repoURL string, progress io.Writer, auth transport.AuthMethod) (map[string]plumbing.Hash, CloneStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := objectCacheRemote(repoURL)
+	prefix := "refs/remotes/" + name + "/"
+
+	remote, err := c.repo.Remote(name)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		remote, err = c.repo.CreateRemote(&config.RemoteConfig{
+			Name:  name,
+			URLs:  []string{repoURL},
+			Fetch: []config.RefSpec{config.RefSpec("+refs/heads/*:" + prefix + "*")},
+		})
+	}
+	if err != nil {
+		return nil, CloneStats{}, fmt.Errorf("failed to set up cache remote: %w", err)
+	}
+
+	// The advertised branches are the ones the clone gets; refs of deleted branches are pruned
+	advertised, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return nil, CloneStats{}, err
+	}
+	branches := make(map[string]plumbing.Hash)
+	for _, ref := range advertised {
+		if ref.Name().IsBranch() {
+			branches[ref.Name().Short()] = ref.Hash()
+		}
+	}
+	if len(branches) == 0 {
+		return nil, CloneStats{}, fmt.Errorf("no branches found in %s", repoURL)
+	}
+
+	before, err := collectPackStats(filepath.Join(c.Dir, "objects", "pack"))
+	if err != nil {
+		return nil, CloneStats{}, err
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{Progress: progress, Auth: auth})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, CloneStats{}, err
+	}
+	after, err := collectPackStats(filepath.Join(c.Dir, "objects", "pack"))
+	if err != nil {
+		return nil, CloneStats{}, err
+	}
+
+	refs, err := c.repo.References()
+	if err != nil {
+		return nil, CloneStats{}, err
+	}
+	var stale []plumbing.ReferenceName
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		if branch := strings.TrimPrefix(ref.Name().String(), prefix); branch != ref.Name().String() {
+			if _, ok := branches[branch]; !ok {
+				stale = append(stale, ref.Name())
+			}
+		}
+		return nil
+	})
+	for _, name := range stale {
+		if err := c.repo.Storer.RemoveReference(name); err != nil {
+			return nil, CloneStats{}, err
+		}
+	}
+	return branches, CloneStats{Objects: after.Objects - before.Objects, Bytes: after.Bytes - before.Bytes}, nil
+}
+
+// checkout creates a working clone of repoURL in repoPath that reads its objects from the
+// cache and otherwise looks like a regular clone
+func (c *ObjectCache) checkout(repoURL, repoPath string, branches map[string]plumbing.Hash) error {
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		return err
+	}
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return errors.New("clone storage does not support alternates")
+	}
+	if err := storage.AddAlternate(c.Dir); err != nil {
+		return err
+	}
+	return checkoutBranches(repo, repoURL, branches)
+}
+
+// checkoutBranches makes a repository whose objects are already present look like a clone of
+// repoURL: origin points at it, every branch gets a remote-tracking ref and the default branch
+// (main, else master) is checked out
+func checkoutBranches(repo *git.Repository, repoURL string, branches map[string]plumbing.Hash) error {
+	if _, err := repo.CreateRemote(&config.RemoteConfig{
+		Name:  git.DefaultRemoteName,
+		URLs:  []string{repoURL},
+		Fetch: []config.RefSpec{config.RefSpec(fmt.Sprintf(config.DefaultFetchRefSpec, git.DefaultRemoteName))},
+	}); err != nil {
+		return err
+	}
+
+	for branch, hash := range branches {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch), hash)); err != nil {
+			return err
+		}
+	}
+
+	branch := defaultCacheBranch(branches)
+	head := plumbing.NewBranchReferenceName(branch)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(head, branches[branch])); err != nil {
+		return err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, head)); err != nil {
+		return err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Reset(&git.ResetOptions{Commit: branches[branch], Mode: git.HardReset})
+}
+
+// defaultCacheBranch picks the branch to check out: main, then master, then the first by name
+func defaultCacheBranch(branches map[string]plumbing.Hash) string {
+	for _, branch := range []string{"main", "master"} {
+		if _, ok := branches[branch]; ok {
+			return branch
+		}
+	}
+	names := make([]string, 0, len(branches))
+	for branch := range branches {
+		names = append(names, branch)
+	}
+	sort.Strings(names)
+	return names[0]
+}
@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestObjectCacheSharesForkHistory(t *testing.T) {
+	baseDir := t.TempDir()
+	baseRepo, err := git.PlainInit(baseDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		commitFile(t, baseRepo, baseDir, fmt.Sprintf("file%d.txt", i), fmt.Sprintf("base commit %d", i))
+	}
+
+	// Two forks share the base history and add a commit each
+	var forks []string
+	for i := 0; i < 2; i++ {
+		forkDir := t.TempDir()
+		forkRepo, err := git.PlainClone(forkDir, false, &git.CloneOptions{URL: baseDir})
+		if err != nil {
+			t.Fatalf("Failed to clone fork: %v", err)
+		}
+		commitFile(t, forkRepo, forkDir, "fork.txt", fmt.Sprintf("fork %d change", i))
+		forks = append(forks, forkDir)
+	}
+
+	fullClone := filepath.Join(t.TempDir(), "full")
+	if _, err := git.PlainClone(fullClone, false, &git.CloneOptions{URL: forks[1]}); err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	full, err := collectCloneStats(fullClone)
+	if err != nil {
+		t.Fatalf("Failed to collect clone stats: %v", err)
+	}
+
+	cache, err := OpenObjectCache(filepath.Join(t.TempDir(), "cache"))
+	if err != nil {
+		t.Fatalf("Failed to open object cache: %v", err)
+	}
+	if _, _, err := cache.fetch(context.Background(), forks[0], nil, nil); err != nil {
+		t.Fatalf("Failed to fetch first fork: %v", err)
+	}
+	branches, stats, err := cache.fetch(context.Background(), forks[1], nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to fetch second fork: %v", err)
+	}
+	t.Logf("second fork: %s through the cache, %s without it", stats, full)
+	if stats.Objects >= full.Objects {
+		t.Errorf("Expected the cache to transfer fewer than %d objects, got %d", full.Objects, stats.Objects)
+	}
+
+	repoPath := filepath.Join(t.TempDir(), "clone")
+	if err := cache.checkout(forks[1], repoPath, branches); err != nil {
+		t.Fatalf("Failed to check out clone: %v", err)
+	}
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to open clone: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	count := 0
+	commits.ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	if count != 11 {
+		t.Errorf("Expected 11 commits through the borrowed objects, got %d", count)
+	}
+	if remote, err := repo.Remote(git.DefaultRemoteName); err != nil || remote.Config().URLs[0] != forks[1] {
+		t.Errorf("Expected origin to point at the fork, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// opmChecksumFile lists the SHA-256 of every download in a release directory of the mirror
+const opmChecksumFile = "sha256sum.txt"
+
+// checksumHTTPClient fetches checksum listings, so a mirror that never answers cannot hang the download
+var checksumHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// verifyOPMDownload checks the archive downloaded to path against the sum published for
+// archiveName in the sha256sum.txt of releaseURL. A missing or mismatching sum is an error, and
+// on a mismatch the download is deleted so it is never extracted.
+func (dm *DependencyManager) verifyOPMDownload(releaseURL, archiveName, path string) error {
+	sumsURL := releaseURL + "/" + opmChecksumFile
+	expected, err := fetchChecksum(sumsURL, archiveName)
+	if err != nil {
+		os.Remove(path)
+		return WrapError(err, ErrorTypeNetwork, "failed to fetch the OPM checksum", map[string]interface{}{
+			"url":         sumsURL,
+			"remediation": "use --skip-checksum for mirrors that don't publish " + opmChecksumFile,
+		})
+	}
+	if err := verifyChecksum(path, expected); err != nil {
+		return err
+	}
+	dm.Logger.Debugf("Verified the SHA-256 of %s: %s", archiveName, expected)
+	return nil
+}
+
+// fetchChecksum downloads a sha256sum listing and returns the sum it gives for name
+func fetchChecksum(sumsURL, name string) (string, error) {
+	resp, err := checksumHTTPClient.Get(sumsURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d for %s", resp.StatusCode, sumsURL)
+	}
+	sum, ok, err := parseChecksums(io.LimitReader(resp.Body, 1<<20), name)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%s has no checksum for %s", sumsURL, name)
+	}
+	return sum, nil
+}
+
+// parseChecksums reads sha256sum output, one "<hex sum>  <file>" per line (the file name
+// prefixed with '*' in binary mode), and returns the sum of name
+func parseChecksums(r io.Reader, name string) (string, bool, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true, nil
+		}
+	}
+	return "", false, scanner.Err()
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyChecksum compares the SHA-256 of the file at path with the expected hex sum. On a
+// mismatch the file is deleted and an ErrorTypeValidation error returned.
+func verifyChecksum(path, expected string) error {
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if actual != strings.ToLower(expected) {
+		os.Remove(path)
+		return WrapError(nil, ErrorTypeValidation, fmt.Sprintf("checksum mismatch for %s: expected SHA-256 %s, got %s", path, expected, actual), map[string]interface{}{
+			"remediation": "the download may be corrupt or tampered with; retry, or check the mirror",
+		})
+	}
+	return nil
+}
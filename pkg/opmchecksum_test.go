@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helloSHA256 is the SHA-256 of "hello\n"
+const helloSHA256 = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+
+func TestVerifyChecksum(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		match    bool
+	}{
+		{name: "match", expected: helloSHA256, match: true},
+		{name: "match ignores case", expected: strings.ToUpper(helloSHA256), match: true},
+		{name: "mismatch", expected: strings.Repeat("0", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "opm")
+			if err := os.WriteFile(path, []byte("hello\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := verifyChecksum(path, tt.expected)
+			if tt.match {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var analyzerErr *AnalyzerError
+			if !errors.As(err, &analyzerErr) || analyzerErr.Type != ErrorTypeValidation || !strings.Contains(err.Error(), "checksum mismatch") {
+				t.Errorf("Expected a checksum mismatch validation error, got %v", err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("Expected the mismatching file to be deleted")
+			}
+		})
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sums := "" +
+		"1111111111111111111111111111111111111111111111111111111111111111  opm-mac-4.17.21.tar.gz\n" +
+		strings.ToUpper(helloSHA256) + " *opm-linux-4.17.21.tar.gz\n" +
+		"not a checksum line\n"
+
+	sum, ok, err := parseChecksums(strings.NewReader(sums), "opm-linux-4.17.21.tar.gz")
+	if err != nil || !ok || sum != helloSHA256 {
+		t.Errorf("Expected %s, got %q (found: %v, error: %v)", helloSHA256, sum, ok, err)
+	}
+	if _, ok, _ := parseChecksums(strings.NewReader(sums), "opm-windows-4.17.21.zip"); ok {
+		t.Errorf("Expected no checksum for an unlisted file")
+	}
+}
+
+func TestFetchChecksumTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	original := checksumHTTPClient
+	checksumHTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+	defer func() { checksumHTTPClient = original }()
+
+	if _, err := fetchChecksum(server.URL+"/"+opmChecksumFile, "opm-linux.tar.gz"); err == nil {
+		t.Errorf("Expected a mirror that never answers to time out")
+	}
+}
+
+func TestDownloadOPMChecksum(t *testing.T) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skipf("opm is not published for %s", runtime.GOARCH)
+	}
+
+	// An archive of the platform's opm binary, as the mirror publishes it
+	var archive, archiveName string
+	entries := []tarEntry{{name: opmBinaryName(runtime.GOOS), body: "binary"}}
+	switch runtime.GOOS {
+	case "windows":
+		archive, archiveName = writeZip(t, entries), "opm-windows-4.17.21.zip"
+	case "darwin":
+		archive, archiveName = writeTarGz(t, entries), "opm-mac-4.17.21.tar.gz"
+	default:
+		archive, archiveName = writeTarGz(t, entries), "opm-linux-4.17.21.tar.gz"
+	}
+	archiveSum, err := fileSHA256(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		sums         string // Content of sha256sum.txt; empty when the mirror has none
+		skipChecksum bool
+		expectError  string
+	}{
+		{name: "valid checksum", sums: fmt.Sprintf("%s  %s\n", archiveSum, archiveName)},
+		{name: "mismatch", sums: fmt.Sprintf("%s  %s\n", helloSHA256, archiveName), expectError: "checksum mismatch"},
+		{name: "unlisted archive", sums: fmt.Sprintf("%s  opm-other.tar.gz\n", archiveSum), expectError: "has no checksum for " + archiveName},
+		{name: "no checksums", expectError: "failed to fetch the OPM checksum"},
+		{name: "no checksums skipped", skipChecksum: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch filepath.Base(r.URL.Path) {
+				case archiveName:
+					http.ServeFile(w, r, archive)
+				case opmChecksumFile:
+					if tt.sums == "" {
+						http.NotFound(w, r)
+						return
+					}
+					fmt.Fprint(w, tt.sums)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			binDir := t.TempDir()
+			dm := NewDependencyManager(binDir, nil)
+			dm.OPMVersion = "4.17.21"
+			dm.SkipChecksum = tt.skipChecksum
+			dm.mirrorURL = server.URL
+
+			binPath := filepath.Join(binDir, "opm")
+			_, err := dm.downloadOPM(binPath)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				if _, err := os.Stat(binPath); !os.IsNotExist(err) {
+					t.Errorf("Expected no opm binary after a failed verification")
+				}
+				if _, err := os.Stat(binPath + ".tmp"); !os.IsNotExist(err) {
+					t.Errorf("Expected the unverified download to be deleted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if data, err := os.ReadFile(binPath); err != nil || string(data) != "binary" {
+				t.Errorf("Expected the opm binary at %s, got %q (%v)", binPath, data, err)
+			}
+		})
+	}
+}
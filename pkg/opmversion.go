@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultOPMVersion is the OCP release whose opm is downloaded unless OPM_VERSION says otherwise
+	DefaultOPMVersion = "4.17.21"
+	// OPMVersionLatest selects the newest stable OCP release published on the mirror
+	OPMVersionLatest = "latest"
+
+	opmMirrorURL = "https://mirror.openshift.com/pub/openshift-v4"
+)
+
+// opmVersionPattern matches an OCP release such as "4.17.21" or "4.18.0-rc.1"
+var opmVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// mirrorListingEntryPattern matches the subdirectories linked from a mirror directory listing
+var mirrorListingEntryPattern = regexp.MustCompile(`href="(?:[^"]*/)?([^"/]+)/"`)
+
+// mirrorClientsURL returns the mirror directory holding one subdirectory per OCP release of the
+// clients for arch
+func (dm *DependencyManager) mirrorClientsURL(arch string) string {
+	base := dm.mirrorURL
+	if base == "" {
+		base = opmMirrorURL
+	}
+	return fmt.Sprintf("%s/%s/clients/ocp", strings.TrimSuffix(base, "/"), arch)
+}
+
+// resolveOPMVersion returns the release whose opm is downloaded: OPMVersion, the newest stable
+// release on the mirror for OPMVersionLatest, or DefaultOPMVersion when unset
+func (dm *DependencyManager) resolveOPMVersion(arch string) (string, error) {
+	version := strings.TrimSpace(dm.OPMVersion)
+	if version == "" {
+		version = DefaultOPMVersion
+	}
+	if strings.EqualFold(version, OPMVersionLatest) {
+		latest, err := dm.latestOPMVersion(arch)
+		if err != nil {
+			return "", err
+		}
+		dm.Logger.Infof("Resolved latest OPM version: %s", latest)
+		version = latest
+	}
+	if !opmVersionPattern.MatchString(version) {
+		return "", fmt.Errorf("invalid OPM version %q (expected a release such as %s, or %q)", version, DefaultOPMVersion, OPMVersionLatest)
+	}
+	return version, nil
+}
+
+// latestOPMVersion reads the mirror's directory listing of releases and returns the newest
+// stable one. Release candidates and aliases such as "stable" or "latest" are ignored.
+func (dm *DependencyManager) latestOPMVersion(arch string) (string, error) {
+	listingURL := dm.mirrorClientsURL(arch) + "/"
+	resp, err := http.Get(listingURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to list OPM releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to list OPM releases at %s: HTTP %d", listingURL, resp.StatusCode)
+	}
+	listing, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to list OPM releases: %w", err)
+	}
+
+	var latest semver
+	var latestName string
+	for _, match := range mirrorListingEntryPattern.FindAllStringSubmatch(string(listing), -1) {
+		name := match[1]
+		version, ok := parseReleaseVersion(name)
+		if !ok || !opmVersionPattern.MatchString(name) {
+			continue
+		}
+		if latestName == "" || latest.less(version) {
+			latest, latestName = version, name
+		}
+	}
+	if latestName == "" {
+		return "", fmt.Errorf("no stable OPM release found at %s", listingURL)
+	}
+	return latestName, nil
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// mirrorListing is a directory listing of OCP client releases as the mirror serves it
+const mirrorListing = `<html><body><table>
+<tr><td><a href="../">Parent Directory</a></td></tr>
+<tr><td><a href="4.9.59/">4.9.59/</a></td></tr>
+<tr><td><a href="4.17.21/">4.17.21/</a></td></tr>
+<tr><td><a href="/pub/openshift-v4/x86_64/clients/ocp/4.18.3/">4.18.3/</a></td></tr>
+<tr><td><a href="4.18.10/">4.18.10/</a></td></tr>
+<tr><td><a href="4.19.0-rc.2/">4.19.0-rc.2/</a></td></tr>
+<tr><td><a href="candidate/">candidate/</a></td></tr>
+<tr><td><a href="latest/">latest/</a></td></tr>
+<tr><td><a href="stable/">stable/</a></td></tr>
+<tr><td><a href="README.txt">README.txt</a></td></tr>
+</table></body></html>`
+
+// newTestMirror serves listing for the x86_64 client releases and 404 for anything else
+func newTestMirror(t *testing.T, listing string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/x86_64/clients/ocp/" || listing == "" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, listing)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolveOPMVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		listing     string
+		expected    string
+		expectError string
+	}{
+		{name: "default", expected: DefaultOPMVersion},
+		{name: "pinned", version: "4.16.3", expected: "4.16.3"},
+		{name: "release candidate", version: "4.19.0-rc.2", expected: "4.19.0-rc.2"},
+		{name: "latest", version: "latest", listing: mirrorListing, expected: "4.18.10"},
+		{name: "latest ignores case", version: " Latest ", listing: mirrorListing, expected: "4.18.10"},
+		{name: "latest without releases", version: "latest", listing: `<a href="stable/">stable/</a>`, expectError: "no stable OPM release found"},
+		{name: "latest without listing", version: "latest", expectError: "HTTP 404"},
+		{name: "not a version", version: "4.17", expectError: `invalid OPM version "4.17"`},
+		{name: "path in version", version: "4.17.21/../../evil", expectError: "invalid OPM version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := NewDependencyManager(t.TempDir(), nil)
+			dm.OPMVersion = tt.version
+			dm.mirrorURL = newTestMirror(t, tt.listing).URL
+
+			version, err := dm.resolveOPMVersion("x86_64")
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expected {
+				t.Errorf("Expected version %s, got %s", tt.expected, version)
+			}
+		})
+	}
+}
+
+func TestNewDependencyManagerOPMVersion(t *testing.T) {
+	t.Setenv("OPM_VERSION", "")
+	if dm := NewDependencyManager(t.TempDir(), nil); dm.OPMVersion != DefaultOPMVersion {
+		t.Errorf("Expected default OPM version %s, got %s", DefaultOPMVersion, dm.OPMVersion)
+	}
+
+	t.Setenv("OPM_VERSION", "4.16.3")
+	if dm := NewDependencyManager(t.TempDir(), nil); dm.OPMVersion != "4.16.3" {
+		t.Errorf("Expected OPM_VERSION to select 4.16.3, got %s", dm.OPMVersion)
+	}
+}
+
+func TestDownloadOPMUnknownVersion(t *testing.T) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skipf("opm is not published for %s", runtime.GOARCH)
+	}
+
+	dir := t.TempDir()
+	dm := NewDependencyManager(dir, nil)
+	dm.OPMVersion = "4.99.0"
+	dm.mirrorURL = newTestMirror(t, "").URL
+
+	_, err := dm.downloadOPM(filepath.Join(dir, "opm"))
+	if err == nil || !strings.Contains(err.Error(), "OPM version 4.99.0 was not found on the mirror (HTTP 404") {
+		t.Errorf("Expected a not found error naming the version, got %v", err)
+	}
+}
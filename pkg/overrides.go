@@ -0,0 +1,155 @@
+package pkg
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepositoryOverride holds the settings of one repository that take precedence over the run's
+// flags. Its analysis window is either the last Days days, or the date range Since..Until
+// (YYYY-MM-DD, both days included); Until alone ends a Days window on that date. Link is the
+// web URL commit links are built from, for repositories such as bundles that have none.
+type RepositoryOverride struct {
+	Days  int
+	Since string
+	Until string
+	Link  string
+}
+
+// ParseOverridesFile reads an overrides file. Each line holds a repository URL followed by
+// key=value settings, e.g.
+//
+//	https://github.com/example/busy-operator     days=3
+//	https://github.com/example/dormant-operator  since=2024-01-01 until=2024-03-31
+//	/srv/bundles/operator.bundle                 link=https://github.com/example/operator
+//
+// Blank lines and lines starting with '#' are ignored. A repository listed on several lines
+// combines their settings, later ones winning.
+func ParseOverridesFile(path string) (map[string]RepositoryOverride, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]RepositoryOverride)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a repository URL followed by settings, got %q", i+1, line)
+		}
+		key := labelKey(fields[0])
+		override := overrides[key]
+		for _, setting := range fields[1:] {
+			name, value, found := strings.Cut(setting, "=")
+			if !found || value == "" {
+				return nil, fmt.Errorf("line %d: expected key=value, got %q", i+1, setting)
+			}
+			switch name {
+			case "days":
+				days, err := strconv.Atoi(value)
+				if err != nil || days < 1 {
+					return nil, fmt.Errorf("line %d: days must be a positive integer, got %q", i+1, value)
+				}
+				override.Days = days
+			case "since":
+				override.Since = value
+			case "until":
+				override.Until = value
+			case "link":
+				parsed, err := url.Parse(value)
+				if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+					return nil, fmt.Errorf("line %d: link must be an http(s) URL, got %q", i+1, value)
+				}
+				override.Link = value
+			default:
+				return nil, fmt.Errorf("line %d: unknown setting %q (expected days, since, until or link)", i+1, name)
+			}
+		}
+		if override.Days > 0 && override.Since != "" {
+			return nil, fmt.Errorf("line %d: days and since cannot be combined for %s", i+1, fields[0])
+		}
+		if override.setsWindow() {
+			if _, err := override.window(time.Now(), DefaultAnalysisDays); err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+		}
+		overrides[key] = override
+	}
+	return overrides, nil
+}
+
+// OverrideLinks returns the commit link base of each repository whose overrides set one, for
+// CommitURLTemplates.Links
+func OverrideLinks(overrides map[string]RepositoryOverride) map[string]string {
+	links := make(map[string]string)
+	for key, override := range overrides {
+		if override.Link != "" {
+			links[key] = override.Link
+		}
+	}
+	return links
+}
+
+// runWindow is the analysis window of one repository
+type runWindow struct {
+	since, until time.Time
+	days         int
+	absolute     bool // Given as dates, so headers show the range instead of "last N days"
+	historical   bool // Ends before now, so the latest commit is looked up as of until
+}
+
+// setsWindow reports whether the override changes the analysis window, rather than only the link
+func (o RepositoryOverride) setsWindow() bool {
+	return o.Days > 0 || o.Since != "" || o.Until != ""
+}
+
+// window resolves the override's window against the run's end and number of days, with the
+// date validation of the web interface's since/until fields
+func (o RepositoryOverride) window(end time.Time, defaultDays int) (runWindow, error) {
+	days := o.Days
+	if days <= 0 {
+		days = defaultDays
+	}
+	req := ReleaseNotesRequest{Days: days, Since: o.Since, Until: o.Until}
+	since, until, days, absolute, err := req.analysisWindow(end)
+	if err != nil {
+		return runWindow{}, err
+	}
+	return runWindow{since: since, until: until, days: days, absolute: absolute, historical: o.Until != ""}, nil
+}
+
+// analysisWindowFor returns a repository's analysis window: its entry in Overrides when it has
+// one, else Since..analysisEnd when Since is set, else the last analysisDays days ending at
+// analysisEnd
+func (vtm *VibeToolsManager) analysisWindowFor(repoURL string) runWindow {
+	end := vtm.analysisEnd()
+	window := runWindow{
+		since:      end.AddDate(0, 0, -vtm.analysisDays()),
+		until:      end,
+		days:       vtm.analysisDays(),
+		historical: !vtm.AsOf.IsZero(),
+	}
+	if !vtm.Since.IsZero() {
+		window.since, window.days, window.absolute = vtm.Since, calendarDays(vtm.Since, end), true
+	}
+	override, ok := vtm.Overrides[labelKey(repoURL)]
+	if !ok || !override.setsWindow() {
+		return window
+	}
+	overridden, err := override.window(end, vtm.analysisDays())
+	if err != nil {
+		// The file was validated when parsed, but an --as-of end can still precede its dates
+		vtm.Logger.Warnf("Ignoring the analysis window override of %s: %v", repoURL, err)
+		return window
+	}
+	overridden.historical = overridden.historical || window.historical
+	return overridden
+}
@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestParseOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides")
+	content := `# per-repository windows
+https://github.com/example/busy-operator.git  days=3
+https://github.com/example/dormant-operator   since=2024-01-01 until=2024-03-31
+
+https://github.com/example/frozen-operator    until=2024-06-30
+https://github.com/example/frozen-operator    days=14
+/srv/bundles/operator.bundle                  link=https://github.com/example/operator
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write overrides: %v", err)
+	}
+	overrides, err := ParseOverridesFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]RepositoryOverride{
+		"https://github.com/example/busy-operator":    {Days: 3},
+		"https://github.com/example/dormant-operator": {Since: "2024-01-01", Until: "2024-03-31"},
+		"https://github.com/example/frozen-operator":  {Days: 14, Until: "2024-06-30"},
+		"/srv/bundles/operator.bundle":                {Link: "https://github.com/example/operator"},
+	}
+	if len(overrides) != len(expected) {
+		t.Fatalf("Expected %d overrides, got %v", len(expected), overrides)
+	}
+	for repo, override := range expected {
+		if overrides[repo] != override {
+			t.Errorf("Expected %+v for %s, got %+v", override, repo, overrides[repo])
+		}
+	}
+
+	invalid := map[string]string{
+		"no settings":     "https://github.com/example/operator\n",
+		"not key=value":   "https://github.com/example/operator days\n",
+		"unknown setting": "https://github.com/example/operator branch=main\n",
+		"zero days":       "https://github.com/example/operator days=0\n",
+		"bad date":        "https://github.com/example/operator since=01/02/2024\n",
+		"reversed range":  "https://github.com/example/operator since=2024-03-01 until=2024-02-01\n",
+		"days with since": "https://github.com/example/operator days=3 since=2024-01-01\n",
+		"relative link":   "/srv/bundles/operator.bundle link=example/operator\n",
+	}
+	for name, content := range invalid {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write overrides: %v", err)
+		}
+		if _, err := ParseOverridesFile(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestProcessRepositoriesOverrideWindow(t *testing.T) {
+	now := time.Now()
+	dormantDir := t.TempDir()
+	dormant, err := git.PlainInit(dormantDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, dormant, dormantDir, "old.txt", "old.txt", withMessage("Add old.txt"), withDate(now.AddDate(0, 0, -40)))
+
+	rangeDir := t.TempDir()
+	ranged, err := git.PlainInit(rangeDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, ranged, rangeDir, "january.txt", "january.txt", withMessage("Add january.txt"), withDate(time.Date(2024, 1, 15, 12, 0, 0, 0, time.Local)))
+	commitFile(t, ranged, rangeDir, "recent.txt", "recent.txt", withMessage("Add recent.txt"), withDate(now.Add(-time.Hour)))
+
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(t.TempDir(), "notes.txt"), false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+	vtm.Logger.SetOutput(io.Discard)
+	vtm.NoCloneProgress = true
+	vtm.Days = 7
+	vtm.Overrides = map[string]RepositoryOverride{
+		dormantDir: {Days: 60},
+		rangeDir:   {Since: "2024-01-01", Until: "2024-01-31"},
+	}
+
+	results, err := vtm.ProcessRepositories(context.Background(), []string{dormantDir, rangeDir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Err != nil || result.ReleaseNotes == nil {
+			t.Fatalf("Expected notes for %s, got %v", result.Repository, result.Err)
+		}
+	}
+
+	dormantNotes := results[0].ReleaseNotes
+	if dormantNotes.AnalysisDays != 60 || dormantNotes.WeeklySummary.TotalCommits != 1 {
+		t.Errorf("Expected the 60-day override to find the old commit, got %d days and %d commits",
+			dormantNotes.AnalysisDays, dormantNotes.WeeklySummary.TotalCommits)
+	}
+
+	rangeNotes := results[1].ReleaseNotes
+	if !rangeNotes.AbsoluteRange || rangeNotes.WeeklySummary.TotalCommits != 1 {
+		t.Errorf("Expected only the January commit in the date range, got %d commits", rangeNotes.WeeklySummary.TotalCommits)
+	}
+	if !strings.HasPrefix(rangeNotes.AnalysisPeriod, "2024-01-01 to 2024-01-31") {
+		t.Errorf("Expected the header to show the date range, got %q", rangeNotes.AnalysisPeriod)
+	}
+	if !strings.HasPrefix(firstLine(rangeNotes.LatestCommit.Message), "Add january.txt") {
+		t.Errorf("Expected the latest commit as of the range end, got %q", rangeNotes.LatestCommit.Message)
+	}
+}
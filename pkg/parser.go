@@ -1,13 +1,29 @@
 package pkg
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+
+	"github.com/sirupsen/logrus"
 )
 
+// IndexLogger receives the parser's diagnostics, such as a catalog that yields no repositories
+var IndexLogger = logrus.StandardLogger()
+
+// searchedRepositoryFields describes where the parser looks for repository URLs, for diagnostics
+var searchedRepositoryFields = []string{
+	"the entry's repository field",
+	"olm.csv.metadata annotations.repository",
+	"olm.package/olm.bundle value.repository",
+	"annotations.repository of the CSV in olm.bundle.object",
+	"a source link in the CSV's spec.links",
+}
+
 // OperatorIndex represents the structure of the operator index JSON
 type OperatorIndex struct {
 	Schema         string      `json:"schema"`
@@ -51,13 +67,210 @@ type Property struct {
 
 // ParserRepositoryInfo represents repository information from parser
 type ParserRepositoryInfo struct {
-	URL         string `json:"repository"`
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
+	URL            string   `json:"repository"`
+	Name           string   `json:"name,omitempty"`
+	Description    string   `json:"description,omitempty"`
+	DefaultChannel string   `json:"defaultChannel,omitempty"`
+	Channels       []string `json:"channels,omitempty"`
+	HeadVersion    string   `json:"headVersion,omitempty"` // Version at the head of the default channel, when the catalog tells
+	DisplayName    string   `json:"displayName,omitempty"`  // Human-readable name from the CSV metadata
+	Capabilities   string   `json:"capabilities,omitempty"` // OLM capability level, e.g. "Seamless Upgrades"
 }
 
 // ParseOperatorIndex parses the operator index JSON file and extracts repository URLs
 func ParseOperatorIndex(filePath string) ([]string, error) {
+	content, err := readIndexFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try to parse as newline-delimited JSON (NDJSON) format first
+	allEntries, ndjsonSuccess := splitJSONObjects(string(content))
+	
+	// Initialize repositories map
+	repositories := make(map[string]bool)
+
+	// If NDJSON parsing failed, try parsing as regular JSON
+	if !ndjsonSuccess {
+		var index OperatorIndex
+		if err := json.Unmarshal(content, &index); err != nil {
+			return nil, WrapError(err, ErrorTypeParsing, "failed to parse JSON", map[string]interface{}{
+				"file_path": filePath,
+				"file_size": len(content),
+			})
+		}
+		
+		// Extract repositories from structured format
+		for _, info := range collectPackageRepositories(index.Packages) {
+			repositories[info.URL] = true
+		}
+		
+		// Convert to map for consistent processing
+		indexBytes, _ := json.Marshal(index)
+		var entry map[string]interface{}
+		json.Unmarshal(indexBytes, &entry)
+		allEntries = []map[string]interface{}{entry}
+	}
+	
+	// Also try to parse as structured OperatorIndex if we have entries but no repositories yet
+	// This handles the case where a single structured JSON was successfully parsed as "NDJSON"
+	if len(repositories) == 0 && len(allEntries) > 0 {
+		var index OperatorIndex
+		if err := json.Unmarshal(content, &index); err == nil && len(index.Packages) > 0 {
+			for _, info := range collectPackageRepositories(index.Packages) {
+				repositories[info.URL] = true
+			}
+		}
+	}
+	
+	// Extract repositories from all entries
+	for _, entry := range allEntries {
+		// Extract repository directly from entry if it exists
+		if repo, exists := entry["repository"]; exists {
+			if repoStr, ok := repo.(string); ok {
+				if isValidRepositoryURL(repoStr) {
+					repositories[repoStr] = true
+				}
+			}
+		}
+		
+		// Extract from properties if they exist
+		for _, repo := range repositoriesFromProperties(entry["properties"]) {
+			repositories[repo] = true
+		}
+	}
+
+	// Also try to extract from raw JSON content as fallback
+	rawRepositories := extractRepositoriesFromRawJSON(string(content))
+	for _, repo := range rawRepositories {
+		if isValidRepositoryURL(repo) {
+			repositories[repo] = true
+		}
+	}
+
+	// Convert map keys to slice
+	var result []string
+	for repo := range repositories {
+		result = append(result, repo)
+	}
+
+	if len(result) == 0 {
+		if err := noRepositoriesExtracted(filePath, allEntries); err != nil {
+			return nil, err
+		}
+		return []string{}, nil
+	}
+
+	return result, nil
+}
+
+// ParseOperatorIndexDetailed parses the operator index JSON file and returns each repository
+// together with the package and channel metadata of the catalog entries that reference it
+func ParseOperatorIndexDetailed(filePath string) ([]ParserRepositoryInfo, error) {
+	content, err := readIndexFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []ParserRepositoryInfo
+	var entries []map[string]interface{}
+
+	// Structured OperatorIndex with nested packages
+	var index OperatorIndex
+	if err := json.Unmarshal(content, &index); err == nil && len(index.Packages) > 0 {
+		infos = collectPackageRepositories(index.Packages)
+		setHeadVersions(infos, packageHeadVersions(index.Packages))
+		var entry map[string]interface{}
+		json.Unmarshal(content, &entry)
+		entries = []map[string]interface{}{entry}
+	} else {
+		// File-based catalog: olm.package, olm.channel and olm.bundle blobs, or a render of a
+		// sqlite-based index whose bundles carry their channels and CSV as properties
+		var ok bool
+		entries, ok = splitJSONObjects(string(content))
+		if !ok {
+			return nil, WrapError(nil, ErrorTypeParsing, "failed to parse JSON", map[string]interface{}{
+				"file_path": filePath,
+				"file_size": len(content),
+			})
+		}
+		infos = collectCatalogRepositories(entries)
+		setHeadVersions(infos, catalogHeadVersions(entries))
+	}
+
+	if len(infos) == 0 {
+		if err := noRepositoriesExtracted(filePath, entries); err != nil {
+			return nil, err
+		}
+		return []ParserRepositoryInfo{}, nil
+	}
+
+	return mergeRepositoryInfos(infos), nil
+}
+
+// ParseCatalogSchemas returns the distinct top-level schema values in the index, sorted.
+// A file-based catalog usually yields several (olm.package, olm.channel, olm.bundle);
+// the result is empty when the index does not declare any schema.
+func ParseCatalogSchemas(filePath string) ([]string, error) {
+	content, err := readIndexFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := splitJSONObjects(string(content))
+	if !ok {
+		return nil, WrapError(nil, ErrorTypeParsing, "failed to parse JSON", map[string]interface{}{
+			"file_path": filePath,
+			"file_size": len(content),
+		})
+	}
+
+	var schemas []string
+	for _, entry := range entries {
+		if schema, ok := entry["schema"].(string); ok && schema != "" && !containsString(schemas, schema) {
+			schemas = append(schemas, schema)
+		}
+	}
+	sort.Strings(schemas)
+	return schemas, nil
+}
+
+// noRepositoriesExtracted handles an index that yielded no repository. A file without any
+// catalog entry is invalid and returns an error. A catalog whose entries simply didn't match
+// the extraction logic is logged as a warning, so callers get an empty list and decide.
+func noRepositoriesExtracted(filePath string, entries []map[string]interface{}) error {
+	scanned := 0
+	var schemas []string
+	for _, entry := range entries {
+		if packages, ok := entry["packages"].([]interface{}); ok && len(packages) > 0 {
+			scanned += len(packages)
+			continue
+		}
+		if schema, ok := entry["schema"].(string); ok && schema != "" {
+			scanned++
+			if !containsString(schemas, schema) {
+				schemas = append(schemas, schema)
+			}
+		}
+	}
+
+	if scanned == 0 {
+		return WrapError(nil, ErrorTypeValidation, "no valid repositories found in index", map[string]interface{}{
+			"file_path": filePath,
+		})
+	}
+
+	sort.Strings(schemas)
+	IndexLogger.WithFields(logrus.Fields{
+		"file_path":       filePath,
+		"entries_scanned": scanned,
+		"schemas":         strings.Join(schemas, ","),
+	}).Warnf("No repositories extracted from %d catalog entries; searched %s", scanned, strings.Join(searchedRepositoryFields, ", "))
+	return nil
+}
+
+// readIndexFile reads the index file, validating that it exists and is not empty
+func readIndexFile(filePath string) ([]byte, error) {
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return nil, WrapError(err, ErrorTypeFileSystem, "index file does not exist", map[string]interface{}{
@@ -74,7 +287,7 @@ func ParseOperatorIndex(filePath string) ([]string, error) {
 	defer func() {
 		if closeErr := file.Close(); closeErr != nil {
 			// Log but don't return error for close failures
-			fmt.Printf("Warning: failed to close file %s: %v\n", filePath, closeErr)
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", filePath, closeErr)
 		}
 	}()
 
@@ -92,17 +305,28 @@ func ParseOperatorIndex(filePath string) ([]string, error) {
 		})
 	}
 
-	// Try to parse as newline-delimited JSON (NDJSON) format first
-	var allEntries []map[string]interface{}
-	lines := strings.Split(string(content), "\n")
-	ndjsonSuccess := true
-	
-	// Initialize repositories map
-	repositories := make(map[string]bool)
+	return content, nil
+}
+
+// splitJSONObjects splits content into JSON objects that may span multiple lines.
+// Braces inside string values, such as a description containing "{", don't count towards
+// an object's balance. Content that is a single JSON array yields its elements instead.
+// It returns false if any object fails to decode.
+func splitJSONObjects(content string) ([]map[string]interface{}, bool) {
+	var entries []map[string]interface{}
+	if strings.HasPrefix(strings.TrimSpace(content), "[") {
+		if err := json.Unmarshal([]byte(content), &entries); err != nil {
+			return nil, false
+		}
+		return entries, true
+	}
+	lines := strings.Split(content, "\n")
 	
 	// Parse JSON objects that may span multiple lines
 	currentJSON := ""
 	braceCount := 0
+	inString := false
+	escaped := false
 	
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -112,11 +336,19 @@ func ParseOperatorIndex(filePath string) ([]string, error) {
 		
 		currentJSON += line
 		
-		// Count braces to determine when we have a complete JSON object
+		// Count braces outside string values to determine when we have a complete JSON object
 		for _, char := range line {
-			if char == '{' {
+			switch {
+			case escaped:
+				escaped = false
+			case inString && char == '\\':
+				escaped = true
+			case char == '"':
+				inString = !inString
+			case inString:
+			case char == '{':
 				braceCount++
-			} else if char == '}' {
+			case char == '}':
 				braceCount--
 			}
 		}
@@ -125,167 +357,358 @@ func ParseOperatorIndex(filePath string) ([]string, error) {
 		if braceCount == 0 && currentJSON != "" {
 			var entry map[string]interface{}
 			if err := json.Unmarshal([]byte(currentJSON), &entry); err != nil {
-				ndjsonSuccess = false
-				break
+				return entries, false
 			}
-			allEntries = append(allEntries, entry)
+			entries = append(entries, entry)
 			currentJSON = ""
 		}
 	}
 
-	// If NDJSON parsing failed, try parsing as regular JSON
-	if !ndjsonSuccess {
-		var index OperatorIndex
-		if err := json.Unmarshal(content, &index); err != nil {
-			return nil, WrapError(err, ErrorTypeParsing, "failed to parse JSON", map[string]interface{}{
-				"file_path": filePath,
-				"file_size": len(content),
-			})
+	return entries, true
+}
+
+// repositoriesFromProperties extracts repository URLs from a catalog entry's properties,
+// checking olm.csv.metadata annotations (or, without a repository annotation, the CSV's
+// source link), the legacy olm.package/olm.bundle values and the ClusterServiceVersion
+// embedded by renders of sqlite-based indexes
+func repositoriesFromProperties(properties interface{}) []string {
+	var repositories []string
+
+	propsArray, ok := properties.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, prop := range propsArray {
+		propMap, ok := prop.(map[string]interface{})
+		if !ok {
+			continue
 		}
-		
-		// Extract repositories from structured format
-		for _, pkg := range index.Packages {
-			for _, channel := range pkg.Channels {
-				for _, entry := range channel.Entries {
-					for _, prop := range entry.Properties {
-						// Try to extract repository from property value
-						if valueMap, ok := prop.Value.(map[string]interface{}); ok {
-							if repo, exists := valueMap["repository"]; exists {
-								if repoStr, ok := repo.(string); ok {
-									if isValidRepositoryURL(repoStr) {
-										repositories[repoStr] = true
-									}
-								}
-							}
-						}
-					}
+		propType, _ := propMap["type"].(string)
+		valueMap, ok := propMap["value"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch propType {
+		case "olm.csv.metadata":
+			// Check for repository in olm.csv.metadata annotations
+			annMap, _ := valueMap["annotations"].(map[string]interface{})
+			if repoStr, ok := annMap["repository"].(string); ok && isValidRepositoryURL(repoStr) {
+				repositories = append(repositories, repoStr)
+			} else if repoStr, ok := sourceRepositoryFromLinks(csvLinksFrom(valueMap["links"])); ok {
+				repositories = append(repositories, repoStr)
+			}
+		case "olm.package", "olm.bundle":
+			// Legacy format: olm.package or olm.bundle
+			if repoStr, ok := valueMap["repository"].(string); ok && isValidRepositoryURL(repoStr) {
+				repositories = append(repositories, repoStr)
+			}
+		case "olm.bundle.object":
+			// sqlite-based renders carry the bundle manifests base64-encoded instead of olm.csv.metadata
+			if data, ok := valueMap["data"].(string); ok {
+				if repoStr, ok := repositoryFromBundleObject(data); ok {
+					repositories = append(repositories, repoStr)
 				}
 			}
 		}
-		
-		// Convert to map for consistent processing
-		indexBytes, _ := json.Marshal(index)
-		var entry map[string]interface{}
-		json.Unmarshal(indexBytes, &entry)
-		allEntries = []map[string]interface{}{entry}
 	}
-	
-	// Also try to parse as structured OperatorIndex if we have entries but no repositories yet
-	// This handles the case where a single structured JSON was successfully parsed as "NDJSON"
-	if len(repositories) == 0 && len(allEntries) > 0 {
-		var index OperatorIndex
-		if err := json.Unmarshal(content, &index); err == nil && len(index.Packages) > 0 {
-			// Extract repositories from structured format
-			for _, pkg := range index.Packages {
-				for _, channel := range pkg.Channels {
-					for _, entry := range channel.Entries {
-						for _, prop := range entry.Properties {
-							// Try to extract repository from property value
-							if valueMap, ok := prop.Value.(map[string]interface{}); ok {
-								if repo, exists := valueMap["repository"]; exists {
-									if repoStr, ok := repo.(string); ok {
-										if isValidRepositoryURL(repoStr) {
-											repositories[repoStr] = true
-										}
-									}
-								}
-							}
+
+	return repositories
+}
+
+// repositoryFromBundleObject decodes a base64-encoded bundle manifest and returns the
+// repository annotation, or else the source link, when the manifest is a ClusterServiceVersion
+func repositoryFromBundleObject(data string) (string, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", false
+	}
+	var object struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+		Spec struct {
+			Links []csvLink `json:"links"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(decoded, &object); err != nil || object.Kind != "ClusterServiceVersion" {
+		return "", false
+	}
+	if repoStr := object.Metadata.Annotations["repository"]; isValidRepositoryURL(repoStr) {
+		return repoStr, true
+	}
+	return sourceRepositoryFromLinks(object.Spec.Links)
+}
+
+// csvLink is an entry of a ClusterServiceVersion's spec.links
+type csvLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// csvLinksFrom reads the links of an olm.csv.metadata property value
+func csvLinksFrom(value interface{}) []csvLink {
+	var links []csvLink
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		if linkMap, ok := item.(map[string]interface{}); ok {
+			name, _ := linkMap["name"].(string)
+			url, _ := linkMap["url"].(string)
+			links = append(links, csvLink{Name: name, URL: url})
+		}
+	}
+	return links
+}
+
+// sourceLinkNames are the lowercased spec.links names under which CSVs document their source
+var sourceLinkNames = map[string]bool{
+	"source":            true,
+	"source code":       true,
+	"source repository": true,
+	"repository":        true,
+	"git repository":    true,
+}
+
+// sourceRepositoryFromLinks returns the source repository documented in a CSV's links: a link
+// named "Source", "Source Code" or the like that points at a git project, else a link to a
+// GitHub or GitLab project root
+func sourceRepositoryFromLinks(links []csvLink) (string, bool) {
+	for _, link := range links {
+		name := strings.ToLower(strings.TrimSpace(link.Name))
+		if _, ok := gitProjectHost(link.URL); ok && sourceLinkNames[name] {
+			return strings.TrimSuffix(link.URL, "/"), true
+		}
+	}
+	for _, link := range links {
+		if isProjectRootURL(link.URL) {
+			return strings.TrimSuffix(link.URL, "/"), true
+		}
+	}
+	return "", false
+}
+
+// gitProjectHost returns the host of an HTTP(S) URL pointing at a git project itself
+// (https://host/owner/name) rather than at its documentation or another page
+func gitProjectHost(url string) (string, bool) {
+	rest, ok := strings.CutPrefix(url, "https://")
+	if !ok {
+		if rest, ok = strings.CutPrefix(url, "http://"); !ok {
+			return "", false
+		}
+	}
+	if strings.ContainsAny(rest, "?#@ ") {
+		return "", false
+	}
+	host, path, _ := strings.Cut(strings.TrimSuffix(rest, "/"), "/")
+	segments := strings.Split(strings.TrimSuffix(path, ".git"), "/")
+	if host == "" || len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", false
+	}
+	return host, true
+}
+
+// isProjectRootURL reports whether a URL points at a github.com or gitlab.com project itself
+func isProjectRootURL(url string) bool {
+	host, ok := gitProjectHost(url)
+	return ok && strings.HasPrefix(url, "https://") && (host == "github.com" || host == "gitlab.com")
+}
+
+// displayMetadataFromProperties returns the display name and capability level declared by a
+// catalog entry's olm.csv.metadata property; both are empty when the entry has none
+func displayMetadataFromProperties(properties interface{}) (displayName, capabilities string) {
+	propsArray, _ := properties.([]interface{})
+	for _, prop := range propsArray {
+		if propMap, ok := prop.(map[string]interface{}); ok && propMap["type"] == "olm.csv.metadata" {
+			return csvDisplayMetadata(propMap["value"])
+		}
+	}
+	return "", ""
+}
+
+// csvDisplayMetadata reads the displayName and the capabilities annotation of an
+// olm.csv.metadata property value
+func csvDisplayMetadata(value interface{}) (displayName, capabilities string) {
+	valueMap, _ := value.(map[string]interface{})
+	displayName, _ = valueMap["displayName"].(string)
+	annMap, _ := valueMap["annotations"].(map[string]interface{})
+	capabilities, _ = annMap["capabilities"].(string)
+	return strings.TrimSpace(displayName), strings.TrimSpace(capabilities)
+}
+
+// channelsFromProperties returns the channels a bundle declares through olm.channel
+// properties, which sqlite-based renders use instead of olm.channel blobs
+func channelsFromProperties(properties interface{}) []string {
+	var channels []string
+	propsArray, _ := properties.([]interface{})
+	for _, prop := range propsArray {
+		propMap, ok := prop.(map[string]interface{})
+		if !ok || propMap["type"] != "olm.channel" {
+			continue
+		}
+		valueMap, _ := propMap["value"].(map[string]interface{})
+		if name, ok := valueMap["name"].(string); ok && name != "" && !containsString(channels, name) {
+			channels = append(channels, name)
+		}
+	}
+	return channels
+}
+
+// collectPackageRepositories extracts repositories from the structured OperatorIndex packages
+func collectPackageRepositories(packages []Package) []ParserRepositoryInfo {
+	var infos []ParserRepositoryInfo
+	for _, pkg := range packages {
+		for _, channel := range pkg.Channels {
+			for _, entry := range channel.Entries {
+				var displayName, capabilities string
+				for _, prop := range entry.Properties {
+					if prop.Type == "olm.csv.metadata" {
+						displayName, capabilities = csvDisplayMetadata(prop.Value)
+					}
+				}
+				for _, prop := range entry.Properties {
+					// Try to extract repository from property value
+					if valueMap, ok := prop.Value.(map[string]interface{}); ok {
+						if repoStr, ok := valueMap["repository"].(string); ok && isValidRepositoryURL(repoStr) {
+							infos = append(infos, ParserRepositoryInfo{
+								URL:            repoStr,
+								Name:           pkg.Name,
+								Description:    pkg.Description,
+								DefaultChannel: pkg.DefaultChannel,
+								Channels:       []string{channel.Name},
+								DisplayName:    displayName,
+								Capabilities:   capabilities,
+							})
 						}
 					}
 				}
 			}
 		}
 	}
-	
-	// Extract repositories from all entries
-	for _, entry := range allEntries {
-		// Extract repository directly from entry if it exists
-		if repo, exists := entry["repository"]; exists {
-			if repoStr, ok := repo.(string); ok {
-				if isValidRepositoryURL(repoStr) {
-					repositories[repoStr] = true
-				}
-			}
-		}
-		
-		// Extract from properties if they exist
-		if properties, exists := entry["properties"]; exists {
-			if propsArray, ok := properties.([]interface{}); ok {
-				for _, prop := range propsArray {
-					if propMap, ok := prop.(map[string]interface{}); ok {
-						// Check for repository in olm.csv.metadata annotations
-						if propType, typeExists := propMap["type"]; typeExists {
-							if propTypeStr, ok := propType.(string); ok {
-								if propTypeStr == "olm.csv.metadata" {
-									if propValue, valueExists := propMap["value"]; valueExists {
-										if valueMap, ok := propValue.(map[string]interface{}); ok {
-											if annotations, annExists := valueMap["annotations"]; annExists {
-												if annMap, ok := annotations.(map[string]interface{}); ok {
-													if repo, repoExists := annMap["repository"]; repoExists {
-														if repoStr, ok := repo.(string); ok {
-															if isValidRepositoryURL(repoStr) {
-																repositories[repoStr] = true
-															}
-														}
-													}
-												}
-											}
-										}
-									}
-								}
-							}
-						}
-						
-						// Legacy format: olm.package or olm.bundle
-						if propType, typeExists := propMap["type"]; typeExists {
-							if propTypeStr, ok := propType.(string); ok {
-								if propTypeStr == "olm.package" || propTypeStr == "olm.bundle" {
-									if propValue, valueExists := propMap["value"]; valueExists {
-										if valueMap, ok := propValue.(map[string]interface{}); ok {
-											if repo, repoExists := valueMap["repository"]; repoExists {
-												if repoStr, ok := repo.(string); ok {
-													if isValidRepositoryURL(repoStr) {
-														repositories[repoStr] = true
-													}
-												}
-											}
-										}
-									}
-								}
-							}
-						}
+	return infos
+}
+
+// collectCatalogRepositories extracts repositories from file-based catalog blobs, joining
+// each olm.bundle with the olm.package and olm.channel blobs that reference it
+func collectCatalogRepositories(entries []map[string]interface{}) []ParserRepositoryInfo {
+	defaultChannels := make(map[string]string)
+	descriptions := make(map[string]string)
+	bundleChannels := make(map[string][]string)
+
+	for _, entry := range entries {
+		schema, _ := entry["schema"].(string)
+		name, _ := entry["name"].(string)
+		switch schema {
+		case "olm.package":
+			defaultChannels[name], _ = entry["defaultChannel"].(string)
+			descriptions[name], _ = entry["description"].(string)
+		case "olm.channel":
+			channelEntries, _ := entry["entries"].([]interface{})
+			for _, ce := range channelEntries {
+				if ceMap, ok := ce.(map[string]interface{}); ok {
+					if bundleName, ok := ceMap["name"].(string); ok {
+						bundleChannels[bundleName] = append(bundleChannels[bundleName], name)
 					}
 				}
 			}
 		}
 	}
 
-	// Also try to extract from raw JSON content as fallback
-	rawRepositories := extractRepositoriesFromRawJSON(string(content))
-	for _, repo := range rawRepositories {
-		if isValidRepositoryURL(repo) {
-			repositories[repo] = true
+	var infos []ParserRepositoryInfo
+	for _, entry := range entries {
+		packageName, _ := entry["package"].(string)
+		bundleName, _ := entry["name"].(string)
+
+		repos := repositoriesFromProperties(entry["properties"])
+		if repoStr, ok := entry["repository"].(string); ok && isValidRepositoryURL(repoStr) {
+			repos = append(repos, repoStr)
+		}
+
+		channels := bundleChannels[bundleName]
+		for _, channel := range channelsFromProperties(entry["properties"]) {
+			if !containsString(channels, channel) {
+				channels = append(channels, channel)
+			}
+		}
+
+		displayName, capabilities := displayMetadataFromProperties(entry["properties"])
+		for _, repo := range repos {
+			infos = append(infos, ParserRepositoryInfo{
+				URL:            repo,
+				Name:           packageName,
+				Description:    descriptions[packageName],
+				DefaultChannel: defaultChannels[packageName],
+				Channels:       channels,
+				DisplayName:    displayName,
+				Capabilities:   capabilities,
+			})
 		}
 	}
+	return infos
+}
 
-	// Convert map keys to slice
-	var result []string
-	for repo := range repositories {
-		result = append(result, repo)
+// mergeRepositoryInfos combines entries for the same repository URL, merging their channels.
+// The result is sorted by URL.
+func mergeRepositoryInfos(infos []ParserRepositoryInfo) []ParserRepositoryInfo {
+	merged := make(map[string]*ParserRepositoryInfo)
+	var order []string
+
+	for _, info := range infos {
+		existing, ok := merged[info.URL]
+		if !ok {
+			copied := info
+			copied.Channels = nil
+			merged[info.URL] = &copied
+			existing = &copied
+			order = append(order, info.URL)
+		}
+		if existing.Name == "" {
+			existing.Name = info.Name
+		}
+		if existing.Description == "" {
+			existing.Description = info.Description
+		}
+		if existing.DefaultChannel == "" {
+			existing.DefaultChannel = info.DefaultChannel
+		}
+		if existing.HeadVersion == "" {
+			existing.HeadVersion = info.HeadVersion
+		}
+		if existing.DisplayName == "" {
+			existing.DisplayName = info.DisplayName
+		}
+		if existing.Capabilities == "" {
+			existing.Capabilities = info.Capabilities
+		}
+		for _, channel := range info.Channels {
+			if !containsString(existing.Channels, channel) {
+				existing.Channels = append(existing.Channels, channel)
+			}
+		}
 	}
 
-	if len(result) == 0 {
-		return nil, WrapError(nil, ErrorTypeValidation, "no valid repositories found in index", map[string]interface{}{
-			"file_path": filePath,
-		})
+	sort.Strings(order)
+	result := make([]ParserRepositoryInfo, 0, len(order))
+	for _, url := range order {
+		result = append(result, *merged[url])
 	}
+	return result
+}
 
-	return result, nil
+// containsString reports whether the slice contains the given value
+func containsString(slice []string, value string) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
 }
 
 // isValidRepositoryURL validates if a string is a valid repository URL
 func isValidRepositoryURL(url string) bool {
-	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "git@")
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "git@") || IsBundlePath(url)
 }
 
 // extractRepositoriesFromRawJSON extracts repository URLs from raw JSON content
@@ -1,7 +1,13 @@
 package pkg
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/sirupsen/logrus"
 )
 
 func TestParseOperatorIndex(t *testing.T) {
@@ -22,6 +28,57 @@ func TestParseOperatorIndex(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:          "file-based catalog",
+			indexFile:     "../testdata/sample_fbc_index.json",
+			expectedCount: 2,
+			expectedRepos: []string{
+				"https://github.com/ComplianceAsCode/compliance-operator",
+				"https://github.com/quay/container-security-operator",
+			},
+			expectError: false,
+		},
+		{
+			name:          "sqlite-based index render",
+			indexFile:     "../testdata/sample_sqlite_index.json",
+			expectedCount: 2,
+			expectedRepos: []string{
+				"https://github.com/openshift/compliance-operator",
+				"https://github.com/openshift/cluster-logging-operator",
+			},
+			expectError: false,
+		},
+		{
+			name:          "repositories documented in CSV links",
+			indexFile:     "../testdata/sample_links_index.json",
+			expectedCount: 3,
+			expectedRepos: []string{
+				"https://github.com/openshift/file-integrity-operator",
+				"https://github.com/openshift/node-observability-operator",
+				"https://github.com/openshift/secrets-store-csi-driver-operator",
+			},
+			expectError: false,
+		},
+		{
+			name:          "catalog as a single JSON array",
+			indexFile:     "../testdata/sample_array_index.json",
+			expectedCount: 2,
+			expectedRepos: []string{
+				"https://github.com/ComplianceAsCode/compliance-operator",
+				"https://github.com/quay/container-security-operator",
+			},
+			expectError: false,
+		},
+		{
+			name:          "braces inside string values",
+			indexFile:     "../testdata/sample_braces_index.json",
+			expectedCount: 2,
+			expectedRepos: []string{
+				"https://github.com/example/template-operator",
+				"https://github.com/example/brace-operator",
+			},
+			expectError: false,
+		},
 		{
 			name:        "non-existent file",
 			indexFile:   "../testdata/non_existent.json",
@@ -66,6 +123,48 @@ func TestParseOperatorIndex(t *testing.T) {
 	}
 }
 
+func TestParseOperatorIndexNoRepositories(t *testing.T) {
+	dir := t.TempDir()
+	unmatched := filepath.Join(dir, "unmatched.json")
+	catalog := `{"schema": "olm.package", "name": "example-operator", "defaultChannel": "stable"}
+{"schema": "olm.bundle", "package": "example-operator", "name": "example-operator.v1.0.0", "properties": [{"type": "olm.gvk", "value": {"group": "example.com", "kind": "Example", "version": "v1"}}]}
+`
+	if err := os.WriteFile(unmatched, []byte(catalog), 0644); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+	noEntries := filepath.Join(dir, "no-entries.json")
+	if err := os.WriteFile(noEntries, []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+
+	var logs bytes.Buffer
+	previous := IndexLogger
+	IndexLogger = logrus.New()
+	IndexLogger.SetOutput(&logs)
+	defer func() { IndexLogger = previous }()
+
+	// A catalog that parsed but didn't match the extraction logic is not an error
+	repositories, err := ParseOperatorIndex(unmatched)
+	if err != nil || repositories == nil || len(repositories) != 0 {
+		t.Errorf("Expected an empty repository list, got %v, %v", repositories, err)
+	}
+	infos, err := ParseOperatorIndexDetailed(unmatched)
+	if err != nil || infos == nil || len(infos) != 0 {
+		t.Errorf("Expected an empty repository info list, got %v, %v", infos, err)
+	}
+	if !strings.Contains(logs.String(), "No repositories extracted from 2 catalog entries") || !strings.Contains(logs.String(), "olm.csv.metadata") {
+		t.Errorf("Expected a warning with the entries scanned and the fields searched, got %q", logs.String())
+	}
+
+	// A file without any catalog entry is invalid
+	if _, err := ParseOperatorIndex(noEntries); err == nil {
+		t.Error("Expected an error for an index without catalog entries")
+	}
+	if _, err := ParseOperatorIndexDetailed(noEntries); err == nil {
+		t.Error("Expected an error for an index without catalog entries")
+	}
+}
+
 func TestRemoveDuplicates(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -158,3 +257,233 @@ func TestIsValidRepositoryURL(t *testing.T) {
 		})
 	}
 }
+
+func TestParseOperatorIndexDetailed(t *testing.T) {
+	tests := []struct {
+		name             string
+		indexFile        string
+		expectedChannels map[string][]string
+		expectedDefaults map[string]string
+		expectedHeads    map[string]string
+		expectedDisplay  map[string]string
+		expectedLevels   map[string]string
+	}{
+		{
+			name:      "structured index",
+			indexFile: "../testdata/sample_index.json",
+			expectedChannels: map[string][]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": {"stable"},
+				"https://github.com/quay/container-security-operator":     {"stable"},
+			},
+			expectedDefaults: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "stable",
+				"https://github.com/quay/container-security-operator":     "stable",
+			},
+			expectedHeads: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "1.0.0",
+				"https://github.com/quay/container-security-operator":     "1.0.0",
+			},
+		},
+		{
+			name:      "file-based catalog",
+			indexFile: "../testdata/sample_fbc_index.json",
+			expectedChannels: map[string][]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": {"stable", "fast"},
+				"https://github.com/quay/container-security-operator":     {"preview"},
+			},
+			expectedDefaults: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "stable",
+				"https://github.com/quay/container-security-operator":     "preview",
+			},
+			expectedHeads: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "1.0.0",
+				"https://github.com/quay/container-security-operator":     "3.10.0",
+			},
+			expectedDisplay: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "Compliance Operator",
+			},
+			expectedLevels: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "Seamless Upgrades",
+			},
+		},
+		{
+			name:      "catalog as a single JSON array",
+			indexFile: "../testdata/sample_array_index.json",
+			expectedChannels: map[string][]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": {"stable", "fast"},
+				"https://github.com/quay/container-security-operator":     {"preview"},
+			},
+			expectedDefaults: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "stable",
+				"https://github.com/quay/container-security-operator":     "preview",
+			},
+			expectedHeads: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "1.0.0",
+				"https://github.com/quay/container-security-operator":     "3.10.0",
+			},
+			expectedDisplay: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "Compliance Operator",
+			},
+			expectedLevels: map[string]string{
+				"https://github.com/ComplianceAsCode/compliance-operator": "Seamless Upgrades",
+			},
+		},
+		{
+			name:      "sqlite-based index render",
+			indexFile: "../testdata/sample_sqlite_index.json",
+			expectedChannels: map[string][]string{
+				"https://github.com/openshift/compliance-operator":      {"release-0.1", "4.7"},
+				"https://github.com/openshift/cluster-logging-operator": {"stable"},
+			},
+			expectedDefaults: map[string]string{
+				"https://github.com/openshift/compliance-operator":      "release-0.1",
+				"https://github.com/openshift/cluster-logging-operator": "stable",
+			},
+			expectedHeads: map[string]string{
+				"https://github.com/openshift/compliance-operator":      "0.1.61",
+				"https://github.com/openshift/cluster-logging-operator": "5.4.2",
+			},
+		},
+		{
+			name:      "repositories documented in CSV links",
+			indexFile: "../testdata/sample_links_index.json",
+			expectedChannels: map[string][]string{
+				"https://github.com/openshift/file-integrity-operator":           {"stable"},
+				"https://github.com/openshift/node-observability-operator":       {"alpha"},
+				"https://github.com/openshift/secrets-store-csi-driver-operator": {"stable"},
+			},
+			expectedDefaults: map[string]string{
+				"https://github.com/openshift/file-integrity-operator":           "stable",
+				"https://github.com/openshift/node-observability-operator":       "alpha",
+				"https://github.com/openshift/secrets-store-csi-driver-operator": "stable",
+			},
+			expectedHeads: map[string]string{
+				"https://github.com/openshift/file-integrity-operator":           "1.3.3",
+				"https://github.com/openshift/node-observability-operator":       "0.2.0",
+				"https://github.com/openshift/secrets-store-csi-driver-operator": "4.14.0",
+			},
+			expectedLevels: map[string]string{
+				"https://github.com/openshift/file-integrity-operator": "Seamless Upgrades",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos, err := ParseOperatorIndexDetailed(tt.indexFile)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(infos) != len(tt.expectedChannels) {
+				t.Fatalf("Expected %d repositories, got %d", len(tt.expectedChannels), len(infos))
+			}
+
+			for _, info := range infos {
+				expected, ok := tt.expectedChannels[info.URL]
+				if !ok {
+					t.Errorf("Unexpected repository %s", info.URL)
+					continue
+				}
+				if len(info.Channels) != len(expected) {
+					t.Errorf("Expected channels %v for %s, got %v", expected, info.URL, info.Channels)
+					continue
+				}
+				for i, channel := range expected {
+					if info.Channels[i] != channel {
+						t.Errorf("Expected channel %s at position %d for %s, got %s", channel, i, info.URL, info.Channels[i])
+					}
+				}
+				if info.DefaultChannel != tt.expectedDefaults[info.URL] {
+					t.Errorf("Expected default channel %s for %s, got %s", tt.expectedDefaults[info.URL], info.URL, info.DefaultChannel)
+				}
+				if info.HeadVersion != tt.expectedHeads[info.URL] {
+					t.Errorf("Expected head version %s for %s, got %s", tt.expectedHeads[info.URL], info.URL, info.HeadVersion)
+				}
+				if info.DisplayName != tt.expectedDisplay[info.URL] {
+					t.Errorf("Expected display name %q for %s, got %q", tt.expectedDisplay[info.URL], info.URL, info.DisplayName)
+				}
+				if info.Capabilities != tt.expectedLevels[info.URL] {
+					t.Errorf("Expected capability level %q for %s, got %q", tt.expectedLevels[info.URL], info.URL, info.Capabilities)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCatalogSchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected []string
+	}{
+		{
+			name:     "file-based catalog",
+			file:     "../testdata/sample_fbc_index.json",
+			expected: []string{"olm.bundle", "olm.channel", "olm.package"},
+		},
+		{
+			name:     "structured index",
+			file:     "../testdata/sample_index.json",
+			expected: []string{"olm.package"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemas, err := ParseCatalogSchemas(tt.file)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if strings.Join(schemas, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected schemas %v, got %v", tt.expected, schemas)
+			}
+		})
+	}
+}
+
+func TestSourceRepositoryFromLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		links    []csvLink
+		expected string
+	}{
+		{
+			name:     "source code link on any host",
+			links:    []csvLink{{Name: "Source Code", URL: "https://gitlab.example.com/team/operator.git"}},
+			expected: "https://gitlab.example.com/team/operator.git",
+		},
+		{
+			name:     "name only containing source",
+			links:    []csvLink{{Name: "Open Source License", URL: "https://example.com/license/apache"}},
+			expected: "",
+		},
+		{
+			name:     "source link to a page of the project",
+			links:    []csvLink{{Name: "Source", URL: "https://github.com/example/operator/tree/main/docs"}},
+			expected: "",
+		},
+		{
+			name: "project root after a non-project source link",
+			links: []csvLink{
+				{Name: "Source", URL: "https://example.com/downloads"},
+				{Name: "Operator", URL: "https://github.com/example/operator/"},
+			},
+			expected: "https://github.com/example/operator",
+		},
+		{
+			name:     "project root with a query string",
+			links:    []csvLink{{Name: "Operator", URL: "https://github.com/example/operator?tab=readme"}},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := sourceRepositoryFromLinks(tt.links)
+			if got != tt.expected || ok != (tt.expected != "") {
+				t.Errorf("Expected %q, got %q (%v)", tt.expected, got, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// pdfConverter is an external HTML-to-PDF renderer and the arguments converting one file
+type pdfConverter struct {
+	command string
+	args    func(htmlPath, pdfPath string) []string
+}
+
+// pdfConverters are tried in order; the first one found in PATH renders the PDF. wkhtmltopdf
+// is preferred as it is small and made for the job, headless Chromium is the fallback.
+var pdfConverters = []pdfConverter{
+	{command: "wkhtmltopdf", args: wkhtmltopdfArgs},
+	{command: "chromium", args: chromiumPDFArgs},
+	{command: "chromium-browser", args: chromiumPDFArgs},
+	{command: "google-chrome", args: chromiumPDFArgs},
+	{command: "google-chrome-stable", args: chromiumPDFArgs},
+}
+
+func wkhtmltopdfArgs(htmlPath, pdfPath string) []string {
+	return []string{"--quiet", "--enable-local-file-access", htmlPath, pdfPath}
+}
+
+func chromiumPDFArgs(htmlPath, pdfPath string) []string {
+	return []string{"--headless", "--disable-gpu", "--no-pdf-header-footer", "--print-to-pdf=" + pdfPath, "file://" + htmlPath}
+}
+
+// findPDFConverter returns the first available converter and its path
+func findPDFConverter() (pdfConverter, string, bool) {
+	for _, converter := range pdfConverters {
+		if path, err := exec.LookPath(converter.command); err == nil {
+			return converter, path, true
+		}
+	}
+	return pdfConverter{}, "", false
+}
+
+// writePDFReport renders the HTML report and converts it to a PDF. Without a converter the
+// HTML report is written next to the requested file instead, so the run still produces a
+// report to share.
+func (vtm *VibeToolsManager) writePDFReport(ctx context.Context, target OutputTarget, report *RunReport) error {
+	data, err := vtm.renderHTMLReport(report)
+	if err != nil {
+		return err
+	}
+
+	converter, converterPath, ok := findPDFConverter()
+	if !ok {
+		htmlPath := strings.TrimSuffix(target.Path, filepath.Ext(target.Path)) + ".html"
+		vtm.Logger.Warnf("No HTML-to-PDF converter found (install wkhtmltopdf or Chromium); writing the HTML report to %s instead of %s", htmlPath, target.Path)
+		if err := os.WriteFile(htmlPath, data, 0644); err != nil {
+			return err
+		}
+		if vtm.pdfFallbacks == nil {
+			vtm.pdfFallbacks = make(map[string]string)
+		}
+		vtm.pdfFallbacks[target.Path] = htmlPath
+		return nil
+	}
+
+	// The converters read from a file, so the HTML is staged next to the PDF
+	pdfPath, err := filepath.Abs(target.Path)
+	if err != nil {
+		return err
+	}
+	staged, err := os.CreateTemp(filepath.Dir(pdfPath), ".report-*.html")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(staged.Name())
+	if _, err := staged.Write(data); err != nil {
+		staged.Close()
+		return err
+	}
+	if err := staged.Close(); err != nil {
+		return err
+	}
+
+	vtm.Logger.Debugf("Converting the HTML report to PDF with %s", converterPath)
+	output, err := exec.CommandContext(ctx, converterPath, converter.args(staged.Name(), pdfPath)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed to render the PDF: %w (output: %s)", converter.command, err, strings.TrimSpace(string(output)))
+	}
+	if _, err := os.Stat(pdfPath); err != nil {
+		return fmt.Errorf("%s did not write the PDF: %w", converter.command, err)
+	}
+	return nil
+}
+
+// OutputPath returns the file actually written for an output path: the HTML fallback of a
+// PDF output when no converter was available, otherwise the path itself
+func (vtm *VibeToolsManager) OutputPath(path string) string {
+	if fallback, ok := vtm.pdfFallbacks[path]; ok {
+		return fallback
+	}
+	return path
+}
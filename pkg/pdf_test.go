@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWritePDFReport(t *testing.T) {
+	report := &RunReport{Total: 1, Failed: 1, Repositories: []RepositoryReport{
+		{Repository: "https://github.com/test/repo", Status: RepositoryStatusFailed, Error: "clone failed"},
+	}}
+
+	t.Run("converter available", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the stand-in converter is a shell script")
+		}
+		// A stand-in for wkhtmltopdf that copies the staged HTML to the PDF path
+		bin := t.TempDir()
+		script := "#!/bin/sh\nwhile [ \"$1\" != \"${1#--}\" ]; do shift; done\ncp \"$1\" \"$2\"\n"
+		if err := os.WriteFile(filepath.Join(bin, "wkhtmltopdf"), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+		target := OutputTarget{Path: filepath.Join(t.TempDir(), "report.pdf"), Format: OutputFormatPDF}
+		if err := vtm.writeReport(context.Background(), target, report); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(target.Path)
+		if err != nil {
+			t.Fatalf("Expected the PDF to be written: %v", err)
+		}
+		if !strings.Contains(string(data), "https://github.com/test/repo") {
+			t.Errorf("Expected the converter to receive the HTML report")
+		}
+		if vtm.OutputPath(target.Path) != target.Path {
+			t.Errorf("Expected no fallback, got %s", vtm.OutputPath(target.Path))
+		}
+		if staged, _ := filepath.Glob(filepath.Join(filepath.Dir(target.Path), ".report-*.html")); len(staged) > 0 {
+			t.Errorf("Expected the staged HTML to be removed, found %v", staged)
+		}
+	})
+
+	t.Run("no converter", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+
+		vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+		dir := t.TempDir()
+		target := OutputTarget{Path: filepath.Join(dir, "report.pdf"), Format: OutputFormatPDF}
+		if err := vtm.writeReport(context.Background(), target, report); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		htmlPath := filepath.Join(dir, "report.html")
+		if vtm.OutputPath(target.Path) != htmlPath {
+			t.Errorf("Expected the HTML fallback %s, got %s", htmlPath, vtm.OutputPath(target.Path))
+		}
+		if _, err := os.Stat(htmlPath); err != nil {
+			t.Errorf("Expected the HTML report to be written: %v", err)
+		}
+		if _, err := os.Stat(target.Path); !os.IsNotExist(err) {
+			t.Errorf("Expected no PDF file, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Stages of a repository reported to a ProgressFunc
+const (
+	ProgressStarted   = "started"   // Processing of the repository began
+	ProgressCloned    = "cloned"    // The clone finished; reported again when a corrupt clone is re-cloned
+	ProgressAnalyzed  = "analyzed"  // The notes were generated from the clone
+	ProgressSucceeded = "succeeded" // The repository is done and its notes are in the report
+	ProgressFailed    = "failed"    // The repository failed after any retries; Err tells why
+	ProgressSkipped   = "skipped"   // The repository was skipped before cloning (e.g. too large)
+
+	// Steps of a server release-notes request, reported as each one starts
+	ProgressCloning    = "cloning"
+	ProgressAnalyzing  = "analyzing commits"
+	ProgressFormatting = "formatting"
+)
+
+// ProgressEvent is a milestone of one repository in a ProcessRepositories run. The counts are
+// the run's outcomes so far, including this event's repository once it finished.
+type ProgressEvent struct {
+	Stage      string
+	Repository string
+	Index      int // 1-based position of the repository in the run
+	Total      int
+	Successful int
+	Failed     int
+	Skipped    int
+	Err        error // Set for ProgressFailed
+}
+
+// ProgressFunc receives the progress of a run, e.g. to drive a custom progress display. Calls
+// are serialized, so it needs no locking of its own, but it should return quickly.
+type ProgressFunc func(event ProgressEvent)
+
+// report passes event to the function; it is a no-op on a nil ProgressFunc
+func (f ProgressFunc) report(event ProgressEvent) {
+	if f != nil {
+		f(event)
+	}
+}
+
+// reportStage reports a step of a request for a single repository
+func (f ProgressFunc) reportStage(stage, repo string) {
+	f.report(ProgressEvent{Stage: stage, Repository: repo, Index: 1, Total: 1})
+}
+
+// LogProgress returns a ProgressFunc logging when each repository starts and finishes, with the
+// run's counts so far: the command line's progress output
+func LogProgress(logger *logrus.Logger) ProgressFunc {
+	return func(event ProgressEvent) {
+		switch event.Stage {
+		case ProgressStarted:
+			logger.Infof("Processing repository %d/%d: %s", event.Index, event.Total, event.Repository)
+		case ProgressSucceeded, ProgressFailed, ProgressSkipped:
+			logger.Infof("Repository %d/%d %s: %s (%d succeeded, %d failed, %d skipped so far)",
+				event.Index, event.Total, event.Stage, event.Repository, event.Successful, event.Failed, event.Skipped)
+		}
+	}
+}
+
+// progressTracker counts the outcomes of a run and forwards its milestones to a ProgressFunc
+type progressTracker struct {
+	mu         sync.Mutex
+	fn         ProgressFunc
+	total      int
+	successful int
+	failed     int
+	skipped    int
+}
+
+// progressIndexKey carries the position of the repository being processed in its context
+type progressIndexKey struct{}
+
+// withProgressIndex returns a context for processing the repository at a 1-based position
+func withProgressIndex(ctx context.Context, index int) context.Context {
+	return context.WithValue(ctx, progressIndexKey{}, index)
+}
+
+// newProgressTracker returns a tracker for a run of total repositories, or nil without fn
+func newProgressTracker(fn ProgressFunc, total int) *progressTracker {
+	if fn == nil {
+		return nil
+	}
+	return &progressTracker{fn: fn, total: total}
+}
+
+// report sends a milestone of the repository processed in ctx; it is a no-op on a nil tracker
+func (p *progressTracker) report(ctx context.Context, stage, repo string, err error) {
+	if p == nil {
+		return
+	}
+	index, _ := ctx.Value(progressIndexKey{}).(int)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch stage {
+	case ProgressSucceeded:
+		p.successful++
+	case ProgressFailed:
+		p.failed++
+	case ProgressSkipped:
+		p.skipped++
+	}
+	p.fn(ProgressEvent{
+		Stage:      stage,
+		Repository: repo,
+		Index:      index,
+		Total:      p.total,
+		Successful: p.successful,
+		Failed:     p.failed,
+		Skipped:    p.skipped,
+		Err:        err,
+	})
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sirupsen/logrus"
+)
+
+func TestProcessRepositoriesProgress(t *testing.T) {
+	sourceDir := t.TempDir()
+	source, err := git.PlainInit(sourceDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, source, sourceDir, "a.txt", "Add feature")
+	bundlePath := filepath.Join(t.TempDir(), "example-operator.bundle")
+	writeBundle(t, source, bundlePath, plumbing.Master)
+	missingPath := filepath.Join(t.TempDir(), "missing-operator.bundle")
+
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(t.TempDir(), "notes.txt"), false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+	vtm.Concurrency = 1 // One repository at a time, so the events arrive in a fixed order
+	var events []ProgressEvent
+	vtm.ProgressFunc = func(event ProgressEvent) {
+		events = append(events, event)
+	}
+
+	if _, err := vtm.ProcessRepositories(context.Background(), []string{bundlePath, missingPath}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, event := range events {
+		got = append(got, fmt.Sprintf("%d/%d %s %d/%d/%d", event.Index, event.Total, event.Stage, event.Successful, event.Failed, event.Skipped))
+	}
+	expected := []string{
+		"1/2 started 0/0/0",
+		"1/2 cloned 0/0/0",
+		"1/2 analyzed 0/0/0",
+		"1/2 succeeded 1/0/0",
+		"2/2 started 1/0/0",
+		"2/2 failed 1/1/0",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected events:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if last := events[len(events)-1]; last.Repository != missingPath || last.Err == nil {
+		t.Errorf("Expected the failure event to carry the error, got %+v", last)
+	}
+
+	// Without a ProgressFunc the run reports nothing
+	vtm.ProgressFunc = nil
+	events = nil
+	if _, err := vtm.ProcessRepositories(context.Background(), []string{bundlePath}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no events without a ProgressFunc, got %d", len(events))
+	}
+}
+
+func TestLogProgress(t *testing.T) {
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&logs)
+
+	progress := LogProgress(logger)
+	progress(ProgressEvent{Stage: ProgressStarted, Repository: "https://github.com/example/operator", Index: 2, Total: 5})
+	progress(ProgressEvent{Stage: ProgressCloned, Repository: "https://github.com/example/operator", Index: 2, Total: 5})
+	progress(ProgressEvent{Stage: ProgressFailed, Repository: "https://github.com/example/operator", Index: 2, Total: 5, Successful: 1, Failed: 1})
+
+	output := logs.String()
+	for _, expected := range []string{
+		"Processing repository 2/5: https://github.com/example/operator",
+		"Repository 2/5 failed: https://github.com/example/operator (1 succeeded, 1 failed, 0 skipped so far)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the log to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "cloned") {
+		t.Errorf("Expected intermediate milestones to stay out of the log, got:\n%s", output)
+	}
+}
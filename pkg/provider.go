@@ -0,0 +1,281 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// githubAPIBase is the base URL for the GitHub REST API
+const githubAPIBase = "https://api.github.com"
+
+// providerHTTPClient is used for all provider API calls so a slow API never stalls analysis
+var providerHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// parseGitHubRepo extracts the owner and repository name from a github.com URL
+func parseGitHubRepo(repoURL string) (string, string, bool) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+
+	// SSH form: git@github.com:owner/repo
+	if strings.HasPrefix(trimmed, "git@github.com:") {
+		parts := strings.Split(strings.TrimPrefix(trimmed, "git@github.com:"), "/")
+		if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			return parts[0], parts[1], true
+		}
+		return "", "", false
+	}
+
+	u, err := url.Parse(trimmed)
+	if err != nil || !strings.EqualFold(u.Hostname(), "github.com") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// newGitHubRequest builds a GET request against the GitHub REST API, authenticated with
+// GITHUB_TOKEN when it is set
+func newGitHubRequest(path string) (*http.Request, error) {
+	return newGitHubTokenRequest(path, os.Getenv("GITHUB_TOKEN"))
+}
+
+// newGitHubTokenRequest builds a GET request against the GitHub REST API sent with token, or
+// anonymously when token is empty
+func newGitHubTokenRequest(path, token string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, githubAPIBase+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// fetchGitHubRepoSizeKB returns the repository size reported by the GitHub API in kilobytes
+func fetchGitHubRepoSizeKB(repoURL string) (int64, error) {
+	owner, name, ok := parseGitHubRepo(repoURL)
+	if !ok {
+		return 0, fmt.Errorf("not a GitHub repository: %s", repoURL)
+	}
+
+	var repoInfo struct {
+		Size int64 `json:"size"`
+	}
+	if err := getGitHubJSON(fmt.Sprintf("/repos/%s/%s", owner, name), os.Getenv("GITHUB_TOKEN"), &repoInfo); err != nil {
+		return 0, err
+	}
+	return repoInfo.Size, nil
+}
+
+// githubBranchesPerPage is the page size of branch listings, the maximum the API allows
+const githubBranchesPerPage = 100
+
+// fetchGitHubBranches lists the branches of a GitHub repository and its default branch through
+// the REST API with token (anonymously when empty), requesting pages until one comes back short
+func fetchGitHubBranches(owner, name, token string) ([]string, string, error) {
+	var repoInfo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := getGitHubJSON(fmt.Sprintf("/repos/%s/%s", owner, name), token, &repoInfo); err != nil {
+		return nil, "", err
+	}
+
+	var branches []string
+	for page := 1; ; page++ {
+		var entries []struct {
+			Name string `json:"name"`
+		}
+		if err := getGitHubJSON(fmt.Sprintf("/repos/%s/%s/branches?per_page=%d&page=%d", owner, name, githubBranchesPerPage, page), token, &entries); err != nil {
+			return nil, "", err
+		}
+		for _, entry := range entries {
+			branches = append(branches, entry.Name)
+		}
+		if len(entries) < githubBranchesPerPage {
+			return branches, repoInfo.DefaultBranch, nil
+		}
+	}
+}
+
+// getGitHubJSON decodes the response of a GitHub REST API GET request sent with token into v
+func getGitHubJSON(path, token string, v interface{}) error {
+	req, err := newGitHubTokenRequest(path, token)
+	if err != nil {
+		return err
+	}
+
+	resp, err := providerHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode GitHub API response: %w", err)
+	}
+	return nil
+}
+
+// openPullRequestCacheTTL is how long an open pull request count is reused before asking the API again
+const openPullRequestCacheTTL = 10 * time.Minute
+
+// openPullRequestCache remembers recent counts per repository URL to stay clear of API rate limits
+var openPullRequestCache = struct {
+	sync.Mutex
+	entries map[string]openPullRequestEntry
+}{entries: make(map[string]openPullRequestEntry)}
+
+// openPullRequestEntry is a cached open pull request count
+type openPullRequestEntry struct {
+	count     int
+	fetchedAt time.Time
+}
+
+// lastPagePattern extracts the page number of the rel="last" link in a GitHub Link header
+var lastPagePattern = regexp.MustCompile(`[?&]page=(\d+)[^>]*>;\s*rel="last"`)
+
+// FetchOpenPullRequestCount returns the number of open pull requests (GitHub) or merge requests
+// (GitLab) for the repository. It returns false when the host is unsupported, no token is
+// configured for it, or the API call fails, so callers can simply omit the count.
+func FetchOpenPullRequestCount(repoURL string) (int, bool) {
+	openPullRequestCache.Lock()
+	entry, ok := openPullRequestCache.entries[repoURL]
+	openPullRequestCache.Unlock()
+	if ok && time.Since(entry.fetchedAt) < openPullRequestCacheTTL {
+		return entry.count, true
+	}
+
+	var count int
+	var err error
+	if owner, name, ok := parseGitHubRepo(repoURL); ok {
+		if os.Getenv("GITHUB_TOKEN") == "" {
+			return 0, false
+		}
+		count, err = fetchGitHubOpenPullRequests(owner, name)
+	} else if host, project, ok := parseGitLabRepo(repoURL); ok {
+		if os.Getenv("GITLAB_TOKEN") == "" || !gitLabTokenHost(host) {
+			return 0, false
+		}
+		count, err = fetchGitLabOpenMergeRequests(host, project)
+	} else {
+		return 0, false
+	}
+	if err != nil {
+		return 0, false
+	}
+
+	openPullRequestCache.Lock()
+	openPullRequestCache.entries[repoURL] = openPullRequestEntry{count: count, fetchedAt: time.Now()}
+	openPullRequestCache.Unlock()
+	return count, true
+}
+
+// fetchGitHubOpenPullRequests counts open pull requests by requesting one per page and
+// reading the number of the last page from the Link header
+func fetchGitHubOpenPullRequests(owner, name string) (int, error) {
+	req, err := newGitHubRequest(fmt.Sprintf("/repos/%s/%s/pulls?state=open&per_page=1", owner, name))
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := providerHTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GitHub API returned HTTP %d", resp.StatusCode)
+	}
+
+	if match := lastPagePattern.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		return strconv.Atoi(match[1])
+	}
+
+	// No pagination means everything fit on the single page
+	var pulls []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&pulls); err != nil {
+		return 0, fmt.Errorf("failed to decode GitHub API response: %w", err)
+	}
+	return len(pulls), nil
+}
+
+// parseGitLabRepo extracts the host and project path from a GitLab URL. Self-hosted instances
+// are recognised by "gitlab" in the host name.
+func parseGitLabRepo(repoURL string) (string, string, bool) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+
+	u, err := url.Parse(trimmed)
+	if err != nil || u.Host == "" || !strings.Contains(strings.ToLower(u.Hostname()), "gitlab") {
+		return "", "", false
+	}
+	project := strings.Trim(u.Path, "/")
+	if !strings.Contains(project, "/") {
+		return "", "", false
+	}
+	return u.Host, project, true
+}
+
+// gitLabTokenHost reports whether GITLAB_TOKEN may be sent to host: gitlab.com or one of the
+// comma-separated self-hosted instances in GITLAB_HOSTS, matched exactly
+func gitLabTokenHost(host string) bool {
+	hosts := append([]string{"gitlab.com"}, strings.Split(os.Getenv("GITLAB_HOSTS"), ",")...)
+	for _, allowed := range hosts {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchGitLabOpenMergeRequests reads the open merge request count from the X-Total header
+func fetchGitLabOpenMergeRequests(host, project string) (int, error) {
+	if !gitLabTokenHost(host) {
+		return 0, fmt.Errorf("GitLab host %s is not listed in GITLAB_HOSTS", host)
+	}
+	apiURL := fmt.Sprintf("https://%s/api/v4/projects/%s/merge_requests?state=opened&per_page=1", host, url.PathEscape(project))
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", os.Getenv("GITLAB_TOKEN"))
+
+	resp, err := providerHTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query GitLab API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GitLab API returned HTTP %d", resp.StatusCode)
+	}
+
+	total := resp.Header.Get("X-Total")
+	if total == "" {
+		return 0, fmt.Errorf("GitLab API response has no X-Total header")
+	}
+	return strconv.Atoi(total)
+}
+
+// openPullRequestCount returns the open pull request count for use in release notes, or nil when unavailable
+func openPullRequestCount(repoURL string) *int {
+	if count, ok := FetchOpenPullRequestCount(repoURL); ok {
+		return &count
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseGitHubRepo(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedOwner string
+		expectedName  string
+		expectedOK    bool
+	}{
+		{
+			name:          "https URL with .git suffix",
+			url:           "https://github.com/openshift/compliance-operator.git",
+			expectedOwner: "openshift",
+			expectedName:  "compliance-operator",
+			expectedOK:    true,
+		},
+		{
+			name:          "SSH URL",
+			url:           "git@github.com:openshift/compliance-operator.git",
+			expectedOwner: "openshift",
+			expectedName:  "compliance-operator",
+			expectedOK:    true,
+		},
+		{
+			name:       "non-GitHub host",
+			url:        "https://gitlab.com/group/project",
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, name, ok := parseGitHubRepo(tt.url)
+			if ok != tt.expectedOK || owner != tt.expectedOwner || name != tt.expectedName {
+				t.Errorf("Expected (%s, %s, %v), got (%s, %s, %v)", tt.expectedOwner, tt.expectedName, tt.expectedOK, owner, name, ok)
+			}
+		})
+	}
+}
+
+func TestParseGitLabRepo(t *testing.T) {
+	tests := []struct {
+		name            string
+		url             string
+		expectedHost    string
+		expectedProject string
+		expectedOK      bool
+	}{
+		{
+			name:            "gitlab.com project",
+			url:             "https://gitlab.com/group/project.git",
+			expectedHost:    "gitlab.com",
+			expectedProject: "group/project",
+			expectedOK:      true,
+		},
+		{
+			name:            "self-hosted nested group",
+			url:             "https://gitlab.cee.redhat.com/team/operators/my-operator",
+			expectedHost:    "gitlab.cee.redhat.com",
+			expectedProject: "team/operators/my-operator",
+			expectedOK:      true,
+		},
+		{
+			name:       "GitHub URL",
+			url:        "https://github.com/openshift/compliance-operator",
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, project, ok := parseGitLabRepo(tt.url)
+			if ok != tt.expectedOK || host != tt.expectedHost || project != tt.expectedProject {
+				t.Errorf("Expected (%s, %s, %v), got (%s, %s, %v)", tt.expectedHost, tt.expectedProject, tt.expectedOK, host, project, ok)
+			}
+		})
+	}
+}
+
+func TestFetchOpenPullRequestCountWithoutToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITLAB_TOKEN", "")
+
+	for _, url := range []string{
+		"https://github.com/openshift/compliance-operator",
+		"https://gitlab.com/group/project",
+		"https://bitbucket.org/team/repo",
+	} {
+		if _, ok := FetchOpenPullRequestCount(url); ok {
+			t.Errorf("Expected no count for %s without a token", url)
+		}
+	}
+}
+
+func TestGitLabTokenHost(t *testing.T) {
+	t.Setenv("GITLAB_HOSTS", "gitlab.example.com, git.internal.example:8443")
+	for host, expected := range map[string]bool{
+		"gitlab.com":                 true,
+		"GitLab.com":                 true,
+		"gitlab.example.com":         true,
+		"git.internal.example:8443":  true,
+		"gitlab.evil.example":        false,
+		"gitlab.com.evil.example":    false,
+		"gitlab.example.com.evil.io": false,
+	} {
+		if got := gitLabTokenHost(host); got != expected {
+			t.Errorf("gitLabTokenHost(%q) = %v, expected %v", host, got, expected)
+		}
+	}
+
+	t.Setenv("GITLAB_TOKEN", "gl-token")
+	if _, ok := FetchOpenPullRequestCount("https://gitlab.evil.example/group/project"); ok {
+		t.Errorf("Expected no count for a GitLab host outside GITLAB_HOSTS")
+	}
+}
+
+func TestLastPagePattern(t *testing.T) {
+	link := `<https://api.github.com/repositories/1/pulls?state=open&per_page=1&page=2>; rel="next", <https://api.github.com/repositories/1/pulls?state=open&per_page=1&page=42>; rel="last"`
+	match := lastPagePattern.FindStringSubmatch(link)
+	if match == nil || match[1] != "42" {
+		t.Errorf("Expected last page 42, got %v", match)
+	}
+}
+
+// githubAPIRewrite sends the requests of the provider client to a test server instead of the
+// GitHub API
+type githubAPIRewrite struct {
+	target *url.URL
+}
+
+func (rt githubAPIRewrite) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// fakeGitHubAPI serves the provider's GitHub API calls with handler for the rest of the test
+func fakeGitHubAPI(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	original := providerHTTPClient
+	providerHTTPClient = &http.Client{Transport: githubAPIRewrite{target: target}}
+	t.Cleanup(func() { providerHTTPClient = original })
+}
+
+func TestFetchGitHubBranches(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "server-token")
+	var names []string
+	for i := 0; i < 150; i++ {
+		names = append(names, fmt.Sprintf("feature-%03d", i))
+	}
+	names = append(names, "main", "release-4.20", "release-4.21", "devel")
+	var anonymous int
+	fakeGitHubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			anonymous++
+		}
+		if r.Header.Get("Authorization") != "Bearer gh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/example/operator":
+			fmt.Fprint(w, `{"default_branch": "devel"}`)
+		case "/repos/example/operator/branches":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			start := min((page-1)*githubBranchesPerPage, len(names))
+			end := min(start+githubBranchesPerPage, len(names))
+			var entries []map[string]string
+			for _, name := range names[start:end] {
+				entries = append(entries, map[string]string{"name": name})
+			}
+			json.NewEncoder(w).Encode(entries)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	branches, defaultBranch, err := s.fetchBranches(context.Background(), "https://github.com/example/operator", "gh-token")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if defaultBranch != "devel" || len(branches) != len(names) {
+		t.Fatalf("Expected %d branches with default devel, got %d (%s)", len(names), len(branches), defaultBranch)
+	}
+	if got := strings.Join(branches[:5], ","); got != "devel,main,release-4.21,release-4.20,feature-000" {
+		t.Errorf("Expected the usual branch order, got %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(s.WorkDir, "cache")); !os.IsNotExist(err) {
+		t.Errorf("Expected no clone when the API lists the branches")
+	}
+
+	if _, _, err := fetchGitHubBranches("example", "missing", "gh-token"); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Expected the API error so callers fall back to an ls-remote, got %v", err)
+	}
+
+	// Anonymous requests never borrow the server's GITHUB_TOKEN
+	if _, _, err := fetchGitHubBranches("example", "operator", ""); err == nil || anonymous == 0 {
+		t.Errorf("Expected an anonymous API call without the server's token, got %v", err)
+	}
+}
+
+func TestFetchGitHubRepoSizeKB(t *testing.T) {
+	fakeGitHubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/example/operator" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"size": 4096}`)
+	})
+
+	size, err := fetchGitHubRepoSizeKB("https://github.com/example/operator.git")
+	if err != nil || size != 4096 {
+		t.Errorf("Expected 4096 KB, got %d (%v)", size, err)
+	}
+	if _, err := fetchGitHubRepoSizeKB("https://gitlab.com/group/project"); err == nil {
+		t.Errorf("Expected an error for a non-GitHub repository")
+	}
+}
+
+func TestCheckRepoSize(t *testing.T) {
+	fakeGitHubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/example/large":
+			fmt.Fprint(w, `{"size": 3072}`)
+		case "/repos/example/exact":
+			fmt.Fprint(w, `{"size": 2048}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.MaxRepoSizeMB = 2
+
+	if reason := vtm.checkRepoSize("https://github.com/example/large"); !strings.Contains(reason, "3 MB exceeds limit of 2 MB") {
+		t.Errorf("Expected a repository above the limit to be skipped, got %q", reason)
+	}
+	if reason := vtm.checkRepoSize("https://github.com/example/exact"); reason != "" {
+		t.Errorf("Expected a repository at the limit to be cloned, got %q", reason)
+	}
+	if reason := vtm.checkRepoSize("https://github.com/example/missing"); reason != "" {
+		t.Errorf("Expected a repository of unknown size to be cloned, got %q", reason)
+	}
+	if reason := vtm.checkRepoSize("https://gitlab.com/group/project"); reason != "" {
+		t.Errorf("Expected a non-GitHub repository to be cloned, got %q", reason)
+	}
+
+	vtm.MaxRepoSizeMB = 0
+	if reason := vtm.checkRepoSize("https://github.com/example/large"); reason != "" {
+		t.Errorf("Expected no check when the limit is disabled, got %q", reason)
+	}
+}
@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// URLStatus is the outcome of a repository reachability probe
+type URLStatus string
+
+const (
+	URLReachable    URLStatus = "reachable"
+	URLUnreachable  URLStatus = "unreachable"
+	URLRedirected   URLStatus = "redirected"
+	URLAuthRequired URLStatus = "auth-required"
+)
+
+// URLCheckResult describes the reachability of a single repository URL
+type URLCheckResult struct {
+	URL      string    `json:"url"`
+	Status   URLStatus `json:"status"`
+	Location string    `json:"location,omitempty"` // Redirect target for redirected URLs
+	Detail   string    `json:"detail,omitempty"`   // HTTP status or error for unreachable URLs
+}
+
+// reachabilityHTTPClient does not follow redirects so moved repositories can be reported
+var reachabilityHTTPClient = &http.Client{
+	Timeout: 15 * time.Second,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// CheckRepositoryURL probes a repository without cloning it. HTTP(S) URLs are checked with
+// the smart-protocol info/refs request, other transports with an ls-remote.
+func CheckRepositoryURL(repoURL string) URLCheckResult {
+	if strings.HasPrefix(repoURL, "http://") || strings.HasPrefix(repoURL, "https://") {
+		return checkHTTPRepository(repoURL)
+	}
+	return checkRemoteRepository(repoURL)
+}
+
+// CheckRepositoryURLs probes all URLs with at most concurrency requests in flight and
+// returns the results in the order of the input
+func CheckRepositoryURLs(repoURLs []string, concurrency int) []URLCheckResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]URLCheckResult, len(repoURLs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, repoURL := range repoURLs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, repoURL string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = CheckRepositoryURL(repoURL)
+		}(i, repoURL)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkHTTPRepository requests the info/refs advertisement that a clone starts with
+func checkHTTPRepository(repoURL string) URLCheckResult {
+	result := URLCheckResult{URL: repoURL}
+
+	infoRefs := strings.TrimSuffix(repoURL, "/") + "/info/refs?service=git-upload-pack"
+	resp, err := reachabilityHTTPClient.Get(infoRefs)
+	if err != nil {
+		result.Status = URLUnreachable
+		result.Detail = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		result.Status = URLReachable
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		result.Status = URLRedirected
+		result.Location = redirectedRepositoryURL(resp)
+		result.Detail = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		result.Status = URLAuthRequired
+		result.Detail = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	default:
+		result.Status = URLUnreachable
+		result.Detail = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return result
+}
+
+// redirectedRepositoryURL turns the Location of a redirected info/refs request back
+// into a repository URL
+func redirectedRepositoryURL(resp *http.Response) string {
+	location, err := resp.Location()
+	if err != nil {
+		return ""
+	}
+	location.RawQuery = ""
+	location.Path = strings.TrimSuffix(location.Path, "/info/refs")
+	return location.String()
+}
+
+// checkRemoteRepository lists the remote references through go-git, which handles SSH
+// and git:// URLs the same way a clone would
+func checkRemoteRepository(repoURL string) URLCheckResult {
+	result := URLCheckResult{URL: repoURL}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repoURL},
+	})
+	if _, err := remote.List(&git.ListOptions{}); err != nil {
+		if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+			result.Status = URLAuthRequired
+		} else {
+			result.Status = URLUnreachable
+		}
+		result.Detail = err.Error()
+		return result
+	}
+
+	result.Status = URLReachable
+	return result
+}
+
+// String returns a one-line description such as "redirected  https://a -> https://b"
+func (r URLCheckResult) String() string {
+	line := fmt.Sprintf("%-13s %s", r.Status, r.URL)
+	if r.Location != "" {
+		line += " -> " + r.Location
+	}
+	if r.Detail != "" && r.Status != URLRedirected {
+		line += " (" + r.Detail + ")"
+	}
+	return line
+}
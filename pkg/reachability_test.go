@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestCheckHTTPRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/org/live/info/refs":
+			w.WriteHeader(http.StatusOK)
+		case "/org/moved/info/refs":
+			http.Redirect(w, r, "/org/renamed/info/refs?service=git-upload-pack", http.StatusMovedPermanently)
+		case "/org/private/info/refs":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name             string
+		path             string
+		expectedStatus   URLStatus
+		expectedLocation string
+	}{
+		{name: "reachable", path: "/org/live", expectedStatus: URLReachable},
+		{name: "redirected", path: "/org/moved", expectedStatus: URLRedirected, expectedLocation: server.URL + "/org/renamed"},
+		{name: "auth required", path: "/org/private", expectedStatus: URLAuthRequired},
+		{name: "not found", path: "/org/missing", expectedStatus: URLUnreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CheckRepositoryURL(server.URL + tt.path)
+			if result.Status != tt.expectedStatus {
+				t.Errorf("Expected status %s, got %s (%s)", tt.expectedStatus, result.Status, result.Detail)
+			}
+			if result.Location != tt.expectedLocation {
+				t.Errorf("Expected location %q, got %q", tt.expectedLocation, result.Location)
+			}
+		})
+	}
+}
+
+func TestCheckRepositoryURLsLocal(t *testing.T) {
+	dir := t.TempDir()
+	repoDir := filepath.Join(dir, "repo")
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, repoDir, "README.md", "initial commit")
+
+	urls := []string{repoDir, filepath.Join(dir, "missing")}
+	results := CheckRepositoryURLs(urls, 2)
+	if len(results) != len(urls) {
+		t.Fatalf("Expected %d results, got %d", len(urls), len(results))
+	}
+	if results[0].URL != urls[0] || results[0].Status != URLReachable {
+		t.Errorf("Expected %s to be reachable, got %s (%s)", urls[0], results[0].Status, results[0].Detail)
+	}
+	if results[1].URL != urls[1] || results[1].Status != URLUnreachable {
+		t.Errorf("Expected %s to be unreachable, got %s", urls[1], results[1].Status)
+	}
+}
@@ -0,0 +1,196 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// repositoryActivityWorkers bounds the ls-remote requests in flight when listing activity
+const repositoryActivityWorkers = 8
+
+// cachedActivity is the last activity found for a repository. The commit date of a tip never
+// changes, so once the tip is known only a new ls-remote is needed to revalidate it.
+type cachedActivity struct {
+	branch       plumbing.ReferenceName // Default branch HEAD points at
+	tip          plumbing.Hash
+	commit       CommitInfo // Tip commit, listed in the activity feed
+	lastActivity time.Time
+	checkedAt    time.Time
+}
+
+// repositoryActivity returns the last activity of each repository, looking them up with at
+// most repositoryActivityWorkers requests in flight. Repositories whose lookup fails are
+// left out so one unreachable remote doesn't fail the whole list.
+func (s *Server) repositoryActivity(ctx context.Context, repoURLs []string) map[string]time.Time {
+	activity := make(map[string]time.Time, len(repoURLs))
+	var mu sync.Mutex
+	sem := make(chan struct{}, repositoryActivityWorkers)
+	var wg sync.WaitGroup
+	for _, repoURL := range repoURLs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(repoURL string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			when, err := s.lastActivity(ctx, repoURL)
+			if err != nil {
+				s.Logger.Warnf("Failed to get last activity of %s: %v", repoURL, err)
+				return
+			}
+			mu.Lock()
+			activity[repoURL] = when
+			mu.Unlock()
+		}(repoURL)
+	}
+	wg.Wait()
+	return activity
+}
+
+// lastActivity returns the commit date of the default branch tip. The tip is listed with an
+// ls-remote and only fetched (one commit deep, into memory) when it moved since the last
+// lookup; results are reused for the server's cache duration.
+func (s *Server) lastActivity(ctx context.Context, repoURL string) (when time.Time, err error) {
+	ctx, span := StartSpan(ctx, "git.last_activity", attribute.String("repository", repoURL))
+	defer func() { EndSpan(span, err) }()
+
+	s.mu.Lock()
+	cached, ok := s.activityCache[repoURL]
+	s.mu.Unlock()
+	fresh := ok && time.Since(cached.checkedAt) < s.cacheDuration
+	span.SetAttributes(attribute.Bool("cache.hit", fresh))
+	if fresh {
+		return cached.lastActivity, nil
+	}
+
+	branch, tip, err := defaultBranchTip(ctx, repoURL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok || cached.tip != tip {
+		commit, err := tipCommit(ctx, repoURL, branch)
+		if err != nil {
+			return time.Time{}, err
+		}
+		cached.lastActivity = commit.Committer.When
+		cached.commit = CommitInfo{
+			Hash:     commit.Hash.String()[:8],
+			Message:  strings.TrimSpace(commit.Message),
+			Author:   commit.Author.Name,
+			Date:     s.DateMode.commitDate(commit),
+			fullHash: commit.Hash.String(),
+		}
+		cached.tip = tip
+	}
+	cached.branch = branch
+	cached.checkedAt = time.Now()
+
+	s.mu.Lock()
+	if s.activityCache == nil {
+		s.activityCache = make(map[string]cachedActivity)
+	}
+	s.activityCache[repoURL] = cached
+	s.mu.Unlock()
+	return cached.lastActivity, nil
+}
+
+// defaultBranch returns the default branch found by the last activity lookup of a repository,
+// or "" when it was not looked up
+func (s *Server) defaultBranch(repoURL string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activityCache[repoURL].branch.Short()
+}
+
+// latestCommit returns the default branch tip found by the last activity lookup of a
+// repository, or a zero CommitInfo when it was not looked up
+func (s *Server) latestCommit(repoURL string) CommitInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activityCache[repoURL].commit
+}
+
+// defaultBranchTip lists the remote references and peels HEAD to the default branch and the
+// commit it points at. Servers that don't advertise HEAD as a symbolic reference get the
+// branch whose tip matches it, preferring main and master.
+func defaultBranchTip(ctx context.Context, repoURL string) (plumbing.ReferenceName, plumbing.Hash, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		if IsAuthError(err) {
+			return "", plumbing.ZeroHash, NewAuthRequiredError(repoURL, err)
+		}
+		return "", plumbing.ZeroHash, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	branches := make(map[plumbing.ReferenceName]plumbing.Hash)
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		switch {
+		case ref.Name() == plumbing.HEAD:
+			head = ref
+		case ref.Name().IsBranch():
+			branches[ref.Name()] = ref.Hash()
+		}
+	}
+
+	if head != nil && head.Type() == plumbing.SymbolicReference {
+		if tip, ok := branches[head.Target()]; ok {
+			return head.Target(), tip, nil
+		}
+	}
+	for _, name := range []plumbing.ReferenceName{plumbing.Main, plumbing.Master} {
+		if tip, ok := branches[name]; ok && (head == nil || head.Hash() == tip) {
+			return name, tip, nil
+		}
+	}
+	if head != nil {
+		for name, tip := range branches {
+			if tip == head.Hash() {
+				return name, tip, nil
+			}
+		}
+	}
+	return "", plumbing.ZeroHash, fmt.Errorf("no default branch found in %s", repoURL)
+}
+
+// tipCommit fetches only the tip commit of branch into memory and returns it
+func tipCommit(ctx context.Context, repoURL string, branch plumbing.ReferenceName) (*object.Commit, error) {
+	repo, err := git.InitWithOptions(memory.NewStorage(), nil, git.InitOptions{DefaultBranch: branch})
+	if err != nil {
+		return nil, err
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", branch, branch))},
+		Depth:    1,
+		Tags:     git.NoTags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", branch.Short(), err)
+	}
+
+	ref, err := repo.Reference(branch, false)
+	if err != nil {
+		return nil, err
+	}
+	return repo.CommitObject(ref.Hash())
+}
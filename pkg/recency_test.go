@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestLastActivity(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	first := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	commitFile(t, repo, dir, "first.txt", "first.txt", withDate(first))
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	when, err := s.lastActivity(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !when.Equal(first) {
+		t.Errorf("Expected last activity %v, got %v", first, when)
+	}
+
+	second := first.Add(48 * time.Hour)
+	commitFile(t, repo, dir, "second.txt", "second.txt", withDate(second))
+
+	// Within the cache duration the previous lookup is reused
+	if when, _ := s.lastActivity(context.Background(), dir); !when.Equal(first) {
+		t.Errorf("Expected the cached activity %v, got %v", first, when)
+	}
+
+	s.cacheDuration = 0
+	if when, _ := s.lastActivity(context.Background(), dir); !when.Equal(second) {
+		t.Errorf("Expected the new tip's activity %v, got %v", second, when)
+	}
+
+	activity := s.repositoryActivity(context.Background(), []string{dir, filepath.Join(t.TempDir(), "missing")})
+	if len(activity) != 1 || !activity[dir].Equal(second) {
+		t.Errorf("Expected only the reachable repository's activity, got %v", activity)
+	}
+}
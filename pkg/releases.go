@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ReleaseTag is the newest release tag of a repository
+type ReleaseTag struct {
+	Name    string    `json:"name"`
+	Version string    `json:"version"`
+	Date    time.Time `json:"date"` // Tagger date of an annotated tag, else the tagged commit's date
+}
+
+// semver is a parsed release version; pre-releases are not releases and never parse
+type semver struct {
+	major, minor, patch int
+}
+
+// parseReleaseVersion parses a tag name such as "v1.2.3", "1.2.3" or "1.2.3+build.4". Tags
+// with a pre-release suffix (e.g. "v1.3.0-rc.1") or any other name are not releases.
+func parseReleaseVersion(name string) (semver, bool) {
+	version := strings.TrimPrefix(name, "v")
+	version, _, _ = strings.Cut(version, "+")
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return semver{}, false
+		}
+		numbers[i] = n
+	}
+	return semver{major: numbers[0], minor: numbers[1], patch: numbers[2]}, true
+}
+
+// less reports whether v is an older release than other
+func (v semver) less(other semver) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	return v.patch < other.patch
+}
+
+// tagDate returns the tagger date of an annotated tag, else the date of the tagged commit
+func tagDate(repo *git.Repository, ref *plumbing.Reference) (time.Time, error) {
+	if tag, err := repo.TagObject(ref.Hash()); err == nil {
+		return tag.Tagger.When, nil
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("tag %s does not point to a commit: %w", ref.Name().Short(), err)
+	}
+	return commit.Author.When, nil
+}
+
+// latestReleaseTag returns the repository's highest semver release tag dated no later than
+// until, or nil when it has none. Tags that cannot be dated are skipped; when some of them point
+// outside the history of a shallow clone, the tag found so far is returned with an error saying
+// the result may be missing releases.
+func latestReleaseTag(repo *git.Repository, until time.Time) (*ReleaseTag, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var latest *ReleaseTag
+	var latestVersion semver
+	truncated := 0
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		version, ok := parseReleaseVersion(ref.Name().Short())
+		if !ok || (latest != nil && !latestVersion.less(version)) {
+			return nil
+		}
+		date, err := tagDate(repo, ref)
+		if outsideShallowHistory(repo, err) {
+			truncated++
+			return nil
+		}
+		if err != nil || date.After(until) {
+			return nil
+		}
+		latest = &ReleaseTag{
+			Name:    ref.Name().Short(),
+			Version: fmt.Sprintf("%d.%d.%d", version.major, version.minor, version.patch),
+			Date:    date,
+		}
+		latestVersion = version
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if truncated > 0 {
+		return latest, fmt.Errorf("%d release tags point outside the history of the shallow clone, so the latest release may be missing (raise --clone-depth or set it to 0)", truncated)
+	}
+	return latest, nil
+}
+
+// releaseAgeDays is the number of whole days between the release and the end of the window
+func releaseAgeDays(format *ReleaseNoteFormat) int {
+	return int(format.AnalysisEnd.Sub(format.LatestRelease.Date).Hours() / 24)
+}
+
+// isStaleRelease reports whether the newest release tag of the notes is older than
+// StaleReleaseDays at the end of the window. Repositories without release tags are never stale.
+func (vtm *VibeToolsManager) isStaleRelease(format *ReleaseNoteFormat) bool {
+	if vtm.StaleReleaseDays <= 0 || format == nil || format.LatestRelease == nil {
+		return false
+	}
+	return releaseAgeDays(format) > vtm.StaleReleaseDays
+}
+
+// staleReleases returns the repositories flagged for a stale release, in report order
+func (r *RunReport) staleReleases() []RepositoryReport {
+	var stale []RepositoryReport
+	for _, repo := range r.Repositories {
+		if repo.StaleRelease {
+			stale = append(stale, repo)
+		}
+	}
+	return stale
+}
+
+// staleReleaseAge describes a stale repository's newest release, e.g. "v1.2.0 from 2024-01-15, 400 days ago"
+func staleReleaseAge(repo RepositoryReport) string {
+	release := repo.ReleaseNotes.LatestRelease
+	return fmt.Sprintf("%s from %s, %d days ago", release.Name, release.Date.Format("2006-01-02"), releaseAgeDays(repo.ReleaseNotes))
+}
+
+// formatTextStaleReleases lists the repositories whose newest release is older than days
+func (vtm *VibeToolsManager) formatTextStaleReleases(repos []RepositoryReport, days int) string {
+	if len(repos) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n=== STALE RELEASES (NO RELEASE IN %d DAYS) ===\n", days))
+	for _, repo := range repos {
+		output.WriteString(fmt.Sprintf("- %s (%s) %s\n", vtm.extractRepoName(repo.Repository), staleReleaseAge(repo), repo.Repository))
+	}
+	return output.String()
+}
+
+// formatHTMLStaleReleases lists the stale-release repositories in a card of the HTML report
+func (vtm *VibeToolsManager) formatHTMLStaleReleases(repos []RepositoryReport, days int) string {
+	if len(repos) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	for _, repo := range repos {
+		list.WriteString(fmt.Sprintf(`
+                    <li><strong>%s</strong> (%s) <span class="repo-url">%s</span></li>`,
+			html.EscapeString(vtm.extractRepoName(repo.Repository)), html.EscapeString(staleReleaseAge(repo)), html.EscapeString(repo.Repository)))
+	}
+	return fmt.Sprintf(`
+        <div class="repo-card stale-releases">
+            <div class="repo-header">
+                <h2>🏷️ Stale Releases</h2>
+                <div class="repo-url">No release tag in the last %d days</div>
+            </div>
+            <div class="repo-body">
+                <ul>%s
+                </ul>
+            </div>
+        </div>
+`, days, list.String())
+}
+
+// writeMarkdownStaleReleases lists the stale-release repositories in the Markdown report
+func (vtm *VibeToolsManager) writeMarkdownStaleReleases(output *strings.Builder, repos []RepositoryReport, days int) {
+	if len(repos) == 0 {
+		return
+	}
+	output.WriteString("## Stale Releases\n\n")
+	output.WriteString(fmt.Sprintf("No release tag in the last %d days:\n\n", days))
+	for _, repo := range repos {
+		output.WriteString(fmt.Sprintf("- %s (%s): %s\n", markdownEscape(vtm.extractRepoName(repo.Repository)), staleReleaseAge(repo), repo.Repository))
+	}
+	output.WriteString("\n")
+}
@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseReleaseVersion(t *testing.T) {
+	valid := map[string]semver{
+		"v1.2.3":       {1, 2, 3},
+		"0.10.0":       {0, 10, 0},
+		"v2.0.1+build": {2, 0, 1},
+	}
+	for name, expected := range valid {
+		if version, ok := parseReleaseVersion(name); !ok || version != expected {
+			t.Errorf("Expected %s to parse as %+v, got %+v (%v)", name, expected, version, ok)
+		}
+	}
+	for _, name := range []string{"v1.3.0-rc.1", "v1.2", "release-1.2.3", "v01.2.3", "latest"} {
+		if _, ok := parseReleaseVersion(name); ok {
+			t.Errorf("Expected %s not to be a release", name)
+		}
+	}
+}
+
+// releaseRepository creates a repository with one commit dated when
+func releaseRepository(t *testing.T, when time.Time) (string, *git.Repository) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "a.txt", withMessage("Add a.txt"), withDate(when))
+	return dir, repo
+}
+
+// tagHead tags the repository's HEAD, as an annotated tag dated when unless when is zero
+func tagHead(t *testing.T, repo *git.Repository, name string, when time.Time) {
+	t.Helper()
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	var opts *git.CreateTagOptions
+	if !when.IsZero() {
+		opts = &git.CreateTagOptions{
+			Tagger:  &object.Signature{Name: "Releaser", Email: "release@example.com", When: when},
+			Message: "Release " + name,
+		}
+	}
+	if _, err := repo.CreateTag(name, head.Hash(), opts); err != nil {
+		t.Fatalf("Failed to tag %s: %v", name, err)
+	}
+}
+
+func TestLatestReleaseTag(t *testing.T) {
+	now := time.Now()
+	_, repo := releaseRepository(t, now.AddDate(0, 0, -300))
+	tagHead(t, repo, "v1.2.0", now.AddDate(0, 0, -200))
+	tagHead(t, repo, "v1.10.0", now.AddDate(0, 0, -100))
+	tagHead(t, repo, "v2.0.0-rc.1", now.AddDate(0, 0, -1))
+
+	release, err := latestReleaseTag(repo, now.AddDate(0, 0, -150))
+	if err != nil || release == nil || release.Name != "v1.2.0" {
+		t.Errorf("Expected v1.2.0 as of 150 days ago, got %+v (%v)", release, err)
+	}
+
+	tagHead(t, repo, "v1.11.0", time.Time{})
+	release, err = latestReleaseTag(repo, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if release == nil || release.Name != "v1.11.0" || release.Version != "1.11.0" {
+		t.Fatalf("Expected the highest release v1.11.0, got %+v", release)
+	}
+	if !release.Date.Equal(now.AddDate(0, 0, -300).Truncate(time.Second)) {
+		t.Errorf("Expected a lightweight tag to carry its commit's date, got %s", release.Date)
+	}
+
+	_, untagged := releaseRepository(t, now)
+	if release, err := latestReleaseTag(untagged, now); err != nil || release != nil {
+		t.Errorf("Expected no release without tags, got %+v (%v)", release, err)
+	}
+}
+
+func TestProcessRepositoriesStaleReleases(t *testing.T) {
+	now := time.Now()
+	staleDir, stale := releaseRepository(t, now.AddDate(0, 0, -400))
+	tagHead(t, stale, "v0.9.0", now.AddDate(0, 0, -400))
+	freshDir, fresh := releaseRepository(t, now.AddDate(0, 0, -10))
+	tagHead(t, fresh, "v3.1.4", now.AddDate(0, 0, -10))
+	untaggedDir, _ := releaseRepository(t, now.AddDate(0, 0, -400))
+
+	out := t.TempDir()
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(out, "notes.txt"), false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+	vtm.Logger.SetOutput(io.Discard)
+	vtm.NoCloneProgress = true
+	vtm.StaleReleaseDays = 180
+
+	results, err := vtm.ProcessRepositories(context.Background(), []string{staleDir, freshDir, untaggedDir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if release := results[1].ReleaseNotes.LatestRelease; release == nil || release.Name != "v3.1.4" {
+		t.Errorf("Expected v3.1.4 as the fresh repository's release, got %+v", release)
+	}
+	if results[2].ReleaseNotes.LatestRelease != nil {
+		t.Errorf("Expected no release for the untagged repository")
+	}
+
+	data, err := os.ReadFile(vtm.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read notes: %v", err)
+	}
+	text := string(data)
+	heading := "=== STALE RELEASES (NO RELEASE IN 180 DAYS) ==="
+	if !strings.Contains(text, heading) {
+		t.Fatalf("Expected a stale releases summary, got:\n%s", text)
+	}
+	section := text[strings.Index(text, heading):strings.Index(text, "=== PROCESSING SUMMARY ===")]
+	if !strings.Contains(section, "v0.9.0 from "+now.AddDate(0, 0, -400).Format("2006-01-02")+", 400 days ago") || !strings.Contains(section, staleDir) {
+		t.Errorf("Expected the stale tag and its age in the summary, got:\n%s", section)
+	}
+	if strings.Contains(section, freshDir) || strings.Contains(section, untaggedDir) {
+		t.Errorf("Expected only the stale repository in the summary, got:\n%s", section)
+	}
+
+	report := &RunReport{StaleReleaseDays: 180, Repositories: []RepositoryReport{
+		{Repository: staleDir, Status: RepositoryStatusSuccess, ReleaseNotes: results[0].ReleaseNotes, StaleRelease: true},
+	}}
+	markdown, err := vtm.renderMarkdownReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(markdown), "## Stale Releases") {
+		t.Errorf("Expected a stale releases section in the Markdown report")
+	}
+}
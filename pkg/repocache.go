@@ -0,0 +1,340 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultRepoCacheMaxEntries is how many cached clones the server keeps unless configured otherwise
+const DefaultRepoCacheMaxEntries = 50
+
+// CacheClearTokenEnv names the environment variable holding the bearer token /api/cache/clear
+// requires; the endpoint is disabled without it
+const CacheClearTokenEnv = "CACHE_CLEAR_TOKEN"
+
+// repoCacheEntry is a bare clone of a repository kept under WorkDir/cache between requests
+type repoCacheEntry struct {
+	mu         sync.Mutex // Held while a request clones, fetches or reads the clone
+	repository string
+	path       string
+	fetchedAt  time.Time // Zero until the clone is created or fetched by this server
+	lastUsed   time.Time // When a request last locked the entry, for least recently used eviction
+	evicted    bool      // Set, under mu, once the clone is removed; lockers then look the repository up again
+}
+
+// repoCacheDir is where the cached clone of repoURL lives; the URL hash keeps forks with the
+// same name apart
+func (s *Server) repoCacheDir(repoURL string) string {
+	name := extractRepoNameFromURL(repoURL)
+	return filepath.Join(s.WorkDir, "cache", fmt.Sprintf("%s-%x", name, sha1.Sum([]byte(repoURL)))[:len(name)+9])
+}
+
+// lockCachedRepository returns the cache entry of repoURL, locked; the caller unlocks it once
+// done with the clone. Adding an entry evicts the least recently used clones past
+// RepoCacheMaxEntries.
+func (s *Server) lockCachedRepository(repoURL string) *repoCacheEntry {
+	for {
+		s.mu.Lock()
+		if s.repoCache == nil {
+			s.repoCache = make(map[string]*repoCacheEntry)
+		}
+		entry, ok := s.repoCache[repoURL]
+		if !ok {
+			entry = &repoCacheEntry{repository: repoURL, path: s.repoCacheDir(repoURL)}
+			s.repoCache[repoURL] = entry
+		}
+		entry.lastUsed = time.Now()
+		s.mu.Unlock()
+		if !ok {
+			s.trimRepositoryCache(entry)
+		}
+
+		entry.mu.Lock()
+		if !entry.evicted {
+			return entry
+		}
+		// Evicted while this request waited; the entry is out of the map, so retry with a new one
+		entry.mu.Unlock()
+	}
+}
+
+// trimRepositoryCache evicts the least recently used clones, other than keep, until the cache
+// holds at most RepoCacheMaxEntries. Clones in use by a request are skipped.
+func (s *Server) trimRepositoryCache(keep *repoCacheEntry) {
+	if s.RepoCacheMaxEntries <= 0 {
+		return
+	}
+	s.mu.Lock()
+	excess := len(s.repoCache) - s.RepoCacheMaxEntries
+	var candidates []*repoCacheEntry
+	for _, entry := range s.repoCache {
+		if entry != keep {
+			candidates = append(candidates, entry)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].lastUsed.Before(candidates[j].lastUsed) })
+	s.mu.Unlock()
+
+	for _, entry := range candidates {
+		if excess <= 0 {
+			return
+		}
+		if !entry.mu.TryLock() {
+			continue
+		}
+		if !entry.evicted {
+			s.Logger.Infof("Evicting the cached clone of %s, the least recently used of %d", entry.repository, s.RepoCacheMaxEntries+excess)
+			s.removeCachedEntry(entry)
+			excess--
+		}
+		entry.mu.Unlock()
+	}
+}
+
+// removeCachedEntry removes the clone of an entry whose mu the caller holds, then drops the entry
+// and the repository's branch lists from the server. The entry stays in the map until its
+// directory is gone, so no new entry can clone into the same path meanwhile.
+func (s *Server) removeCachedEntry(entry *repoCacheEntry) {
+	os.RemoveAll(entry.path)
+	entry.evicted = true
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repoCache[entry.repository] == entry {
+		delete(s.repoCache, entry.repository)
+	}
+	for key := range s.branchCache {
+		if key.repository == entry.repository {
+			delete(s.branchCache, key)
+		}
+	}
+}
+
+// syncCachedRepository opens the locked entry's clone of repoURL, cloning it on first use and
+// fetching every branch and tag when it is older than the cache duration or force is set.
+// A clone that cannot be fetched is replaced by a fresh clone. Cached clones always hold full
+// history and are made with the server's own credentials, never a request's token.
+func (s *Server) syncCachedRepository(ctx context.Context, entry *repoCacheEntry, repoURL string, force bool) (*git.Repository, error) {
+	auth := cloneAuth(repoURL, s.Credentials)
+	repo, err := git.PlainOpen(entry.path)
+	if err == nil {
+		if !force && time.Since(entry.fetchedAt) < s.cacheDuration {
+			return repo, nil
+		}
+		s.Logger.Infof("Updating the cached clone of %s...", repoURL)
+		_, span := StartSpan(ctx, "git.fetch", attribute.String("repository", repoURL))
+		err = fetchCachedRepository(ctx, repo, auth)
+		EndSpan(span, err)
+		if err == nil {
+			entry.fetchedAt = time.Now()
+			return repo, nil
+		}
+		if IsAuthError(err) {
+			return nil, NewAuthRequiredError(repoURL, err)
+		}
+		s.Logger.Warnf("Failed to update the cached clone of %s, cloning it again: %v", repoURL, err)
+	}
+
+	os.RemoveAll(entry.path)
+	os.MkdirAll(filepath.Dir(entry.path), 0755)
+	s.Logger.Infof("Cloning %s into the repository cache...", repoURL)
+	_, span := StartSpan(ctx, "git.clone", attribute.String("repository", repoURL))
+	repo, err = git.PlainCloneContext(ctx, entry.path, true, &git.CloneOptions{
+		URL:  repoURL,
+		Auth: auth,
+		Tags: git.AllTags,
+	})
+	EndSpan(span, err)
+	if err != nil {
+		os.RemoveAll(entry.path)
+		if IsAuthError(err) {
+			return nil, NewAuthRequiredError(repoURL, err)
+		}
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+	logCloneStats(s.Logger, repoURL, entry.path)
+	entry.fetchedAt = time.Now()
+	return repo, nil
+}
+
+// fetchCachedRepository updates every branch and tag of a cached clone and drops the
+// remote-tracking refs of branches that were deleted upstream
+func fetchCachedRepository(ctx context.Context, repo *git.Repository, auth transport.AuthMethod) error {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+	advertised, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return err
+	}
+	branches := make(map[string]bool)
+	for _, ref := range advertised {
+		if ref.Name().IsBranch() {
+			branches[ref.Name().Short()] = true
+		}
+	}
+
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf(config.DefaultFetchRefSpec, git.DefaultRemoteName))},
+		Tags:     git.AllTags,
+		Force:    true,
+		Auth:     auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return err
+	}
+	prefix := "refs/remotes/" + git.DefaultRemoteName + "/"
+	var stale []plumbing.ReferenceName
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		if branch := strings.TrimPrefix(ref.Name().String(), prefix); branch != ref.Name().String() && branch != "HEAD" && !branches[branch] {
+			stale = append(stale, ref.Name())
+		}
+		return nil
+	})
+	for _, name := range stale {
+		if err := repo.Storer.RemoveReference(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cachedBranchHead resolves a branch of a cached clone to its tip
+func cachedBranchHead(repo *git.Repository, branch string) (*plumbing.Reference, error) {
+	for _, name := range []plumbing.ReferenceName{plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch), plumbing.NewBranchReferenceName(branch)} {
+		if ref, err := repo.Reference(name, true); err == nil {
+			return ref, nil
+		}
+	}
+	return nil, fmt.Errorf("branch %s not found", branch)
+}
+
+// evictCachedRepository removes the cached clone and branch lists of repoURL, waiting for any
+// request still using the clone
+func (s *Server) evictCachedRepository(repoURL string) {
+	s.mu.Lock()
+	entry, ok := s.repoCache[repoURL]
+	s.mu.Unlock()
+	if !ok {
+		// No entry to lock, so only a clone left by an earlier run can be there
+		s.removeUntrackedCacheDir(s.repoCacheDir(repoURL))
+		return
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if !entry.evicted {
+		s.removeCachedEntry(entry)
+	}
+}
+
+// removeUntrackedCacheDirs removes the clones under WorkDir/cache that no cache entry tracks,
+// such as those left by an earlier run of the server
+func (s *Server) removeUntrackedCacheDirs() {
+	dir := filepath.Join(s.WorkDir, "cache")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		s.removeUntrackedCacheDir(filepath.Join(dir, entry.Name()))
+	}
+}
+
+// removeUntrackedCacheDir removes a directory under WorkDir/cache unless a cache entry tracks it.
+// The check and a rename out of the way happen under the server lock, so a request can't start
+// cloning into the path between them; the slow removal happens after.
+func (s *Server) removeUntrackedCacheDir(path string) {
+	s.mu.Lock()
+	for _, entry := range s.repoCache {
+		if entry.path == path {
+			s.mu.Unlock()
+			return
+		}
+	}
+	trash := fmt.Sprintf("%s.removing-%d", path, time.Now().UnixNano())
+	err := os.Rename(path, trash)
+	s.mu.Unlock()
+	if err == nil {
+		os.RemoveAll(trash)
+	}
+}
+
+// handleCacheClear evicts the cached clone and branch list of the repository parameter, or of
+// every repository without it, so the next request clones afresh. It requires the
+// CacheClearToken as a bearer token and is disabled without one.
+func (s *Server) handleCacheClear(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "POST method required",
+		})
+		return
+	}
+	if s.CacheClearToken == "" {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "clearing the cache is disabled; set " + CacheClearTokenEnv + " to enable it",
+		})
+		return
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.CacheClearToken)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "a valid bearer token is required",
+		})
+		return
+	}
+
+	repoURL := r.URL.Query().Get("repository")
+	repos := []string{repoURL}
+	if repoURL == "" {
+		repos = nil
+		s.mu.Lock()
+		for repo := range s.repoCache {
+			repos = append(repos, repo)
+		}
+		s.branchCache = nil
+		s.mu.Unlock()
+	}
+	for _, repo := range repos {
+		s.evictCachedRepository(repo)
+	}
+	if repoURL == "" {
+		s.removeUntrackedCacheDirs()
+	}
+	s.Logger.Infof("Cleared the repository cache (%d repositories)", len(repos))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"cleared": len(repos),
+	})
+}
@@ -0,0 +1,291 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// warmRepositoryCache clones repoURL into the server's repository cache
+func warmRepositoryCache(t *testing.T, s *Server, repoURL string) {
+	t.Helper()
+	entry := s.lockCachedRepository(repoURL)
+	defer entry.mu.Unlock()
+	if _, err := s.syncCachedRepository(context.Background(), entry, repoURL, false); err != nil {
+		t.Fatalf("Failed to clone into the cache: %v", err)
+	}
+}
+
+func TestRepositoryCacheReusesClone(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "first")
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+	warmRepositoryCache(t, s, dir)
+	entry := s.repoCache[dir]
+	if entry == nil {
+		t.Fatalf("Expected the clone to be cached")
+	}
+	if _, err := os.Stat(entry.path); err != nil {
+		t.Fatalf("Expected the cached clone under the work directory: %v", err)
+	}
+	fetchedAt := entry.fetchedAt
+
+	// Within the cache duration the notes read the cached clone
+	commitFile(t, repo, dir, "b.txt", "second")
+	notes, err := s.generateReleaseNotesForBranch(context.Background(), ReleaseNotesRequest{Repository: dir, Branch: "master", Days: 7}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !entry.fetchedAt.Equal(fetchedAt) || !strings.Contains(notes.text, "Total Commits: 1\n") {
+		t.Errorf("Expected the cached clone to be reused without a fetch, got:\n%s", notes.text)
+	}
+
+	// A branch missing from the clone is fetched instead of failing
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), head.Hash())); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	notes, err = s.generateReleaseNotesForBranch(context.Background(), ReleaseNotesRequest{Repository: dir, Branch: "feature", Days: 7}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !entry.fetchedAt.After(fetchedAt) || !strings.Contains(notes.text, "Total Commits: 2\n") {
+		t.Errorf("Expected the clone to be fetched for the new branch, got:\n%s", notes.text)
+	}
+
+	// Once the cache duration passes the clone is fetched again
+	s.cacheDuration = 0
+	commitFile(t, repo, dir, "c.txt", "third")
+	notes, err = s.generateReleaseNotesForBranch(context.Background(), ReleaseNotesRequest{Repository: dir, Branch: "master", Days: 7}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(notes.text, "Total Commits: 3\n") {
+		t.Errorf("Expected the stale clone to be fetched, got:\n%s", notes.text)
+	}
+}
+
+func TestRepositoryCacheUpstreamComparisons(t *testing.T) {
+	upstreamDir := t.TempDir()
+	upstreamRepo, err := git.PlainInit(upstreamDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init upstream: %v", err)
+	}
+	commitFile(t, upstreamRepo, upstreamDir, "base.txt", "base commit")
+	forkDir := t.TempDir()
+	if _, err := git.PlainClone(forkDir, false, &git.CloneOptions{URL: upstreamDir}); err != nil {
+		t.Fatalf("Failed to clone fork: %v", err)
+	}
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+	// The cached clone keeps the upstream remote of the first comparison
+	for i := 0; i < 2; i++ {
+		notes, err := s.generateReleaseNotesForBranch(context.Background(), ReleaseNotesRequest{Repository: forkDir, Branch: "master", Days: 7, Upstream: upstreamDir}, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(notes.text, "Status: 0 commits behind upstream, 0 ahead") {
+			t.Errorf("Request %d: expected an upstream comparison, got:\n%s", i+1, notes.text)
+		}
+	}
+}
+
+func TestHandleCacheClear(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "first")
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+	warmRepositoryCache(t, s, dir)
+	if _, err := s.cachedBranchList(context.Background(), dir, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := s.repoCache[dir].path
+	// A clone left by an earlier run of the server
+	leftover := s.repoCacheDir("https://github.com/example/old-operator")
+	if err := os.MkdirAll(leftover, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	clearRequest := func(target, token string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+
+	recorder := httptest.NewRecorder()
+	s.handleCacheClear(recorder, httptest.NewRequest(http.MethodGet, "/api/cache/clear", nil))
+	if !strings.Contains(recorder.Body.String(), "POST method required") {
+		t.Errorf("Expected GET to be rejected, got %s", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	s.handleCacheClear(recorder, clearRequest("/api/cache/clear", "secret"))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("Expected clearing to be disabled without a configured token, got %d", recorder.Code)
+	}
+
+	s.CacheClearToken = "secret"
+	for _, token := range []string{"", "wrong"} {
+		recorder = httptest.NewRecorder()
+		s.handleCacheClear(recorder, clearRequest("/api/cache/clear", token))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("Token %q: expected 401, got %d", token, recorder.Code)
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected rejected requests to keep the cached clone")
+	}
+
+	recorder = httptest.NewRecorder()
+	s.handleCacheClear(recorder, clearRequest("/api/cache/clear?repository="+url.QueryEscape(dir), "secret"))
+	var response struct {
+		Success bool `json:"success"`
+		Cleared int  `json:"cleared"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Success || response.Cleared != 1 {
+		t.Errorf("Expected one repository to be cleared, got %+v", response)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the cached clone to be removed")
+	}
+	if _, ok := s.branchCache[newBranchCacheKey(dir, "")]; ok {
+		t.Errorf("Expected the branch list to be evicted")
+	}
+	if _, err := os.Stat(leftover); err != nil {
+		t.Errorf("Expected other clones to be kept when one repository is cleared")
+	}
+
+	recorder = httptest.NewRecorder()
+	s.handleCacheClear(recorder, clearRequest("/api/cache/clear", "secret"))
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Errorf("Expected clearing everything to remove clones of earlier runs")
+	}
+}
+
+func TestRepositoryCacheSerializesRequests(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	entry := s.lockCachedRepository("https://github.com/example/operator")
+
+	locked := make(chan struct{})
+	go func() {
+		s.lockCachedRepository("https://github.com/example/operator").mu.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("Expected a second request for the repository to wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+	entry.mu.Unlock()
+	<-locked
+}
+
+func TestEvictCachedRepositoryWaitsForRequest(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+	repoURL := "https://github.com/example/operator"
+	entry := s.lockCachedRepository(repoURL)
+	if err := os.MkdirAll(entry.path, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	evicted := make(chan struct{})
+	go func() {
+		s.evictCachedRepository(repoURL)
+		close(evicted)
+	}()
+	select {
+	case <-evicted:
+		t.Fatal("Expected eviction to wait for the request using the clone")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := os.Stat(entry.path); err != nil {
+		t.Fatalf("Expected the clone to be kept while in use")
+	}
+
+	// A request arriving meanwhile waits on the same entry and must not reuse it once evicted
+	next := make(chan *repoCacheEntry)
+	go func() { next <- s.lockCachedRepository(repoURL) }()
+	time.Sleep(20 * time.Millisecond)
+	entry.mu.Unlock()
+	<-evicted
+
+	fresh := <-next
+	defer fresh.mu.Unlock()
+	if fresh == entry || fresh.evicted {
+		t.Errorf("Expected a new cache entry after eviction")
+	}
+	if s.repoCache[repoURL] != fresh {
+		t.Errorf("Expected the new entry to be tracked")
+	}
+}
+
+func TestRepositoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+	s.RepoCacheMaxEntries = 2
+
+	var paths []string
+	for _, repoURL := range []string{"https://github.com/example/a", "https://github.com/example/b"} {
+		entry := s.lockCachedRepository(repoURL)
+		if err := os.MkdirAll(entry.path, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		paths = append(paths, entry.path)
+		entry.mu.Unlock()
+	}
+	// Using a again makes b the least recently used
+	s.lockCachedRepository("https://github.com/example/a").mu.Unlock()
+
+	// Adding c evicts b; c is then the least recently used but still in use when d is added,
+	// so a is evicted instead
+	c := s.lockCachedRepository("https://github.com/example/c")
+	defer c.mu.Unlock()
+	s.lockCachedRepository("https://github.com/example/a").mu.Unlock()
+	s.lockCachedRepository("https://github.com/example/d").mu.Unlock()
+
+	if _, err := os.Stat(paths[1]); !os.IsNotExist(err) {
+		t.Errorf("Expected the least recently used clone to be removed")
+	}
+	for _, repoURL := range []string{"https://github.com/example/a", "https://github.com/example/b"} {
+		if _, ok := s.repoCache[repoURL]; ok {
+			t.Errorf("Expected %s to be evicted", repoURL)
+		}
+	}
+	if len(s.repoCache) != 2 {
+		t.Errorf("Expected the cache to be trimmed to 2 entries, got %d", len(s.repoCache))
+	}
+	if _, ok := s.repoCache["https://github.com/example/c"]; !ok {
+		t.Errorf("Expected the clone in use to be kept")
+	}
+}
@@ -0,0 +1,556 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RepositoryNotes holds the release notes produced for one repository. Format is set when the
+// notes come from the built-in analysis; external tools only provide rendered text.
+type RepositoryNotes struct {
+	Format   *ReleaseNoteFormat
+	Text     string
+	Branches []BranchNotes // Notes of each selected branch; Format and Text then cover them all
+}
+
+// Repository processing outcomes
+const (
+	RepositoryStatusSuccess = "success"
+	RepositoryStatusFailed  = "failed"
+	RepositoryStatusSkipped = "skipped"
+)
+
+// RepositoryReport is the outcome of processing a single repository
+type RepositoryReport struct {
+	Repository   string             `json:"repository"`
+	Status       string             `json:"status"`
+	ReleaseNotes *ReleaseNoteFormat `json:"releaseNotes,omitempty"`
+	Text         string             `json:"text,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	Remediation  string             `json:"remediation,omitempty"`
+	SkipReason   string             `json:"skipReason,omitempty"`
+	Labels       []string           `json:"labels,omitempty"`
+	Branches     []BranchNotes      `json:"branches,omitempty"`     // Notes of each selected branch; ReleaseNotes is the first one's
+	LowActivity  bool               `json:"lowActivity,omitempty"`  // Fewer commits than MinCommits; only listed in the low activity summary
+	StaleRelease bool               `json:"staleRelease,omitempty"` // Newest release tag older than StaleReleaseDays; also listed in the stale releases summary
+
+	err      error
+	duration time.Duration // Time spent on the repository, retries included
+}
+
+// RepoResult is the outcome of one repository as returned to programmatic callers of
+// ProcessRepositories. ReleaseNotes is nil when the repository failed (Err is set) or was
+// skipped (SkipReason is set). With several branches selected, ReleaseNotes is the first
+// branch's and Branches holds each one.
+type RepoResult struct {
+	Repository   string
+	ReleaseNotes *ReleaseNoteFormat
+	Branches     []BranchNotes
+	Err          error
+	SkipReason   string
+}
+
+// Results returns the outcome of each repository in processing order
+func (r *RunReport) Results() []RepoResult {
+	results := make([]RepoResult, 0, len(r.Repositories))
+	for _, repo := range r.Repositories {
+		results = append(results, RepoResult{
+			Repository:   repo.Repository,
+			ReleaseNotes: repo.ReleaseNotes,
+			Branches:     repo.Branches,
+			Err:          repo.err,
+			SkipReason:   repo.SkipReason,
+		})
+	}
+	return results
+}
+
+// RunReport collects the results of a whole run so every output format renders the same data
+type RunReport struct {
+	GeneratedAt      *time.Time         `json:"generatedAt,omitempty"`
+	CatalogSchemas   []string           `json:"catalogSchemas,omitempty"`
+	Repositories     []RepositoryReport `json:"repositories"`
+	Total            int                `json:"total"`
+	Successful       int                `json:"successful"`
+	Failed           int                `json:"failed"`
+	Skipped          int                `json:"skipped"`
+	MinCommits       int                `json:"minCommits,omitempty"`       // Successful repositories with fewer commits are marked LowActivity
+	StaleReleaseDays int                `json:"staleReleaseDays,omitempty"` // Successful repositories with an older newest release are marked StaleRelease
+	Recloned         []string           `json:"recloned,omitempty"`
+	DataTransferred  *CloneStats        `json:"dataTransferred,omitempty"`
+
+	startedAt time.Time     // Start of the run
+	duration  time.Duration // Time spent processing the repositories
+}
+
+// SuccessRate returns the percentage of repositories processed successfully, 0 for an empty run
+func (r *RunReport) SuccessRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Successful) / float64(r.Total) * 100
+}
+
+// Output formats supported by OutputTarget
+const (
+	OutputFormatText      = "text"
+	OutputFormatHTML      = "html"
+	OutputFormatJSON      = "json"
+	OutputFormatMarkdown  = "markdown"
+	OutputFormatAtom      = "atom"       // Atom feed of each repository's latest activity
+	OutputFormatPDF       = "pdf"        // The HTML report converted by an external renderer, see writePDFReport
+	OutputFormatJSONArray = "json-array" // Only the repositories of the JSON report, as a single array; selected with --format=json
+)
+
+// StdoutOutputPath is the output path that writes the text report to standard output
+const StdoutOutputPath = "-"
+
+// OutputTarget is a file to write and the format to render into it
+type OutputTarget struct {
+	Path   string
+	Format string
+}
+
+// outputFormatsByExtension maps file extensions to the output format they produce
+var outputFormatsByExtension = map[string]string{
+	".txt":  OutputFormatText,
+	".html": OutputFormatHTML,
+	".htm":  OutputFormatHTML,
+	".json": OutputFormatJSON,
+	".md":   OutputFormatMarkdown,
+	".xml":  OutputFormatAtom,
+	".atom": OutputFormatAtom,
+	".pdf":  OutputFormatPDF,
+}
+
+// reportRenderers renders a run report in each output format but PDF, which is converted
+// from the HTML report by an external program
+var reportRenderers = map[string]func(*VibeToolsManager, *RunReport) ([]byte, error){
+	OutputFormatText:      (*VibeToolsManager).renderTextReport,
+	OutputFormatHTML:      (*VibeToolsManager).renderHTMLReport,
+	OutputFormatJSON:      (*VibeToolsManager).renderJSONReport,
+	OutputFormatMarkdown:  (*VibeToolsManager).renderMarkdownReport,
+	OutputFormatAtom:      (*VibeToolsManager).renderAtomReport,
+	OutputFormatJSONArray: (*VibeToolsManager).renderJSONArrayReport,
+}
+
+// ParseOutputTargets parses a comma-separated list of output files, inferring each
+// file's format from its extension
+func ParseOutputTargets(value string) ([]OutputTarget, error) {
+	var targets []OutputTarget
+	stdout := false
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if path == StdoutOutputPath {
+			if stdout {
+				return nil, fmt.Errorf("standard output (%q) can only be given once", StdoutOutputPath)
+			}
+			stdout = true
+		}
+		format, err := OutputFormatForPath(path)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, OutputTarget{Path: path, Format: format})
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no output files given")
+	}
+	return targets, nil
+}
+
+// OutputFormatForPath returns the output format for a file based on its extension
+func OutputFormatForPath(path string) (string, error) {
+	if path == StdoutOutputPath {
+		return OutputFormatText, nil
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	format, ok := outputFormatsByExtension[ext]
+	if !ok {
+		return "", fmt.Errorf("no output format for %q (supported extensions: %s)", path, supportedOutputExtensions())
+	}
+	return format, nil
+}
+
+// supportedOutputExtensions lists the known output extensions in sorted order
+func supportedOutputExtensions() string {
+	var exts []string
+	for ext := range outputFormatsByExtension {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return strings.Join(exts, ", ")
+}
+
+// renderTextReport renders the plain text release notes file
+func (vtm *VibeToolsManager) renderTextReport(report *RunReport) ([]byte, error) {
+	var output strings.Builder
+	staleReleases := report.staleReleases()
+	report, lowActivity := report.splitLowActivity()
+
+	// Header
+	header := "Release Notes\n"
+	if report.GeneratedAt != nil {
+		header = fmt.Sprintf("Release Notes Generated on: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	}
+	header += "=" + strings.Repeat("=", len(header)-1) + "\n"
+	header += vtm.asOfLine()
+	header += vtm.catalogSchemaLine()
+	header += "\n"
+	output.WriteString(header)
+
+	// Repository sections, grouped under an index when labels are configured
+	if groups := report.labelGroups(); groups != nil {
+		output.WriteString("=== INDEX ===\n")
+		for _, group := range groups {
+			output.WriteString(fmt.Sprintf("%s (%d): %s\n", group.Label, len(group.Repositories), strings.Join(vtm.repoNames(group.Repositories), ", ")))
+		}
+		for _, group := range groups {
+			output.WriteString("\n" + strings.Repeat("#", 80) + "\n")
+			output.WriteString(fmt.Sprintf("# %s (%d)\n", strings.ToUpper(group.Label), len(group.Repositories)))
+			output.WriteString(strings.Repeat("#", 80) + "\n")
+			for _, repo := range group.Repositories {
+				output.WriteString(vtm.textRepoSection(repo))
+			}
+		}
+	} else {
+		for _, repo := range report.Repositories {
+			output.WriteString(vtm.textRepoSection(repo))
+		}
+	}
+
+	output.WriteString(vtm.formatTextLowActivity(lowActivity, report.MinCommits))
+	output.WriteString(vtm.formatTextStaleReleases(staleReleases, report.StaleReleaseDays))
+
+	// Summary
+	output.WriteString("\n=== PROCESSING SUMMARY ===\n")
+	output.WriteString(fmt.Sprintf("Total Repositories: %d\n", report.Total))
+	output.WriteString(fmt.Sprintf("Successfully Processed: %d\n", report.Successful))
+	output.WriteString(fmt.Sprintf("Failed: %d\n", report.Failed))
+	if report.Skipped > 0 {
+		output.WriteString(fmt.Sprintf("Skipped (too large): %d\n", report.Skipped))
+	}
+	if len(report.Recloned) > 0 {
+		output.WriteString(fmt.Sprintf("Re-cloned (corrupt object database): %d (%s)\n", len(report.Recloned), strings.Join(report.Recloned, ", ")))
+	}
+	output.WriteString(fmt.Sprintf("Success Rate: %.1f%%\n", report.SuccessRate()))
+	if report.DataTransferred != nil {
+		output.WriteString(fmt.Sprintf("Data Transferred: %s\n", report.DataTransferred))
+	}
+	output.WriteString(vtm.catalogSchemaLine())
+	if report.GeneratedAt != nil {
+		output.WriteString(fmt.Sprintf("Generated on: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05")))
+	}
+
+	return []byte(applyLineEnding(output.String(), vtm.Formatter.LineEnding)), nil
+}
+
+// textRepoSection renders one repository's section of the text report
+func (vtm *VibeToolsManager) textRepoSection(repo RepositoryReport) string {
+	switch repo.Status {
+	case RepositoryStatusSuccess:
+		return repo.Text
+	case RepositoryStatusSkipped:
+		return vtm.Formatter.FormatSkippedSection(repo.Repository, repo.SkipReason)
+	default:
+		return vtm.Formatter.FormatErrorSection(repo.Repository, repo.err)
+	}
+}
+
+// repoNames returns the short names of the repositories, as listed in the label index
+func (vtm *VibeToolsManager) repoNames(repos []RepositoryReport) []string {
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, vtm.extractRepoName(repo.Repository))
+	}
+	return names
+}
+
+// renderHTMLReport renders the standalone HTML release notes page
+func (vtm *VibeToolsManager) renderHTMLReport(report *RunReport) ([]byte, error) {
+	var output strings.Builder
+	staleReleases := report.staleReleases()
+	report, lowActivity := report.splitLowActivity()
+
+	output.WriteString(vtm.generateHTMLHeader())
+	if groups := report.labelGroups(); groups != nil {
+		output.WriteString(vtm.formatHTMLLabelIndex(groups))
+		for _, group := range groups {
+			output.WriteString(fmt.Sprintf(`
+        <h2 class="label-heading" id="%s">%s <span class="label-count">%d</span></h2>
+`, labelAnchor(group.Label), html.EscapeString(group.Label), len(group.Repositories)))
+			for _, repo := range group.Repositories {
+				output.WriteString(vtm.htmlRepoSection(repo))
+			}
+		}
+	} else {
+		for _, repo := range report.Repositories {
+			output.WriteString(vtm.htmlRepoSection(repo))
+		}
+	}
+	output.WriteString(vtm.formatHTMLLowActivity(lowActivity, report.MinCommits))
+	output.WriteString(vtm.formatHTMLStaleReleases(staleReleases, report.StaleReleaseDays))
+	output.WriteString(vtm.generateHTMLSummary(report))
+	output.WriteString(vtm.generateHTMLFooter())
+
+	return []byte(output.String()), nil
+}
+
+// htmlRepoSection renders one repository's card of the HTML report
+func (vtm *VibeToolsManager) htmlRepoSection(repo RepositoryReport) string {
+	switch repo.Status {
+	case RepositoryStatusSuccess:
+		if len(repo.Branches) > 0 {
+			return vtm.formatHTMLRepoBranches(repo.Repository, repo.Branches)
+		}
+		return vtm.formatHTMLRepoSection(repo.Repository, repo.Text, repo.ReleaseNotes)
+	case RepositoryStatusSkipped:
+		return vtm.formatHTMLSkippedSection(repo.Repository, repo.SkipReason)
+	default:
+		return vtm.formatHTMLErrorSection(repo.Repository, repo.err)
+	}
+}
+
+// formatHTMLLabelIndex renders the index linking to each label section of the HTML report
+func (vtm *VibeToolsManager) formatHTMLLabelIndex(groups []labelGroup) string {
+	var index strings.Builder
+	index.WriteString(`
+        <div class="label-index">
+            <h2>Index</h2>
+            <ul>`)
+	for _, group := range groups {
+		index.WriteString(fmt.Sprintf(`
+                <li><a href="#%s">%s</a> <span class="label-count">%d</span> <span class="label-repos">%s</span></li>`,
+			labelAnchor(group.Label), html.EscapeString(group.Label), len(group.Repositories),
+			html.EscapeString(strings.Join(vtm.repoNames(group.Repositories), ", "))))
+	}
+	index.WriteString(`
+            </ul>
+        </div>
+`)
+	return index.String()
+}
+
+// renderJSONReport renders the machine-readable JSON report
+func (vtm *VibeToolsManager) renderJSONReport(report *RunReport) ([]byte, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// renderJSONArrayReport renders the repositories of the run as a JSON array, one object per
+// repository with its status and notes, or its error when it failed
+func (vtm *VibeToolsManager) renderJSONArrayReport(report *RunReport) ([]byte, error) {
+	repositories := report.Repositories
+	if repositories == nil {
+		repositories = []RepositoryReport{}
+	}
+	data, err := json.MarshalIndent(repositories, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// renderMarkdownReport renders the release notes as Markdown
+func (vtm *VibeToolsManager) renderMarkdownReport(report *RunReport) ([]byte, error) {
+	var output strings.Builder
+	staleReleases := report.staleReleases()
+	report, lowActivity := report.splitLowActivity()
+
+	output.WriteString("# Release Notes\n\n")
+	if report.GeneratedAt != nil {
+		output.WriteString(fmt.Sprintf("Generated on: %s\n\n", report.GeneratedAt.Format("2006-01-02 15:04:05")))
+	}
+	if line := vtm.asOfLine(); line != "" {
+		output.WriteString(line + "\n")
+	}
+	if len(report.CatalogSchemas) > 0 {
+		output.WriteString(fmt.Sprintf("Catalog schema: %s\n\n", strings.Join(report.CatalogSchemas, ", ")))
+	}
+
+	if groups := report.labelGroups(); groups != nil {
+		output.WriteString("## Index\n\n")
+		for _, group := range groups {
+			output.WriteString(fmt.Sprintf("- [%s](#%s) (%d): %s\n", markdownEscape(group.Label), labelAnchor(group.Label),
+				len(group.Repositories), markdownEscape(strings.Join(vtm.repoNames(group.Repositories), ", "))))
+		}
+		output.WriteString("\n")
+		for _, group := range groups {
+			output.WriteString(fmt.Sprintf("## <a id=\"%s\"></a>%s\n\n", labelAnchor(group.Label), markdownEscape(group.Label)))
+			for _, repo := range group.Repositories {
+				vtm.writeMarkdownRepository(&output, repo, "###")
+			}
+		}
+	} else {
+		for _, repo := range report.Repositories {
+			vtm.writeMarkdownRepository(&output, repo, "##")
+		}
+	}
+
+	vtm.writeMarkdownLowActivity(&output, lowActivity, report.MinCommits)
+	vtm.writeMarkdownStaleReleases(&output, staleReleases, report.StaleReleaseDays)
+	output.WriteString("## Processing Summary\n\n")
+	output.WriteString("| Metric | Value |\n|---|---|\n")
+	output.WriteString(fmt.Sprintf("| Total Repositories | %d |\n", report.Total))
+	output.WriteString(fmt.Sprintf("| Successfully Processed | %d |\n", report.Successful))
+	output.WriteString(fmt.Sprintf("| Failed | %d |\n", report.Failed))
+	output.WriteString(fmt.Sprintf("| Skipped | %d |\n", report.Skipped))
+	output.WriteString(fmt.Sprintf("| Success Rate | %.1f%% |\n", report.SuccessRate()))
+
+	return []byte(output.String()), nil
+}
+
+// writeMarkdownRepository writes one repository's section under a heading of the given level
+// (e.g. "##"); the release notes subsections are nested one level deeper
+func (vtm *VibeToolsManager) writeMarkdownRepository(output *strings.Builder, repo RepositoryReport, heading string) {
+	output.WriteString(fmt.Sprintf("%s %s\n\n", heading, markdownEscape(vtm.extractRepoName(repo.Repository))))
+	output.WriteString(fmt.Sprintf("Repository: %s\n\n", markdownEscape(repo.Repository)))
+
+	switch {
+	case repo.Status == RepositoryStatusSkipped:
+		output.WriteString(fmt.Sprintf("**Skipped:** %s\n\n", markdownEscape(repo.SkipReason)))
+	case repo.Status == RepositoryStatusFailed:
+		output.WriteString(fmt.Sprintf("**Error:** %s\n\n", markdownEscape(repo.Error)))
+		if repo.Remediation != "" {
+			output.WriteString(fmt.Sprintf("**Remediation:** %s\n\n", markdownEscape(repo.Remediation)))
+		}
+	case len(repo.Branches) > 0:
+		writeMarkdownBranches(output, repo.Branches, heading+"#")
+	case repo.ReleaseNotes != nil:
+		writeMarkdownNotes(output, repo.ReleaseNotes, heading+"#")
+	default:
+		output.WriteString("```text\n")
+		output.WriteString(strings.TrimSpace(repo.Text))
+		output.WriteString("\n```\n\n")
+	}
+}
+
+// writeMarkdownNotes writes the sections of one repository's release notes as Markdown,
+// using heading (e.g. "###") for the subsection titles
+func writeMarkdownNotes(output *strings.Builder, format *ReleaseNoteFormat, heading string) {
+	if format.Snapshot != nil {
+		output.WriteString(fmt.Sprintf("> ⚠️ **Warning:** %s\n\n", markdownEscape(format.Snapshot.Note())))
+		if format.Snapshot.Commit != "" {
+			output.WriteString(fmt.Sprintf("**Current commit:** `%s`\n\n", format.Snapshot.Commit))
+		}
+		output.WriteString(fmt.Sprintf("**Current state:** %d files, %.1f MB\n\n", format.Snapshot.Files, float64(format.Snapshot.Bytes)/(1024*1024)))
+		return
+	}
+	if format.HistoryRewrite != nil {
+		output.WriteString(fmt.Sprintf("> ⚠️ **Warning:** %s\n\n", markdownEscape(format.HistoryRewrite.Message())))
+	}
+	if format.WeeklySummary.StatsUnavailable() {
+		output.WriteString(fmt.Sprintf("> ⚠️ **Warning:** %s\n\n", markdownEscape(format.WeeklySummary.statsUnavailableNote())))
+	}
+	if len(format.BreakingChanges) > 0 {
+		output.WriteString(heading + " ⚠️ Breaking Changes\n\n")
+		for _, change := range format.BreakingChanges {
+			output.WriteString(fmt.Sprintf("- %s (`%s`) by %s\n", markdownEscape(change.Description), change.Commit.Hash, markdownEscape(change.Commit.Author)))
+		}
+		output.WriteString("\n")
+	}
+	if format.RepositoryInfo.DisplayName != "" {
+		output.WriteString(fmt.Sprintf("**Display name:** %s\n\n", markdownEscape(format.RepositoryInfo.DisplayName)))
+	}
+	if format.RepositoryInfo.Capabilities != "" {
+		output.WriteString(fmt.Sprintf("**Capability level:** %s\n\n", markdownEscape(format.RepositoryInfo.Capabilities)))
+	}
+	if format.RepositoryInfo.OpenPullRequests != nil {
+		output.WriteString(fmt.Sprintf("**Open pull requests:** %d\n\n", *format.RepositoryInfo.OpenPullRequests))
+	}
+	if len(format.OnlyAuthors) > 0 {
+		output.WriteString(fmt.Sprintf("**Author filter:** %s\n\n", markdownEscape(authorFilterNote(format.OnlyAuthors))))
+	}
+	output.WriteString(fmt.Sprintf("**Latest commit:** `%s` %s by %s on %s\n\n",
+		format.LatestCommit.Hash,
+		markdownEscape(firstLine(format.LatestCommit.Message)),
+		markdownEscape(format.LatestCommit.Author),
+		format.LatestCommit.Date.Format("2006-01-02")))
+	output.WriteString(fmt.Sprintf("**%s activity:** %d commits, %s lines changed, %d contributors, %d merged PRs\n\n",
+		getPeriodLabel(format.AnalysisDays),
+		format.WeeklySummary.TotalCommits,
+		format.WeeklySummary.linesChangedValue(),
+		format.WeeklySummary.ActiveContributors,
+		format.WeeklySummary.MergedPRs))
+
+	if len(format.MergedPullRequests) > 0 {
+		output.WriteString(heading + " Merged Pull Requests\n\n")
+		for _, pr := range format.MergedPullRequests {
+			label := pr.label()
+			if pr.URL != "" {
+				label = fmt.Sprintf("[%s](%s)", label, pr.URL)
+			}
+			output.WriteString(fmt.Sprintf("- %s %s (`%s`) by %s\n", label, markdownEscape(pr.Title), pr.Commit.Hash, markdownEscape(pr.Commit.Author)))
+		}
+		output.WriteString("\n")
+	}
+
+	if format.Upstream != nil {
+		output.WriteString(fmt.Sprintf("**Upstream:** %s (%s): %s\n\n", format.Upstream.UpstreamURL, format.Upstream.Branch, format.Upstream.Status()))
+	}
+
+	if len(format.NotableChanges) > 0 {
+		output.WriteString(heading + " Notable Changes\n\n")
+		for _, change := range format.NotableChanges {
+			output.WriteString(fmt.Sprintf("- %s (`%s`) by %s matched `%s`: %s\n",
+				markdownEscape(firstLine(change.Commit.Message)),
+				change.Commit.Hash,
+				markdownEscape(change.Commit.Author),
+				change.Pattern,
+				strings.Join(change.Files, ", ")))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(format.Contributors) > 0 {
+		output.WriteString(heading + " Top Contributors\n\n")
+		for _, contributor := range format.Contributors {
+			output.WriteString(fmt.Sprintf("%d. %s (%s)\n", contributor.Rank, markdownEscape(contributor.Name), contributor.Activity(format.RankBy)))
+		}
+		output.WriteString("\n")
+	}
+
+	output.WriteString(fmt.Sprintf("%s Commits (%s)\n\n", heading, format.windowDescription()))
+	if len(format.Commits) == 0 {
+		output.WriteString("No commits in this period.\n\n")
+	} else {
+		writeMarkdownCommits(output, format.Commits)
+	}
+
+	// Vendoring updates are collapsed so the list above stays focused on first-party changes
+	if len(format.VendoredCommits) > 0 {
+		output.WriteString(fmt.Sprintf("<details>\n<summary>Dependency vendoring (%d commits)</summary>\n\n", len(format.VendoredCommits)))
+		writeMarkdownCommits(output, format.VendoredCommits)
+		output.WriteString("</details>\n\n")
+	}
+	writeMarkdownSubmoduleChanges(output, format.Submodules, heading)
+}
+
+// writeMarkdownCommits writes one list item per commit
+func writeMarkdownCommits(output *strings.Builder, commits []CommitDetail) {
+	for _, commit := range commits {
+		output.WriteString(fmt.Sprintf("- %s (`%s`) by %s on %s\n",
+			markdownEscape(firstLine(commit.Message)),
+			commit.Hash,
+			markdownEscape(commit.Author),
+			commit.Date.Format("2006-01-02")))
+	}
+	output.WriteString("\n")
+}
+
+// markdownEscape escapes characters that would otherwise be interpreted as Markdown or HTML
+func markdownEscape(text string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "*", "\\*", "_", "\\_", "`", "\\`", "[", "\\[", "]", "\\]")
+	return html.EscapeString(replacer.Replace(text))
+}
@@ -0,0 +1,328 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestParseOutputTargets(t *testing.T) {
+	targets, err := ParseOutputTargets("notes.html, notes.json,notes.md,notes.txt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []OutputTarget{
+		{Path: "notes.html", Format: OutputFormatHTML},
+		{Path: "notes.json", Format: OutputFormatJSON},
+		{Path: "notes.md", Format: OutputFormatMarkdown},
+		{Path: "notes.txt", Format: OutputFormatText},
+	}
+	if len(targets) != len(expected) {
+		t.Fatalf("Expected %d targets, got %d", len(expected), len(targets))
+	}
+	for i, target := range targets {
+		if target != expected[i] {
+			t.Errorf("Expected target %+v, got %+v", expected[i], target)
+		}
+	}
+
+	if _, err := ParseOutputTargets("notes.html,notes.docx"); err == nil || !strings.Contains(err.Error(), "notes.docx") {
+		t.Errorf("Expected error naming the unsupported file, got %v", err)
+	}
+	if _, err := ParseOutputTargets(" , "); err == nil {
+		t.Errorf("Expected error for empty output list")
+	}
+
+	targets, err = ParseOutputTargets("-,notes.json")
+	if err != nil || len(targets) != 2 || targets[0] != (OutputTarget{Path: StdoutOutputPath, Format: OutputFormatText}) {
+		t.Errorf("Expected stdout text target, got %+v (err: %v)", targets, err)
+	}
+	if _, err := ParseOutputTargets("-,-"); err == nil {
+		t.Errorf("Expected error for repeated stdout target")
+	}
+}
+
+func TestWriteReportToStdout(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.Formatter.OmitTimestamp = true
+	var stdout strings.Builder
+	vtm.Stdout = &stdout
+
+	report := &RunReport{Total: 1, Failed: 1, Repositories: []RepositoryReport{
+		{Repository: "https://github.com/test/repo", Status: RepositoryStatusFailed, Error: "clone failed"},
+	}}
+	if err := vtm.writeReport(context.Background(), OutputTarget{Path: StdoutOutputPath, Format: OutputFormatText}, report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "https://github.com/test/repo") {
+		t.Errorf("Expected the text report on stdout, got %q", stdout.String())
+	}
+}
+
+func TestRenderReports(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.Formatter.OmitTimestamp = true
+
+	end := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	format := vtm.Formatter.CreateStandardFormat(
+		"https://github.com/test/repo",
+		end.AddDate(0, 0, -7),
+		end,
+		CommitInfo{Hash: "a1b2c3d4", Message: "Add feature", Author: "Alice", Date: end},
+		WeeklySummary{TotalCommits: 1, TotalLinesChanged: 10, ActiveContributors: 1},
+		[]Contributor{{Name: "Alice", CommitCount: 1, Rank: 1}},
+		[]CommitDetail{{Hash: "a1b2c3d4", Message: "Add feature", Author: "Alice", Date: end}},
+	)
+	cloneErr := errors.New("clone failed")
+	report := &RunReport{
+		Total:      3,
+		Successful: 1,
+		Failed:     1,
+		Skipped:    1,
+		Repositories: []RepositoryReport{
+			{Repository: "https://github.com/test/repo", Status: RepositoryStatusSuccess, ReleaseNotes: &format, Text: vtm.Formatter.FormatReleaseNote(format)},
+			{Repository: "https://github.com/test/broken", Status: RepositoryStatusFailed, Error: cloneErr.Error(), err: cloneErr},
+			{Repository: "https://github.com/test/huge", Status: RepositoryStatusSkipped, SkipReason: "too large"},
+		},
+	}
+
+	text, err := vtm.renderTextReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, section := range []string{"Repository: https://github.com/test/repo", "=== ERROR PROCESSING REPOSITORY ===", "=== REPOSITORY SKIPPED ===", "Success Rate: 33.3%"} {
+		if !strings.Contains(string(text), section) {
+			t.Errorf("Expected %q in text output", section)
+		}
+	}
+
+	data, err := vtm.renderJSONReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded RunReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if decoded.Repositories[0].ReleaseNotes.LatestCommit.Hash != "a1b2c3d4" {
+		t.Errorf("Expected latest commit hash in JSON output")
+	}
+	if decoded.Repositories[1].Error != "clone failed" {
+		t.Errorf("Expected error message in JSON output, got %q", decoded.Repositories[1].Error)
+	}
+
+	data, err = vtm.renderJSONArrayReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var repositories []RepositoryReport
+	if err := json.Unmarshal(data, &repositories); err != nil {
+		t.Fatalf("Expected a JSON array of repositories: %v", err)
+	}
+	if len(repositories) != 3 || repositories[0].ReleaseNotes == nil || repositories[1].Error != "clone failed" || repositories[2].SkipReason != "too large" {
+		t.Errorf("Unexpected repositories in the JSON array: %s", data)
+	}
+	if data, err := vtm.renderJSONArrayReport(&RunReport{}); err != nil || strings.TrimSpace(string(data)) != "[]" {
+		t.Errorf("Expected an empty array without repositories, got %q (%v)", data, err)
+	}
+
+	markdown, err := vtm.renderMarkdownReport(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, section := range []string{"## repo", "- Add feature (`a1b2c3d4`) by Alice on 2024-03-01", "**Error:** clone failed", "**Skipped:** too large", "| Success Rate | 33.3% |"} {
+		if !strings.Contains(string(markdown), section) {
+			t.Errorf("Expected %q in Markdown output", section)
+		}
+	}
+}
+
+func TestRenderEmptyReport(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	vtm.Formatter.OmitTimestamp = true
+	report := &RunReport{}
+
+	if rate := report.SuccessRate(); rate != 0 {
+		t.Errorf("Expected a 0%% success rate without repositories, got %v", rate)
+	}
+	for name, render := range map[string]func(*RunReport) ([]byte, error){
+		"text":     vtm.renderTextReport,
+		"HTML":     vtm.renderHTMLReport,
+		"Markdown": vtm.renderMarkdownReport,
+	} {
+		data, err := render(report)
+		if err != nil {
+			t.Fatalf("Unexpected error rendering %s: %v", name, err)
+		}
+		if strings.Contains(string(data), "NaN") {
+			t.Errorf("Expected no NaN in the %s output of an empty run", name)
+		}
+		if !strings.Contains(string(data), "0.0%") {
+			t.Errorf("Expected a 0.0%% success rate in the %s output", name)
+		}
+	}
+}
+
+func TestMarkdownEscapesRepository(t *testing.T) {
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	var output strings.Builder
+	vtm.writeMarkdownRepository(&output, RepositoryReport{
+		Repository: "https://github.com/test/my_repo<x>",
+		Status:     RepositoryStatusSkipped,
+		SkipReason: "too *large*",
+	}, "##")
+
+	markdown := output.String()
+	for _, expected := range []string{"Repository: https://github.com/test/my\\_repo&lt;x&gt;", "**Skipped:** too \\*large\\*"} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in Markdown output, got:\n%s", expected, markdown)
+		}
+	}
+}
+
+func TestProcessRepositoriesResults(t *testing.T) {
+	sourceDir := t.TempDir()
+	source, err := git.PlainInit(sourceDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, source, sourceDir, "a.txt", "Add feature")
+	bundlePath := filepath.Join(t.TempDir(), "example-operator.bundle")
+	writeBundle(t, source, bundlePath, plumbing.Master)
+	missingPath := filepath.Join(t.TempDir(), "missing-operator.bundle")
+
+	outputDir := t.TempDir()
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(outputDir, "notes.txt"), false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+
+	results, err := vtm.ProcessRepositories(context.Background(), []string{bundlePath, missingPath})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Repository != bundlePath || results[0].Err != nil || results[0].ReleaseNotes == nil {
+		t.Fatalf("Expected release notes for %s, got %+v", bundlePath, results[0])
+	}
+	if results[0].ReleaseNotes.WeeklySummary.TotalCommits != 1 {
+		t.Errorf("Expected 1 commit in the returned notes, got %d", results[0].ReleaseNotes.WeeklySummary.TotalCommits)
+	}
+	if results[1].Repository != missingPath || results[1].Err == nil || results[1].ReleaseNotes != nil {
+		t.Errorf("Expected an error for %s, got %+v", missingPath, results[1])
+	}
+
+	// The files are still written alongside the returned results
+	if _, err := os.Stat(filepath.Join(outputDir, "notes.txt")); err != nil {
+		t.Errorf("Expected the text notes to be written: %v", err)
+	}
+}
+
+func TestProcessRepositoriesConcurrently(t *testing.T) {
+	// Forks share the name "operator", so workers sharing a clone directory would mix them up
+	var repositories []string
+	for i := 0; i < 5; i++ {
+		dir := filepath.Join(t.TempDir(), "operator")
+		repo, err := git.PlainInit(dir, false)
+		if err != nil {
+			t.Fatalf("Failed to init repository: %v", err)
+		}
+		for commit := 0; commit <= i; commit++ {
+			commitFile(t, repo, dir, fmt.Sprintf("file%d.txt", commit), fmt.Sprintf("Commit %d", commit))
+		}
+		repositories = append(repositories, dir)
+	}
+	missing := filepath.Join(t.TempDir(), "operator")
+	repositories = append(repositories[:2], append([]string{missing}, repositories[2:]...)...)
+
+	out := t.TempDir()
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(out, "notes.txt"), false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+	vtm.Logger.SetOutput(io.Discard)
+	vtm.NoCloneProgress = true
+	vtm.Concurrency = 3
+	vtm.Outputs = []OutputTarget{{Path: filepath.Join(out, "notes.json"), Format: OutputFormatJSON}}
+
+	results, err := vtm.ProcessRepositories(context.Background(), repositories)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	commits := 0
+	for i, result := range results {
+		if result.Repository != repositories[i] {
+			t.Fatalf("Expected results in input order, got %s at %d", result.Repository, i)
+		}
+		if result.Repository == missing {
+			if result.Err == nil {
+				t.Errorf("Expected an error for the missing repository")
+			}
+			continue
+		}
+		commits++
+		if result.Err != nil || result.ReleaseNotes.WeeklySummary.TotalCommits != commits {
+			t.Errorf("Expected %d commits for %s, got %+v", commits, result.Repository, result)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "notes.json"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Total != 6 || report.Successful != 5 || report.Failed != 1 {
+		t.Errorf("Expected 5 successful and 1 failed of 6, got %d/%d of %d", report.Successful, report.Failed, report.Total)
+	}
+}
+
+func TestRenderJSONArrayReportNotes(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	end := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+	format := formatter.CreateStandardFormat(
+		"https://github.com/test/repo",
+		end.AddDate(0, 0, -7),
+		end,
+		CommitInfo{Hash: "a1b2c3d4", Message: "Add feature", Author: "Alice", Date: end},
+		WeeklySummary{TotalCommits: 1, TotalLinesChanged: 10, ActiveContributors: 1},
+		[]Contributor{{Name: "Alice", CommitCount: 1, Rank: 1}},
+		[]CommitDetail{{Hash: "a1b2c3d4", Message: "Add feature", Author: "Alice", Date: end}},
+	)
+
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(t.TempDir(), "notes.txt"), false)
+	output, err := vtm.renderJSONArrayReport(&RunReport{Repositories: []RepositoryReport{
+		{Repository: "https://github.com/test/repo", Status: RepositoryStatusSuccess, ReleaseNotes: &format},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var repositories []map[string]interface{}
+	if err := json.Unmarshal(output, &repositories); err != nil || len(repositories) != 1 {
+		t.Fatalf("Expected a JSON array with one repository, got %v:\n%s", err, output)
+	}
+	decoded, _ := repositories[0]["releaseNotes"].(map[string]interface{})
+	for _, field := range []string{"repositoryInfo", "analysisPeriod", "latestCommit", "weeklySummary", "contributors", "commits"} {
+		if _, ok := decoded[field]; !ok {
+			t.Errorf("Expected %q in the JSON notes", field)
+		}
+	}
+	date := decoded["latestCommit"].(map[string]interface{})["date"].(string)
+	if parsed, err := time.Parse(time.RFC3339, date); err != nil || !parsed.Equal(end) {
+		t.Errorf("Expected an RFC 3339 timestamp, got %q (%v)", date, err)
+	}
+}
@@ -1,36 +1,107 @@
 package pkg
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// DefaultHTMLMaxCommits is the number of commits listed in the HTML notes when no cap is configured
+const DefaultHTMLMaxCommits = 50
+
+// maxHTMLCommitsLimit bounds the commit cap a request may ask for
+const maxHTMLCommitsLimit = 1000
+
+// DefaultIndexCacheTTL is how long a refresh of an unchanged index image reuses its parsed
+// repositories instead of rendering and parsing the index again
+const DefaultIndexCacheTTL = 5 * time.Minute
+
 // Server represents the web server for the analyzer
 type Server struct {
+	Host           string // Interface to bind to; empty binds to all interfaces
 	Port           int
 	WorkDir        string
 	OutputDir      string
 	Repositories   []string
+	RepositoryInfo map[string]ParserRepositoryInfo
+	NotablePatterns []string // File globs whose changes are highlighted as notable
+	ReportOpenPRs   bool     // Look up the open pull/merge request count through the provider API
+	IgnoreWhitespace bool    // Count changed lines with a whitespace-insensitive diff instead of Stats()
+	ExcludeExtensions []string // File extensions whose lines are not counted as changed (e.g. "svg", "min.js")
+	OnlyAuthors    []string  // Author names or emails whose commits are analyzed; empty analyzes every author
+	VendorPatterns []string  // File globs of vendored dependencies; commits only touching them are listed separately
+	RankBy         RankBy    // Metric used to rank contributors; empty ranks by commit count
+	DateMode       DateMode  // Commit date that places commits in the window and is shown for them; empty uses the committer date
+	StatsWorkers   int       // Commits whose changes are computed concurrently per repository
+	ShowAvatars    bool      // Resolve contributor avatars for GitHub repositories (may call the GitHub API)
+	ActivityHeatmap bool     // Add a histogram of commits by weekday and hour (in the local time zone)
+	HTMLMaxCommits int       // Commits listed in the HTML notes; the text notes keep the formatter's MaxCommits
+	CommitURLs     CommitURLTemplates // Commit link templates; the zero value links to {base}/commit/{hash}
+	AllowedHosts   []string  // Git hosts repositories may be cloned from; empty allows every host
+	LineEnding     string    // Line ending of the text notes, LineEndingLF or LineEndingCRLF
+	MaxSubjectLength int     // Cut commit subjects in the text notes to this many characters; 0 keeps full messages
+	IndexPath      string    // Where the rendered index JSON is written; empty uses WorkDir/prega-operator-index/index.json
+	IndexCacheTTL  time.Duration // How long a refresh of the same image reuses its parsed index; 0 always re-renders
+	IdleTimeout    time.Duration // How long idle keep-alive connections stay open; 0 keeps them open indefinitely
+	HTTP2MaxConcurrentStreams uint32 // Requests in flight per HTTP/2 connection; 0 uses the HTTP/2 package default
+	H2C            bool      // Also accept HTTP/2 in cleartext, for use behind a TLS-terminating proxy
+	CloneConcurrency int     // Clones and analyses running at once across all requests; 0 leaves them unbounded
+	CloneQueueWait time.Duration // How long a request waits for a clone slot before getting a "server busy" error
+	CloneDepth     int       // Commits of history fetched per analysis clone unless the request sets a depth; 0 clones full history
+	Credentials    *GitCredentials // Sent to HTTP(S) remotes on their hosts when cloning unless the request has a token; read from GIT_TOKEN, GIT_USERNAME and GIT_TOKEN_HOSTS by NewServer
+	RepoCacheMaxEntries int  // Cached clones kept under WorkDir/cache; the least recently used are evicted past it. 0 keeps every clone
+	CacheClearToken string   // Bearer token /api/cache/clear requires; read from CACHE_CLEAR_TOKEN by NewServer. Empty disables the endpoint
 	PregaIndex     string
+	Deps           *DependencyManager // Finds or downloads the opm that renders index images
 	Logger         *logrus.Logger
 	mu             sync.Mutex
 	cachedData     *CachedData
 	lastCacheTime  time.Time
 	cacheDuration  time.Duration
+	branchCache    map[branchCacheKey]cachedBranches // Repository URL and request token -> branch list, so paging doesn't list again
+	repoCache      map[string]*repoCacheEntry // Repository URL -> clone kept under WorkDir/cache between requests
+	indexCache     map[string]parsedIndex    // Index image -> repositories parsed from its render
+	renderedImage  string                    // Index image whose render is currently at IndexJSONPath
+	activityCache  map[string]cachedActivity // Repository URL -> default branch tip, its commit and commit date
+	cloneSlots     chan struct{}             // Holds a token per clone in flight, sized CloneConcurrency
+	cloneSlotsOnce sync.Once
+}
+
+// parsedIndex is the repository list parsed from an index image's render and when it was parsed
+type parsedIndex struct {
+	repositories []string
+	infos        []ParserRepositoryInfo
+	parsedAt     time.Time
+}
+
+// cachedBranches is a repository's sorted branch list and when it was fetched
+type cachedBranches struct {
+	branches      []string
+	defaultBranch string // Branch the remote HEAD points at, "" when the remote doesn't say
+	fetchedAt     time.Time
 }
 
 // CachedData holds cached repository and branch information
@@ -41,10 +112,15 @@ type CachedData struct {
 
 // RepositoryData holds repository information with branches
 type RepositoryData struct {
-	URL         string   `json:"url"`
-	Name        string   `json:"name"`
-	Branches    []string `json:"branches"`
-	Description string   `json:"description,omitempty"`
+	URL            string   `json:"url"`
+	Name           string   `json:"name"`
+	Branches       []string `json:"branches"`
+	Description    string   `json:"description,omitempty"`
+	Package        string   `json:"package,omitempty"`
+	DefaultChannel string   `json:"defaultChannel,omitempty"`
+	Channels       []string `json:"channels,omitempty"`
+	LastActivity   *time.Time `json:"lastActivity,omitempty"` // Commit date of the default branch tip, with activity=true
+	DefaultBranch  string   `json:"defaultBranch,omitempty"` // Branch the remote HEAD points at, with activity=true
 }
 
 // ReleaseNotesRequest represents a request for release notes
@@ -52,6 +128,72 @@ type ReleaseNotesRequest struct {
 	Repository string `json:"repository"`
 	Branch     string `json:"branch"`
 	Days       int    `json:"days"`
+	Since      string `json:"since,omitempty"`    // Optional start date (YYYY-MM-DD); overrides Days
+	Until      string `json:"until,omitempty"`    // Optional inclusive end date (YYYY-MM-DD); defaults to now
+	Upstream   string `json:"upstream,omitempty"` // Optional upstream URL to compare a fork against
+	MaxCommits int    `json:"maxCommits,omitempty"` // Optional cap on the HTML commit list; defaults to the server's HTMLMaxCommits
+	Depth      int    `json:"depth,omitempty"`      // Optional clone depth in commits; defaults to the server's CloneDepth
+	Token      string `json:"token,omitempty"`      // Optional access token for cloning a private repository; overrides the server's GIT_TOKEN
+	FromTag    string `json:"fromTag,omitempty"`    // With ToTag, cover the commits between the two tags instead of Days/Since/Until
+	ToTag      string `json:"toTag,omitempty"`
+	Page       int    `json:"page,omitempty"`       // Optional page of the commit list, from 1; the text notes then list the same page
+	PageSize   int    `json:"pageSize,omitempty"`   // Optional commits per page; defaults to MaxCommits
+}
+
+// paginated reports whether the request asks for a page of the commit list
+func (req ReleaseNotesRequest) paginated() bool {
+	return req.Page > 0 || req.PageSize > 0
+}
+
+// commitPage returns the page of a list of total commits the request covers: page Page
+// (default 1) of PageSize commits, defaulting to MaxCommits
+func (req ReleaseNotesRequest) commitPage(total int) CommitPage {
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = req.MaxCommits
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultHTMLMaxCommits
+	}
+	return NewCommitPage(req.Page, pageSize, total)
+}
+
+// analysisWindow returns the commit window for a request. With Since the window is the
+// absolute range Since..Until (Until inclusive, defaulting to now); otherwise it is the last
+// Days days, ending at Until when given.
+func (req ReleaseNotesRequest) analysisWindow(now time.Time) (since, until time.Time, days int, absolute bool, err error) {
+	until = now
+	if req.Until != "" {
+		untilDate, err := time.ParseInLocation("2006-01-02", req.Until, now.Location())
+		if err != nil {
+			return since, until, 0, false, fmt.Errorf("invalid until date %q (expected YYYY-MM-DD)", req.Until)
+		}
+		// Include the whole end day
+		until = untilDate.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	if req.Since == "" {
+		return until.AddDate(0, 0, -req.Days), until, req.Days, req.Until != "", nil
+	}
+
+	since, err = time.ParseInLocation("2006-01-02", req.Since, now.Location())
+	if err != nil {
+		return since, until, 0, false, fmt.Errorf("invalid since date %q (expected YYYY-MM-DD)", req.Since)
+	}
+	if !since.Before(until) {
+		return since, until, 0, false, fmt.Errorf("since date %s must be before until date %s", req.Since, until.Format("2006-01-02"))
+	}
+	if since.After(now) {
+		return since, until, 0, false, fmt.Errorf("since date %s is in the future", req.Since)
+	}
+	// Count calendar days so DST changes inside the range don't shift the total
+	sinceDay := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+	untilDay := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, time.UTC)
+	days = int(untilDay.Sub(sinceDay).Hours()/24) + 1
+	if days > 366 {
+		return since, until, 0, false, fmt.Errorf("date range of %d days is longer than a year", days)
+	}
+	return since, until, days, true, nil
 }
 
 // ReleaseNotesResponse represents the response with release notes
@@ -62,6 +204,14 @@ type ReleaseNotesResponse struct {
 	Repository   string `json:"repository"`
 	Branch       string `json:"branch"`
 	Days         int    `json:"days"`
+	Since        string `json:"since,omitempty"`
+	Until        string `json:"until,omitempty"`
+	FromTag      string `json:"fromTag,omitempty"`
+	ToTag        string `json:"toTag,omitempty"`
+	TotalCommits int    `json:"totalCommits,omitempty"` // Commits listed over all pages
+	Page         int    `json:"page,omitempty"`         // Page of the commit list the notes show
+	PageSize     int    `json:"pageSize,omitempty"`
+	TotalPages   int    `json:"totalPages,omitempty"`
 	ErrorMessage string `json:"errorMessage,omitempty"`
 }
 
@@ -76,16 +226,30 @@ func NewServer(port int, workDir, outputDir, pregaIndex string, logger *logrus.L
 		WorkDir:       workDir,
 		OutputDir:     outputDir,
 		PregaIndex:    pregaIndex,
+		Deps:          NewDependencyManager(".bin", logger),
 		Logger:        logger,
+		StatsWorkers:  DefaultStatsWorkers,
+		HTMLMaxCommits: DefaultHTMLMaxCommits,
+		VendorPatterns: DefaultVendorPatterns,
+		IndexCacheTTL: DefaultIndexCacheTTL,
+		IdleTimeout:   DefaultIdleTimeout,
+		HTTP2MaxConcurrentStreams: DefaultHTTP2MaxConcurrentStreams,
+		CloneQueueWait: DefaultCloneQueueWait,
+		Credentials:   GitCredentialsFromEnv(),
+		RepoCacheMaxEntries: DefaultRepoCacheMaxEntries,
+		CacheClearToken: os.Getenv(CacheClearTokenEnv),
 		cacheDuration: 5 * time.Minute,
 	}
 }
 
 // Start starts the web server
 func (s *Server) Start() error {
-	// Create directories
-	os.MkdirAll(s.WorkDir, 0755)
-	os.MkdirAll(s.OutputDir, 0755)
+	// Create directories, failing before the first clone when one is not writable
+	for _, dir := range []string{s.WorkDir, s.OutputDir} {
+		if err := ensureWritableDir(dir); err != nil {
+			return err
+		}
+	}
 
 	// Set up routes
 	mux := http.NewServeMux()
@@ -94,14 +258,44 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/", s.handleIndex)
 	mux.HandleFunc("/api/repositories", s.handleRepositories)
 	mux.HandleFunc("/api/branches", s.handleBranches)
+	mux.HandleFunc("/api/tags", s.handleTags)
+	mux.HandleFunc("/api/cache/clear", s.handleCacheClear)
 	mux.HandleFunc("/api/release-notes", s.handleReleaseNotes)
+	mux.HandleFunc("/api/release-notes/stream", s.handleReleaseNotesStream)
 	mux.HandleFunc("/api/refresh", s.handleRefresh)
+	mux.HandleFunc("/api/index-json", s.handleIndexJSON)
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/health", s.handleHealth)
+	mux.HandleFunc("/api/ready", s.handleReady)
 	mux.HandleFunc("/api/commit-summary", s.handleCommitSummary)
+	mux.HandleFunc("/api/catalog-diff", s.handleCatalogDiff)
+	mux.HandleFunc("/api/feed.xml", s.handleFeed)
 
-	s.Logger.Infof("Starting web server on port %d", s.Port)
-	s.Logger.Infof("Access the web interface at: http://localhost:%d", s.Port)
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	s.Logger.Infof("Starting web server on %s", addr)
+	s.Logger.Infof("Access the web interface at: http://%s", net.JoinHostPort(s.displayHost(), strconv.Itoa(s.Port)))
 	
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.Port), mux)
+	server, err := s.newHTTPServer(addr, traceRequests(mux))
+	if err != nil {
+		return err
+	}
+	return server.ListenAndServe()
+}
+
+// displayHost returns the host to show in the access URL, using localhost when bound to all interfaces
+func (s *Server) displayHost() string {
+	if s.Host == "" || s.Host == "0.0.0.0" || s.Host == "::" {
+		return "localhost"
+	}
+	return s.Host
+}
+
+// IndexJSONPath returns the path of the rendered index JSON that refreshes write and parse
+func (s *Server) IndexJSONPath() string {
+	if s.IndexPath != "" {
+		return s.IndexPath
+	}
+	return filepath.Join(s.WorkDir, "prega-operator-index", "index.json")
 }
 
 // SetRepositories sets the list of repositories
@@ -109,6 +303,17 @@ func (s *Server) SetRepositories(repos []string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Repositories = repos
+	s.lastCacheTime = time.Now()
+}
+
+// SetRepositoryInfo sets the catalog metadata (package and channels) for the repositories
+func (s *Server) SetRepositoryInfo(infos []ParserRepositoryInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RepositoryInfo = make(map[string]ParserRepositoryInfo, len(infos))
+	for _, info := range infos {
+		s.RepositoryInfo[info.URL] = info
+	}
 }
 
 // handleIndex serves the main HTML page
@@ -123,21 +328,45 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	tmpl.Execute(w, nil)
 }
 
-// handleRepositories returns the list of repositories
+// handleRepositories returns the list of repositories. channels=true adds the catalog
+// metadata and activity=true the commit date of each default branch tip.
 func (s *Server) handleRepositories(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	
+	includeChannels := r.URL.Query().Get("channels") == "true"
+	// Activity costs a network round-trip per repository, so it is only looked up on request
+	includeActivity := r.URL.Query().Get("activity") == "true"
+
 	s.mu.Lock()
 	repos := s.Repositories
+	repoInfo := s.RepositoryInfo
 	s.mu.Unlock()
 
+	var activity map[string]time.Time
+	if includeActivity {
+		activity = s.repositoryActivity(r.Context(), repos)
+	}
+
 	var repoData []RepositoryData
 	for _, repo := range repos {
 		name := extractRepoNameFromURL(repo)
-		repoData = append(repoData, RepositoryData{
+		data := RepositoryData{
 			URL:  repo,
 			Name: name,
-		})
+		}
+		if includeChannels {
+			if info, ok := repoInfo[repo]; ok {
+				data.Package = info.Name
+				data.Description = info.Description
+				data.DefaultChannel = info.DefaultChannel
+				data.Channels = info.Channels
+			}
+		}
+		if when, ok := activity[repo]; ok {
+			data.LastActivity = &when
+			data.DefaultBranch = s.defaultBranch(repo)
+		}
+		repoData = append(repoData, data)
 	}
 
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -146,7 +375,10 @@ func (s *Server) handleRepositories(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleBranches returns the branches for a repository
+// handleBranches returns the branches for a repository. The optional query parameter keeps
+// branches whose name contains it (case-insensitive) and limit/offset select a page of the
+// matches; total is the number of matching branches so clients can page through all of them.
+// Without limit every matching branch is returned.
 func (s *Server) handleBranches(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	
@@ -158,10 +390,25 @@ func (s *Server) handleBranches(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if err := serverRepositoryError(s.AllowedHosts, repoURL); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
 
-	branches, err := s.fetchBranches(repoURL)
+	query := r.URL.Query()
+	offset, err := nonNegativeQueryInt(query, "offset")
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	limit, err := nonNegativeQueryInt(query, "limit")
 	if err != nil {
-		s.Logger.Errorf("Failed to fetch branches for %s: %v", repoURL, err)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   err.Error(),
@@ -169,40 +416,109 @@ func (s *Server) handleBranches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	list, err := s.cachedBranchList(r.Context(), repoURL, r.Header.Get(GitTokenHeader))
+	if err != nil {
+		s.Logger.Errorf("Failed to fetch branches for %s: %v", repoURL, err)
+		s.writeBusyStatus(w, err)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   ErrorWithRemediation(err),
+		})
+		return
+	}
+
+	page, total := pageBranches(list.branches, query.Get("query"), offset, limit)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":  true,
-		"branches": branches,
+		"success":       true,
+		"branches":      page,
+		"total":         total,
+		"offset":        offset,
+		"defaultBranch": list.defaultBranch,
 	})
 }
 
+// nonNegativeQueryInt parses an optional non-negative integer query parameter; a missing
+// parameter is 0
+func nonNegativeQueryInt(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+	}
+	return n, nil
+}
+
+// pageBranches filters branches to those containing query (case-insensitive) and returns the
+// page starting at offset with at most limit entries (0 for no limit), along with the number
+// of matching branches
+func pageBranches(branches []string, query string, offset, limit int) ([]string, int) {
+	matches := branches
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		matches = nil
+		for _, branch := range branches {
+			if strings.Contains(strings.ToLower(branch), query) {
+				matches = append(matches, branch)
+			}
+		}
+	}
+
+	total := len(matches)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	page := make([]string, 0, end-offset)
+	return append(page, matches[offset:end]...), total
+}
+
 // handleReleaseNotes generates release notes for a repository
 func (s *Server) handleReleaseNotes(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if r.Method != http.MethodPost {
+	req, err := s.decodeReleaseNotesRequest(r)
+	if err != nil {
 		json.NewEncoder(w).Encode(ReleaseNotesResponse{
 			Success:      false,
-			ErrorMessage: "POST method required",
+			ErrorMessage: err.Error(),
 		})
 		return
 	}
 
+	response, err := s.releaseNotesResponse(r.Context(), req, nil)
+	if err != nil {
+		s.writeBusyStatus(w, err)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// decodeReleaseNotesRequest reads and validates the JSON body of a release-notes request,
+// filling in the defaults of unset fields
+func (s *Server) decodeReleaseNotesRequest(r *http.Request) (ReleaseNotesRequest, error) {
 	var req ReleaseNotesRequest
+	if r.Method != http.MethodPost {
+		return req, errors.New("POST method required")
+	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		json.NewEncoder(w).Encode(ReleaseNotesResponse{
-			Success:      false,
-			ErrorMessage: "Invalid request body: " + err.Error(),
-		})
-		return
+		return req, errors.New("Invalid request body: " + err.Error())
 	}
 
 	// Validate request
 	if req.Repository == "" {
-		json.NewEncoder(w).Encode(ReleaseNotesResponse{
-			Success:      false,
-			ErrorMessage: "repository is required",
-		})
-		return
+		return req, errors.New("repository is required")
+	}
+	for _, repoURL := range []string{req.Repository, req.Upstream} {
+		if repoURL == "" {
+			continue
+		}
+		if err := serverRepositoryError(s.AllowedHosts, repoURL); err != nil {
+			return req, err
+		}
 	}
 	if req.Branch == "" {
 		req.Branch = "main"
@@ -213,33 +529,65 @@ func (s *Server) handleReleaseNotes(w http.ResponseWriter, r *http.Request) {
 	if req.Days > 365 {
 		req.Days = 365 // Cap at 1 year
 	}
-
-	// Generate release notes
-	htmlNotes, textNotes, err := s.generateReleaseNotesForBranch(req.Repository, req.Branch, req.Days)
-	if err != nil {
-		json.NewEncoder(w).Encode(ReleaseNotesResponse{
-			Success:      false,
-			Repository:   req.Repository,
-			Branch:       req.Branch,
-			Days:         req.Days,
-			ErrorMessage: err.Error(),
-		})
-		return
+	if req.MaxCommits <= 0 {
+		req.MaxCommits = s.HTMLMaxCommits
 	}
+	if req.MaxCommits > maxHTMLCommitsLimit {
+		req.MaxCommits = maxHTMLCommitsLimit
+	}
+	if req.Depth < 0 {
+		return req, errors.New("depth must not be negative")
+	}
+	if req.Page < 0 || req.PageSize < 0 {
+		return req, errors.New("page and pageSize must not be negative")
+	}
+	if req.PageSize > maxHTMLCommitsLimit {
+		req.PageSize = maxHTMLCommitsLimit
+	}
+	if (req.FromTag == "") != (req.ToTag == "") {
+		return req, errors.New("fromTag and toTag must be set together")
+	}
+	return req, nil
+}
 
-	json.NewEncoder(w).Encode(ReleaseNotesResponse{
-		Success:    true,
-		HTML:       htmlNotes,
-		Text:       textNotes,
+// releaseNotesResponse generates the release notes of a validated request, reporting its
+// stages to progress. On failure the response carries the error message and err the cause.
+func (s *Server) releaseNotesResponse(ctx context.Context, req ReleaseNotesRequest, progress ProgressFunc) (ReleaseNotesResponse, error) {
+	response := ReleaseNotesResponse{
 		Repository: req.Repository,
 		Branch:     req.Branch,
 		Days:       req.Days,
-	})
+		Since:      req.Since,
+		Until:      req.Until,
+		FromTag:    req.FromTag,
+		ToTag:      req.ToTag,
+	}
+
+	// Generate release notes
+	generate := s.generateReleaseNotesForBranch
+	if req.FromTag != "" {
+		generate = s.generateReleaseNotesBetweenTags
+	}
+	notes, err := generate(ctx, req, progress)
+	if err != nil {
+		response.ErrorMessage = ErrorWithRemediation(err)
+		return response, err
+	}
+
+	response.Success = true
+	response.HTML = notes.html
+	response.Text = notes.text
+	response.TotalCommits = notes.page.Total
+	response.Page = notes.page.Page
+	response.PageSize = notes.page.PageSize
+	response.TotalPages = notes.page.TotalPages()
+	return response, nil
 }
 
 // RefreshRequest represents a request to refresh repositories
 type RefreshRequest struct {
 	IndexImage string `json:"indexImage"`
+	Force      bool   `json:"force,omitempty"` // Render and parse the index even when a cached parse is fresh
 }
 
 // handleRefresh refreshes the repository list from the Prega index
@@ -275,10 +623,29 @@ func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	s.mu.Unlock()
 
 	// Re-generate index and reload repositories
-	indexPath := filepath.Join(s.WorkDir, "prega-operator-index", "index.json")
-	
+	indexPath := s.IndexJSONPath()
+
+	if cached, ok := s.cachedIndex(indexImage, req.Force); ok {
+		s.Logger.Infof("Using the index of %s parsed at %s", indexImage, cached.parsedAt.Format(time.RFC3339))
+		s.SetRepositories(cached.repositories)
+		if cached.infos != nil {
+			s.SetRepositoryInfo(cached.infos)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"cached":      true,
+			"count":       len(cached.repositories),
+			"indexImage":  indexImage,
+			"indexPath":   indexPath,
+			"indexUrl":    "/api/index-json",
+			"message":     fmt.Sprintf("Successfully refreshed %d repositories from %s (cached)", len(cached.repositories), indexImage),
+		})
+		return
+	}
+	s.invalidateIndexCache(indexImage)
+
 	// Generate index with the specified image
-	if err := s.generateIndexJSON(indexPath); err != nil {
+	if err := s.generateIndexJSON(r.Context(), indexPath); err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to generate index: " + err.Error(),
@@ -287,7 +654,9 @@ func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse repositories
+	_, parseSpan := StartSpan(r.Context(), "index.parse", attribute.String("index.path", indexPath))
 	repos, err := ParseOperatorIndex(indexPath)
+	EndSpan(parseSpan, err)
 	if err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
@@ -296,73 +665,260 @@ func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	uniqueRepos := RemoveDuplicates(repos)
+	uniqueRepos := FilterAllowedHosts(RemoveDuplicates(repos), s.AllowedHosts, s.Logger)
 	s.SetRepositories(uniqueRepos)
 
+	// Channel metadata is optional; the flat list still works without it
+	infos, err := ParseOperatorIndexDetailed(indexPath)
+	if err == nil {
+		s.SetRepositoryInfo(infos)
+	} else {
+		s.Logger.Debugf("Failed to parse channel metadata: %v", err)
+	}
+	s.cacheIndex(indexImage, parsedIndex{repositories: uniqueRepos, infos: infos, parsedAt: time.Now()})
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":     true,
+		"cached":      false,
 		"count":       len(uniqueRepos),
 		"indexImage":  indexImage,
+		"indexPath":   indexPath,
+		"indexUrl":    "/api/index-json",
 		"message":     fmt.Sprintf("Successfully refreshed %d repositories from %s", len(uniqueRepos), indexImage),
 	})
 }
 
-// fetchBranches fetches all branches from a repository
-func (s *Server) fetchBranches(repoURL string) ([]string, error) {
-	repoName := extractRepoNameFromURL(repoURL)
-	repoPath := filepath.Join(s.WorkDir, "branch-check", repoName)
-	
-	// Remove existing and clone fresh
-	os.RemoveAll(repoPath)
-	os.MkdirAll(filepath.Dir(repoPath), 0755)
+// StatusResponse reports the server's effective configuration and when its repository list was last loaded
+type StatusResponse struct {
+	Success         bool       `json:"success"`
+	PregaIndex      string     `json:"pregaIndex"`
+	RepositoryCount int        `json:"repositoryCount"`
+	LastRefresh     *time.Time `json:"lastRefresh,omitempty"`
+	CacheAgeSeconds *int64     `json:"cacheAgeSeconds,omitempty"`
+	WorkDir         string     `json:"workDir"`
+	OutputDir       string     `json:"outputDir"`
+	IndexPath       string     `json:"indexPath"`
+	Build           BuildInfo  `json:"build"`
+}
 
-	_, err := git.PlainClone(repoPath, false, &git.CloneOptions{
-		URL:          repoURL,
-		NoCheckout:   true,
-		SingleBranch: false,
-	})
+// handleStatus returns the server configuration and repository cache age
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s.mu.Lock()
+	status := StatusResponse{
+		Success:         true,
+		PregaIndex:      s.PregaIndex,
+		RepositoryCount: len(s.Repositories),
+		WorkDir:         s.WorkDir,
+		OutputDir:       s.OutputDir,
+		IndexPath:       s.IndexJSONPath(),
+		Build:           GetBuildInfo(),
+	}
+	if !s.lastCacheTime.IsZero() {
+		lastRefresh := s.lastCacheTime
+		age := int64(time.Since(lastRefresh).Seconds())
+		status.LastRefresh = &lastRefresh
+		status.CacheAgeSeconds = &age
+	}
+	s.mu.Unlock()
+
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleCatalogDiff renders the old and new index images and reports the operators added,
+// removed or moved to another head version between them
+func (s *Server) handleCatalogDiff(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	oldImage := strings.TrimSpace(r.URL.Query().Get("old"))
+	newImage := strings.TrimSpace(r.URL.Query().Get("new"))
+	if oldImage == "" || newImage == "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "old and new parameters are required",
+		})
+		return
+	}
+	for _, image := range []string{oldImage, newImage} {
+		if err := validateImageReference(image); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	// Rendering two catalogs is as heavy as a clone, so it shares the clone slots
+	release, err := s.acquireCloneSlot(r.Context())
 	if err != nil {
-		return nil, fmt.Errorf("failed to clone repository: %w", err)
+		s.writeBusyStatus(w, err)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	s.Logger.Infof("Comparing catalogs %s and %s", oldImage, newImage)
+	diff, err := DiffIndexImages(r.Context(), s.Deps, oldImage, newImage, s.WorkDir)
+	release()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to compare catalogs: " + err.Error(),
+		})
+		return
 	}
-	defer os.RemoveAll(repoPath)
 
-	repo, err := git.PlainOpen(repoPath)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"diff":    diff,
+		"text":    diff.FormatText(),
+	})
+}
+
+// handleIndexJSON serves the raw index JSON from the last refresh as a download
+func (s *Server) handleIndexJSON(w http.ResponseWriter, r *http.Request) {
+	indexPath := s.IndexJSONPath()
+	if _, err := os.Stat(indexPath); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "index JSON not available, refresh repositories first",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="index.json"`)
+	http.ServeFile(w, r, indexPath)
+}
+
+// fetchBranches fetches all branches from a repository and the default branch with the
+// request's own token, never the server's credentials, so a private repository's branches
+// are only listed for callers who can read it. GitHub repositories are listed through the API;
+// other hosts, and GitHub when the API fails, are listed with an ls-remote.
+func (s *Server) fetchBranches(ctx context.Context, repoURL, token string) ([]string, string, error) {
+	if owner, name, ok := parseGitHubRepo(repoURL); ok {
+		branches, defaultBranch, err := fetchGitHubBranches(owner, name, token)
+		if err == nil && len(branches) == 0 {
+			err = fmt.Errorf("no branches returned")
+		}
+		if err == nil {
+			sortBranches(branches, defaultBranch)
+			return branches, defaultBranch, nil
+		}
+		s.Logger.Debugf("Listing the branches of %s with an ls-remote, the GitHub API failed: %v", repoURL, err)
+	}
+
+	var auth transport.AuthMethod
+	if token != "" {
+		auth = cloneAuth(repoURL, s.requestCredentials(token, repoURL))
+	}
+	branches, defaultBranch, err := listRemoteBranches(ctx, repoURL, auth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open repository: %w", err)
+		return nil, "", err
+	}
+	if len(branches) == 0 {
+		return nil, "", fmt.Errorf("no branches found in repository %s", repoURL)
 	}
 
-	refs, err := repo.References()
+	sortBranches(branches, defaultBranch)
+	return branches, defaultBranch, nil
+}
+
+// branchCacheKey identifies a cached branch list by repository and by the token it was
+// fetched with, so a list only readable with one caller's token is never served to others
+type branchCacheKey struct {
+	repository string
+	credential string // Hash of the request's token, "" for anonymous lists
+}
+
+// newBranchCacheKey returns the cache key of the branch list of repoURL fetched with token
+func newBranchCacheKey(repoURL, token string) branchCacheKey {
+	key := branchCacheKey{repository: repoURL}
+	if token != "" {
+		key.credential = fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+	}
+	return key
+}
+
+// cachedBranchList returns the repository's sorted branches and default branch, reusing a list
+// fetched with the same token within the cache duration so paging and searching don't list
+// the repository again
+func (s *Server) cachedBranchList(ctx context.Context, repoURL, token string) (list cachedBranches, err error) {
+	ctx, span := StartSpan(ctx, "git.branches", attribute.String("repository", repoURL))
+	defer func() { EndSpan(span, err) }()
+
+	key := newBranchCacheKey(repoURL, token)
+	s.mu.Lock()
+	cached, ok := s.branchCache[key]
+	s.mu.Unlock()
+	fresh := ok && time.Since(cached.fetchedAt) < s.cacheDuration
+	span.SetAttributes(attribute.Bool("cache.hit", fresh))
+	if fresh {
+		return cached, nil
+	}
+
+	branches, defaultBranch, err := s.fetchBranches(ctx, repoURL, token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get references: %w", err)
+		return cachedBranches{}, err
 	}
 
-	var branches []string
-	branchSet := make(map[string]bool)
+	list = cachedBranches{branches: branches, defaultBranch: defaultBranch, fetchedAt: time.Now()}
+	s.mu.Lock()
+	if s.branchCache == nil {
+		s.branchCache = make(map[branchCacheKey]cachedBranches)
+	}
+	s.branchCache[key] = list
+	s.mu.Unlock()
+	return list, nil
+}
 
-	refs.ForEach(func(ref *plumbing.Reference) error {
-		name := ref.Name().String()
-		
-		// Filter for remote branches
-		if strings.HasPrefix(name, "refs/remotes/origin/") {
-			branchName := strings.TrimPrefix(name, "refs/remotes/origin/")
-			if branchName != "HEAD" {
-				branchSet[branchName] = true
-			}
-		}
-		return nil
+// listRemoteBranches lists the branches of a remote with an ls-remote, always including the
+// default branch that the remote HEAD points at
+func listRemoteBranches(ctx context.Context, repoURL string, auth transport.AuthMethod) ([]string, string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
 	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		if IsAuthError(err) {
+			return nil, "", NewAuthRequiredError(repoURL, err)
+		}
+		return nil, "", fmt.Errorf("failed to list references: %w", err)
+	}
 
-	for branch := range branchSet {
-		branches = append(branches, branch)
+	var branches []string
+	var defaultBranch string
+	for _, ref := range refs {
+		switch {
+		case ref.Name().IsBranch():
+			branches = append(branches, ref.Name().Short())
+		case ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch():
+			defaultBranch = ref.Target().Short()
+		}
 	}
+	return branches, defaultBranch, nil
+}
 
-	// Sort branches: main/master first, then release-* branches, then others
+// sortBranches orders branches with the default branch first, then main/master, then
+// release-* branches, then others
+func sortBranches(branches []string, defaultBranch string) {
 	sort.Slice(branches, func(i, j int) bool {
 		bi, bj := branches[i], branches[j]
 		
+		// The default branch leads even when it is neither main nor master
+		if bi == defaultBranch || bj == defaultBranch {
+			return bi == defaultBranch && bj != defaultBranch
+		}
+		
 		// Prioritize main/master
 		if bi == "main" || bi == "master" {
-			return true
+			return (bj != "main" && bj != "master") || (bi == "main" && bj == "master")
 		}
 		if bj == "main" || bj == "master" {
 			return false
@@ -386,25 +942,68 @@ func (s *Server) fetchBranches(repoURL string) ([]string, error) {
 		
 		return bi < bj
 	})
+}
 
-	return branches, nil
+// requestCredentials returns the credentials a request for repoURL clones with: its own token
+// when it has one, sent with the server's user name to repoURL's host only, else the server's
+// credentials
+func (s *Server) requestCredentials(token, repoURL string) *GitCredentials {
+	if token == "" {
+		return s.Credentials
+	}
+	username := ""
+	if s.Credentials != nil {
+		username = s.Credentials.Auth.Username
+	}
+	return &GitCredentials{Auth: gitCredentials(username, token), Hosts: []string{repositoryHost(repoURL)}}
 }
 
-// generateReleaseNotesForBranch generates release notes for a specific branch and period
-func (s *Server) generateReleaseNotesForBranch(repoURL, branch string, days int) (string, string, error) {
-	repoName := extractRepoNameFromURL(repoURL)
-	repoPath := filepath.Join(s.WorkDir, "analysis", repoName)
+// openBranchRepository returns a repository holding the request's branch, its path and the
+// branch tip. Requests without their own token or clone depth read the repository cache;
+// the others get a fresh clone of the branch. done releases the cached clone or removes the
+// fresh one.
+func (s *Server) openBranchRepository(ctx context.Context, req ReleaseNotesRequest, depth int, credentials *GitCredentials) (repo *git.Repository, repoPath string, head *plumbing.Reference, done func(), err error) {
+	repoURL, branch := req.Repository, req.Branch
+	if req.Token == "" && depth == 0 {
+		entry := s.lockCachedRepository(repoURL)
+		repo, err = s.syncCachedRepository(ctx, entry, repoURL, false)
+		if err == nil {
+			head, err = cachedBranchHead(repo, branch)
+			if err != nil {
+				// The branch may have been pushed since the clone was last fetched
+				if repo, err = s.syncCachedRepository(ctx, entry, repoURL, true); err == nil {
+					head, err = cachedBranchHead(repo, branch)
+				}
+			}
+		}
+		if err != nil {
+			entry.mu.Unlock()
+			return nil, "", nil, nil, err
+		}
+		return repo, entry.path, head, entry.mu.Unlock, nil
+	}
+
+	// Each branch gets its own clone so requests for several branches of a repository don't
+	// share a directory; the segment keeps names like feature/foo out of the path structure
+	repoPath = filepath.Join(s.WorkDir, "analysis", extractRepoNameFromURL(repoURL), NewBranchPath(branch).Segment)
 	
 	// Remove existing and clone fresh
 	os.RemoveAll(repoPath)
 	os.MkdirAll(filepath.Dir(repoPath), 0755)
+	done = func() {
+		os.RemoveAll(repoPath)
+		os.Remove(filepath.Dir(repoPath)) // Only removed once no other branch of the repository is being analyzed
+	}
 
 	s.Logger.Infof("Cloning %s (branch: %s) for analysis...", repoURL, branch)
 
-	_, err := git.PlainClone(repoPath, false, &git.CloneOptions{
+	_, cloneSpan := StartSpan(ctx, "git.clone", attribute.String("repository", repoURL), attribute.String("branch", branch))
+	_, err = git.PlainClone(repoPath, false, &git.CloneOptions{
 		URL:           repoURL,
 		ReferenceName: plumbing.NewBranchReferenceName(branch),
 		SingleBranch:  true,
+		Depth:         depth,
+		Auth:          cloneAuth(repoURL, credentials),
 	})
 	if err != nil {
 		// Try with origin/branch reference
@@ -412,126 +1011,222 @@ func (s *Server) generateReleaseNotesForBranch(repoURL, branch string, days int)
 			URL:           repoURL,
 			ReferenceName: plumbing.NewRemoteReferenceName("origin", branch),
 			SingleBranch:  true,
+			Depth:         depth,
+			Auth:          cloneAuth(repoURL, credentials),
 		})
 		if err != nil {
-			return "", "", fmt.Errorf("failed to clone branch %s: %w", branch, err)
+			EndSpan(cloneSpan, err)
+			done()
+			if IsAuthError(err) {
+				return nil, "", nil, nil, NewAuthRequiredError(repoURL, err)
+			}
+			return nil, "", nil, nil, fmt.Errorf("failed to clone branch %s: %w", branch, err)
 		}
 	}
-	defer os.RemoveAll(repoPath)
+	cloneSpan.End()
+	logCloneStats(s.Logger, repoURL, repoPath)
 
-	// Open repo and analyze
-	repo, err := git.PlainOpen(repoPath)
+	if repo, err = git.PlainOpen(repoPath); err != nil {
+		done()
+		return nil, "", nil, nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	if head, err = repo.Head(); err != nil {
+		done()
+		return nil, "", nil, nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return repo, repoPath, head, done, nil
+}
+
+// generateReleaseNotesForBranch generates release notes for a specific branch and period,
+// reporting its stages to progress
+func (s *Server) generateReleaseNotesForBranch(ctx context.Context, req ReleaseNotesRequest, progress ProgressFunc) (notes releaseNotes, err error) {
+	repoURL, branch := req.Repository, req.Branch
+	since, now, days, absolute, err := req.analysisWindow(time.Now())
+	if err != nil {
+		return releaseNotes{}, err
+	}
+	release, err := s.acquireCloneSlot(ctx)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to open repository: %w", err)
+		return releaseNotes{}, err
+	}
+	defer release()
+
+	depth := req.Depth
+	if depth == 0 {
+		depth = s.CloneDepth
 	}
+	credentials := s.requestCredentials(req.Token, req.Repository)
 
-	head, err := repo.Head()
+	progress.reportStage(ProgressCloning, req.Repository)
+	repo, repoPath, head, done, err := s.openBranchRepository(ctx, req, depth, credentials)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get HEAD: %w", err)
+		return releaseNotes{}, err
 	}
+	defer done()
+	progress.reportStage(ProgressAnalyzing, req.Repository)
+
+	ctx, analyzeSpan := StartSpan(ctx, "repository.analyze", attribute.String("repository", repoURL), attribute.String("branch", branch))
+	defer func() { EndSpan(analyzeSpan, err) }()
 
 	// Get latest commit
 	latestCommit, err := repo.CommitObject(head.Hash())
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get latest commit: %w", err)
+		return releaseNotes{}, fmt.Errorf("failed to get latest commit: %w", err)
 	}
 
-	// Calculate date range
-	now := time.Now()
-	since := now.AddDate(0, 0, -days)
-	
-	s.Logger.Infof("Analyzing commits from the last %d days (since %s)", days, since.Format("2006-01-02"))
+	if absolute {
+		s.Logger.Infof("Analyzing commits from %s to %s", since.Format("2006-01-02"), now.Format("2006-01-02"))
+	} else {
+		s.Logger.Infof("Analyzing commits from the last %d days (since %s)", days, since.Format("2006-01-02"))
+	}
 
 	// Get commits from the specified period
-	commitIter, err := repo.Log(&git.LogOptions{
-		From:  head.Hash(),
-		Since: &since,
-	})
+	commits, err := logWindowCommits(repo, head.Hash(), since, now, s.DateMode)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get commit log: %w", err)
+		return releaseNotes{}, fmt.Errorf("failed to get commit log: %w", err)
+	}
+	if depth > 0 {
+		warnTruncatedHistory(s.Logger, repo, repoURL, depth, since)
+	}
+	commits = filterCommitsByAuthor(commits, s.OnlyAuthors)
+
+	// Compare the fork against its upstream when requested
+	var upstream *UpstreamComparison
+	if req.Upstream != "" {
+		var compareErr error
+		upstream, compareErr = compareWithUpstream(repo, head.Hash(), req.Upstream, branch, cloneAuth(req.Upstream, credentials))
+		if compareErr != nil {
+			s.Logger.Warnf("Failed to compare %s with upstream %s: %v", repoURL, req.Upstream, compareErr)
+		}
 	}
 
+	return s.renderReleaseNotes(ctx, req, repoPath, branch, latestCommit, commits, notesWindow{since: since, until: now, days: days, absolute: absolute}, upstream, progress), nil
+}
+
+// notesWindow is the part of a repository's history a release-notes request covers: the
+// commits of a time window, or those between two tags dated since..until
+type notesWindow struct {
+	since, until   time.Time
+	days           int
+	absolute       bool
+	fromTag, toTag string
+}
+
+// releaseNotes are the notes of a request, with the page of their commit list they show
+type releaseNotes struct {
+	html, text string
+	page       CommitPage
+}
+
+// renderReleaseNotes computes the statistics of commits, the commits a request covers with
+// latestCommit the newest of the analyzed history, and renders them as HTML and text notes
+// listing the requested page of commits, reporting the formatting stage to progress
+func (s *Server) renderReleaseNotes(ctx context.Context, req ReleaseNotesRequest, repoPath, label string, latestCommit *object.Commit, commits []*object.Commit, window notesWindow, upstream *UpstreamComparison, progress ProgressFunc) releaseNotes {
+	repoURL := req.Repository
+	since, now, days, absolute := window.since, window.until, window.days, window.absolute
+
 	var commitDetails []CommitDetail
-	authorStats := make(map[string]int)
-	var totalChanges int
-
-	commitIter.ForEach(func(c *object.Commit) error {
-		// Safe stats calculation with panic recovery
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					s.Logger.Debugf("Recovered from panic calculating stats: %v", r)
-				}
-			}()
-			
-			stats, err := c.Stats()
-			if err == nil {
-				for _, stat := range stats {
-					totalChanges += stat.Addition + stat.Deletion
-				}
-			}
-		}()
+	var breaking []BreakingChange
+	var merged []MergedPullRequest
+	authorStats := make(contributorTally)
+	var totalChanges, statsSkipped int
+
+	for i, stats := range computeCommitChanges(repoPath, commits, newChurnOptions(s.IgnoreWhitespace, s.ExcludeExtensions), s.StatsWorkers) {
+		c := commits[i]
+		if stats.err != nil {
+			s.Logger.Debugf("Failed to calculate stats for commit %s: %v", c.Hash.String()[:8], stats.err)
+			statsSkipped++
+		}
+		totalChanges += stats.lines
 
-		authorStats[c.Author.Name]++
+		authorStats.add(c, stats.lines)
 		
-		commitDetails = append(commitDetails, CommitDetail{
+		detail := CommitDetail{
 			Hash:    c.Hash.String()[:8],
 			Message: strings.Split(strings.TrimSpace(c.Message), "\n")[0], // First line only
 			Author:  c.Author.Name,
-			Date:    c.Author.When,
-		})
-		
-		return nil
-	})
+			Date:    s.DateMode.commitDate(c),
+			Files:   stats.files,
+			fullHash: c.Hash.String(),
+		}
+		commitDetails = append(commitDetails, detail)
+		// The footer is only in the full message, so look for breaking changes before it is cut
+		if description, ok := breakingChangeDescription(c.Message); ok {
+			breaking = append(breaking, BreakingChange{Commit: detail, Description: description})
+		}
+		if pr, ok := mergedPullRequest(c, repoURL, detail, s.CommitURLs); ok {
+			merged = append(merged, pr)
+		}
+	}
 
-	// Create contributors list sorted by commit count
-	type authorCommit struct {
-		author string
-		count  int
+	// Create contributors list sorted by the configured metric
+	contributors := authorStats.ranked(s.RankBy)
+	if s.ShowAvatars {
+		// Only the top contributors are displayed, so only look those up
+		resolveContributorAvatars(repoURL, contributors[:min(len(contributors), NewReleaseNoteFormatter().MaxContributors)])
 	}
-	var sortedAuthors []authorCommit
-	for author, count := range authorStats {
-		sortedAuthors = append(sortedAuthors, authorCommit{author, count})
+
+	notable := findNotableChanges(commitDetails, s.NotablePatterns)
+	firstParty, vendored := splitVendoredCommits(commitDetails, s.VendorPatterns)
+	var activity *CommitActivity
+	if s.ActivityHeatmap {
+		activity = newCommitActivity(commitDetails, nil)
+	}
+	var openPRs *int
+	if s.ReportOpenPRs {
+		openPRs = openPullRequestCount(repoURL)
 	}
-	sort.Slice(sortedAuthors, func(i, j int) bool {
-		return sortedAuthors[i].count > sortedAuthors[j].count
-	})
 
-	var contributors []Contributor
-	for i, a := range sortedAuthors {
-		contributors = append(contributors, Contributor{
-			Name:        a.author,
-			CommitCount: a.count,
-			Rank:        i + 1,
-		})
+	summary := WeeklySummary{
+		TotalCommits:       len(commitDetails),
+		TotalLinesChanged:  totalChanges,
+		ActiveContributors: len(authorStats),
+		AnalysisStart:      since,
+		AnalysisEnd:        now,
+		StatsSkipped:       statsSkipped,
+		MergedPRs:          len(merged),
+	}
+	if summary.StatsUnavailable() {
+		s.Logger.Warnf("%s: %s", repoURL, summary.statsUnavailableNote())
 	}
 
 	// Generate HTML output
-	htmlOutput := s.generateHTMLReleaseNotes(
-		repoURL,
-		branch,
-		days,
-		since,
-		now,
-		CommitInfo{
+	progress.reportStage(ProgressFormatting, req.Repository)
+	_, formatSpan := StartSpan(ctx, "report.format")
+	defer formatSpan.End()
+	page := req.commitPage(len(firstParty))
+	htmlOutput := s.generateHTMLReleaseNotes(label, page.Page, page.PageSize, ReleaseNoteFormat{
+		RepositoryInfo: RepositoryInfo{URL: repoURL, OpenPullRequests: openPRs},
+		AnalysisDays:   days,
+		AbsoluteRange:  absolute,
+		FromTag:        window.fromTag,
+		ToTag:          window.toTag,
+		AnalysisStart:  since,
+		AnalysisEnd:    now,
+		LatestCommit: CommitInfo{
 			Hash:    latestCommit.Hash.String()[:8],
 			Message: strings.Split(strings.TrimSpace(latestCommit.Message), "\n")[0],
 			Author:  latestCommit.Author.Name,
-			Date:    latestCommit.Author.When,
+			Date:    s.DateMode.commitDate(latestCommit),
+			fullHash: latestCommit.Hash.String(),
 		},
-		WeeklySummary{
-			TotalCommits:       len(commitDetails),
-			TotalLinesChanged:  totalChanges,
-			ActiveContributors: len(authorStats),
-			AnalysisStart:      since,
-			AnalysisEnd:        now,
-		},
-		contributors,
-		commitDetails,
-	)
+		WeeklySummary: summary,
+		Contributors:  contributors,
+		RankBy:        s.RankBy,
+		OnlyAuthors:   s.OnlyAuthors,
+		Commits:        firstParty,
+		VendoredCommits: vendored,
+		Upstream:       upstream,
+		NotableChanges: notable,
+		BreakingChanges: breaking,
+		MergedPullRequests: merged,
+		Activity:       activity,
+	})
 
 	// Generate text output
 	formatter := NewReleaseNoteFormatter()
+	formatter.LineEnding = s.LineEnding
+	formatter.MaxSubjectLength = s.MaxSubjectLength
 	format := formatter.CreateStandardFormatWithDays(
 		repoURL,
 		days,
@@ -541,58 +1236,165 @@ func (s *Server) generateReleaseNotesForBranch(repoURL, branch string, days int)
 			Hash:    latestCommit.Hash.String()[:8],
 			Message: latestCommit.Message,
 			Author:  latestCommit.Author.Name,
-			Date:    latestCommit.Author.When,
-		},
-		WeeklySummary{
-			TotalCommits:       len(commitDetails),
-			TotalLinesChanged:  totalChanges,
-			ActiveContributors: len(authorStats),
-			AnalysisStart:      since,
-			AnalysisEnd:        now,
+			Date:    s.DateMode.commitDate(latestCommit),
 		},
+		summary,
 		contributors,
-		commitDetails,
+		firstParty,
 	)
+	format.VendoredCommits = vendored
+	if window.fromTag != "" {
+		formatter.SetTagRange(&format, window.fromTag, window.toTag)
+	} else if absolute {
+		formatter.SetAbsoluteRange(&format)
+	}
+	format.Upstream = upstream
+	format.RankBy = s.RankBy
+	format.NotableChanges = notable
+	format.BreakingChanges = breaking
+	format.MergedPullRequests = merged
+	format.Activity = activity
+	s.mu.Lock()
+	if info, ok := s.RepositoryInfo[repoURL]; ok {
+		format.RepositoryInfo.applyCatalog(info)
+	}
+	s.mu.Unlock()
+	format.RepositoryInfo.OpenPullRequests = openPRs
 	textOutput := formatter.FormatReleaseNote(format)
+	if req.paginated() {
+		// The text notes list the same page as the HTML ones, not their first MaxCommits commits
+		format.Commits = firstParty
+		textOutput = formatter.FormatReleaseNotePage(format, page.Page, page.PageSize)
+	}
+
+	return releaseNotes{html: htmlOutput, text: textOutput, page: page}
+}
 
-	return htmlOutput, textOutput, nil
+// latestCommitMessageHTML renders the latest commit's full message for its highlight box: the
+// subject, then the escaped body with its line breaks kept, whatever the commit list shows
+func latestCommitMessageHTML(message string) string {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	out := fmt.Sprintf(`<span class="commit-message">%s</span>`, template.HTMLEscapeString(strings.TrimSpace(subject)))
+	if body = strings.TrimSpace(body); body != "" {
+		out += fmt.Sprintf(`<span class="commit-body">%s</span>`, template.HTMLEscapeString(body))
+	}
+	return out
 }
 
-// generateHTMLReleaseNotes generates HTML formatted release notes
-func (s *Server) generateHTMLReleaseNotes(
-	repoURL, branch string,
-	days int,
-	analysisStart, analysisEnd time.Time,
-	latestCommit CommitInfo,
-	summary WeeklySummary,
-	contributors []Contributor,
-	commits []CommitDetail,
-) string {
+// openPullRequestsTag returns the notes-meta tag for the open pull request count, or nothing when unknown
+func openPullRequestsTag(count *int) string {
+	if count == nil {
+		return ""
+	}
+	return fmt.Sprintf(`
+				<span class="pr-tag">🔃 %d open PRs</span>`, *count)
+}
+
+// authorFilterTag notes that the notes only cover some authors, or nothing when unfiltered
+func authorFilterTag(authors []string) string {
+	if len(authors) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`
+				<span class="author-filter-tag">👤 %s</span>`, template.HTMLEscapeString(authorFilterNote(authors)))
+}
+
+// breakingChangesSection returns the breaking changes section, or nothing when there are none
+func breakingChangesSection(changes []BreakingChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	return `
+		<div class="breaking-section">
+			<h4>⚠️ Breaking Changes</h4>
+			` + formatHTMLBreakingChanges(changes) + `
+		</div>
+		`
+}
+
+// changesByTypeSection returns the commits grouped by conventional-commit type under colored
+// category headings, or nothing without commits
+func (s *Server) changesByTypeSection(repoURL string, commits []CommitDetail) string {
+	groups := groupCommitsByType(commits)
+	if len(groups) == 0 {
+		return ""
+	}
+	var section strings.Builder
+	section.WriteString(`<div class="change-types-section">
+			<h4>🏷️ Changes by Type</h4>`)
+	for _, group := range groups {
+		section.WriteString(fmt.Sprintf(`
+			<div class="change-type change-type-%s">
+				<h5 class="change-type-header">%s <span class="change-type-count">%d</span></h5>
+				<ul class="change-type-list">`,
+			strings.ToLower(group.Category), group.Category, len(group.Commits)))
+		for _, c := range group.Commits {
+			section.WriteString(fmt.Sprintf(`
+					<li><a href="%s" target="_blank"><code class="commit-hash">%s</code></a> %s <span class="change-type-author">👤 %s</span></li>`,
+				s.CommitURLs.CommitURL(repoURL, c.fullHash, c.Hash),
+				c.Hash,
+				template.HTMLEscapeString(firstLine(c.Message)),
+				template.HTMLEscapeString(c.Author),
+			))
+		}
+		section.WriteString(`
+				</ul>
+			</div>`)
+	}
+	section.WriteString(`
+		</div>`)
+	return section.String()
+}
+
+// statsUnavailableSection warns that the lines changed of the summary are not meaningful
+func statsUnavailableSection(summary WeeklySummary) string {
+	if !summary.StatsUnavailable() {
+		return ""
+	}
+	return `
+		<div class="stats-unavailable">⚠️ ` + template.HTMLEscapeString(summary.statsUnavailableNote()) + `</div>
+		`
+}
+
+// generateHTMLReleaseNotes generates HTML formatted release notes listing one page of pageSize
+// commits, page counting from 1; a non-positive pageSize uses DefaultHTMLMaxCommits
+func (s *Server) generateHTMLReleaseNotes(branch string, page, pageSize int, format ReleaseNoteFormat) string {
+	repoURL := format.RepositoryInfo.URL
+	analysisStart, analysisEnd := format.AnalysisStart, format.AnalysisEnd
+	periodTag := fmt.Sprintf("Last %d days", format.AnalysisDays)
+	if format.FromTag != "" {
+		periodTag = fmt.Sprintf("%s → %s", format.FromTag, format.ToTag)
+	} else if format.AbsoluteRange {
+		periodTag = fmt.Sprintf("%d-day range", format.AnalysisDays)
+	}
+	latestCommit := format.LatestCommit
+	summary := format.WeeklySummary
+	contributors := format.Contributors
+	commits := format.Commits
+
 	var html strings.Builder
 	
-	// Build commit URL base
-	commitURLBase := strings.TrimSuffix(repoURL, ".git")
-	latestCommitURL := fmt.Sprintf("%s/commit/%s", commitURLBase, latestCommit.Hash)
+	latestCommitURL := s.CommitURLs.CommitURL(repoURL, latestCommit.fullHash, latestCommit.Hash)
 	
 	html.WriteString(fmt.Sprintf(`<div class="release-notes-content">
 		<div class="notes-header">
 			<h3>%s</h3>
 			<div class="notes-meta">
 				<span class="branch-tag">📌 %s</span>
-				<span class="period-tag">📅 Last %d days</span>
-				<span class="date-range">%s → %s</span>
+				<span class="period-tag">📅 %s</span>
+				<span class="date-range">%s → %s</span>%s
 			</div>
 		</div>
-		
+		%s
 		<div class="latest-commit">
 			<h4>🔥 Latest Commit</h4>
 			<a href="%s" target="_blank" class="commit-box-link">
 				<div class="commit-box highlight">
 					<div class="commit-box-header">
 						<code class="commit-hash">%s</code>
-						<span class="view-commit-btn">View on GitHub →</span>
+						<span class="view-commit-btn">%s</span>
 					</div>
-					<span class="commit-message">%s</span>
+					%s
 					<span class="commit-author">👤 %s</span>
 					<span class="commit-date">📅 %s</span>
 				</div>
@@ -607,30 +1409,86 @@ func (s *Server) generateHTMLReleaseNotes(
 					<span class="stat-label">Commits</span>
 				</div>
 				<div class="stat-card">
-					<span class="stat-value">%d</span>
+					<span class="stat-value">%s</span>
 					<span class="stat-label">Lines Changed</span>
 				</div>
 				<div class="stat-card">
 					<span class="stat-value">%d</span>
 					<span class="stat-label">Contributors</span>
 				</div>
+				<div class="stat-card">
+					<span class="stat-value">%d</span>
+					<span class="stat-label">Merged PRs</span>
+				</div>
 			</div>
 		</div>`,
 		extractRepoNameFromURL(repoURL),
 		branch,
-		days,
+		periodTag,
 		analysisStart.Format("Jan 02, 2006"),
 		analysisEnd.Format("Jan 02, 2006"),
+		openPullRequestsTag(format.RepositoryInfo.OpenPullRequests)+authorFilterTag(format.OnlyAuthors),
+		statsUnavailableSection(summary)+breakingChangesSection(format.BreakingChanges),
 		latestCommitURL,
 		latestCommit.Hash,
-		template.HTMLEscapeString(latestCommit.Message),
+		s.CommitURLs.CommitLinkLabel(repoURL),
+		latestCommitMessageHTML(latestCommit.Message),
 		template.HTMLEscapeString(latestCommit.Author),
 		latestCommit.Date.Format("Jan 02, 2006 15:04"),
 		summary.TotalCommits,
-		summary.TotalLinesChanged,
+		summary.linesChangedValue(),
 		summary.ActiveContributors,
+		summary.MergedPRs,
 	))
 
+	// Merged pull requests section
+	if len(format.MergedPullRequests) > 0 {
+		html.WriteString(`<div class="merged-prs-section">
+			<h4>🔀 Merged Pull Requests</h4>
+			` + formatHTMLMergedPullRequests(format.MergedPullRequests) + `
+		</div>`)
+	}
+
+	// Upstream comparison section
+	if format.Upstream != nil {
+		html.WriteString(fmt.Sprintf(`<div class="upstream-section">
+			<h4>🔀 Upstream Comparison</h4>
+			<div class="commits-note">%s (%s): %s</div>
+		</div>`,
+			template.HTMLEscapeString(format.Upstream.UpstreamURL),
+			template.HTMLEscapeString(format.Upstream.Branch),
+			template.HTMLEscapeString(format.Upstream.Status()),
+		))
+	}
+
+	// Notable changes section
+	if len(format.NotableChanges) > 0 {
+		html.WriteString(`<div class="notable-section">
+			<h4>🚩 Notable Changes</h4>
+			<div class="commits-list">`)
+		for _, change := range format.NotableChanges {
+			html.WriteString(fmt.Sprintf(`
+				<div class="commit-item">
+					<div class="commit-header">
+						<code class="commit-hash">%s</code>
+						<span class="notable-pattern">%s</span>
+					</div>
+					<span class="commit-message">%s</span>
+					<div class="commit-meta">
+						<span class="author">👤 %s</span>
+						<span class="notable-files">📄 %s</span>
+					</div>
+				</div>`,
+				change.Commit.Hash,
+				template.HTMLEscapeString(change.Pattern),
+				template.HTMLEscapeString(change.Commit.Message),
+				template.HTMLEscapeString(change.Commit.Author),
+				template.HTMLEscapeString(strings.Join(change.Files, ", ")),
+			))
+		}
+		html.WriteString(`</div></div>`)
+	}
+
 	// Contributors section
 	if len(contributors) > 0 {
 		html.WriteString(`<div class="contributors-section">
@@ -646,41 +1504,51 @@ func (s *Server) generateHTMLReleaseNotes(
 			c := contributors[i]
 			html.WriteString(fmt.Sprintf(`
 				<div class="contributor">
-					<span class="rank">#%d</span>
+					<span class="rank">#%d</span>%s
 					<span class="name">%s</span>
-					<span class="commits">%d commits</span>
+					<span class="commits">%s</span>
 				</div>`,
 				c.Rank,
+				contributorAvatarHTML(c),
 				template.HTMLEscapeString(c.Name),
-				c.CommitCount,
+				c.Activity(format.RankBy),
 			))
 		}
 		html.WriteString(`</div></div>`)
 	}
 
+	// Commit activity section
+	if format.Activity != nil {
+		html.WriteString(`<div class="activity-section">
+			<h4>🗓️ Commit Activity</h4>
+			` + format.Activity.FormatHTML() + `
+		</div>`)
+	}
+
+	if pageSize <= 0 {
+		pageSize = DefaultHTMLMaxCommits
+	}
+	commitPage := NewCommitPage(page, pageSize, len(commits))
+	start, end := commitPage.bounds()
+	pageCommits := commits[start:end]
+
+	// Changes by type section
+	html.WriteString(s.changesByTypeSection(repoURL, pageCommits))
+
 	// Commits section
 	html.WriteString(`<div class="commits-section">
 		<h4>📝 Recent Commits</h4>
 		<div class="commits-list">`)
 	
-	maxCommits := 50
-	if len(commits) < maxCommits {
-		maxCommits = len(commits)
-	}
-	
-	if maxCommits == 0 {
+	if len(pageCommits) == 0 {
 		html.WriteString(`<div class="no-commits">No commits found in this period</div>`)
 	} else {
-		if len(commits) > maxCommits {
-			html.WriteString(fmt.Sprintf(`<div class="commits-note">Showing %d of %d commits</div>`, maxCommits, len(commits)))
+		if len(commits) > len(pageCommits) {
+			html.WriteString(fmt.Sprintf(`<div class="commits-note">Showing %d of %d commits (page %d of %d)</div>`, len(pageCommits), len(commits), commitPage.Page, commitPage.TotalPages()))
 		}
 		
-		// Build commit URL base (remove .git suffix if present)
-		commitURLBase := strings.TrimSuffix(repoURL, ".git")
-		
-		for i := 0; i < maxCommits; i++ {
-			c := commits[i]
-			commitURL := fmt.Sprintf("%s/commit/%s", commitURLBase, c.Hash)
+		for _, c := range pageCommits {
+			commitURL := s.CommitURLs.CommitURL(repoURL, c.fullHash, c.Hash)
 			html.WriteString(fmt.Sprintf(`
 				<div class="commit-item-wrapper">
 					<a href="%s" target="_blank" class="commit-item-link">
@@ -711,39 +1579,49 @@ func (s *Server) generateHTMLReleaseNotes(
 		}
 	}
 	
-	html.WriteString(`</div></div></div>`)
+	html.WriteString(`</div></div>`)
+	html.WriteString(s.vendoredCommitsSection(repoURL, format.VendoredCommits))
+	html.WriteString(`</div>`)
 	
 	return html.String()
 }
 
-// generateIndexJSON generates the index JSON file using opm render
-func (s *Server) generateIndexJSON(outputPath string) error {
-	dir := filepath.Dir(outputPath)
-	os.MkdirAll(dir, 0755)
-
-	// Find or download opm
-	dm := NewDependencyManager(".bin", s.Logger)
-	opmPath, err := dm.FindOrDownloadTool("opm")
-	if err != nil {
-		return fmt.Errorf("opm command not found and could not be downloaded: %w", err)
-	}
-	s.Logger.Debugf("Using opm at: %s", opmPath)
-
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+// cachedIndex returns the parsed index of an image refreshed within IndexCacheTTL. The cache
+// pairs with the rendered file: it is only used while the render at IndexJSONPath is still
+// the one of that image, so /api/index-json keeps serving the matching index.
+func (s *Server) cachedIndex(indexImage string, force bool) (parsedIndex, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.indexCache[indexImage]
+	if force || !ok || s.renderedImage != indexImage || time.Since(cached.parsedAt) >= s.IndexCacheTTL {
+		return parsedIndex{}, false
 	}
-	defer outputFile.Close()
+	return cached, true
+}
 
-	cmd := exec.Command(opmPath, "render", s.PregaIndex, "--output=json")
-	cmd.Stdout = outputFile
-	cmd.Stderr = os.Stderr
+// invalidateIndexCache drops the parsed index of an image that is about to be rendered again;
+// until the new render is parsed the file at IndexJSONPath belongs to no cached image
+func (s *Server) invalidateIndexCache(indexImage string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.indexCache, indexImage)
+	s.renderedImage = ""
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to execute opm render: %w", err)
+// cacheIndex records the parse of the image just rendered to IndexJSONPath
+func (s *Server) cacheIndex(indexImage string, parsed parsedIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.indexCache == nil {
+		s.indexCache = make(map[string]parsedIndex)
 	}
+	s.indexCache[indexImage] = parsed
+	s.renderedImage = indexImage
+}
 
-	return nil
+// generateIndexJSON generates the index JSON file using opm render
+func (s *Server) generateIndexJSON(ctx context.Context, outputPath string) error {
+	return RenderIndexImage(ctx, s.Deps, s.PregaIndex, outputPath)
 }
 
 // CommitSummaryRequest represents a request for commit summary
@@ -796,9 +1674,27 @@ func (s *Server) handleCommitSummary(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if err := serverRepositoryError(s.AllowedHosts, req.Repository); err != nil {
+		json.NewEncoder(w).Encode(CommitSummaryResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
 
 	// Generate commit summary
+	release, err := s.acquireCloneSlot(r.Context())
+	if err != nil {
+		s.writeBusyStatus(w, err)
+		json.NewEncoder(w).Encode(CommitSummaryResponse{
+			Success:      false,
+			CommitHash:   req.CommitHash,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
 	summary, commitDetailedInfo, err := s.generateCommitSummary(req.Repository, req.Branch, req.CommitHash)
+	release()
 	if err != nil {
 		json.NewEncoder(w).Encode(CommitSummaryResponse{
 			Success:      false,
@@ -848,6 +1744,7 @@ func (s *Server) generateCommitSummary(repoURL, branch, commitHash string) (stri
 		URL:           repoURL,
 		ReferenceName: plumbing.NewBranchReferenceName(branch),
 		SingleBranch:  true,
+		Auth:          cloneAuth(repoURL, s.Credentials),
 	})
 	if err != nil {
 		// Try with origin/branch reference
@@ -855,6 +1752,7 @@ func (s *Server) generateCommitSummary(repoURL, branch, commitHash string) (stri
 		URL:           repoURL,
 		ReferenceName: plumbing.NewRemoteReferenceName("origin", branch),
 		SingleBranch:  true,
+		Auth:          cloneAuth(repoURL, s.Credentials),
 	})
 	if err != nil {
 		return "", CommitDetailedInfo{}, fmt.Errorf("failed to clone branch %s: %w", branch, err)
@@ -1010,7 +1908,7 @@ func (s *Server) generateAISummary(commit *object.Commit, diffSummary, repoPath
 
 // extractRepoNameFromURL extracts repository name from URL
 func extractRepoNameFromURL(repoURL string) string {
-	repoURL = strings.TrimSuffix(repoURL, ".git")
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(repoURL, ".git"), bundleExtension)
 	parts := strings.Split(repoURL, "/")
 	if len(parts) > 0 {
 		return parts[len(parts)-1]
@@ -1257,6 +2155,17 @@ const indexHTML = `<!DOCTYPE html>
             gap: 16px;
         }
 
+        .date-range-inputs {
+            display: flex;
+            gap: 8px;
+            margin-top: 10px;
+        }
+
+        .date-range-inputs .text-input {
+            padding: 6px 8px;
+            font-size: 12px;
+        }
+
         .period-slider {
             flex: 1;
             -webkit-appearance: none;
@@ -1286,14 +2195,32 @@ const indexHTML = `<!DOCTYPE html>
             font-size: 16px;
             font-weight: 600;
             color: var(--accent-primary);
-            min-width: 70px;
-            text-align: right;
+            min-width: 70px;
+            text-align: right;
+        }
+
+        .index-input-container {
+            display: flex;
+            flex-direction: column;
+            gap: 6px;
+        }
+
+        .index-download {
+            display: block;
+            margin-top: 8px;
+            font-family: 'JetBrains Mono', monospace;
+            font-size: 11px;
+            color: var(--text-muted);
+            text-align: center;
+            text-decoration: none;
+        }
+
+        .index-download:hover {
+            color: var(--accent-primary);
         }
 
-        .index-input-container {
-            display: flex;
-            flex-direction: column;
-            gap: 6px;
+        .index-download[hidden] {
+            display: none;
         }
 
         .index-prefix {
@@ -1434,6 +2361,25 @@ const indexHTML = `<!DOCTYPE html>
             align-items: center;
         }
 
+        .group-select {
+            background: var(--bg-tertiary);
+            color: var(--text-secondary);
+            border: 1px solid var(--border-color);
+            border-radius: 6px;
+            font-size: 11px;
+            padding: 2px 6px;
+            margin-left: auto;
+            margin-right: 8px;
+        }
+        .repo-group-header {
+            font-size: 11px;
+            font-weight: 600;
+            color: var(--accent-primary);
+            text-transform: uppercase;
+            letter-spacing: 0.5px;
+            padding: 12px 8px 4px;
+            list-style: none;
+        }
         .repo-count {
             background: var(--bg-tertiary);
             padding: 2px 8px;
@@ -1523,6 +2469,12 @@ const indexHTML = `<!DOCTYPE html>
             text-overflow: ellipsis;
         }
 
+        .repo-activity {
+            font-size: 11px;
+            color: var(--text-muted);
+            margin-top: 2px;
+        }
+
         .drag-handle {
             color: var(--text-muted);
             cursor: grab;
@@ -1771,6 +2723,23 @@ const indexHTML = `<!DOCTYPE html>
             margin-left: 16px;
         }
 
+        .branch-search {
+            width: 180px;
+            margin-left: 16px;
+            padding: 12px 14px;
+            background: var(--bg-tertiary);
+            border: 2px solid var(--border-color);
+            border-radius: 10px;
+            color: var(--text-primary);
+            font-family: 'JetBrains Mono', monospace;
+            font-size: 13px;
+        }
+
+        .branch-search:focus {
+            outline: none;
+            border-color: var(--accent-primary);
+        }
+
         .branch-dropdown {
             width: 100%;
             padding: 12px 40px 12px 16px;
@@ -2241,6 +3210,35 @@ const indexHTML = `<!DOCTYPE html>
             overflow-y: auto;
         }
 
+        .notes-pagination {
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            gap: 16px;
+            padding: 12px 24px;
+            border-top: 1px solid var(--border-color);
+        }
+
+        .notes-pagination .page-info {
+            font-size: 13px;
+            color: var(--text-muted);
+        }
+
+        .page-btn {
+            padding: 6px 14px;
+            border: 1px solid var(--border-color);
+            background: var(--bg-tertiary);
+            color: var(--text-secondary);
+            border-radius: 6px;
+            font-size: 13px;
+            cursor: pointer;
+        }
+
+        .page-btn:disabled {
+            opacity: 0.5;
+            cursor: not-allowed;
+        }
+
         .release-notes-body pre {
             font-family: 'JetBrains Mono', monospace;
             font-size: 13px;
@@ -2284,25 +3282,63 @@ const indexHTML = `<!DOCTYPE html>
             color: var(--accent-blue);
         }
 
+        .pr-tag {
+            color: var(--accent-tertiary);
+        }
+
         .period-tag {
             color: var(--accent-secondary);
         }
 
+        .author-filter-tag {
+            color: var(--accent-tertiary);
+        }
+
         .date-range {
             color: var(--text-muted);
         }
 
-        .latest-commit, .activity-summary, .contributors-section, .commits-section {
+        .latest-commit, .activity-summary, .upstream-section, .notable-section, .contributors-section, .activity-section, .commits-section, .breaking-section, .merged-prs-section, .change-types-section {
             margin-bottom: 24px;
         }
 
-        .latest-commit h4, .activity-summary h4, .contributors-section h4, .commits-section h4 {
+        .latest-commit h4, .activity-summary h4, .upstream-section h4, .notable-section h4, .contributors-section h4, .activity-section h4, .commits-section h4, .breaking-section h4, .merged-prs-section h4, .change-types-section h4 {
             font-size: 16px;
             font-weight: 600;
             margin-bottom: 16px;
             color: var(--text-secondary);
         }
 
+        .activity-table {
+            border-collapse: collapse;
+            font-family: 'JetBrains Mono', monospace;
+            font-size: 12px;
+        }
+
+        .activity-table caption {
+            text-align: left;
+            color: var(--text-muted);
+            margin-bottom: 8px;
+        }
+
+        .activity-table th {
+            color: var(--text-muted);
+            font-weight: 500;
+            padding: 4px 8px;
+        }
+
+        .activity-table td {
+            min-width: 44px;
+            padding: 4px 8px;
+            text-align: center;
+            border: 1px solid var(--border-color);
+        }
+
+        .activity-table .activity-total {
+            color: var(--text-secondary);
+            font-weight: 600;
+        }
+
         .commit-box-link {
             text-decoration: none;
             color: inherit;
@@ -2358,6 +3394,14 @@ const indexHTML = `<!DOCTYPE html>
             font-weight: 500;
         }
 
+        .commit-body {
+            display: block;
+            white-space: pre-wrap;
+            font-size: 13px;
+            color: var(--text-muted);
+            margin-top: 6px;
+        }
+
         .commit-author, .commit-date {
             display: block;
             font-size: 13px;
@@ -2367,7 +3411,7 @@ const indexHTML = `<!DOCTYPE html>
 
         .stats-grid {
             display: grid;
-            grid-template-columns: repeat(3, 1fr);
+            grid-template-columns: repeat(4, 1fr);
             gap: 16px;
         }
 
@@ -2441,6 +3485,12 @@ const indexHTML = `<!DOCTYPE html>
             border-radius: 8px;
         }
 
+        .contributor .avatar {
+            width: 24px;
+            height: 24px;
+            border-radius: 50%;
+        }
+
         .contributor .rank {
             font-family: 'JetBrains Mono', monospace;
             font-size: 12px;
@@ -2565,6 +3615,125 @@ const indexHTML = `<!DOCTYPE html>
             color: var(--text-muted);
         }
 
+        .notable-pattern {
+            font-size: 12px;
+            color: var(--accent-primary);
+        }
+
+        .stats-unavailable {
+            padding: 12px 16px;
+            border: 1px solid var(--warning);
+            border-radius: 12px;
+            color: var(--warning);
+            font-size: 13px;
+        }
+
+        .merged-pr-list {
+            list-style: none;
+            display: flex;
+            flex-direction: column;
+            gap: 8px;
+        }
+
+        .merged-pr-number a {
+            color: var(--accent-primary);
+        }
+
+        .merged-pr-author {
+            font-size: 12px;
+            color: var(--text-muted);
+        }
+
+        .vendoring-section {
+            margin-top: 16px;
+        }
+
+        .vendoring-section summary {
+            cursor: pointer;
+            font-size: 14px;
+            font-weight: 600;
+            color: var(--text-secondary);
+        }
+
+        .vendoring-list {
+            list-style: none;
+            display: flex;
+            flex-direction: column;
+            gap: 8px;
+            margin-top: 12px;
+        }
+
+        .vendoring-list a {
+            color: var(--accent-primary);
+        }
+
+        .vendoring-meta {
+            font-size: 12px;
+            color: var(--text-muted);
+        }
+
+        .breaking-section {
+            padding: 16px;
+            border: 1px solid var(--error);
+            border-radius: 12px;
+            background: rgba(255, 85, 85, 0.08);
+        }
+
+        .breaking-section h4 {
+            color: var(--error);
+        }
+
+        .breaking-list {
+            list-style: none;
+            display: flex;
+            flex-direction: column;
+            gap: 8px;
+        }
+
+        .breaking-author {
+            font-size: 12px;
+            color: var(--text-muted);
+        }
+
+        .change-type {
+            margin-bottom: 12px;
+        }
+
+        .change-type-header {
+            font-size: 13px;
+            margin-bottom: 6px;
+            padding-left: 8px;
+            border-left: 3px solid var(--change-type-color, var(--text-muted));
+            color: var(--change-type-color, var(--text-muted));
+        }
+
+        .change-type-count {
+            font-weight: normal;
+            color: var(--text-muted);
+        }
+
+        .change-type-list {
+            list-style: none;
+            display: flex;
+            flex-direction: column;
+            gap: 4px;
+            font-size: 13px;
+        }
+
+        .change-type-author {
+            font-size: 12px;
+            color: var(--text-muted);
+        }
+
+        .change-type-features { --change-type-color: #50fa7b; }
+        .change-type-fixes { --change-type-color: #ff5555; }
+        .change-type-performance { --change-type-color: #ffb86c; }
+        .change-type-refactoring { --change-type-color: #bd93f9; }
+        .change-type-docs { --change-type-color: #8be9fd; }
+        .change-type-tests { --change-type-color: #f1fa8c; }
+        .change-type-build, .change-type-ci { --change-type-color: #6272a4; }
+        .change-type-style, .change-type-reverts { --change-type-color: #ff79c6; }
+
         .no-commits {
             padding: 40px;
             text-align: center;
@@ -2717,6 +3886,15 @@ const indexHTML = `<!DOCTYPE html>
                         <input type="range" class="period-slider" id="periodSlider" min="1" max="90" value="7">
                         <span class="period-value" id="periodValue">7 days</span>
                     </div>
+                    <div class="date-range-inputs">
+                        <input type="date" class="text-input" id="sinceInput" title="Start date (optional, overrides the slider)">
+                        <input type="date" class="text-input" id="untilInput" title="End date (optional, inclusive)">
+                    </div>
+                </div>
+
+                <div class="control-group">
+                    <label class="control-label">Access Token</label>
+                    <input type="password" class="text-input" id="tokenInput" placeholder="Optional, for private repositories" autocomplete="off">
                 </div>
 
                 <div class="control-group">
@@ -2729,12 +3907,18 @@ const indexHTML = `<!DOCTYPE html>
                     <button class="btn btn-secondary" id="refreshBtn">
                         <span>🔄</span> Refresh Repositories
                     </button>
+                    <a class="index-download" id="indexDownloadLink" href="/api/index-json" download="index.json" hidden>⬇ Download rendered index JSON</a>
                 </div>
             </div>
 
             <div class="repo-section">
                 <div class="section-title">
                     <span>Operators</span>
+                    <select class="group-select" id="groupSelect" title="Group operators">
+                        <option value="none">No grouping</option>
+                        <option value="channel">By channel</option>
+                        <option value="activity">By recent activity</option>
+                    </select>
                     <span class="repo-count" id="repoCount">0</span>
                 </div>
                 <ul class="repo-list" id="repoList">
@@ -2770,6 +3954,7 @@ const indexHTML = `<!DOCTYPE html>
             <div class="branch-selector" id="branchSelector" style="display: none;">
                 <div class="branch-selector-header">
                     <span class="branch-selector-title">Select Branch</span>
+                    <input type="search" class="branch-search" id="branchSearch" placeholder="Search branches..." autocomplete="off">
                     <div class="branch-dropdown-container">
                         <select class="branch-dropdown" id="branchDropdown">
                             <option value="">-- Select a branch --</option>
@@ -2800,6 +3985,11 @@ const indexHTML = `<!DOCTYPE html>
                 <div class="release-notes-body" id="releaseNotesBody">
                     <!-- Release notes content -->
                 </div>
+                <div class="notes-pagination" id="notesPagination" style="display: none;">
+                    <button class="page-btn" id="prevPageBtn">← Previous</button>
+                    <span class="page-info" id="pageInfo"></span>
+                    <button class="page-btn" id="nextPageBtn">Next →</button>
+                </div>
             </div>
 
             <!-- Empty State -->
@@ -2843,24 +4033,36 @@ const indexHTML = `<!DOCTYPE html>
         let activeOperator = null;
         let selectedBranch = null;
         let currentReleaseNotes = { html: '', text: '' };
+        let currentPage = 1;
+        let totalPages = 1;
         let currentView = 'html';
+        let activityLoaded = false;
 
         // DOM Elements
         const indexTagInput = document.getElementById('indexTagInput');
         const periodSlider = document.getElementById('periodSlider');
         const periodValue = document.getElementById('periodValue');
+        const sinceInput = document.getElementById('sinceInput');
+        const untilInput = document.getElementById('untilInput');
         const generateBtn = document.getElementById('generateBtn');
         const refreshBtn = document.getElementById('refreshBtn');
+        const indexDownloadLink = document.getElementById('indexDownloadLink');
         const repoList = document.getElementById('repoList');
         const repoCount = document.getElementById('repoCount');
+        const groupSelect = document.getElementById('groupSelect');
         const dropZone = document.getElementById('dropZone');
         const selectedSection = document.getElementById('selectedSection');
         const selectedOperatorsEl = document.getElementById('selectedOperators');
         const branchSelector = document.getElementById('branchSelector');
         const branchDropdown = document.getElementById('branchDropdown');
         const branchLoading = document.getElementById('branchLoading');
+        const branchSearch = document.getElementById('branchSearch');
         const releaseNotesContainer = document.getElementById('releaseNotesContainer');
         const releaseNotesBody = document.getElementById('releaseNotesBody');
+        const notesPagination = document.getElementById('notesPagination');
+        const prevPageBtn = document.getElementById('prevPageBtn');
+        const nextPageBtn = document.getElementById('nextPageBtn');
+        const pageInfo = document.getElementById('pageInfo');
         const emptyState = document.getElementById('emptyState');
         const loadingOverlay = document.getElementById('loadingOverlay');
         const loadingText = document.getElementById('loadingText');
@@ -2885,11 +4087,24 @@ const indexHTML = `<!DOCTYPE html>
             });
 
             // Generate button
-            generateBtn.addEventListener('click', generateReleaseNotes);
+            generateBtn.addEventListener('click', () => generateReleaseNotes(1));
+
+            // Commit list pages
+            prevPageBtn.addEventListener('click', () => generateReleaseNotes(currentPage - 1));
+            nextPageBtn.addEventListener('click', () => generateReleaseNotes(currentPage + 1));
 
             // Refresh button
             refreshBtn.addEventListener('click', refreshRepositories);
 
+            // Operator grouping
+            groupSelect.addEventListener('change', () => {
+                if (groupSelect.value === 'activity' && !activityLoaded) {
+                    loadRepositoryActivity();
+                } else {
+                    renderRepositoryList();
+                }
+            });
+
             // Clear all button
             clearAllBtn.addEventListener('click', clearAllSelected);
 
@@ -2962,7 +4177,7 @@ const indexHTML = `<!DOCTYPE html>
         async function loadRepositories() {
             showLoading('Loading repositories...');
             try {
-                const response = await fetch('/api/repositories');
+                const response = await fetch('/api/repositories?channels=true');
                 const data = await response.json();
                 if (data.success) {
                     repositories = data.repositories || [];
@@ -2976,6 +4191,25 @@ const indexHTML = `<!DOCTYPE html>
             hideLoading();
         }
 
+        // Activity needs an ls-remote per repository, so it is only fetched when sorting by it
+        async function loadRepositoryActivity() {
+            showLoading('Checking repository activity...');
+            try {
+                const response = await fetch('/api/repositories?channels=true&activity=true');
+                const data = await response.json();
+                if (data.success) {
+                    repositories = data.repositories || [];
+                    activityLoaded = true;
+                } else {
+                    console.error('Failed to load repository activity:', data.error);
+                }
+            } catch (error) {
+                console.error('Error loading repository activity:', error);
+            }
+            renderRepositoryList();
+            hideLoading();
+        }
+
         async function refreshRepositories() {
             const indexTag = indexTagInput.value.trim() || 'v4.21';
             const fullIndex = 'quay.io/prega/prega-operator-index:' + indexTag;
@@ -2988,7 +4222,15 @@ const indexHTML = `<!DOCTYPE html>
                 });
                 const data = await response.json();
                 if (data.success) {
+                    activityLoaded = false;
                     await loadRepositories();
+                    if (groupSelect.value === 'activity') {
+                        await loadRepositoryActivity();
+                    }
+                    if (data.indexUrl) {
+                        indexDownloadLink.href = data.indexUrl;
+                        indexDownloadLink.hidden = false;
+                    }
                     alert('Successfully refreshed ' + data.count + ' repositories from ' + fullIndex);
                 } else {
                     alert('Failed to refresh: ' + data.error);
@@ -3003,34 +4245,65 @@ const indexHTML = `<!DOCTYPE html>
         function renderRepositoryList() {
             repoCount.textContent = repositories.length;
             repoList.innerHTML = '';
-            
-            repositories.forEach(repo => {
-                const li = document.createElement('li');
-                li.className = 'repo-item';
-                li.draggable = true;
-                li.innerHTML = ` + "`" + `
-                    <div class="repo-name">
-                        <span class="drag-handle">⋮⋮</span>
-                        ${escapeHtml(repo.name)}
-                    </div>
-                    <div class="repo-url">${escapeHtml(repo.url)}</div>
-                ` + "`" + `;
-
-                // Click to select
-                li.addEventListener('click', () => addSelectedOperator(repo));
 
-                // Drag start
-                li.addEventListener('dragstart', (e) => {
-                    e.dataTransfer.setData('application/json', JSON.stringify(repo));
-                    li.classList.add('dragging');
+            if (groupSelect.value === 'channel') {
+                // Group by the package's default channel; repos without catalog metadata go last
+                const groups = {};
+                repositories.forEach(repo => {
+                    const channel = repo.defaultChannel || 'unknown';
+                    (groups[channel] = groups[channel] || []).push(repo);
                 });
-
-                li.addEventListener('dragend', () => {
-                    li.classList.remove('dragging');
+                Object.keys(groups).sort((a, b) => {
+                    if (a === 'unknown') return 1;
+                    if (b === 'unknown') return -1;
+                    return a.localeCompare(b);
+                }).forEach(channel => {
+                    const header = document.createElement('li');
+                    header.className = 'repo-group-header';
+                    header.textContent = channel + ' (' + groups[channel].length + ')';
+                    repoList.appendChild(header);
+                    groups[channel].forEach(appendRepositoryItem);
                 });
+                return;
+            }
+
+            if (groupSelect.value === 'activity') {
+                // Most recently updated first; repositories whose activity is unknown go last
+                const activityTime = repo => repo.lastActivity ? Date.parse(repo.lastActivity) : 0;
+                repositories.slice().sort((a, b) => activityTime(b) - activityTime(a)).forEach(appendRepositoryItem);
+                return;
+            }
+
+            repositories.forEach(appendRepositoryItem);
+        }
 
-                repoList.appendChild(li);
+        function appendRepositoryItem(repo) {
+            const li = document.createElement('li');
+            li.className = 'repo-item';
+            li.draggable = true;
+            li.innerHTML = ` + "`" + `
+                <div class="repo-name">
+                    <span class="drag-handle">⋮⋮</span>
+                    ${escapeHtml(repo.name)}
+                </div>
+                <div class="repo-url">${escapeHtml(repo.url)}</div>
+                ${repo.lastActivity ? ` + "`" + `<div class="repo-activity">Updated ${new Date(repo.lastActivity).toLocaleDateString()}</div>` + "`" + ` : ''}
+            ` + "`" + `;
+
+            // Click to select
+            li.addEventListener('click', () => addSelectedOperator(repo));
+
+            // Drag start
+            li.addEventListener('dragstart', (e) => {
+                e.dataTransfer.setData('application/json', JSON.stringify(repo));
+                li.classList.add('dragging');
+            });
+
+            li.addEventListener('dragend', () => {
+                li.classList.remove('dragging');
             });
+
+            repoList.appendChild(li);
         }
 
         function addSelectedOperator(repo) {
@@ -3048,6 +4321,7 @@ const indexHTML = `<!DOCTYPE html>
         function setActiveOperator(repo) {
             activeOperator = repo;
             selectedBranch = null;
+            branchSearch.value = '';
             updateSelectedOperatorsUI();
             loadBranches(repo);
         }
@@ -3057,6 +4331,7 @@ const indexHTML = `<!DOCTYPE html>
             if (activeOperator && activeOperator.url === repo.url) {
                 activeOperator = selectedOps.length > 0 ? selectedOps[0] : null;
                 if (activeOperator) {
+                    branchSearch.value = '';
                     loadBranches(activeOperator);
                 } else {
                     branchSelector.style.display = 'none';
@@ -3108,23 +4383,49 @@ const indexHTML = `<!DOCTYPE html>
             generateBtn.disabled = !selectedBranch;
         }
 
-        async function loadBranches(repo) {
+        // Branches are fetched a page at a time; the dropdown offers loading the next page
+        // and the search box filters on the server so every branch can be reached
+        const BRANCH_PAGE_SIZE = 50;
+        const LOAD_MORE_BRANCHES = '__load_more__';
+        let loadedBranches = [];
+        let branchTotal = 0;
+        let defaultBranch = '';
+        let branchSearchTimer = null;
+
+        async function loadBranches(repo, append) {
             branchSelector.style.display = 'block';
             branchLoading.textContent = 'Loading...';
-            branchDropdown.innerHTML = '<option value="">Loading branches...</option>';
+            if (!append) {
+                loadedBranches = [];
+                branchDropdown.innerHTML = '<option value="">Loading branches...</option>';
+            }
             branchDropdown.disabled = true;
 
+            const params = new URLSearchParams({
+                repository: repo.url,
+                query: branchSearch.value.trim(),
+                limit: BRANCH_PAGE_SIZE,
+                offset: loadedBranches.length
+            });
+
             try {
-                const response = await fetch('/api/branches?repository=' + encodeURIComponent(repo.url));
+                // A token lists private branches; without one the server lists them anonymously
+                const token = document.getElementById('tokenInput').value;
+                const response = await fetch('/api/branches?' + params.toString(), token ? { headers: { 'X-Git-Token': token } } : {});
                 const data = await response.json();
+                if (activeOperator !== repo) return; // Another operator was selected meanwhile
                 
                 if (data.success) {
                     branchLoading.textContent = '';
                     branchDropdown.disabled = false;
-                    renderBranches(data.branches || []);
+                    loadedBranches = loadedBranches.concat(data.branches || []);
+                    branchTotal = data.total || loadedBranches.length;
+                    defaultBranch = data.defaultBranch || '';
+                    renderBranches(loadedBranches, branchTotal, !append && !branchSearch.value.trim());
                 } else {
                     branchLoading.textContent = 'Error: ' + data.error;
-                    branchDropdown.innerHTML = '<option value="">Error loading branches</option>';
+                    const noBranches = (data.error || '').indexOf('no branches found') !== -1;
+                    branchDropdown.innerHTML = '<option value="">' + (noBranches ? 'No branches available' : 'Error loading branches') + '</option>';
                 }
             } catch (error) {
                 branchLoading.textContent = 'Error loading branches';
@@ -3133,19 +4434,30 @@ const indexHTML = `<!DOCTYPE html>
             }
         }
 
-        function renderBranches(branches) {
+        // The remote's default branch, or main/master when the server could not tell
+        function isDefaultBranch(branch) {
+            return defaultBranch ? branch === defaultBranch : branch === 'main' || branch === 'master';
+        }
+
+        function renderBranches(branches, total, autoSelectMain) {
             // Clear dropdown and add placeholder
-            branchDropdown.innerHTML = '<option value="">-- Select a branch --</option>';
+            const query = branchSearch.value.trim();
+            const placeholder = branches.length === 0 && query ? 'No branches match "' + query + '"' : '-- Select a branch --';
+            branchDropdown.innerHTML = '';
+            const placeholderOption = document.createElement('option');
+            placeholderOption.value = '';
+            placeholderOption.textContent = placeholder;
+            branchDropdown.appendChild(placeholderOption);
             
             // Group branches by type
-            const mainBranches = branches.filter(b => b === 'main' || b === 'master');
-            const releaseBranches = branches.filter(b => b.startsWith('release-')).sort((a, b) => b.localeCompare(a));
-            const otherBranches = branches.filter(b => b !== 'main' && b !== 'master' && !b.startsWith('release-'));
+            const mainBranches = branches.filter(b => isDefaultBranch(b));
+            const releaseBranches = branches.filter(b => !isDefaultBranch(b) && b.startsWith('release-')).sort((a, b) => b.localeCompare(a));
+            const otherBranches = branches.filter(b => !isDefaultBranch(b) && !b.startsWith('release-'));
             
             // Add main/master first
             if (mainBranches.length > 0) {
                 const optgroup = document.createElement('optgroup');
-                optgroup.label = '🏠 Main Branch';
+                optgroup.label = '🏠 Default Branch';
                 mainBranches.forEach(branch => {
                     const option = document.createElement('option');
                     option.value = branch;
@@ -3172,57 +4484,84 @@ const indexHTML = `<!DOCTYPE html>
             if (otherBranches.length > 0) {
                 const optgroup = document.createElement('optgroup');
                 optgroup.label = '🔀 Other Branches';
-                otherBranches.slice(0, 20).forEach(branch => { // Limit to 20 to keep dropdown manageable
+                otherBranches.forEach(branch => {
                     const option = document.createElement('option');
                     option.value = branch;
                     option.textContent = branch.length > 50 ? branch.substring(0, 47) + '...' : branch;
                     option.title = branch; // Full name on hover
                     optgroup.appendChild(option);
                 });
-                if (otherBranches.length > 20) {
-                    const option = document.createElement('option');
-                    option.disabled = true;
-                    option.textContent = '... and ' + (otherBranches.length - 20) + ' more';
-                    optgroup.appendChild(option);
-                }
                 branchDropdown.appendChild(optgroup);
             }
 
-            // Auto-select main/master if available
-            const mainBranch = branches.find(b => b === 'main' || b === 'master');
-            if (mainBranch) {
-                branchDropdown.value = mainBranch;
-                selectedBranch = mainBranch;
-                generateBtn.disabled = false;
+            // Offer the next page while the server has more matching branches
+            if (total > branches.length) {
+                const option = document.createElement('option');
+                option.value = LOAD_MORE_BRANCHES;
+                option.textContent = '⬇ Load more branches (' + (total - branches.length) + ' remaining)';
+                branchDropdown.appendChild(option);
+            }
+
+            // Keep the current selection if it is still listed, otherwise auto-select the default branch
+            if (selectedBranch && branches.includes(selectedBranch)) {
+                branchDropdown.value = selectedBranch;
+            } else {
+                selectedBranch = null;
+                const mainBranch = autoSelectMain ? branches.find(b => isDefaultBranch(b)) : null;
+                if (mainBranch) {
+                    branchDropdown.value = mainBranch;
+                    selectedBranch = mainBranch;
+                }
             }
+            generateBtn.disabled = !selectedBranch;
         }
         
         // Add event listener for dropdown change
         branchDropdown.addEventListener('change', (e) => {
+            if (e.target.value === LOAD_MORE_BRANCHES) {
+                branchDropdown.value = selectedBranch || '';
+                if (activeOperator) loadBranches(activeOperator, true);
+                return;
+            }
             selectedBranch = e.target.value;
             generateBtn.disabled = !selectedBranch;
         });
 
-        async function generateReleaseNotes() {
+        // Search all branches on the server, waiting for typing to pause
+        branchSearch.addEventListener('input', () => {
+            clearTimeout(branchSearchTimer);
+            branchSearchTimer = setTimeout(() => {
+                if (activeOperator) loadBranches(activeOperator);
+            }, 300);
+        });
+
+        async function generateReleaseNotes(page) {
             if (!activeOperator || !selectedBranch) return;
 
-            showLoading('Generating release notes for ' + activeOperator.name + '...');
+            const name = activeOperator.name;
+            const stageLabels = {
+                'cloning': 'Cloning ' + name + '...',
+                'analyzing commits': 'Analyzing commits of ' + name + '...',
+                'formatting': 'Formatting release notes for ' + name + '...'
+            };
+            showLoading('Generating release notes for ' + name + '...');
             
             try {
-                const response = await fetch('/api/release-notes', {
-                    method: 'POST',
-                    headers: { 'Content-Type': 'application/json' },
-                    body: JSON.stringify({
-                        repository: activeOperator.url,
-                        branch: selectedBranch,
-                        days: parseInt(periodSlider.value)
-                    })
+                const data = await streamReleaseNotes({
+                    repository: activeOperator.url,
+                    branch: selectedBranch,
+                    days: parseInt(periodSlider.value),
+                    since: sinceInput.value,
+                    until: untilInput.value,
+                    token: document.getElementById('tokenInput').value,
+                    page: page
+                }, stage => {
+                    loadingText.textContent = stageLabels[stage] || stage;
                 });
-
-                const data = await response.json();
                 
                 if (data.success) {
                     currentReleaseNotes = { html: data.html, text: data.text };
+                    updatePagination(data.page, data.totalPages, data.totalCommits);
                     releaseNotesContainer.style.display = 'block';
                     emptyState.style.display = 'none';
                     updateReleaseNotesView();
@@ -3237,6 +4576,52 @@ const indexHTML = `<!DOCTYPE html>
             hideLoading();
         }
 
+        // Posts a release-notes request to the streaming endpoint, passing each progress stage
+        // to onProgress, and resolves to the response carried by the final result event
+        async function streamReleaseNotes(body, onProgress) {
+            const response = await fetch('/api/release-notes/stream', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body)
+            });
+            const reader = response.body.getReader();
+            const decoder = new TextDecoder();
+            let buffer = '';
+            let result = null;
+            while (true) {
+                const { done, value } = await reader.read();
+                if (done) break;
+                buffer += decoder.decode(value, { stream: true });
+                let end;
+                while ((end = buffer.indexOf('\n\n')) >= 0) {
+                    const message = buffer.slice(0, end);
+                    buffer = buffer.slice(end + 2);
+                    let event = 'message';
+                    let data = '';
+                    message.split('\n').forEach(line => {
+                        if (line.startsWith('event: ')) event = line.slice(7);
+                        else if (line.startsWith('data: ')) data += line.slice(6);
+                    });
+                    if (event === 'progress') {
+                        onProgress(JSON.parse(data).stage);
+                    } else if (event === 'result') {
+                        result = JSON.parse(data);
+                    }
+                }
+            }
+            if (!result) throw new Error('release notes stream ended without a result');
+            return result;
+        }
+
+        function updatePagination(page, pages, commits) {
+            currentPage = page || 1;
+            totalPages = pages || 1;
+            notesPagination.style.display = totalPages > 1 ? 'flex' : 'none';
+            pageInfo.textContent = 'Page ' + currentPage + ' of ' + totalPages + ' (' + commits + ' commits)';
+            prevPageBtn.disabled = currentPage <= 1;
+            nextPageBtn.disabled = currentPage >= totalPages;
+        }
+
         function updateReleaseNotesView() {
             if (currentView === 'html') {
                 releaseNotesBody.innerHTML = currentReleaseNotes.html;
@@ -0,0 +1,501 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestHandleIndexJSON(t *testing.T) {
+	dir := t.TempDir()
+	server := NewServer(0, dir, dir, "", nil)
+	server.IndexPath = filepath.Join(dir, "rendered", "index.json")
+
+	rec := httptest.NewRecorder()
+	server.handleIndexJSON(rec, httptest.NewRequest(http.MethodGet, "/api/index-json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before the index is rendered, got %d", rec.Code)
+	}
+
+	content := `{"schema": "olm.package", "name": "test-operator"}`
+	if err := os.MkdirAll(filepath.Dir(server.IndexPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(server.IndexPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleIndexJSON(rec, httptest.NewRequest(http.MethodGet, "/api/index-json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != content {
+		t.Errorf("Expected the raw index JSON, got %q", rec.Body.String())
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); disposition == "" {
+		t.Error("Expected the index to be served as an attachment")
+	}
+}
+
+func TestIndexJSONPathDefault(t *testing.T) {
+	server := NewServer(0, "work", "out", "", nil)
+	if got := server.IndexJSONPath(); got != filepath.Join("work", "prega-operator-index", "index.json") {
+		t.Errorf("Unexpected default index path: %s", got)
+	}
+}
+
+func TestHandleStatus(t *testing.T) {
+	server := NewServer(0, "work", "out", "quay.io/prega/prega-operator-index:v4.21", nil)
+
+	decode := func() StatusResponse {
+		rec := httptest.NewRecorder()
+		server.handleStatus(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+		var status StatusResponse
+		if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+			t.Fatalf("Failed to decode status: %v", err)
+		}
+		return status
+	}
+
+	status := decode()
+	if !status.Success || status.PregaIndex != server.PregaIndex || status.WorkDir != "work" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if status.LastRefresh != nil || status.CacheAgeSeconds != nil {
+		t.Error("Expected no refresh time before repositories are loaded")
+	}
+	if status.Build.Version == "" || status.Build.GoVersion == "" {
+		t.Errorf("Expected build info, got %+v", status.Build)
+	}
+
+	server.SetRepositories([]string{"https://github.com/example/a", "https://github.com/example/b"})
+	status = decode()
+	if status.RepositoryCount != 2 {
+		t.Errorf("Expected 2 repositories, got %d", status.RepositoryCount)
+	}
+	if status.LastRefresh == nil || status.CacheAgeSeconds == nil {
+		t.Error("Expected refresh time after repositories are loaded")
+	}
+}
+
+func TestReleaseNotesRequestAnalysisWindow(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		req              ReleaseNotesRequest
+		expectedSince    time.Time
+		expectedUntil    time.Time
+		expectedDays     int
+		expectedAbsolute bool
+		expectError      bool
+	}{
+		{
+			name:          "relative window",
+			req:           ReleaseNotesRequest{Days: 7},
+			expectedSince: now.AddDate(0, 0, -7),
+			expectedUntil: now,
+			expectedDays:  7,
+		},
+		{
+			name:             "absolute range with inclusive end day",
+			req:              ReleaseNotesRequest{Since: "2024-01-01", Until: "2024-02-01"},
+			expectedSince:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedUntil:    time.Date(2024, 2, 1, 23, 59, 59, 0, time.UTC),
+			expectedDays:     32,
+			expectedAbsolute: true,
+		},
+		{
+			name:             "since without until ends now",
+			req:              ReleaseNotesRequest{Since: "2024-03-01"},
+			expectedSince:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			expectedUntil:    now,
+			expectedDays:     15,
+			expectedAbsolute: true,
+		},
+		{name: "until before since", req: ReleaseNotesRequest{Since: "2024-02-01", Until: "2024-01-01"}, expectError: true},
+		{name: "invalid date", req: ReleaseNotesRequest{Since: "01/02/2024"}, expectError: true},
+		{name: "future since", req: ReleaseNotesRequest{Since: "2024-04-01", Until: "2024-05-01"}, expectError: true},
+		{name: "longer than a year", req: ReleaseNotesRequest{Since: "2022-01-01", Until: "2024-01-01"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, days, absolute, err := tt.req.analysisWindow(now)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !since.Equal(tt.expectedSince) || !until.Equal(tt.expectedUntil) {
+				t.Errorf("Expected %s..%s, got %s..%s", tt.expectedSince, tt.expectedUntil, since, until)
+			}
+			if days != tt.expectedDays || absolute != tt.expectedAbsolute {
+				t.Errorf("Expected %d days (absolute %v), got %d (absolute %v)", tt.expectedDays, tt.expectedAbsolute, days, absolute)
+			}
+		})
+	}
+}
+
+func TestHandleBranchesPaging(t *testing.T) {
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	repoURL := "https://github.com/test/operator"
+	server.branchCache = map[branchCacheKey]cachedBranches{
+		newBranchCacheKey(repoURL, ""): {branches: []string{"main", "release-4.21", "feature-a", "feature-B", "fix-c"}, defaultBranch: "main", fetchedAt: time.Now()},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		expected      []string
+		expectedTotal int
+		expectError   bool
+	}{
+		{name: "all branches", query: "", expected: []string{"main", "release-4.21", "feature-a", "feature-B", "fix-c"}, expectedTotal: 5},
+		{name: "first page", query: "&limit=2", expected: []string{"main", "release-4.21"}, expectedTotal: 5},
+		{name: "last page", query: "&limit=2&offset=4", expected: []string{"fix-c"}, expectedTotal: 5},
+		{name: "offset past end", query: "&offset=9", expected: []string{}, expectedTotal: 5},
+		{name: "case-insensitive search", query: "&query=FEATURE&limit=1&offset=1", expected: []string{"feature-B"}, expectedTotal: 2},
+		{name: "invalid limit", query: "&limit=-1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.handleBranches(rec, httptest.NewRequest(http.MethodGet, "/api/branches?repository="+repoURL+tt.query, nil))
+
+			var resp struct {
+				Success       bool     `json:"success"`
+				Branches      []string `json:"branches"`
+				Total         int      `json:"total"`
+				DefaultBranch string   `json:"defaultBranch"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Success == tt.expectError {
+				t.Fatalf("Expected success=%v, got %+v", !tt.expectError, resp)
+			}
+			if tt.expectError {
+				return
+			}
+			if resp.Total != tt.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tt.expectedTotal, resp.Total)
+			}
+			if resp.DefaultBranch != "main" {
+				t.Errorf("Expected default branch main, got %q", resp.DefaultBranch)
+			}
+			if len(resp.Branches) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, resp.Branches)
+			}
+			for i := range tt.expected {
+				if resp.Branches[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, resp.Branches)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFetchBranchesDefaultBranch(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "README.md", "initial commit")
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+
+	// The operator's default branch is neither main nor master
+	for _, branch := range []string{"main", "devel", "release-4.21"} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head.Hash())); err != nil {
+			t.Fatalf("Failed to create branch %s: %v", branch, err)
+		}
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("devel"))); err != nil {
+		t.Fatalf("Failed to point HEAD at devel: %v", err)
+	}
+
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	branches, defaultBranch, err := server.fetchBranches(context.Background(), dir, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if defaultBranch != "devel" {
+		t.Errorf("Expected default branch devel, got %q", defaultBranch)
+	}
+	expected := []string{"devel", "main", "master", "release-4.21"}
+	if strings.Join(branches, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected branches %v, got %v", expected, branches)
+	}
+}
+
+func TestBranchCacheIsPerCredential(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "first")
+
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	// A list fetched earlier with one caller's token
+	server.branchCache = map[branchCacheKey]cachedBranches{
+		newBranchCacheKey(dir, "caller-token"): {branches: []string{"master", "private-feature"}, defaultBranch: "master", fetchedAt: time.Now()},
+	}
+
+	listBranches := func(token string) []string {
+		request := httptest.NewRequest(http.MethodGet, "/api/branches?repository="+url.QueryEscape(dir), nil)
+		if token != "" {
+			request.Header.Set(GitTokenHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		server.handleBranches(rec, request)
+		var resp struct {
+			Branches []string `json:"branches"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.Branches
+	}
+
+	if branches := strings.Join(listBranches("caller-token"), ","); branches != "master,private-feature" {
+		t.Errorf("Expected the caller's cached list, got %s", branches)
+	}
+	if branches := strings.Join(listBranches(""), ","); branches != "master" {
+		t.Errorf("Expected an anonymous caller to get its own list, got %s", branches)
+	}
+	if branches := strings.Join(listBranches("other-token"), ","); branches != "master" {
+		t.Errorf("Expected another token to get its own list, got %s", branches)
+	}
+}
+
+func TestGenerateHTMLReleaseNotesCommitCap(t *testing.T) {
+	commits := make([]CommitDetail, 120)
+	for i := range commits {
+		commits[i] = CommitDetail{Hash: fmt.Sprintf("%08x", i), Message: "Change", Author: "Alice", Date: time.Now()}
+	}
+	format := ReleaseNoteFormat{RepositoryInfo: RepositoryInfo{URL: "https://github.com/test/repo"}, Commits: commits}
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	tests := []struct {
+		maxCommits int
+		shown      int
+		note       string
+	}{
+		{maxCommits: 0, shown: DefaultHTMLMaxCommits, note: "Showing 50 of 120 commits"},
+		{maxCommits: 100, shown: 100, note: "Showing 100 of 120 commits"},
+		{maxCommits: 200, shown: 120},
+	}
+	for _, tt := range tests {
+		html := server.generateHTMLReleaseNotes("main", 1, tt.maxCommits, format)
+		if got := strings.Count(html, `class="commit-item-wrapper"`); got != tt.shown {
+			t.Errorf("maxCommits %d: expected %d commits, got %d", tt.maxCommits, tt.shown, got)
+		}
+		if hasNote := strings.Contains(html, "Showing "); hasNote != (tt.note != "") || !strings.Contains(html, tt.note) {
+			t.Errorf("maxCommits %d: expected note %q", tt.maxCommits, tt.note)
+		}
+	}
+}
+
+func TestHandleReleaseNotesPage(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	for _, message := range []string{"first", "second", "third"} {
+		commitFile(t, repo, dir, message+".txt", message)
+	}
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+
+	request := func(body string) ReleaseNotesResponse {
+		recorder := httptest.NewRecorder()
+		s.handleReleaseNotes(recorder, httptest.NewRequest(http.MethodPost, "/api/release-notes", strings.NewReader(body)))
+		var response ReleaseNotesResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	response := request(fmt.Sprintf(`{"repository": %q, "branch": "master", "page": 2, "pageSize": 2}`, dir))
+	if !response.Success {
+		t.Fatalf("Unexpected error: %s", response.ErrorMessage)
+	}
+	if response.TotalCommits != 3 || response.Page != 2 || response.PageSize != 2 || response.TotalPages != 2 {
+		t.Errorf("Expected page 2 of 2 over 3 commits, got %+v", response)
+	}
+	// The oldest commit is alone on the last page, while the summary covers all three
+	if !strings.Contains(response.Text, "(Showing commits 3-3 of 3, page 2 of 2)\n- first (") || !strings.Contains(response.Text, "Total Commits: 3\n") {
+		t.Errorf("Expected the second page in the text notes, got:\n%s", response.Text)
+	}
+	if !strings.Contains(response.HTML, "Showing 1 of 3 commits (page 2 of 2)") || strings.Count(response.HTML, `class="commit-item-wrapper"`) != 1 {
+		t.Errorf("Expected the second page in the HTML notes")
+	}
+
+	if response := request(fmt.Sprintf(`{"repository": %q, "page": -1}`, dir)); response.Success || response.ErrorMessage != "page and pageSize must not be negative" {
+		t.Errorf("Expected an error for a negative page, got %+v", response)
+	}
+}
+
+func TestGenerateHTMLReleaseNotesLatestCommitBody(t *testing.T) {
+	message := "Fix <crash> on reconcile\n\nThe controller dereferenced a nil status.\nSee #42 & #43."
+	format := ReleaseNoteFormat{
+		RepositoryInfo: RepositoryInfo{URL: "https://github.com/test/repo"},
+		LatestCommit:   CommitInfo{Hash: "a1b2c3d4", Message: message, Author: "Alice", Date: time.Now()},
+		Commits:        []CommitDetail{{Hash: "a1b2c3d4", Message: message, Author: "Alice", Date: time.Now()}},
+	}
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	html := server.generateHTMLReleaseNotes("main", 1, 0, format)
+	expected := `<span class="commit-message">Fix &lt;crash&gt; on reconcile</span><span class="commit-body">The controller dereferenced a nil status.` + "\n" + `See #42 &amp; #43.</span>`
+	if !strings.Contains(html, expected) {
+		t.Errorf("Expected the latest commit box to contain %q", expected)
+	}
+	if got := latestCommitMessageHTML("Subject only\n"); got != `<span class="commit-message">Subject only</span>` {
+		t.Errorf("Expected no body for a one-line message, got %q", got)
+	}
+}
+
+func TestGenerateHTMLReleaseNotesChangesByType(t *testing.T) {
+	format := ReleaseNoteFormat{
+		RepositoryInfo: RepositoryInfo{URL: "https://github.com/test/repo"},
+		Commits: []CommitDetail{
+			{Hash: "aaaaaaaa", Message: "fix: <nil> status", Author: "Alice", Date: time.Now()},
+			{Hash: "bbbbbbbb", Message: "Bump version", Author: "Bob", Date: time.Now()},
+		},
+	}
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	html := server.generateHTMLReleaseNotes("main", 1, 0, format)
+	fixes := strings.Index(html, `<div class="change-type change-type-fixes">`)
+	other := strings.Index(html, `<div class="change-type change-type-other">`)
+	if fixes < 0 || other < fixes {
+		t.Fatalf("Expected Fixes then Other groups, got:\n%s", html)
+	}
+	if !strings.Contains(html[fixes:other], "fix: &lt;nil&gt; status") || !strings.Contains(html[other:], "Bump version") {
+		t.Errorf("Expected each commit under its category")
+	}
+	if got := strings.Count(html, `class="commit-item-wrapper"`); got != 2 {
+		t.Errorf("Expected the commit list to stay unchanged, got %d commits", got)
+	}
+
+	format.Commits = nil
+	if html := server.generateHTMLReleaseNotes("main", 1, 0, format); strings.Contains(html, "change-types-section") {
+		t.Errorf("Expected no changes by type without commits")
+	}
+}
+
+func TestGenerateHTMLReleaseNotesLinkHostRewrite(t *testing.T) {
+	const latestHash = "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+	const olderHash = "b2c3d4e5f60718293a4b5c6d7e8f901234567890"
+	format := ReleaseNoteFormat{
+		RepositoryInfo: RepositoryInfo{URL: "https://mirror.internal/example/operator.git"},
+		LatestCommit:   CommitInfo{Hash: latestHash[:8], Message: "Latest", Author: "Alice", Date: time.Now(), fullHash: latestHash},
+		Commits: []CommitDetail{
+			{Hash: latestHash[:8], Message: "Latest", Author: "Alice", Date: time.Now(), fullHash: latestHash},
+			{Hash: olderHash[:8], Message: "Older", Author: "Bob", Date: time.Now(), fullHash: olderHash},
+		},
+	}
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	server.CommitURLs = CommitURLTemplates{HostRewrites: map[string]string{"mirror.internal": "github.com"}}
+
+	html := server.generateHTMLReleaseNotes("main", 1, 0, format)
+	latestLink := `href="https://github.com/example/operator/commit/` + latestHash + `" target="_blank" class="commit-box-link"`
+	if !strings.Contains(html, latestLink) {
+		t.Errorf("Expected the latest commit to link to the public host")
+	}
+	for _, hash := range []string{latestHash, olderHash} {
+		commitLink := `href="https://github.com/example/operator/commit/` + hash + `" target="_blank" class="commit-item-link"`
+		if !strings.Contains(html, commitLink) {
+			t.Errorf("Expected commit %s to link to the public host", hash[:8])
+		}
+	}
+	if strings.Contains(html, "mirror.internal/example/operator/commit") {
+		t.Error("Expected no commit link to the mirror")
+	}
+}
+
+func TestGenerateHTMLReleaseNotesGitLabLinks(t *testing.T) {
+	const hash = "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+	format := ReleaseNoteFormat{
+		RepositoryInfo: RepositoryInfo{URL: "https://gitlab.example.com/group/operator.git"},
+		LatestCommit:   CommitInfo{Hash: hash[:8], Message: "Latest", Author: "Alice", Date: time.Now(), fullHash: hash},
+		Commits:        []CommitDetail{{Hash: hash[:8], Message: "Latest", Author: "Alice", Date: time.Now(), fullHash: hash}},
+	}
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	html := server.generateHTMLReleaseNotes("main", 1, 0, format)
+	for _, class := range []string{"commit-box-link", "commit-item-link"} {
+		link := `href="https://gitlab.example.com/group/operator/-/commit/` + hash + `" target="_blank" class="` + class + `"`
+		if !strings.Contains(html, link) {
+			t.Errorf("Expected a GitLab commit link with class %s", class)
+		}
+	}
+}
+
+func TestRefreshIndexCache(t *testing.T) {
+	dir := t.TempDir()
+	server := NewServer(0, dir, dir, "", nil)
+	image := "quay.io/prega/prega-operator-index:v4.21"
+	repos := []string{"https://github.com/openshift/compliance-operator"}
+	server.cacheIndex(image, parsedIndex{repositories: repos, parsedAt: time.Now()})
+
+	if _, ok := server.cachedIndex(image, false); !ok {
+		t.Error("Expected a fresh parse of the rendered image to be cached")
+	}
+	if _, ok := server.cachedIndex(image, true); ok {
+		t.Error("Expected a forced refresh to bypass the cache")
+	}
+	if _, ok := server.cachedIndex("quay.io/prega/prega-operator-index:v4.20", false); ok {
+		t.Error("Expected no cached parse for another image")
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleRefresh(rec, httptest.NewRequest(http.MethodPost, "/api/refresh", strings.NewReader(`{"indexImage": "`+image+`"}`)))
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response["success"] != true || response["cached"] != true || response["count"] != float64(1) {
+		t.Errorf("Expected the cached repositories, got %v", response)
+	}
+	if len(server.Repositories) != 1 || server.Repositories[0] != repos[0] {
+		t.Errorf("Expected the cached repositories to be loaded, got %v", server.Repositories)
+	}
+
+	// Rendering another image replaces the file the cache pairs with
+	server.invalidateIndexCache("quay.io/prega/prega-operator-index:v4.20")
+	if _, ok := server.cachedIndex(image, false); ok {
+		t.Error("Expected the cache to be unused once the rendered file changed")
+	}
+
+	server.cacheIndex(image, parsedIndex{repositories: repos, parsedAt: time.Now().Add(-DefaultIndexCacheTTL)})
+	if _, ok := server.cachedIndex(image, false); ok {
+		t.Error("Expected an expired parse not to be used")
+	}
+}
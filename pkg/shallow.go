@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sirupsen/logrus"
+)
+
+// shallowBoundary returns the commits at which the history of a shallow clone is cut, whose
+// parents are missing from the clone; nil for a full clone
+func shallowBoundary(repo *git.Repository) map[plumbing.Hash]bool {
+	hashes, err := repo.Storer.Shallow()
+	if err != nil || len(hashes) == 0 {
+		return nil
+	}
+	boundary := make(map[plumbing.Hash]bool, len(hashes))
+	for _, hash := range hashes {
+		boundary[hash] = true
+	}
+	return boundary
+}
+
+// outsideShallowHistory reports whether err is an object missing from a shallow clone, i.e. one
+// the clone depth left out
+func outsideShallowHistory(repo *git.Repository, err error) bool {
+	return errors.Is(err, plumbing.ErrObjectNotFound) && shallowBoundary(repo) != nil
+}
+
+// logCommits walks the commits reachable from from that were committed within since..until,
+// either bound being optional, like repo.Log. In a shallow clone the walk ends at the boundary
+// instead of failing on the missing parents of its commits.
+func logCommits(repo *git.Repository, from plumbing.Hash, since, until *time.Time) (object.CommitIter, error) {
+	boundary := shallowBoundary(repo)
+	if boundary == nil {
+		return repo.Log(&git.LogOptions{From: from, Since: since, Until: until})
+	}
+
+	start, err := repo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+	var missing []plumbing.Hash
+	for hash := range boundary {
+		if commit, err := repo.CommitObject(hash); err == nil {
+			missing = append(missing, commit.ParentHashes...)
+		}
+	}
+	commits := object.NewCommitPreorderIter(start, nil, missing)
+	if since == nil && until == nil {
+		return commits, nil
+	}
+	return object.NewCommitLimitIterFromIter(commits, object.LogLimitOptions{Since: since, Until: until}), nil
+}
+
+// truncatedAt returns the newest commit of a shallow clone's boundary committed after since:
+// the history stops there, so commits of the window older than it are missing. It returns nil
+// for a full clone or when the window lies entirely within the cloned history.
+func truncatedAt(repo *git.Repository, since time.Time) *object.Commit {
+	var newest *object.Commit
+	for hash := range shallowBoundary(repo) {
+		commit, err := repo.CommitObject(hash)
+		if err != nil || !commit.Committer.When.After(since) {
+			continue
+		}
+		if newest == nil || commit.Committer.When.After(newest.Committer.When) {
+			newest = commit
+		}
+	}
+	return newest
+}
+
+// warnTruncatedHistory warns when a shallow clone of depth commits does not reach back to the
+// start of the analysis window, so the notes may be missing commits
+func warnTruncatedHistory(logger *logrus.Logger, repo *git.Repository, repoURL string, depth int, since time.Time) {
+	commit := truncatedAt(repo, since)
+	if commit == nil {
+		return
+	}
+	logger.Warnf("The clone of %s at depth %d ends at commit %s from %s, after the analysis window starts on %s; "+
+		"older commits of the window are missing and the results may be incomplete (raise the clone depth or set it to 0 for full history)",
+		repoURL, depth, commit.Hash.String()[:8], commit.Committer.When.Format("2006-01-02"), since.Format("2006-01-02"))
+}
@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// dailyRepository creates a repository with one commit per day, the newest an hour ago
+func dailyRepository(t *testing.T, days int) string {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	now := time.Now().Add(-time.Hour)
+	for day := days - 1; day >= 0; day-- {
+		name := fmt.Sprintf("day%d.txt", day)
+		commitFile(t, repo, dir, name, name, withMessage("Add "+name), withDate(now.AddDate(0, 0, -day)))
+	}
+	return dir
+}
+
+func TestLogCommitsShallowClone(t *testing.T) {
+	source := dailyRepository(t, 5)
+	repo, err := git.PlainClone(filepath.Join(t.TempDir(), "clone"), false, &git.CloneOptions{URL: source, Depth: 2})
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+
+	since := time.Now().AddDate(0, 0, -30)
+	commits, err := logCommits(repo, head.Hash(), &since, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	count := 0
+	if err := commits.ForEach(func(*object.Commit) error { count++; return nil }); err != nil {
+		t.Fatalf("Expected the walk to end at the shallow boundary, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected the 2 cloned commits, got %d", count)
+	}
+
+	boundary := truncatedAt(repo, since)
+	if boundary == nil || !strings.HasPrefix(boundary.Message, "Add day1.txt") {
+		t.Errorf("Expected the history to be cut at the day1 commit, got %v", boundary)
+	}
+	if commit := truncatedAt(repo, time.Now().Add(-12*time.Hour)); commit != nil {
+		t.Errorf("Expected a window within the cloned history not to be truncated, got %s", commit.Hash)
+	}
+}
+
+func TestProcessRepositoriesCloneDepth(t *testing.T) {
+	source := dailyRepository(t, 5)
+
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(t.TempDir(), "notes.txt"), false)
+	vtm.ErrorHandler.MaxRetries = 0
+	vtm.Stdout = io.Discard
+	var logs bytes.Buffer
+	vtm.Logger.SetOutput(&logs)
+	vtm.NoCloneProgress = true
+	vtm.Days = 30
+	vtm.CloneDepth = 2
+
+	results, err := vtm.ProcessRepositories(context.Background(), []string{source})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("Expected the shallow clone to be analyzed, got %v", results[0].Err)
+	}
+	if commits := results[0].ReleaseNotes.WeeklySummary.TotalCommits; commits != 2 {
+		t.Errorf("Expected the 2 cloned commits, got %d", commits)
+	}
+	if !strings.Contains(logs.String(), "results may be incomplete") {
+		t.Errorf("Expected a warning about the truncated history, got:\n%s", logs.String())
+	}
+
+	logs.Reset()
+	vtm.Days = 7
+	vtm.CloneDepth = 10
+	if _, err := vtm.ProcessRepositories(context.Background(), []string{source}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(logs.String(), "results may be incomplete") {
+		t.Errorf("Expected no warning when the clone covers the history, got:\n%s", logs.String())
+	}
+}
+
+func TestShallowCloneTags(t *testing.T) {
+	source := dailyRepository(t, 5)
+	sourceRepo, err := git.PlainOpen(source)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	commits, err := sourceRepo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	var history []plumbing.Hash
+	commits.ForEach(func(c *object.Commit) error {
+		history = append(history, c.Hash)
+		return nil
+	})
+	// The oldest commit lies outside a clone of depth 2
+	if _, err := sourceRepo.CreateTag("v1.0.0", history[len(history)-1], nil); err != nil {
+		t.Fatalf("Failed to tag: %v", err)
+	}
+
+	vtm := NewVibeToolsManager(t.TempDir(), filepath.Join(t.TempDir(), "notes.txt"), false)
+	vtm.Logger.SetOutput(io.Discard)
+	vtm.CloneDepth = 2
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	if err := vtm.cloneRepository(context.Background(), source, clonePath); err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	repo, err := git.PlainOpen(clonePath)
+	if err != nil {
+		t.Fatalf("Failed to open clone: %v", err)
+	}
+	if shallowBoundary(repo) == nil {
+		t.Fatal("Expected a shallow clone")
+	}
+
+	if tag, commit, err := taggedCommit(repo, []string{"v1.0.0"}); err != nil || commit == nil || commit.Hash != history[len(history)-1] {
+		t.Errorf("Expected the tag older than the depth to resolve, got %s %v (%v)", tag, commit, err)
+	}
+	if release, err := latestReleaseTag(repo, time.Now()); err != nil || release == nil || release.Name != "v1.0.0" {
+		t.Errorf("Expected v1.0.0 as the latest release, got %+v (%v)", release, err)
+	}
+
+	// A tag whose commit the clone lacks is reported as truncated history, not as a missing tag
+	missing := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v2.0.0"), missing)); err != nil {
+		t.Fatalf("Failed to add tag: %v", err)
+	}
+	if _, _, err := taggedCommit(repo, []string{"v2.0.0"}); err == nil || !strings.Contains(err.Error(), "outside the history of the shallow clone") {
+		t.Errorf("Expected the tag to be reported outside the shallow history, got %v", err)
+	}
+	release, err := latestReleaseTag(repo, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "latest release may be missing") {
+		t.Errorf("Expected a warning that the latest release may be missing, got %v", err)
+	}
+	if release == nil || release.Name != "v1.0.0" {
+		t.Errorf("Expected the resolvable release to be kept, got %+v", release)
+	}
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RepositoryState records the branch tip analyzed for a repository in a previous run
+type RepositoryState struct {
+	Branch     string    `json:"branch"`
+	Commit     string    `json:"commit"`
+	AnalyzedAt time.Time `json:"analyzedAt"`
+}
+
+// RunState is the state file carried from one run to the next, keyed by repository URL
+type RunState struct {
+	Repositories map[string]RepositoryState `json:"repositories"`
+}
+
+// LoadRunState reads a state file; a missing file is an empty state so the first run starts one
+func LoadRunState(path string) (*RunState, error) {
+	state := &RunState{Repositories: make(map[string]RepositoryState)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	if state.Repositories == nil {
+		state.Repositories = make(map[string]RepositoryState)
+	}
+	return state, nil
+}
+
+// Save writes the state file, replacing it atomically so an interrupted run keeps the old state
+func (s *RunState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// HistoryRewrite reports that the commit analyzed in the previous run is no longer part of the
+// branch, i.e. the branch was force-pushed or rebased since
+type HistoryRewrite struct {
+	Branch         string    `json:"branch"`
+	PreviousCommit string    `json:"previousCommit"`
+	PreviousRunAt  time.Time `json:"previousRunAt"`
+	CurrentCommit  string    `json:"currentCommit"`
+}
+
+// Message explains the rewrite to readers of the notes
+func (h *HistoryRewrite) Message() string {
+	return fmt.Sprintf("branch history was rewritten since last run: commit %s analyzed on %s is no longer on %s (now at %s)",
+		h.PreviousCommit, h.PreviousRunAt.Format("2006-01-02 15:04"), h.Branch, h.CurrentCommit)
+}
+
+// detectHistoryRewrite checks whether the previously analyzed commit is still an ancestor of the
+// branch tip. A full clone only holds reachable objects, so a previous commit that is missing
+// altogether was dropped from the history as well. It returns nil when the history only moved
+// forward or when the branch was not analyzed before.
+func detectHistoryRewrite(repo *git.Repository, branch string, tip *object.Commit, previous RepositoryState) (*HistoryRewrite, error) {
+	if previous.Commit == "" || previous.Branch != branch || previous.Commit == tip.Hash.String() {
+		return nil, nil
+	}
+
+	rewrite := &HistoryRewrite{
+		Branch:         branch,
+		PreviousCommit: shortHash(previous.Commit),
+		PreviousRunAt:  previous.AnalyzedAt,
+		CurrentCommit:  tip.Hash.String()[:8],
+	}
+	previousCommit, err := repo.CommitObject(plumbing.NewHash(previous.Commit))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return rewrite, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previously analyzed commit %s: %w", rewrite.PreviousCommit, err)
+	}
+
+	ancestor, err := previousCommit.IsAncestor(tip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ancestry of %s: %w", rewrite.PreviousCommit, err)
+	}
+	if ancestor {
+		return nil, nil
+	}
+	return rewrite, nil
+}
+
+// shortHash abbreviates a commit hash the way the notes display it
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestRunStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "state.json")
+	state, err := LoadRunState(path)
+	if err != nil {
+		t.Fatalf("Expected a missing state file to load as empty, got %v", err)
+	}
+	if len(state.Repositories) != 0 {
+		t.Fatalf("Expected an empty state, got %+v", state.Repositories)
+	}
+
+	analyzedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	state.Repositories["https://github.com/test/repo"] = RepositoryState{Branch: "main", Commit: "a1b2c3d4e5f6", AnalyzedAt: analyzedAt}
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	loaded, err := LoadRunState(path)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if got := loaded.Repositories["https://github.com/test/repo"]; got.Commit != "a1b2c3d4e5f6" || !got.AnalyzedAt.Equal(analyzedAt) {
+		t.Errorf("Expected the saved repository state, got %+v", got)
+	}
+}
+
+func TestDetectHistoryRewrite(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "base.txt", "base commit")
+	base, _ := repo.Head()
+	commitFile(t, repo, dir, "first.txt", "first commit")
+	first, _ := repo.Head()
+
+	// Force-push equivalent: move the branch back to base and commit something else
+	wt, _ := repo.Worktree()
+	if err := wt.Reset(&git.ResetOptions{Commit: base.Hash(), Mode: git.HardReset}); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	commitFile(t, repo, dir, "second.txt", "replacement commit")
+	head, _ := repo.Head()
+	tip, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read tip: %v", err)
+	}
+	branch := head.Name().Short()
+
+	tests := []struct {
+		name      string
+		previous  RepositoryState
+		rewritten bool
+	}{
+		{name: "first run", previous: RepositoryState{}},
+		{name: "history moved forward", previous: RepositoryState{Branch: branch, Commit: base.Hash().String()}},
+		{name: "same tip", previous: RepositoryState{Branch: branch, Commit: head.Hash().String()}},
+		{name: "previous tip dropped by a force push", previous: RepositoryState{Branch: branch, Commit: first.Hash().String()}, rewritten: true},
+		{name: "previous tip no longer in the clone", previous: RepositoryState{Branch: branch, Commit: strings.Repeat("ab", 20)}, rewritten: true},
+		{name: "different branch", previous: RepositoryState{Branch: "release-1.0", Commit: first.Hash().String()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewrite, err := detectHistoryRewrite(repo, branch, tip, tt.previous)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (rewrite != nil) != tt.rewritten {
+				t.Fatalf("Expected rewritten=%v, got %+v", tt.rewritten, rewrite)
+			}
+			if rewrite != nil && !strings.Contains(rewrite.Message(), "branch history was rewritten since last run") {
+				t.Errorf("Unexpected message %q", rewrite.Message())
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// SubmoduleChanges is the activity of one submodule of a repository in the analysis window
+type SubmoduleChanges struct {
+	Path    string         `json:"path"`
+	URL     string         `json:"url"`
+	Commit  string         `json:"commit,omitempty"` // Commit the superproject pins the submodule to
+	Commits []CommitDetail `json:"commits"`
+	Error   string         `json:"error,omitempty"` // Why the submodule could not be analyzed
+}
+
+// analyzeSubmodules initializes and updates the submodules of a clone and returns the commits of
+// each one in the window, walking back from the commit the superproject pins. A submodule that
+// cannot be fetched, or that submoduleRemote refuses, is reported with its error instead of
+// failing the repository.
+func analyzeSubmodules(ctx context.Context, repo *git.Repository, since, until time.Time, mode DateMode, allowedHosts []string) ([]SubmoduleChanges, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+
+	var changes []SubmoduleChanges
+	for _, submodule := range submodules {
+		sub := SubmoduleChanges{Path: submodule.Config().Path, URL: submodule.Config().URL, Commits: []CommitDetail{}}
+		commits, pinned, err := submoduleCommits(ctx, repo, submodule, since, until, mode, allowedHosts)
+		if err != nil {
+			sub.Error = err.Error()
+		} else {
+			sub.Commit = pinned
+			sub.Commits = commits
+		}
+		changes = append(changes, sub)
+	}
+	return changes, nil
+}
+
+// submoduleRemote returns the URL a submodule of repo is fetched from: its .gitmodules URL,
+// resolved against the superproject's origin when relative, as go-git does. A .gitmodules file
+// comes from the analyzed repository, so local paths and file:// URLs, which would read files of
+// the machine running the analysis, are refused, and so are hosts outside allowedHosts.
+func submoduleRemote(repo *git.Repository, rawURL string, allowedHosts []string) (string, error) {
+	endpoint, err := transport.NewEndpoint(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid submodule URL %q: %w", rawURL, err)
+	}
+	if endpoint.Protocol == "file" && !path.IsAbs(endpoint.Path) && !strings.HasPrefix(rawURL, "file:") {
+		remote, err := repo.Remote(git.DefaultRemoteName)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve relative submodule URL %q: %w", rawURL, err)
+		}
+		root, err := transport.NewEndpoint(remote.Config().URLs[0])
+		if err != nil {
+			return "", err
+		}
+		root.Path = path.Join(root.Path, endpoint.Path)
+		endpoint = root
+	}
+	remoteURL := endpoint.String()
+	if endpoint.Protocol == "file" {
+		return "", fmt.Errorf("submodule URL %q is a local path, which is not fetched", rawURL)
+	}
+	if !HostAllowed(allowedHosts, remoteURL) {
+		return "", fmt.Errorf("submodule host %q is not in the allowed hosts", repositoryHost(remoteURL))
+	}
+	return remoteURL, nil
+}
+
+// submoduleCommits fetches a submodule once submoduleRemote accepts its URL, checks out the
+// pinned commit and lists the commits reachable from it whose date under mode is in the window
+func submoduleCommits(ctx context.Context, parent *git.Repository, submodule *git.Submodule, since, until time.Time, mode DateMode, allowedHosts []string) ([]CommitDetail, string, error) {
+	if _, err := submoduleRemote(parent, submodule.Config().URL, allowedHosts); err != nil {
+		return nil, "", err
+	}
+	err := submodule.UpdateContext(ctx, &git.SubmoduleUpdateOptions{Init: true, RecurseSubmodules: git.NoRecurseSubmodules})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update submodule: %w", err)
+	}
+	repo, err := submodule.Repository()
+	if err != nil {
+		return nil, "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, "", err
+	}
+
+	windowCommits, err := logWindowCommits(repo, head.Hash(), since, until, mode)
+	if err != nil {
+		return nil, "", err
+	}
+	commits := []CommitDetail{}
+	for _, c := range windowCommits {
+		commits = append(commits, CommitDetail{
+			Hash:    c.Hash.String()[:8],
+			Message: strings.TrimSpace(c.Message),
+			Author:  c.Author.Name,
+			Date:    mode.commitDate(c),
+		})
+	}
+	return commits, head.Hash().String()[:8], nil
+}
+
+// formatTextSubmoduleChanges renders the submodule section of the text notes, one nested list
+// of commits per submodule
+func (rnf *ReleaseNoteFormatter) formatTextSubmoduleChanges(submodules []SubmoduleChanges) string {
+	if len(submodules) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString("=== SUBMODULE CHANGES ===\n")
+	for _, sub := range submodules {
+		switch {
+		case sub.Error != "":
+			output.WriteString(fmt.Sprintf("%s (%s): not analyzed: %s\n", sub.Path, sub.URL, sub.Error))
+		case len(sub.Commits) == 0:
+			output.WriteString(fmt.Sprintf("%s (%s) at %s: no commits in the window\n", sub.Path, sub.URL, sub.Commit))
+		default:
+			output.WriteString(fmt.Sprintf("%s (%s) at %s: %d commits\n", sub.Path, sub.URL, sub.Commit, len(sub.Commits)))
+			for _, commit := range sub.Commits {
+				output.WriteString(fmt.Sprintf("  - %s (%s) by %s on %s\n",
+					rnf.truncateSubject(firstLine(commit.Message)),
+					commit.Hash,
+					commit.Author,
+					commit.Date.Format("2006-01-02 15:04:05")))
+			}
+		}
+	}
+	return output.String()
+}
+
+// writeMarkdownSubmoduleChanges writes the submodule section of the Markdown notes
+func writeMarkdownSubmoduleChanges(output *strings.Builder, submodules []SubmoduleChanges, heading string) {
+	if len(submodules) == 0 {
+		return
+	}
+	output.WriteString(heading + " Submodule Changes\n\n")
+	for _, sub := range submodules {
+		switch {
+		case sub.Error != "":
+			output.WriteString(fmt.Sprintf("- `%s` (%s): not analyzed: %s\n", sub.Path, sub.URL, markdownEscape(sub.Error)))
+		case len(sub.Commits) == 0:
+			output.WriteString(fmt.Sprintf("- `%s` (%s) at `%s`: no commits in the window\n", sub.Path, sub.URL, sub.Commit))
+		default:
+			output.WriteString(fmt.Sprintf("- `%s` (%s) at `%s`:\n", sub.Path, sub.URL, sub.Commit))
+			for _, commit := range sub.Commits {
+				output.WriteString(fmt.Sprintf("  - %s (`%s`) by %s on %s\n",
+					markdownEscape(firstLine(commit.Message)),
+					commit.Hash,
+					markdownEscape(commit.Author),
+					commit.Date.Format("2006-01-02")))
+			}
+		}
+	}
+	output.WriteString("\n")
+}
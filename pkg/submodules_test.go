@@ -0,0 +1,308 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/pktline"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+)
+
+// singleRepositoryLoader serves one repository at every path
+type singleRepositoryLoader struct{ storer storer.Storer }
+
+func (l singleRepositoryLoader) Load(*transport.Endpoint) (storer.Storer, error) {
+	return l.storer, nil
+}
+
+// serveGitRepository serves a repository over the smart HTTP protocol, so it can be fetched
+// from an http:// URL instead of a local path, and returns that URL
+func serveGitRepository(t *testing.T, repo *git.Repository) string {
+	t.Helper()
+	gitServer := server.NewServer(singleRepositoryLoader{repo.Storer})
+	uploadPack := func(w http.ResponseWriter, r *http.Request) (transport.UploadPackSession, bool) {
+		endpoint, err := transport.NewEndpoint("http://" + r.Host + "/repository")
+		if err == nil {
+			var session transport.UploadPackSession
+			if session, err = gitServer.NewUploadPackSession(endpoint, nil); err == nil {
+				return session, true
+			}
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repository/info/refs", func(w http.ResponseWriter, r *http.Request) {
+		session, ok := uploadPack(w, r)
+		if !ok {
+			return
+		}
+		refs, err := session.AdvertisedReferencesContext(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		encoder := pktline.NewEncoder(w)
+		encoder.EncodeString("# service=git-upload-pack\n")
+		encoder.Flush()
+		refs.Encode(w)
+	})
+	mux.HandleFunc("/repository/git-upload-pack", func(w http.ResponseWriter, r *http.Request) {
+		session, ok := uploadPack(w, r)
+		if !ok {
+			return
+		}
+		request := packp.NewUploadPackRequest()
+		if err := request.Decode(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response, err := session.UploadPack(r.Context(), request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-result")
+		response.Encode(w)
+	})
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL + "/repository"
+}
+
+// commitSubmodules commits .gitmodules and a gitlink per submodule path, which go-git's
+// worktree cannot add itself
+func commitSubmodules(t *testing.T, repo *git.Repository, dir string, submodules map[string]string, pins map[string]plumbing.Hash) {
+	t.Helper()
+	var gitmodules strings.Builder
+	for _, path := range []string{"broken", "lib"} {
+		if url, ok := submodules[path]; ok {
+			fmt.Fprintf(&gitmodules, "[submodule %q]\n\tpath = %s\n\turl = %s\n", path, path, url)
+		}
+	}
+	commitFile(t, repo, dir, ".gitmodules", gitmodules.String())
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	parent, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	tree, err := parent.Tree()
+	if err != nil {
+		t.Fatalf("Failed to read tree: %v", err)
+	}
+	entries := append([]object.TreeEntry{}, tree.Entries...)
+	for _, path := range []string{"broken", "lib"} {
+		if pin, ok := pins[path]; ok {
+			entries = append(entries, object.TreeEntry{Name: path, Mode: filemode.Submodule, Hash: pin})
+		}
+	}
+
+	newTree := &object.Tree{Entries: entries}
+	treeObject := repo.Storer.NewEncodedObject()
+	if err := newTree.Encode(treeObject); err != nil {
+		t.Fatalf("Failed to encode tree: %v", err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(treeObject)
+	if err != nil {
+		t.Fatalf("Failed to store tree: %v", err)
+	}
+	signature := object.Signature{Name: "Test Author", Email: "test@example.com", When: time.Now()}
+	commit := &object.Commit{Author: signature, Committer: signature, Message: "Add submodules", TreeHash: treeHash, ParentHashes: []plumbing.Hash{parent.Hash}}
+	commitObject := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObject); err != nil {
+		t.Fatalf("Failed to encode commit: %v", err)
+	}
+	commitHash, err := repo.Storer.SetEncodedObject(commitObject)
+	if err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), commitHash)); err != nil {
+		t.Fatalf("Failed to update %s: %v", head.Name(), err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := wt.Reset(&git.ResetOptions{Commit: commitHash, Mode: git.HardReset}); err != nil {
+		t.Fatalf("Failed to check out submodules commit: %v", err)
+	}
+}
+
+func TestAnalyzeSubmodules(t *testing.T) {
+	libDir := t.TempDir()
+	lib, err := git.PlainInit(libDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init submodule repository: %v", err)
+	}
+	commitFile(t, lib, libDir, "a.txt", "Add parser")
+	commitFile(t, lib, libDir, "b.txt", "Fix parser bug")
+	libHead, _ := lib.Head()
+	commitFile(t, lib, libDir, "c.txt", "Unpinned change")
+
+	parentDir := t.TempDir()
+	parent, err := git.PlainInit(parentDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, parent, parentDir, "main.go", "Initial commit")
+	libURL := serveGitRepository(t, lib)
+	commitSubmodules(t, parent, parentDir,
+		map[string]string{"lib": libURL, "broken": "http://127.0.0.1:1/missing"},
+		map[string]plumbing.Hash{"lib": libHead.Hash(), "broken": libHead.Hash()})
+
+	until := time.Now().Add(time.Minute)
+	changes, err := analyzeSubmodules(context.Background(), parent, until.AddDate(0, 0, -7), until, DateModeCommitter, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 submodules, got %+v", changes)
+	}
+
+	byPath := make(map[string]SubmoduleChanges)
+	for _, sub := range changes {
+		byPath[sub.Path] = sub
+	}
+	if broken := byPath["broken"]; broken.Error == "" || len(broken.Commits) != 0 {
+		t.Errorf("Expected the unreachable submodule to be noted with an error, got %+v", broken)
+	}
+	libChanges := byPath["lib"]
+	if libChanges.Error != "" {
+		t.Fatalf("Unexpected submodule error: %s", libChanges.Error)
+	}
+	if libChanges.Commit != libHead.Hash().String()[:8] {
+		t.Errorf("Expected the pinned commit %s, got %s", libHead.Hash().String()[:8], libChanges.Commit)
+	}
+	// Only the history of the pinned commit counts, not later submodule commits
+	if len(libChanges.Commits) != 2 || libChanges.Commits[0].Message != "Fix parser bug" {
+		t.Errorf("Expected the 2 pinned commits, got %+v", libChanges.Commits)
+	}
+
+	text := NewReleaseNoteFormatter().formatTextSubmoduleChanges(changes)
+	for _, expected := range []string{
+		"=== SUBMODULE CHANGES ===\n",
+		"lib (" + libURL + ") at " + libChanges.Commit + ": 2 commits\n",
+		"  - Fix parser bug (",
+		"broken (",
+		"): not analyzed: ",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected text to contain %q, got:\n%s", expected, text)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(parentDir, "lib", "b.txt")); err != nil {
+		t.Errorf("Expected the submodule to be checked out: %v", err)
+	}
+}
+
+func TestSubmoduleRemote(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{"https://github.com/example/operator"}}); err != nil {
+		t.Fatalf("Failed to add origin: %v", err)
+	}
+	allowed := []string{"github.com"}
+
+	tests := []struct {
+		url      string
+		expected string // Empty when the URL is refused
+	}{
+		{"https://github.com/example/lib.git", "https://github.com/example/lib.git"},
+		{"../lib.git", "https://github.com/example/lib.git"},
+		{"https://gitlab.com/example/lib.git", ""},
+		{"file:///etc/secrets", ""},
+		{"/var/lib/repos/lib", ""},
+	}
+	for _, tt := range tests {
+		got, err := submoduleRemote(repo, tt.url, allowed)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("Expected %q to be refused, got %q", tt.url, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("Expected %q to be fetched from %q, got %q (%v)", tt.url, tt.expected, got, err)
+		}
+	}
+
+	local, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if _, err := local.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{"/srv/bundles/operator.bundle"}}); err != nil {
+		t.Fatalf("Failed to add origin: %v", err)
+	}
+	if got, err := submoduleRemote(local, "../lib.git", nil); err == nil {
+		t.Errorf("Expected a relative URL of a local clone to be refused, got %q", got)
+	}
+}
+
+func TestAnalyzeSubmodulesDateMode(t *testing.T) {
+	now := time.Now()
+	libDir := t.TempDir()
+	lib, err := git.PlainInit(libDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init submodule repository: %v", err)
+	}
+	wt, err := lib.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(libDir, "picked.txt"), []byte("picked"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wt.Add("picked.txt"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	// Written months ago and cherry-picked yesterday
+	if _, err := wt.Commit("Cherry-pick old fix", &git.CommitOptions{
+		Author:    &object.Signature{Name: "Test Author", Email: "test@example.com", When: now.AddDate(0, -3, 0)},
+		Committer: &object.Signature{Name: "Test Committer", Email: "committer@example.com", When: now.AddDate(0, 0, -1)},
+	}); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	libHead, _ := lib.Head()
+
+	until := now.Add(time.Minute)
+	for mode, expected := range map[DateMode]int{DateModeCommitter: 1, DateModeAuthor: 0} {
+		parentDir := t.TempDir()
+		parent, err := git.PlainInit(parentDir, false)
+		if err != nil {
+			t.Fatalf("Failed to init repository: %v", err)
+		}
+		commitFile(t, parent, parentDir, "main.go", "Initial commit")
+		commitSubmodules(t, parent, parentDir, map[string]string{"lib": serveGitRepository(t, lib)}, map[string]plumbing.Hash{"lib": libHead.Hash()})
+
+		changes, err := analyzeSubmodules(context.Background(), parent, now.AddDate(0, 0, -7), until, mode, nil)
+		if err != nil || len(changes) != 1 || changes[0].Error != "" {
+			t.Fatalf("%s: unexpected result %+v (%v)", mode, changes, err)
+		}
+		if len(changes[0].Commits) != expected {
+			t.Errorf("%s: expected %d commits in the window, got %+v", mode, expected, changes[0].Commits)
+		}
+	}
+}
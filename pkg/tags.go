@@ -0,0 +1,267 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// parseTagVersion parses a tag name as a semver version with an optional pre-release suffix,
+// e.g. "v1.3.0-rc.1"
+func parseTagVersion(name string) (version semver, prerelease string, ok bool) {
+	core, _, _ := strings.Cut(name, "+")
+	core, prerelease, _ = strings.Cut(core, "-")
+	version, ok = parseReleaseVersion(core)
+	return version, prerelease, ok
+}
+
+// sortTags orders tags by semver, newest first, with a release ahead of its pre-releases.
+// Tags that are not versions follow in name order.
+func sortTags(tags []string) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		vi, pi, oki := parseTagVersion(tags[i])
+		vj, pj, okj := parseTagVersion(tags[j])
+		switch {
+		case oki != okj:
+			return oki
+		case !oki:
+			return tags[i] < tags[j]
+		case vi != vj:
+			return vj.less(vi)
+		case (pi == "") != (pj == ""):
+			return pi == ""
+		case pi != pj:
+			return pi > pj
+		}
+		return tags[i] < tags[j]
+	})
+}
+
+// listRemoteTags lists the tags of a repository with an ls-remote, without cloning it
+func listRemoteTags(ctx context.Context, repoURL string, auth transport.AuthMethod) ([]string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		if IsAuthError(err) {
+			return nil, NewAuthRequiredError(repoURL, err)
+		}
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	tags := []string{}
+	for _, ref := range refs {
+		// Peeled entries of annotated tags ("v1.0.0^{}") repeat the tag
+		if ref.Name().IsTag() && !strings.HasSuffix(ref.Name().String(), "^{}") {
+			tags = append(tags, ref.Name().Short())
+		}
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+// handleTags returns the tags of a repository, newest version first
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	repoURL := r.URL.Query().Get("repository")
+	if repoURL == "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "repository parameter is required",
+		})
+		return
+	}
+	if err := serverRepositoryError(s.AllowedHosts, repoURL); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	tags, err := listRemoteTags(r.Context(), repoURL, cloneAuth(repoURL, s.requestCredentials(r.Header.Get(GitTokenHeader), repoURL)))
+	if err != nil {
+		s.Logger.Errorf("Failed to list tags for %s: %v", repoURL, err)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   ErrorWithRemediation(err),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"tags":    tags,
+	})
+}
+
+// resolveTagCommit returns the commit a tag points at, peeling annotated tags
+func resolveTagCommit(repo *git.Repository, name string) (*object.Commit, error) {
+	ref, err := repo.Tag(name)
+	if err != nil {
+		return nil, fmt.Errorf("tag %s not found: %w", name, err)
+	}
+	if tag, err := repo.TagObject(ref.Hash()); err == nil {
+		commit, err := tag.Commit()
+		if err != nil {
+			return nil, fmt.Errorf("tag %s does not point to a commit: %w", name, err)
+		}
+		return commit, nil
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("tag %s does not point to a commit: %w", name, err)
+	}
+	return commit, nil
+}
+
+// commitsBetween returns the commits reachable from to that are not reachable from from,
+// like git log from..to
+func commitsBetween(repo *git.Repository, from, to plumbing.Hash) ([]*object.Commit, error) {
+	excluded, err := ancestorSet(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := logCommits(repo, to, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history from %s: %w", to.String()[:8], err)
+	}
+	var commits []*object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !excluded[c.Hash] {
+			commits = append(commits, c)
+		}
+		return nil
+	})
+	return commits, err
+}
+
+// calendarDays counts the calendar days from since to until, both included, at least 1
+func calendarDays(since, until time.Time) int {
+	sinceDay := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+	untilDay := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, time.UTC)
+	if days := int(untilDay.Sub(sinceDay).Hours()/24) + 1; days > 1 {
+		return days
+	}
+	return 1
+}
+
+// openTagRepository returns a repository holding every tag of the request's repository and its
+// path. Requests without their own token read the repository cache, fetching it again when
+// one of the tags is missing; the others get a fresh clone. done releases the cached clone or
+// removes the fresh one.
+func (s *Server) openTagRepository(ctx context.Context, req ReleaseNotesRequest) (repo *git.Repository, repoPath string, done func(), err error) {
+	repoURL, fromTag, toTag := req.Repository, req.FromTag, req.ToTag
+	if req.Token == "" {
+		entry := s.lockCachedRepository(repoURL)
+		repo, err = s.syncCachedRepository(ctx, entry, repoURL, false)
+		if err == nil && !hasTags(repo, fromTag, toTag) {
+			// The tags may have been pushed since the clone was last fetched
+			repo, err = s.syncCachedRepository(ctx, entry, repoURL, true)
+		}
+		if err != nil {
+			entry.mu.Unlock()
+			return nil, "", nil, err
+		}
+		return repo, entry.path, entry.mu.Unlock, nil
+	}
+
+	repoPath = filepath.Join(s.WorkDir, "analysis", extractRepoNameFromURL(repoURL), NewBranchPath("tags-"+fromTag+".."+toTag).Segment)
+	os.RemoveAll(repoPath)
+	os.MkdirAll(filepath.Dir(repoPath), 0755)
+	done = func() {
+		os.RemoveAll(repoPath)
+		os.Remove(filepath.Dir(repoPath))
+	}
+
+	s.Logger.Infof("Cloning %s (tags: %s..%s) for analysis...", repoURL, fromTag, toTag)
+
+	_, cloneSpan := StartSpan(ctx, "git.clone", attribute.String("repository", repoURL), attribute.String("tags", fromTag+".."+toTag))
+	repo, err = git.PlainClone(repoPath, false, &git.CloneOptions{
+		URL:        repoURL,
+		NoCheckout: true,
+		Tags:       git.AllTags,
+		Auth:       cloneAuth(repoURL, s.requestCredentials(req.Token, req.Repository)),
+	})
+	EndSpan(cloneSpan, err)
+	if err != nil {
+		done()
+		if IsAuthError(err) {
+			return nil, "", nil, NewAuthRequiredError(repoURL, err)
+		}
+		return nil, "", nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+	logCloneStats(s.Logger, repoURL, repoPath)
+	return repo, repoPath, done, nil
+}
+
+// hasTags reports whether the repository has every one of the tags
+func hasTags(repo *git.Repository, names ...string) bool {
+	for _, name := range names {
+		if _, err := repo.Tag(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generateReleaseNotesBetweenTags generates release notes for the commits of req.ToTag that
+// are not in req.FromTag. The window shown runs from the commit date of FromTag to that of
+// ToTag. The clone always holds full history, since FromTag's ancestors must all be known.
+// The stages are reported to progress.
+func (s *Server) generateReleaseNotesBetweenTags(ctx context.Context, req ReleaseNotesRequest, progress ProgressFunc) (notes releaseNotes, err error) {
+	repoURL, fromTag, toTag := req.Repository, req.FromTag, req.ToTag
+	release, err := s.acquireCloneSlot(ctx)
+	if err != nil {
+		return releaseNotes{}, err
+	}
+	defer release()
+
+	progress.reportStage(ProgressCloning, req.Repository)
+	repo, repoPath, done, err := s.openTagRepository(ctx, req)
+	if err != nil {
+		return releaseNotes{}, err
+	}
+	defer done()
+	progress.reportStage(ProgressAnalyzing, req.Repository)
+
+	ctx, analyzeSpan := StartSpan(ctx, "repository.analyze", attribute.String("repository", repoURL), attribute.String("tags", fromTag+".."+toTag))
+	defer func() { EndSpan(analyzeSpan, err) }()
+
+	from, err := resolveTagCommit(repo, fromTag)
+	if err != nil {
+		return releaseNotes{}, err
+	}
+	to, err := resolveTagCommit(repo, toTag)
+	if err != nil {
+		return releaseNotes{}, err
+	}
+
+	s.Logger.Infof("Analyzing commits from %s to %s", fromTag, toTag)
+	commits, err := commitsBetween(repo, from.Hash, to.Hash)
+	if err != nil {
+		return releaseNotes{}, fmt.Errorf("failed to get commit log: %w", err)
+	}
+	commits = filterCommitsByAuthor(commits, s.OnlyAuthors)
+
+	since, until := from.Committer.When, to.Committer.When
+	window := notesWindow{since: since, until: until, days: calendarDays(since, until), absolute: true, fromTag: fromTag, toTag: toTag}
+	return s.renderReleaseNotes(ctx, req, repoPath, toTag, to, commits, window, nil, progress), nil
+}
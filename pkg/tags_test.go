@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestSortTags(t *testing.T) {
+	tags := []string{"v1.2.0", "latest", "v1.10.0", "v1.10.0-rc.1", "v1.10.0-rc.2", "0.9.0", "nightly"}
+	sortTags(tags)
+	expected := "v1.10.0,v1.10.0-rc.2,v1.10.0-rc.1,v1.2.0,0.9.0,latest,nightly"
+	if got := strings.Join(tags, ","); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+// taggedRepository creates a repository with the commits "first" (tagged v1.0.0, annotated),
+// "second", "third" (tagged v1.1.0) and "fourth"
+func taggedRepository(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitFile(t, repo, dir, "a.txt", "first")
+	tagHead(t, repo, "v1.0.0", time.Now())
+	commitFile(t, repo, dir, "b.txt", "second")
+	commitFile(t, repo, dir, "c.txt", "third")
+	tagHead(t, repo, "v1.1.0", time.Time{})
+	commitFile(t, repo, dir, "d.txt", "fourth")
+	return dir
+}
+
+func TestGenerateReleaseNotesBetweenTags(t *testing.T) {
+	dir := taggedRepository(t)
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Logger.SetOutput(io.Discard)
+
+	notes, err := s.generateReleaseNotesBetweenTags(context.Background(), ReleaseNotesRequest{Repository: dir, FromTag: "v1.0.0", ToTag: "v1.1.0"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	textNotes, htmlNotes := notes.text, notes.html
+	if !strings.Contains(textNotes, "Total Commits: 2\n") || !strings.Contains(textNotes, "=== COMMITS FROM V1.0.0..V1.1.0 ===") {
+		t.Errorf("Expected the 2 commits between the tags, got:\n%s", textNotes)
+	}
+	for _, message := range []string{"- second (", "- third ("} {
+		if !strings.Contains(textNotes, message) {
+			t.Errorf("Expected %q in the notes", message)
+		}
+	}
+	for _, message := range []string{"- first (", "- fourth ("} {
+		if strings.Contains(textNotes, message) {
+			t.Errorf("Expected %q to be outside the tag range", message)
+		}
+	}
+	if !strings.Contains(textNotes, "Analysis Period: v1.0.0..v1.1.0 (") {
+		t.Errorf("Expected the tags as the analysis period, got:\n%s", textNotes)
+	}
+	if !strings.Contains(htmlNotes, "v1.0.0 → v1.1.0") {
+		t.Errorf("Expected the tag range in the HTML notes")
+	}
+
+	if _, err := s.generateReleaseNotesBetweenTags(context.Background(), ReleaseNotesRequest{Repository: dir, FromTag: "v0.1.0", ToTag: "v1.1.0"}, nil); err == nil || !strings.Contains(err.Error(), "tag v0.1.0 not found") {
+		t.Errorf("Expected an unknown tag error, got %v", err)
+	}
+}
+
+func TestHandleTags(t *testing.T) {
+	dir := taggedRepository(t)
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	recorder := httptest.NewRecorder()
+	s.handleTags(recorder, httptest.NewRequest(http.MethodGet, "/api/tags?repository="+url.QueryEscape(dir), nil))
+	var response struct {
+		Success bool     `json:"success"`
+		Tags    []string `json:"tags"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Success || strings.Join(response.Tags, ",") != "v1.1.0,v1.0.0" {
+		t.Errorf("Expected the tags newest first, got %+v", response)
+	}
+}
+
+func TestHandleTagsCredentials(t *testing.T) {
+	var passwords []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, password, _ := r.BasicAuth()
+		passwords = append(passwords, password)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	s.Credentials = &GitCredentials{Auth: gitCredentials("robot", "server-token"), Hosts: []string{"github.com"}}
+	repoURL := server.URL + "/attacker/collect"
+
+	s.handleTags(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/tags?repository="+url.QueryEscape(repoURL), nil))
+	request := httptest.NewRequest(http.MethodGet, "/api/tags?repository="+url.QueryEscape(repoURL), nil)
+	request.Header.Set(GitTokenHeader, "request-token")
+	s.handleTags(httptest.NewRecorder(), request)
+
+	if len(passwords) != 2 || passwords[0] != "" || passwords[1] != "request-token" {
+		t.Errorf("Expected no server token and then the request's own token, got %q", passwords)
+	}
+}
+
+func TestHandleReleaseNotesRequiresBothTags(t *testing.T) {
+	s := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"repository": "https://github.com/example/operator", "fromTag": "v1.0.0"}`)
+	s.handleReleaseNotes(recorder, httptest.NewRequest(http.MethodPost, "/api/release-notes", body))
+	var response ReleaseNotesResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Success || response.ErrorMessage != "fromTag and toTag must be set together" {
+		t.Errorf("Expected an error for a single tag, got %+v", response)
+	}
+}
@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// githubCodeloadBase serves GitHub's source tarballs over plain HTTPS
+var githubCodeloadBase = "https://codeload.github.com"
+
+// tarballHTTPClient downloads source tarballs, which can take much longer than an API call
+var tarballHTTPClient = &http.Client{Timeout: 10 * time.Minute}
+
+// TarballSnapshot is the current state of a repository read from its GitHub tarball when the
+// repository could not be cloned. A tarball has no history, so it only tells what the default
+// branch holds.
+type TarballSnapshot struct {
+	Source string `json:"source"`           // Tarball URL
+	Commit string `json:"commit,omitempty"` // Commit the tarball was made from, when the archive records it
+	Files  int    `json:"files"`
+	Bytes  int64  `json:"bytes"`
+	Reason string `json:"reason"` // Why the history is unavailable, i.e. the clone error
+}
+
+// Note explains the missing history to readers of the notes
+func (s *TarballSnapshot) Note() string {
+	return fmt.Sprintf("commit history unavailable, showing only the current state from %s (git clone failed: %s)", s.Source, s.Reason)
+}
+
+// tarballFallbackApplies reports whether a failed clone of repoURL can fall back to the GitHub
+// tarball: only GitHub repositories whose clone failed for a network or protocol reason, not
+// because credentials are missing
+func tarballFallbackApplies(repoURL string, cloneErr error) bool {
+	if _, _, ok := parseGitHubRepo(repoURL); !ok {
+		return false
+	}
+	var analyzerErr *AnalyzerError
+	if errors.As(cloneErr, &analyzerErr) && analyzerErr.Type == ErrorTypeValidation {
+		return false
+	}
+	return !IsAuthError(cloneErr)
+}
+
+// downloadGitHubTarball downloads the default branch tarball of a GitHub repository from
+// codeload and extracts it into repoPath
+func downloadGitHubTarball(ctx context.Context, repoURL, repoPath string) (*TarballSnapshot, error) {
+	owner, name, ok := parseGitHubRepo(repoURL)
+	if !ok {
+		return nil, fmt.Errorf("not a GitHub repository: %s", repoURL)
+	}
+	source := fmt.Sprintf("%s/%s/%s/tar.gz/HEAD", githubCodeloadBase, owner, name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := tarballHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download tarball: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tarball download returned HTTP %d", resp.StatusCode)
+	}
+
+	snapshot, err := extractTarball(resp.Body, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Source = source
+	return snapshot, nil
+}
+
+// extractTarball extracts a gzipped source tarball into dir, dropping the top-level directory
+// GitHub wraps the files in. Entries that would land outside dir are rejected.
+func extractTarball(r io.Reader, dir string) (*TarballSnapshot, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tarball: %w", err)
+	}
+	defer gz.Close()
+
+	snapshot := &TarballSnapshot{}
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return snapshot, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tarball: %w", err)
+		}
+
+		// git archive records the commit in the global header's comment
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			snapshot.Commit = header.PAXRecords["comment"]
+			continue
+		}
+		_, name, _ := strings.Cut(path.Clean(header.Name), "/")
+		if name == "" {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("tarball entry %q is outside the repository", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return nil, err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return nil, err
+			}
+			written, err := io.Copy(file, archive)
+			file.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+			}
+			snapshot.Files++
+			snapshot.Bytes += written
+		}
+	}
+}
+
+// formatTextSnapshot renders the current state section that replaces the history sections of
+// the text notes when only a tarball was available
+func formatTextSnapshot(snapshot *TarballSnapshot) string {
+	var output strings.Builder
+	output.WriteString("=== CURRENT STATE (NO HISTORY) ===\n")
+	if snapshot.Commit != "" {
+		output.WriteString(fmt.Sprintf("Commit: %s\n", snapshot.Commit))
+	}
+	output.WriteString(fmt.Sprintf("Files: %d\n", snapshot.Files))
+	output.WriteString(fmt.Sprintf("Size: %.1f MB\n", float64(snapshot.Bytes)/(1024*1024)))
+	output.WriteString(fmt.Sprintf("Source: %s\n", snapshot.Source))
+	return output.String()
+}
+
+// snapshotFromTarball produces notes holding only the current state of a GitHub repository
+// whose clone failed with cloneErr, from its codeload tarball
+func (vtm *VibeToolsManager) snapshotFromTarball(ctx context.Context, repoURL, repoPath string, cloneErr error) (RepositoryNotes, error) {
+	vtm.Logger.Warnf("Clone of %s failed (%v), downloading its tarball instead; the notes will have no commit history", repoURL, cloneErr)
+	if err := os.RemoveAll(repoPath); err != nil {
+		vtm.Logger.Warnf("Failed to remove existing directory %s: %v", repoPath, err)
+	}
+	snapshot, err := downloadGitHubTarball(ctx, repoURL, repoPath)
+	if err != nil {
+		return RepositoryNotes{}, WrapError(err, ErrorTypeNetwork, "failed to clone repository or download its tarball", map[string]interface{}{
+			"repository":  repoURL,
+			"clone_error": cloneErr.Error(),
+		})
+	}
+	snapshot.Reason = cloneErr.Error()
+	if err := os.RemoveAll(repoPath); err != nil {
+		vtm.Logger.Warnf("Failed to clean up repository directory %s: %v", repoPath, err)
+	}
+
+	window := vtm.analysisWindowFor(repoURL)
+	format := vtm.Formatter.CreateStandardFormatWithDays(
+		repoURL,
+		window.days,
+		window.since,
+		window.until,
+		CommitInfo{Hash: shortHash(snapshot.Commit)},
+		WeeklySummary{AnalysisStart: window.since, AnalysisEnd: window.until},
+		nil,
+		nil,
+	)
+	if window.absolute {
+		vtm.Formatter.SetAbsoluteRange(&format)
+	}
+	format.Snapshot = snapshot
+	return RepositoryNotes{Format: &format, Text: vtm.Formatter.FormatReleaseNote(format)}, nil
+}
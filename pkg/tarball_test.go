@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// sourceTarball builds a gzipped tarball shaped like GitHub's: a global header naming the
+// commit and every file below a "<repo>-<commit>/" directory
+func sourceTarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	headers := []*tar.Header{
+		{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "0123456789abcdef0123456789abcdef01234567"}},
+		{Typeflag: tar.TypeDir, Name: "operator-0123456/", Mode: 0755},
+	}
+	for _, header := range headers {
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+	}
+	for name, content := range files {
+		if err := archive.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "operator-0123456/" + name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := archive.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write tar entry: %v", err)
+		}
+	}
+	archive.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestTarballFallbackApplies(t *testing.T) {
+	networkErr := WrapError(errors.New("connection reset by peer"), ErrorTypeGit, "failed to clone repository", nil)
+	tests := []struct {
+		name     string
+		repoURL  string
+		err      error
+		expected bool
+	}{
+		{name: "github network failure", repoURL: "https://github.com/test/operator", err: networkErr, expected: true},
+		{name: "other host", repoURL: "https://gitlab.com/test/operator", err: networkErr, expected: false},
+		{name: "missing credentials", repoURL: "https://github.com/test/operator", err: NewAuthRequiredError("https://github.com/test/operator", transport.ErrAuthenticationRequired), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tarballFallbackApplies(tt.repoURL, tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSnapshotFromTarball(t *testing.T) {
+	tarball := sourceTarball(t, map[string]string{"README.md": "# Operator\n", "main.go": "package main\n"})
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Write(tarball)
+	}))
+	defer server.Close()
+	defer func(base string) { githubCodeloadBase = base }(githubCodeloadBase)
+	githubCodeloadBase = server.URL
+
+	vtm := NewVibeToolsManager(t.TempDir(), "notes.txt", false)
+	repoPath := filepath.Join(vtm.WorkDir, "operator")
+	cloneErr := WrapError(errors.New("unexpected EOF"), ErrorTypeGit, "failed to clone repository", nil)
+	notes, err := vtm.snapshotFromTarball(context.Background(), "https://github.com/test/operator", repoPath, cloneErr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if requested != "/test/operator/tar.gz/HEAD" {
+		t.Errorf("Expected the default branch tarball to be requested, got %s", requested)
+	}
+	snapshot := notes.Format.Snapshot
+	if snapshot == nil || snapshot.Files != 2 || snapshot.Bytes != int64(len("# Operator\n")+len("package main\n")) {
+		t.Fatalf("Unexpected snapshot %+v", snapshot)
+	}
+	if snapshot.Commit != "0123456789abcdef0123456789abcdef01234567" || notes.Format.LatestCommit.Hash != "01234567" {
+		t.Errorf("Expected the commit from the archive header, got %s (latest %s)", snapshot.Commit, notes.Format.LatestCommit.Hash)
+	}
+	for _, expected := range []string{
+		"⚠️ WARNING: commit history unavailable",
+		"git clone failed: [GIT_ERROR] failed to clone repository: unexpected EOF",
+		"=== CURRENT STATE (NO HISTORY) ===\nCommit: 0123456789abcdef0123456789abcdef01234567\nFiles: 2\n",
+	} {
+		if !strings.Contains(notes.Text, expected) {
+			t.Errorf("Expected notes to contain %q, got:\n%s", expected, notes.Text)
+		}
+	}
+	if strings.Contains(notes.Text, "=== NO COMMITS") {
+		t.Errorf("Expected no commit sections without history, got:\n%s", notes.Text)
+	}
+	if _, err := os.Stat(repoPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the extracted tarball to be cleaned up")
+	}
+}
+
+func TestExtractTarballRejectsEscapingEntries(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	archive.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "operator-0123456/../../../escape.txt", Mode: 0644})
+	archive.Close()
+	gz.Close()
+
+	dir := t.TempDir()
+	if _, err := extractTarball(&buf, filepath.Join(dir, "repo")); err == nil {
+		t.Fatal("Expected an error for an entry outside the repository")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file to be written outside the repository")
+	}
+}
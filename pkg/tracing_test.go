@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans routes spans to an in-memory recorder for the duration of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInitTracingDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	if TracingEnabled() {
+		t.Fatal("Expected tracing to be disabled without an OTLP endpoint")
+	}
+	shutdown, err := InitTracing(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Expected no-op shutdown, got %v", err)
+	}
+}
+
+func TestSpansAreNested(t *testing.T) {
+	recorder := recordSpans(t)
+
+	server := NewServer(0, t.TempDir(), t.TempDir(), "", nil)
+	repoURL := "https://github.com/test/operator"
+	server.branchCache = map[branchCacheKey]cachedBranches{newBranchCacheKey(repoURL, ""): {branches: []string{"main"}, fetchedAt: time.Now()}}
+
+	ctx, parent := StartSpan(context.Background(), "request")
+	if _, err := server.cachedBranchList(ctx, repoURL, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, failed := StartSpan(ctx, "git.clone")
+	EndSpan(failed, errors.New("authentication required"))
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	branches, clone := spans[0], spans[1]
+	if branches.Name() != "git.branches" || branches.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Expected git.branches as a child of the request span, got %s", branches.Name())
+	}
+	hit := false
+	for _, attr := range branches.Attributes() {
+		if attr.Key == "cache.hit" {
+			hit = attr.Value.AsBool()
+		}
+	}
+	if !hit {
+		t.Error("Expected the cached branch list to be recorded as a cache hit")
+	}
+	if clone.Status().Code != codes.Error || len(clone.Events()) == 0 {
+		t.Errorf("Expected the failed span to record the error, got status %v", clone.Status())
+	}
+}
+
+func TestRequestSpansNamedByRoute(t *testing.T) {
+	recorder := recordSpans(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {})
+	handler := traceRequests(mux)
+
+	for _, path := range []string{"/api/status", "/random/a1b2c3", "/another/d4e5f6"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	want := []string{"GET /api/status", "GET /", "GET /"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected spans %v, got %v", want, names)
+	}
+}
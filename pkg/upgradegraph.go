@@ -0,0 +1,324 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UpgradeGraphFormat selects how --upgrade-graph renders the catalog's upgrade edges
+type UpgradeGraphFormat string
+
+const (
+	UpgradeGraphText UpgradeGraphFormat = "text" // Adjacency list, one line per bundle that can be upgraded
+	UpgradeGraphDOT  UpgradeGraphFormat = "dot"  // Graphviz digraph with a cluster per channel
+)
+
+// ParseUpgradeGraphFormat validates an --upgrade-graph value
+func ParseUpgradeGraphFormat(value string) (UpgradeGraphFormat, error) {
+	switch format := UpgradeGraphFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case UpgradeGraphText, UpgradeGraphDOT:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown upgrade graph format %q (expected text or dot)", value)
+}
+
+// UpgradeEdge is an upgrade path within a channel: a subscription on From can move to To
+// because To replaces or skips it
+type UpgradeEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"` // "replaces" or "skips"
+}
+
+// ChannelUpgradeGraph is the upgrade topology of one channel of a package
+type ChannelUpgradeGraph struct {
+	Package    string            `json:"package"`
+	Channel    string            `json:"channel"`
+	Default    bool              `json:"default,omitempty"`    // The package's default channel
+	Entries    []string          `json:"entries"`              // Bundles in the channel, in catalog order
+	Heads      []string          `json:"heads,omitempty"`      // Entries no other entry replaces or skips
+	Edges      []UpgradeEdge     `json:"edges,omitempty"`      // Sorted by From, then To
+	SkipRanges map[string]string `json:"skipRanges,omitempty"` // Entry -> semver range of versions it upgrades directly from
+}
+
+// BuildUpgradeGraphs turns the replaces, skips and skipRange of every channel entry into upgrade
+// edges, one graph per channel, sorted by package and channel. Edges may start at bundles that
+// are no longer part of the channel, as older catalogs prune them.
+func BuildUpgradeGraphs(packages []Package) []ChannelUpgradeGraph {
+	var graphs []ChannelUpgradeGraph
+	for _, pkg := range packages {
+		for _, channel := range pkg.Channels {
+			graph := ChannelUpgradeGraph{
+				Package: pkg.Name,
+				Channel: channel.Name,
+				Default: channel.Name == pkg.DefaultChannel,
+			}
+			superseded := make(map[string]bool)
+			for _, entry := range channel.Entries {
+				if entry.Name == "" || containsString(graph.Entries, entry.Name) {
+					continue
+				}
+				graph.Entries = append(graph.Entries, entry.Name)
+				if entry.Replaces != "" {
+					graph.Edges = append(graph.Edges, UpgradeEdge{From: entry.Replaces, To: entry.Name, Kind: "replaces"})
+					superseded[entry.Replaces] = true
+				}
+				for _, skip := range entry.Skips {
+					if skip == "" {
+						continue
+					}
+					graph.Edges = append(graph.Edges, UpgradeEdge{From: skip, To: entry.Name, Kind: "skips"})
+					superseded[skip] = true
+				}
+				if entry.SkipRange != "" {
+					if graph.SkipRanges == nil {
+						graph.SkipRanges = make(map[string]string)
+					}
+					graph.SkipRanges[entry.Name] = entry.SkipRange
+				}
+			}
+			for _, name := range graph.Entries {
+				if !superseded[name] {
+					graph.Heads = append(graph.Heads, name)
+				}
+			}
+			sort.SliceStable(graph.Edges, func(i, j int) bool {
+				if graph.Edges[i].From != graph.Edges[j].From {
+					return graph.Edges[i].From < graph.Edges[j].From
+				}
+				return graph.Edges[i].To < graph.Edges[j].To
+			})
+			graphs = append(graphs, graph)
+		}
+	}
+	sort.SliceStable(graphs, func(i, j int) bool {
+		if graphs[i].Package != graphs[j].Package {
+			return graphs[i].Package < graphs[j].Package
+		}
+		return graphs[i].Channel < graphs[j].Channel
+	})
+	return graphs
+}
+
+// FormatUpgradeGraphs renders the graphs in the given format
+func FormatUpgradeGraphs(graphs []ChannelUpgradeGraph, format UpgradeGraphFormat) string {
+	if format == UpgradeGraphDOT {
+		return formatUpgradeGraphsDOT(graphs)
+	}
+	return formatUpgradeGraphsText(graphs)
+}
+
+// formatUpgradeGraphsText lists, per channel, every bundle with the bundles it can upgrade to:
+//
+//	=== compliance-operator / stable (default) ===
+//	compliance-operator.v1.0.0 -> compliance-operator.v1.1.0 (replaces), compliance-operator.v1.2.0 (skips)
+//	compliance-operator.v1.2.0 [head] skipRange >=1.0.0 <1.2.0
+func formatUpgradeGraphsText(graphs []ChannelUpgradeGraph) string {
+	var sb strings.Builder
+	for i, graph := range graphs {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		header := graph.Package + " / " + graph.Channel
+		if graph.Default {
+			header += " (default)"
+		}
+		sb.WriteString(fmt.Sprintf("=== %s ===\n", header))
+
+		targets := make(map[string][]string)
+		var sources []string
+		for _, edge := range graph.Edges {
+			if _, ok := targets[edge.From]; !ok {
+				sources = append(sources, edge.From)
+			}
+			targets[edge.From] = append(targets[edge.From], fmt.Sprintf("%s (%s)", edge.To, edge.Kind))
+		}
+		nodes := append([]string{}, graph.Entries...)
+		for _, source := range sources {
+			if !containsString(nodes, source) {
+				nodes = append(nodes, source)
+			}
+		}
+		sort.Strings(nodes)
+
+		for _, node := range nodes {
+			line := node
+			if containsString(graph.Heads, node) {
+				line += " [head]"
+			}
+			if !containsString(graph.Entries, node) {
+				line += " [not in channel]"
+			}
+			if upgrades := targets[node]; len(upgrades) > 0 {
+				line += " -> " + strings.Join(upgrades, ", ")
+			}
+			if skipRange := graph.SkipRanges[node]; skipRange != "" {
+				line += " skipRange " + skipRange
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// formatUpgradeGraphsDOT renders the graphs as a Graphviz digraph. Each channel is a cluster
+// whose node ids are prefixed with the package and channel, so a bundle in several channels
+// appears once per channel. Replaces edges are solid, skips edges dashed, and heads bold.
+func formatUpgradeGraphsDOT(graphs []ChannelUpgradeGraph) string {
+	var sb strings.Builder
+	sb.WriteString("digraph upgrades {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for i, graph := range graphs {
+		prefix := graph.Package + "/" + graph.Channel + "/"
+		label := graph.Package + " / " + graph.Channel
+		if graph.Default {
+			label += " (default)"
+		}
+		sb.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
+		sb.WriteString(fmt.Sprintf("    label=%s;\n", strconv.Quote(label)))
+
+		nodes := append([]string{}, graph.Entries...)
+		for _, edge := range graph.Edges {
+			if !containsString(nodes, edge.From) {
+				nodes = append(nodes, edge.From)
+			}
+		}
+		for _, node := range nodes {
+			nodeLabel := node
+			if skipRange := graph.SkipRanges[node]; skipRange != "" {
+				nodeLabel += "\nskipRange " + skipRange
+			}
+			attrs := []string{"label=" + strconv.Quote(nodeLabel)}
+			if containsString(graph.Heads, node) {
+				attrs = append(attrs, "style=bold")
+			}
+			if !containsString(graph.Entries, node) {
+				attrs = append(attrs, "color=gray")
+			}
+			sb.WriteString(fmt.Sprintf("    %s [%s];\n", strconv.Quote(prefix+node), strings.Join(attrs, ", ")))
+		}
+		for _, edge := range graph.Edges {
+			style := ""
+			if edge.Kind == "skips" {
+				style = " [style=dashed]"
+			}
+			sb.WriteString(fmt.Sprintf("    %s -> %s%s;\n", strconv.Quote(prefix+edge.From), strconv.Quote(prefix+edge.To), style))
+		}
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// ParseCatalogPackages reads the packages of an index with their channels and entries. A
+// structured index already nests them; a file-based catalog is assembled from its olm.package
+// and olm.channel blobs or, in sqlite-based renders, from the bundles' olm.channel properties.
+func ParseCatalogPackages(filePath string) ([]Package, error) {
+	content, err := readIndexFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var index OperatorIndex
+	if err := json.Unmarshal(content, &index); err == nil && len(index.Packages) > 0 {
+		return index.Packages, nil
+	}
+
+	entries, ok := splitJSONObjects(string(content))
+	if !ok {
+		return nil, WrapError(nil, ErrorTypeParsing, "failed to parse JSON", map[string]interface{}{
+			"file_path": filePath,
+			"file_size": len(content),
+		})
+	}
+	return catalogPackages(entries), nil
+}
+
+// catalogPackages assembles Package values from the blobs of a file-based catalog, keeping the
+// order in which packages and channels first appear
+func catalogPackages(entries []map[string]interface{}) []Package {
+	var packages []Package
+	packageIndex := make(map[string]int)
+	packageFor := func(name string) *Package {
+		i, ok := packageIndex[name]
+		if !ok {
+			i = len(packages)
+			packageIndex[name] = i
+			packages = append(packages, Package{Schema: "olm.package", Name: name})
+		}
+		return &packages[i]
+	}
+	channelFor := func(pkg *Package, name string) *Channel {
+		for i := range pkg.Channels {
+			if pkg.Channels[i].Name == name {
+				return &pkg.Channels[i]
+			}
+		}
+		pkg.Channels = append(pkg.Channels, Channel{Name: name})
+		return &pkg.Channels[len(pkg.Channels)-1]
+	}
+
+	for _, entry := range entries {
+		schema, _ := entry["schema"].(string)
+		name, _ := entry["name"].(string)
+		packageName, _ := entry["package"].(string)
+		switch schema {
+		case "olm.package":
+			if name == "" {
+				continue
+			}
+			pkg := packageFor(name)
+			pkg.DefaultChannel, _ = entry["defaultChannel"].(string)
+			pkg.Description, _ = entry["description"].(string)
+		case "olm.channel":
+			if packageName == "" || name == "" {
+				continue
+			}
+			channel := channelFor(packageFor(packageName), name)
+			channelEntries, _ := entry["entries"].([]interface{})
+			for _, ce := range channelEntries {
+				if ceMap, ok := ce.(map[string]interface{}); ok {
+					channel.Entries = append(channel.Entries, channelEntryFrom(ceMap))
+				}
+			}
+		case "olm.bundle":
+			if packageName == "" || name == "" {
+				continue
+			}
+			propsArray, _ := entry["properties"].([]interface{})
+			for _, prop := range propsArray {
+				propMap, _ := prop.(map[string]interface{})
+				valueMap, _ := propMap["value"].(map[string]interface{})
+				if propMap["type"] != "olm.channel" {
+					continue
+				}
+				if channelName, ok := valueMap["name"].(string); ok && channelName != "" {
+					channelEntry := channelEntryFrom(valueMap)
+					channelEntry.Name = name
+					channel := channelFor(packageFor(packageName), channelName)
+					channel.Entries = append(channel.Entries, channelEntry)
+				}
+			}
+		}
+	}
+	return packages
+}
+
+// channelEntryFrom reads the name, replaces, skips and skipRange of a channel entry
+func channelEntryFrom(value map[string]interface{}) Entry {
+	entry := Entry{}
+	entry.Name, _ = value["name"].(string)
+	entry.Replaces, _ = value["replaces"].(string)
+	entry.SkipRange, _ = value["skipRange"].(string)
+	skips, _ := value["skips"].([]interface{})
+	for _, skip := range skips {
+		if name, ok := skip.(string); ok && name != "" {
+			entry.Skips = append(entry.Skips, name)
+		}
+	}
+	return entry
+}
@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildUpgradeGraphs(t *testing.T) {
+	packages := []Package{{
+		Name:           "compliance-operator",
+		DefaultChannel: "stable",
+		Channels: []Channel{
+			{
+				Name: "stable",
+				Entries: []Entry{
+					{Name: "compliance-operator.v1.0.0", Replaces: "compliance-operator.v0.9.0"},
+					{Name: "compliance-operator.v1.1.0", Replaces: "compliance-operator.v1.0.0"},
+					{Name: "compliance-operator.v1.2.0", Replaces: "compliance-operator.v1.1.0", Skips: []string{"compliance-operator.v1.0.0"}, SkipRange: ">=0.9.0 <1.2.0"},
+				},
+			},
+			{Name: "alpha", Entries: []Entry{{Name: "compliance-operator.v1.2.0"}}},
+		},
+	}}
+
+	graphs := BuildUpgradeGraphs(packages)
+	if len(graphs) != 2 || graphs[0].Channel != "alpha" || graphs[1].Channel != "stable" {
+		t.Fatalf("Expected the alpha and stable graphs sorted by channel, got %+v", graphs)
+	}
+	stable := graphs[1]
+	if !stable.Default || graphs[0].Default {
+		t.Error("Expected only the stable channel to be the default")
+	}
+	expectedEdges := []UpgradeEdge{
+		{From: "compliance-operator.v0.9.0", To: "compliance-operator.v1.0.0", Kind: "replaces"},
+		{From: "compliance-operator.v1.0.0", To: "compliance-operator.v1.1.0", Kind: "replaces"},
+		{From: "compliance-operator.v1.0.0", To: "compliance-operator.v1.2.0", Kind: "skips"},
+		{From: "compliance-operator.v1.1.0", To: "compliance-operator.v1.2.0", Kind: "replaces"},
+	}
+	if !reflect.DeepEqual(stable.Edges, expectedEdges) {
+		t.Errorf("Expected edges %+v, got %+v", expectedEdges, stable.Edges)
+	}
+	if !reflect.DeepEqual(stable.Heads, []string{"compliance-operator.v1.2.0"}) {
+		t.Errorf("Expected v1.2.0 as the only head, got %v", stable.Heads)
+	}
+	if stable.SkipRanges["compliance-operator.v1.2.0"] != ">=0.9.0 <1.2.0" {
+		t.Errorf("Expected the skipRange of v1.2.0, got %v", stable.SkipRanges)
+	}
+
+	text := FormatUpgradeGraphs(graphs, UpgradeGraphText)
+	for _, expected := range []string{
+		"=== compliance-operator / stable (default) ===",
+		"compliance-operator.v0.9.0 [not in channel] -> compliance-operator.v1.0.0 (replaces)\n",
+		"compliance-operator.v1.0.0 -> compliance-operator.v1.1.0 (replaces), compliance-operator.v1.2.0 (skips)\n",
+		"compliance-operator.v1.2.0 [head] skipRange >=0.9.0 <1.2.0\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected text graph to contain %q, got:\n%s", expected, text)
+		}
+	}
+
+	dot := FormatUpgradeGraphs(graphs, UpgradeGraphDOT)
+	for _, expected := range []string{
+		"digraph upgrades {",
+		`"compliance-operator/stable/compliance-operator.v1.0.0" -> "compliance-operator/stable/compliance-operator.v1.2.0" [style=dashed];`,
+		`"compliance-operator/stable/compliance-operator.v1.2.0" [label="compliance-operator.v1.2.0\nskipRange >=0.9.0 <1.2.0", style=bold];`,
+		`"compliance-operator/alpha/compliance-operator.v1.2.0" [label="compliance-operator.v1.2.0", style=bold];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("Expected DOT graph to contain %q, got:\n%s", expected, dot)
+		}
+	}
+}
+
+func TestParseCatalogPackages(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string][]Entry // "package/channel" -> entries
+	}{
+		{
+			name: "file-based catalog",
+			content: `{"schema": "olm.package", "name": "foo", "defaultChannel": "stable"}
+{"schema": "olm.channel", "package": "foo", "name": "stable", "entries": [
+  {"name": "foo.v1.0.0"},
+  {"name": "foo.v1.1.0", "replaces": "foo.v1.0.0", "skips": ["foo.v0.9.0"], "skipRange": "<1.1.0"}
+]}
+{"schema": "olm.bundle", "package": "foo", "name": "foo.v1.1.0"}`,
+			expected: map[string][]Entry{
+				"foo/stable": {
+					{Name: "foo.v1.0.0"},
+					{Name: "foo.v1.1.0", Replaces: "foo.v1.0.0", Skips: []string{"foo.v0.9.0"}, SkipRange: "<1.1.0"},
+				},
+			},
+		},
+		{
+			name: "sqlite render",
+			content: `{"schema": "olm.package", "name": "foo", "defaultChannel": "stable"}
+{"schema": "olm.bundle", "package": "foo", "name": "foo.v1.1.0", "properties": [
+  {"type": "olm.channel", "value": {"name": "stable", "replaces": "foo.v1.0.0"}},
+  {"type": "olm.channel", "value": {"name": "fast"}}
+]}`,
+			expected: map[string][]Entry{
+				"foo/stable": {{Name: "foo.v1.1.0", Replaces: "foo.v1.0.0"}},
+				"foo/fast":   {{Name: "foo.v1.1.0"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "index.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write index: %v", err)
+			}
+			packages, err := ParseCatalogPackages(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got := make(map[string][]Entry)
+			for _, pkg := range packages {
+				if pkg.DefaultChannel != "stable" {
+					t.Errorf("Expected default channel stable for %s, got %q", pkg.Name, pkg.DefaultChannel)
+				}
+				for _, channel := range pkg.Channels {
+					got[pkg.Name+"/"+channel.Name] = channel.Entries
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	// A structured index already nests its packages
+	packages, err := ParseCatalogPackages(filepath.Join("..", "testdata", "sample_index.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(packages) == 0 || len(packages[0].Channels) == 0 {
+		t.Errorf("Expected the structured index's packages, got %+v", packages)
+	}
+}
+
+func TestParseUpgradeGraphFormat(t *testing.T) {
+	for _, value := range []string{"text", "DOT"} {
+		if _, err := ParseUpgradeGraphFormat(value); err != nil {
+			t.Errorf("Expected %q to be accepted, got %v", value, err)
+		}
+	}
+	if _, err := ParseUpgradeGraphFormat("svg"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
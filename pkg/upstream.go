@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// upstreamRemoteName is the remote added to a cloned fork to fetch its upstream
+const upstreamRemoteName = "upstream"
+
+// UpstreamComparison describes how a fork's branch relates to the same branch upstream
+type UpstreamComparison struct {
+	UpstreamURL string `json:"upstreamUrl"`
+	Branch      string `json:"branch"`
+	Ahead       int    `json:"ahead"`
+	Behind      int    `json:"behind"`
+	MergeBase   string `json:"mergeBase,omitempty"`
+	Unrelated   bool   `json:"unrelated"`
+}
+
+// Status returns a human-readable summary of the comparison
+func (uc *UpstreamComparison) Status() string {
+	if uc.Unrelated {
+		return "histories are unrelated (no common merge base)"
+	}
+	return fmt.Sprintf("%d commits behind upstream, %d ahead", uc.Behind, uc.Ahead)
+}
+
+// compareWithUpstream fetches the upstream branch into the cloned fork and counts the commits
+// each side has beyond their merge base
+func compareWithUpstream(repo *git.Repository, forkHead plumbing.Hash, upstreamURL, branch string, auth transport.AuthMethod) (*UpstreamComparison, error) {
+	// A cached clone keeps the remote of an earlier comparison, possibly with another URL
+	repo.DeleteRemote(upstreamRemoteName)
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: upstreamRemoteName,
+		URLs: []string{upstreamURL},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add upstream remote: %w", err)
+	}
+
+	upstreamRef := plumbing.NewRemoteReferenceName(upstreamRemoteName, branch)
+	refSpec := config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), upstreamRef))
+	if err := remote.Fetch(&git.FetchOptions{RefSpecs: []config.RefSpec{refSpec}, Auth: auth}); err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, fmt.Errorf("failed to fetch upstream branch %s: %w", branch, err)
+	}
+
+	ref, err := repo.Reference(upstreamRef, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve upstream branch %s: %w", branch, err)
+	}
+
+	forkCommit, err := repo.CommitObject(forkHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fork commit: %w", err)
+	}
+	upstreamCommit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upstream commit: %w", err)
+	}
+
+	comparison := &UpstreamComparison{
+		UpstreamURL: upstreamURL,
+		Branch:      branch,
+	}
+
+	bases, err := forkCommit.MergeBase(upstreamCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute merge base: %w", err)
+	}
+	if len(bases) == 0 {
+		comparison.Unrelated = true
+		return comparison, nil
+	}
+	base := bases[0]
+	comparison.MergeBase = base.Hash.String()[:8]
+
+	// Every ancestor of the merge base is shared, so anything else is ahead or behind
+	shared, err := ancestorSet(repo, base.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if comparison.Ahead, err = countCommitsNotIn(repo, forkHead, shared); err != nil {
+		return nil, err
+	}
+	if comparison.Behind, err = countCommitsNotIn(repo, upstreamCommit.Hash, shared); err != nil {
+		return nil, err
+	}
+
+	return comparison, nil
+}
+
+// ancestorSet returns the hashes of the commit and all of its ancestors
+func ancestorSet(repo *git.Repository, from plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	iter, err := logCommits(repo, from, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history from %s: %w", from.String()[:8], err)
+	}
+
+	set := make(map[plumbing.Hash]bool)
+	err = iter.ForEach(func(c *object.Commit) error {
+		set[c.Hash] = true
+		return nil
+	})
+	return set, err
+}
+
+// countCommitsNotIn counts the commits reachable from the given hash that are not in the excluded set
+func countCommitsNotIn(repo *git.Repository, from plumbing.Hash, excluded map[plumbing.Hash]bool) (int, error) {
+	iter, err := logCommits(repo, from, nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk history from %s: %w", from.String()[:8], err)
+	}
+
+	count := 0
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !excluded[c.Hash] {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
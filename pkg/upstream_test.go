@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestCompareWithUpstream(t *testing.T) {
+	upstreamDir := t.TempDir()
+	upstreamRepo, err := git.PlainInit(upstreamDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init upstream: %v", err)
+	}
+	commitFile(t, upstreamRepo, upstreamDir, "base.txt", "base commit")
+
+	forkDir := t.TempDir()
+	forkRepo, err := git.PlainClone(forkDir, false, &git.CloneOptions{URL: upstreamDir})
+	if err != nil {
+		t.Fatalf("Failed to clone fork: %v", err)
+	}
+
+	// Upstream moves on by two commits, the fork adds one of its own
+	commitFile(t, upstreamRepo, upstreamDir, "up1.txt", "upstream change 1")
+	commitFile(t, upstreamRepo, upstreamDir, "up2.txt", "upstream change 2")
+	commitFile(t, forkRepo, forkDir, "fork.txt", "fork change")
+
+	head, err := forkRepo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get fork HEAD: %v", err)
+	}
+
+	comparison, err := compareWithUpstream(forkRepo, head.Hash(), upstreamDir, head.Name().Short(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if comparison.Unrelated {
+		t.Fatalf("Expected related histories")
+	}
+	if comparison.Behind != 2 {
+		t.Errorf("Expected 2 commits behind, got %d", comparison.Behind)
+	}
+	if comparison.Ahead != 1 {
+		t.Errorf("Expected 1 commit ahead, got %d", comparison.Ahead)
+	}
+	if comparison.Status() != "2 commits behind upstream, 1 ahead" {
+		t.Errorf("Unexpected status: %s", comparison.Status())
+	}
+}
+
+func TestCompareWithUpstreamUnrelated(t *testing.T) {
+	upstreamDir := t.TempDir()
+	upstreamRepo, err := git.PlainInit(upstreamDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init upstream: %v", err)
+	}
+	commitFile(t, upstreamRepo, upstreamDir, "upstream.txt", "upstream root")
+
+	forkDir := t.TempDir()
+	forkRepo, err := git.PlainInit(forkDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init fork: %v", err)
+	}
+	commitFile(t, forkRepo, forkDir, "fork.txt", "fork root")
+
+	head, err := forkRepo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get fork HEAD: %v", err)
+	}
+
+	comparison, err := compareWithUpstream(forkRepo, head.Hash(), upstreamDir, head.Name().Short(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !comparison.Unrelated {
+		t.Errorf("Expected unrelated histories")
+	}
+}
@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// DefaultVendorPatterns are the file globs of vendored dependencies: a commit touching only
+// these files is a dependency vendoring update rather than a first-party change
+var DefaultVendorPatterns = []string{"vendor/**", "third_party/**"}
+
+// isVendoringCommit reports whether every file changed by the commit matches a vendor pattern.
+// Commits whose files are unknown (their stats failed) are kept with the first-party changes.
+func isVendoringCommit(commit CommitDetail, patterns []string) bool {
+	if len(commit.Files) == 0 || len(patterns) == 0 {
+		return false
+	}
+	for _, file := range commit.Files {
+		vendored := false
+		for _, pattern := range patterns {
+			if matchFilePattern(pattern, file) {
+				vendored = true
+				break
+			}
+		}
+		if !vendored {
+			return false
+		}
+	}
+	return true
+}
+
+// splitVendoredCommits separates dependency vendoring commits from first-party commits,
+// preserving the order of both
+func splitVendoredCommits(commits []CommitDetail, patterns []string) (firstParty, vendored []CommitDetail) {
+	for _, commit := range commits {
+		if isVendoringCommit(commit, patterns) {
+			vendored = append(vendored, commit)
+		} else {
+			firstParty = append(firstParty, commit)
+		}
+	}
+	return firstParty, vendored
+}
+
+// formatTextVendoredCommits renders the dependency vendoring section of the text notes
+func (rnf *ReleaseNoteFormatter) formatTextVendoredCommits(commits []CommitDetail) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("=== DEPENDENCY VENDORING (%d COMMITS) ===\n", len(commits)))
+	for _, commit := range commits {
+		output.WriteString(fmt.Sprintf("- %s (%s) by %s on %s\n",
+			rnf.truncateSubject(firstLine(commit.Message)),
+			commit.Hash,
+			commit.Author,
+			commit.Date.Format("2006-01-02 15:04:05")))
+	}
+	return output.String()
+}
+
+// vendoredCommitsSection renders the vendoring commits as a collapsed section below the
+// first-party commits, or nothing when there are none
+func (s *Server) vendoredCommitsSection(repoURL string, commits []CommitDetail) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	var section strings.Builder
+	section.WriteString(fmt.Sprintf(`<details class="vendoring-section">
+		<summary>📦 Dependency Vendoring (%d commits)</summary>
+		<ul class="vendoring-list">`, len(commits)))
+	for _, c := range commits {
+		section.WriteString(fmt.Sprintf(`
+			<li><a href="%s" target="_blank"><code class="commit-hash">%s</code></a> %s <span class="vendoring-meta">👤 %s · 📅 %s</span></li>`,
+			s.CommitURLs.CommitURL(repoURL, c.fullHash, c.Hash),
+			c.Hash,
+			template.HTMLEscapeString(firstLine(c.Message)),
+			template.HTMLEscapeString(c.Author),
+			c.Date.Format("Jan 02, 15:04")))
+	}
+	section.WriteString(`
+		</ul>
+	</details>`)
+	return section.String()
+}
@@ -0,0 +1,68 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitVendoredCommits(t *testing.T) {
+	commits := []CommitDetail{
+		{Hash: "a1", Message: "Bump client-go", Files: []string{"vendor/k8s.io/client-go/rest/client.go", "vendor/modules.txt"}},
+		{Hash: "b2", Message: "Bump client-go and fix build", Files: []string{"vendor/modules.txt", "go.mod"}},
+		{Hash: "c3", Message: "Update protobuf", Files: []string{"third_party/protobuf/any.proto"}},
+		{Hash: "d4", Message: "Fix reconcile", Files: []string{"controllers/reconcile.go"}},
+		{Hash: "e5", Message: "Stats failed"},
+		{Hash: "f6", Message: "Nested vendor directory", Files: []string{"hack/vendor/tool.go"}},
+	}
+
+	tests := []struct {
+		name       string
+		patterns   []string
+		firstParty []string
+		vendored   []string
+	}{
+		{name: "default patterns", patterns: DefaultVendorPatterns, firstParty: []string{"b2", "d4", "e5", "f6"}, vendored: []string{"a1", "c3"}},
+		{name: "custom patterns", patterns: []string{"**/vendor/**"}, firstParty: []string{"b2", "c3", "d4", "e5"}, vendored: []string{"a1", "f6"}},
+		{name: "disabled", patterns: nil, firstParty: []string{"a1", "b2", "c3", "d4", "e5", "f6"}},
+	}
+
+	hashes := func(commits []CommitDetail) []string {
+		var result []string
+		for _, commit := range commits {
+			result = append(result, commit.Hash)
+		}
+		return result
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			firstParty, vendored := splitVendoredCommits(commits, tt.patterns)
+			if got := strings.Join(hashes(firstParty), ","); got != strings.Join(tt.firstParty, ",") {
+				t.Errorf("Expected first-party commits %v, got %s", tt.firstParty, got)
+			}
+			if got := strings.Join(hashes(vendored), ","); got != strings.Join(tt.vendored, ",") {
+				t.Errorf("Expected vendored commits %v, got %s", tt.vendored, got)
+			}
+		})
+	}
+}
+
+func TestFormatReleaseNoteVendoredCommits(t *testing.T) {
+	formatter := NewReleaseNoteFormatter()
+	until := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	format := formatter.CreateStandardFormat("https://github.com/test/repo", until.AddDate(0, 0, -7), until,
+		CommitInfo{Hash: "a1b2c3d4", Message: "Bump client-go", Author: "Alice", Date: until},
+		WeeklySummary{TotalCommits: 1, TotalLinesChanged: 300, ActiveContributors: 1}, nil, nil)
+	format.VendoredCommits = []CommitDetail{{Hash: "a1b2c3d4", Message: "Bump client-go", Author: "Alice", Date: until}}
+
+	result := formatter.FormatReleaseNote(format)
+	for _, expected := range []string{
+		"No commits outside vendored dependencies found",
+		"=== DEPENDENCY VENDORING (1 COMMITS) ===",
+		"- Bump client-go (a1b2c3d4) by Alice",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, result)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the release version of the analyzer; it can be set at build time with
+// -ldflags "-X prega-operator-analyzer/pkg.Version=v1.2.3"
+var Version = "dev"
+
+// BuildInfo describes the binary that is running
+type BuildInfo struct {
+	Version      string `json:"version"`
+	GoVersion    string `json:"goVersion"`
+	Revision     string `json:"revision,omitempty"`
+	RevisionTime string `json:"revisionTime,omitempty"`
+	Modified     bool   `json:"modified,omitempty"`
+}
+
+// GetBuildInfo returns the version plus the VCS details the Go toolchain embedded at build time
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{Version: Version, GoVersion: runtime.Version()}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.RevisionTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
@@ -1,18 +1,29 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
+	"html"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// DefaultAnalysisDays is the analysis window used when no number of days is configured
+const DefaultAnalysisDays = 7
+
+// DefaultConcurrency is the number of repositories cloned and analyzed at once when no
+// concurrency is configured
+const DefaultConcurrency = 4
+
 // VibeToolsManager handles vibe-tools operations
 type VibeToolsManager struct {
 	WorkDir        string
@@ -22,7 +33,59 @@ type VibeToolsManager struct {
 	Formatter      *ReleaseNoteFormatter
 	UseCursorAgent bool
 	GenerateHTML   bool
+	GenerateText   bool
 	HTMLOutputFile string
+	Outputs        []OutputTarget    // Explicit output files; when empty, OutputFile/HTMLOutputFile are used
+	MaxRepoSizeMB  int64 // Skip repositories larger than this before cloning (0 disables the check)
+	Upstreams      map[string]string // Fork repository URL -> upstream URL to compare against
+	NotablePatterns []string         // File globs whose changes are highlighted as notable
+	CatalogSchemas []string          // Top-level schemas of the catalog the repositories came from
+	CatalogInfo    map[string]ParserRepositoryInfo // Repository URL -> catalog metadata shown in the repository's header
+	ReportOpenPRs  bool              // Look up the open pull/merge request count through the provider API
+	IgnoreWhitespace bool            // Count changed lines with a whitespace-insensitive diff instead of Stats()
+	ExcludeExtensions []string       // File extensions whose lines are not counted as changed (e.g. "svg", "min.js")
+	OnlyAuthors    []string          // Author names or emails whose commits are analyzed; empty analyzes every author
+	VendorPatterns []string          // File globs of vendored dependencies; commits only touching them are listed separately
+	Branches       []string          // Branch names or globs (e.g. "release-*") analyzed from each clone, each in its own section; empty analyzes main, else master
+	IncludeSubmodules bool           // Fetch each repository's submodules and list their commits in the window
+	AllowedHosts      []string       // Git hosts submodules may be fetched from; empty allows every host
+	TarballFallback bool             // Read the codeload tarball of a GitHub repository whose clone fails, reporting its current state without history
+	ProgressFunc   ProgressFunc      // Receives each repository's milestones during ProcessRepositories; nil disables it
+	progress       *progressTracker  // Forwards the current run's milestones to ProgressFunc
+	MinCommits     int               // Successful repositories with fewer commits in the window are only listed in a low activity summary; 0 lists every repository in full
+	StaleReleaseDays int             // Successful repositories whose newest semver tag is older are listed in a stale releases summary; 0 disables the check
+	CloneDepth     int               // Commits of history fetched per clone; 0 clones full history. Bundles and the object cache always hold full history
+	Days           int               // Number of days of history to analyze
+	RankBy         RankBy            // Metric used to rank contributors; empty ranks by commit count
+	DateMode       DateMode          // Commit date that places commits in the window and is shown for them; empty uses the committer date
+	Stdout         io.Writer         // Destination for the "-" output target
+	StatsWorkers   int               // Commits whose changes are computed concurrently per repository
+	Concurrency    int               // Repositories cloned and analyzed at once, each worker in its own work subdirectory; below 1 processes one at a time
+	ShowAvatars    bool              // Resolve contributor avatars for GitHub repositories (may call the GitHub API)
+	Labels         map[string][]string // Repository URL -> labels used to group the report into sections
+	Overrides      map[string]RepositoryOverride // Repository URL -> settings taking precedence over the run's, such as its analysis window
+	ActivityHeatmap bool             // Add a histogram of commits by weekday and hour (in the local time zone)
+	NotifyWebhook  string            // Incoming webhook URL that receives a run summary; empty disables notification
+	NotifyFormat   NotifyFormat      // Payload format of the notification; empty posts Slack's {"text": ...}
+	ReportURL      string            // Link to the published report, included in the notification and the Atom feed
+	CommitURLs     CommitURLTemplates // Commit link templates used by the Atom feed, whose link bases merged pull requests also link through; the zero value links to {base}/commit/{hash}
+	AsOf           time.Time         // End of the analysis window for historical reports; zero ends it now
+	Since          time.Time         // Start of the analysis window, which then runs to AsOf instead of covering Days days; zero uses Days
+	NoCloneProgress bool             // Never log git's clone progress, even with verbose logging
+	Credentials    *GitCredentials // Sent to HTTP(S) remotes on their hosts when cloning; read from GIT_TOKEN, GIT_USERNAME and GIT_TOKEN_HOSTS by NewVibeToolsManager
+	ObjectCacheDir string            // Bare repository shared by all clones so forks fetch common history once; empty disables it
+	objectCache    *ObjectCache      // Opened from ObjectCacheDir for the current run
+	StateFile      string            // JSON file remembering each repository's analyzed commit between runs; empty disables it
+	state          *RunState         // Loaded from StateFile; only updated once the run is over so retries compare against the previous run
+	analyzed       map[string]RepositoryState // Branch tips analyzed in the current run, saved to StateFile at the end
+	IdentitiesFile string            // Candidate .mailmap listing the author identities seen in each repository; empty disables it
+	JUnitFile      string            // JUnit XML report with a test case per repository, for CI dashboards; empty disables it
+	identities     map[string][]Identity // Author identities per repository in the current run, written to IdentitiesFile at the end
+	cloneTotals    CloneStats        // Data pulled by all clones in the run, collected with verbose logging
+	recloned       []string          // Repositories that needed a fresh clone after a corrupt object database
+	pdfFallbacks   map[string]string // PDF output path -> HTML file written instead when no converter was found
+	mu             sync.Mutex        // Guards the run state the workers share: cloneTotals, recloned, analyzed and identities
+	toolsMu        sync.Mutex        // Keeps workers from downloading vibe-tools at the same time
 }
 
 // NewVibeToolsManager creates a new VibeToolsManager
@@ -41,158 +104,495 @@ func NewVibeToolsManager(workDir, outputFile string, useCursorAgent bool) *VibeT
 		Formatter:      NewReleaseNoteFormatter(),
 		UseCursorAgent: useCursorAgent,
 		GenerateHTML:   true,
+		GenerateText:   true,
 		HTMLOutputFile: htmlOutputFile,
+		Days:           DefaultAnalysisDays,
+		Stdout:         os.Stdout,
+		StatsWorkers:   DefaultStatsWorkers,
+		Concurrency:    DefaultConcurrency,
+		VendorPatterns: DefaultVendorPatterns,
+		Credentials:    GitCredentialsFromEnv(),
 	}
 }
 
-// ProcessRepositories processes all repositories and generates release notes
-func (vtm *VibeToolsManager) ProcessRepositories(repositories []string) error {
-	// Create output file with error handling
-	outputFile, err := os.Create(vtm.OutputFile)
-	if err != nil {
-		return WrapError(err, ErrorTypeFileSystem, "failed to create output file", map[string]interface{}{
-			"output_file": vtm.OutputFile,
-		})
+// analysisEnd returns the end of the analysis window: AsOf when set, otherwise now
+func (vtm *VibeToolsManager) analysisEnd() time.Time {
+	if vtm.AsOf.IsZero() {
+		return time.Now()
+	}
+	return vtm.AsOf
+}
+
+// analysisDays returns the number of days of history to analyze, falling back to
+// DefaultAnalysisDays when Days is not positive
+func (vtm *VibeToolsManager) analysisDays() int {
+	if vtm.Days <= 0 {
+		return DefaultAnalysisDays
+	}
+	return vtm.Days
+}
+
+// ProcessRepositories processes all repositories, writes the release notes to the configured
+// outputs and returns each repository's result, so library callers get the notes without
+// reading the files back. The results are returned even when writing an output failed. Each
+// repository and output is traced as a child of any span in ctx.
+func (vtm *VibeToolsManager) ProcessRepositories(ctx context.Context, repositories []string) ([]RepoResult, error) {
+	targets := vtm.outputTargets()
+	if len(targets) == 0 {
+		return nil, WrapError(nil, ErrorTypeValidation, "at least one of text or HTML output must be enabled", nil)
 	}
-	defer func() {
-		if closeErr := outputFile.Close(); closeErr != nil {
-			vtm.Logger.Errorf("Failed to close output file: %v", closeErr)
+	for _, target := range targets {
+		if _, ok := reportRenderers[target.Format]; !ok {
+			return nil, WrapError(nil, ErrorTypeValidation, "unknown output format", map[string]interface{}{
+				"output_file": target.Path,
+				"format":      target.Format,
+			})
 		}
-	}()
+	}
 
-	// Create HTML output file if enabled
-	var htmlFile *os.File
-	if vtm.GenerateHTML {
-		htmlFile, err = os.Create(vtm.HTMLOutputFile)
+	// Fail before the first clone when the work directory or an output directory is not writable
+	dirs := []string{vtm.WorkDir}
+	for _, target := range targets {
+		if target.Path != StdoutOutputPath {
+			dirs = append(dirs, filepath.Dir(target.Path))
+		}
+	}
+	for _, dir := range RemoveDuplicates(dirs) {
+		if err := ensureWritableDir(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &RunReport{
+		CatalogSchemas: vtm.CatalogSchemas,
+		Total:          len(repositories),
+		MinCommits:     vtm.MinCommits,
+		StaleReleaseDays: vtm.StaleReleaseDays,
+		startedAt:      time.Now(),
+	}
+	if !vtm.Formatter.OmitTimestamp {
+		now := time.Now()
+		report.GeneratedAt = &now
+	}
+	vtm.cloneTotals = CloneStats{}
+	vtm.recloned = nil
+	vtm.state = nil
+	if vtm.StateFile != "" {
+		state, err := LoadRunState(vtm.StateFile)
+		if err != nil {
+			return nil, WrapError(err, ErrorTypeFileSystem, "failed to load state file", map[string]interface{}{
+				"state_file": vtm.StateFile,
+			})
+		}
+		vtm.state = state
+		vtm.analyzed = make(map[string]RepositoryState)
+	}
+	vtm.identities = make(map[string][]Identity)
+	vtm.progress = newProgressTracker(vtm.ProgressFunc, len(repositories))
+	vtm.objectCache = nil
+	if vtm.ObjectCacheDir != "" {
+		cache, err := OpenObjectCache(vtm.ObjectCacheDir)
 		if err != nil {
-			vtm.Logger.Warnf("Failed to create HTML output file: %v", err)
+			vtm.Logger.Warnf("Object cache unavailable, cloning every repository in full: %v", err)
 		} else {
-			defer func() {
-				if closeErr := htmlFile.Close(); closeErr != nil {
-					vtm.Logger.Errorf("Failed to close HTML file: %v", closeErr)
-				}
-			}()
-			// Write HTML header
-			htmlFile.WriteString(vtm.generateHTMLHeader())
+			vtm.objectCache = cache
 		}
 	}
 
-	// Write header
-	header := fmt.Sprintf("Release Notes Generated on: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	header += "=" + strings.Repeat("=", len(header)-1) + "\n\n"
-	if _, err := outputFile.WriteString(header); err != nil {
-		return WrapError(err, ErrorTypeFileSystem, "failed to write header", map[string]interface{}{
-			"output_file": vtm.OutputFile,
-		})
+	// Workers pick repositories in order and store each result at the repository's position,
+	// so the report keeps the input order whichever repository finishes first
+	report.Repositories = make([]RepositoryReport, len(repositories))
+	var countersMu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < vtm.workers(len(repositories)); worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range jobs {
+				repoReport := vtm.processRepository(ctx, worker, i, repositories[i])
+				countersMu.Lock()
+				report.Repositories[i] = repoReport
+				switch repoReport.Status {
+				case RepositoryStatusSuccess:
+					report.Successful++
+				case RepositoryStatusFailed:
+					report.Failed++
+				case RepositoryStatusSkipped:
+					report.Skipped++
+				}
+				countersMu.Unlock()
+			}
+		}(worker)
+	}
+	for i := range repositories {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
-	successCount := 0
-	errorCount := 0
-	var htmlContent strings.Builder
+	report.duration = time.Since(report.startedAt)
+	report.Recloned = vtm.recloned
+	if vtm.cloneTotals.Objects > 0 {
+		totals := vtm.cloneTotals
+		report.DataTransferred = &totals
+	}
 
-	for i, repo := range repositories {
-		vtm.Logger.Infof("Processing repository %d/%d: %s", i+1, len(repositories), repo)
-		
-		// Use retry mechanism for repository processing
-		err := vtm.ErrorHandler.HandleWithRetry(func() error {
-			releaseNotes, err := vtm.generateReleaseNotes(repo)
-			if err != nil {
-				return err
+	// Render every requested output from the same report
+	var writeErr error
+	for _, target := range targets {
+		if err := vtm.writeReport(ctx, target, report); err != nil {
+			vtm.Logger.Errorf("Failed to write %s output %s: %v", target.Format, target.Path, err)
+			if writeErr == nil {
+				writeErr = err
 			}
+			continue
+		}
+		formatName := strings.ToUpper(target.Format[:1]) + target.Format[1:]
+		if target.Path == StdoutOutputPath {
+			vtm.Logger.Infof("%s release notes written to standard output", formatName)
+			continue
+		}
+		if vtm.OutputPath(target.Path) != target.Path {
+			continue // the PDF fallback already warned where the HTML went
+		}
+		vtm.Logger.Infof("%s release notes saved to: %s", formatName, target.Path)
+	}
 
-			// Write repository section to output file
-			if _, writeErr := outputFile.WriteString(releaseNotes); writeErr != nil {
-				return WrapError(writeErr, ErrorTypeFileSystem, "failed to write release notes", map[string]interface{}{
-					"repository": repo,
-					"output_file": vtm.OutputFile,
-				})
-			}
-			return nil
-		}, fmt.Sprintf("process repository %s", repo))
+	vtm.Logger.Infof("Processing complete (Success: %d, Failed: %d, Skipped: %d)", report.Successful, report.Failed, report.Skipped)
 
-		if err != nil {
-			errorCount++
-			vtm.Logger.Errorf("Failed to generate release notes for %s: %v", repo, err)
-			
-			// Write error section using formatter
-			errorSection := vtm.Formatter.FormatErrorSection(repo, err)
-			if _, writeErr := outputFile.WriteString(errorSection); writeErr != nil {
-				vtm.Logger.Errorf("Failed to write error section: %v", writeErr)
-			}
-			
-			// Add error to HTML
-			if vtm.GenerateHTML {
-				htmlContent.WriteString(vtm.formatHTMLErrorSection(repo, err))
-			}
+	if vtm.IdentitiesFile != "" {
+		if err := vtm.writeIdentities(repositories); err != nil {
+			vtm.Logger.Warnf("Failed to write identities file %s: %v", vtm.IdentitiesFile, err)
 		} else {
-			successCount++
+			vtm.Logger.Infof("Candidate .mailmap saved to: %s", vtm.IdentitiesFile)
 		}
 	}
 
-	// Write summary
-	summary := fmt.Sprintf("\n=== PROCESSING SUMMARY ===\n")
-	summary += fmt.Sprintf("Total Repositories: %d\n", len(repositories))
-	summary += fmt.Sprintf("Successfully Processed: %d\n", successCount)
-	summary += fmt.Sprintf("Failed: %d\n", errorCount)
-	summary += fmt.Sprintf("Success Rate: %.1f%%\n", float64(successCount)/float64(len(repositories))*100)
-	summary += fmt.Sprintf("Generated on: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	
-	if _, err := outputFile.WriteString(summary); err != nil {
-		vtm.Logger.Errorf("Failed to write summary: %v", err)
+	if vtm.JUnitFile != "" {
+		if err := vtm.writeJUnitReport(report); err != nil {
+			vtm.Logger.Warnf("Failed to write JUnit report %s: %v", vtm.JUnitFile, err)
+		} else {
+			vtm.Logger.Infof("JUnit report saved to: %s", vtm.JUnitFile)
+		}
+	}
+
+	if vtm.state != nil {
+		for repoURL, analyzed := range vtm.analyzed {
+			vtm.state.Repositories[repoURL] = analyzed
+		}
+		if err := vtm.state.Save(vtm.StateFile); err != nil {
+			vtm.Logger.Warnf("Failed to save state file %s: %v", vtm.StateFile, err)
+		}
+	}
+
+	// A failed notification must not fail a run whose notes were written
+	if vtm.NotifyWebhook != "" {
+		if err := postNotification(ctx, vtm.NotifyWebhook, vtm.NotifyFormat, newRunSummary(report, vtm.ReportURL)); err != nil {
+			vtm.Logger.Warnf("Failed to send run notification: %v", err)
+		} else {
+			vtm.Logger.Info("Run summary posted to the notification webhook")
+		}
+	}
+	return report.Results(), writeErr
+}
+
+// outputTargets returns the files to write. Without explicit Outputs, the text file and its
+// companion HTML file are written according to GenerateText and GenerateHTML.
+func (vtm *VibeToolsManager) outputTargets() []OutputTarget {
+	if len(vtm.Outputs) > 0 {
+		return vtm.Outputs
+	}
+
+	var targets []OutputTarget
+	if vtm.GenerateText {
+		targets = append(targets, OutputTarget{Path: vtm.OutputFile, Format: OutputFormatText})
 	}
+	if vtm.GenerateHTML {
+		targets = append(targets, OutputTarget{Path: vtm.HTMLOutputFile, Format: OutputFormatHTML})
+	}
+	return targets
+}
 
-	// Write HTML footer and close
-	if vtm.GenerateHTML && htmlFile != nil {
-		htmlFile.WriteString(htmlContent.String())
-		htmlFile.WriteString(vtm.generateHTMLSummary(len(repositories), successCount, errorCount))
-		htmlFile.WriteString(vtm.generateHTMLFooter())
-		vtm.Logger.Infof("HTML release notes saved to: %s", vtm.HTMLOutputFile)
+// writeReport renders the report in the target's format and writes it to the target file
+func (vtm *VibeToolsManager) writeReport(ctx context.Context, target OutputTarget, report *RunReport) (err error) {
+	_, span := StartSpan(ctx, "report.format", attribute.String("format", target.Format), attribute.String("output", target.Path))
+	defer func() { EndSpan(span, err) }()
+
+	if target.Format == OutputFormatPDF {
+		if err := vtm.writePDFReport(ctx, target, report); err != nil {
+			return WrapError(err, ErrorTypeFileSystem, "failed to write PDF release notes", map[string]interface{}{
+				"output_file": target.Path,
+			})
+		}
+		return nil
 	}
 
-	vtm.Logger.Infof("Release notes saved to: %s (Success: %d, Failed: %d)", vtm.OutputFile, successCount, errorCount)
+	data, err := reportRenderers[target.Format](vtm, report)
+	if err != nil {
+		return WrapError(err, ErrorTypeUnknown, "failed to render release notes", map[string]interface{}{
+			"output_file": target.Path,
+			"format":      target.Format,
+		})
+	}
+	if target.Path == StdoutOutputPath {
+		if _, err := vtm.Stdout.Write(data); err != nil {
+			return WrapError(err, ErrorTypeFileSystem, "failed to write release notes to standard output", nil)
+		}
+		return nil
+	}
+	if err := os.WriteFile(target.Path, data, 0644); err != nil {
+		return WrapError(err, ErrorTypeFileSystem, "failed to write output file", map[string]interface{}{
+			"output_file": target.Path,
+		})
+	}
 	return nil
 }
 
-// generateReleaseNotes generates release notes for a single repository
-func (vtm *VibeToolsManager) generateReleaseNotes(repoURL string) (string, error) {
-	// Clone repository to temporary directory
-	repoName := vtm.extractRepoName(repoURL)
-	repoPath := filepath.Join(vtm.WorkDir, repoName)
-	
+// checkRepoSize returns a skip reason when the repository exceeds MaxRepoSizeMB.
+// An empty string means the repository should be cloned, including when its size
+// cannot be determined.
+func (vtm *VibeToolsManager) checkRepoSize(repoURL string) string {
+	if vtm.MaxRepoSizeMB <= 0 {
+		return ""
+	}
+
+	sizeKB, err := fetchGitHubRepoSizeKB(repoURL)
+	if err != nil {
+		vtm.Logger.Debugf("Could not determine size of %s, cloning anyway: %v", repoURL, err)
+		return ""
+	}
+
+	sizeMB := sizeKB / 1024
+	if sizeMB > vtm.MaxRepoSizeMB {
+		return fmt.Sprintf("skipped: too large (%d MB exceeds limit of %d MB)", sizeMB, vtm.MaxRepoSizeMB)
+	}
+	vtm.Logger.Debugf("Repository %s size is %d MB (limit %d MB)", repoURL, sizeMB, vtm.MaxRepoSizeMB)
+	return ""
+}
+
+// workers returns the number of repositories processed at once for a run of total repositories
+func (vtm *VibeToolsManager) workers(total int) int {
+	workers := vtm.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > total {
+		workers = total
+	}
+	return workers
+}
+
+// processRepository clones and analyzes the repository at position i of the run with the
+// retry behavior of the error handler, returning its entry in the report. Each worker clones
+// into its own subdirectory of the work directory.
+func (vtm *VibeToolsManager) processRepository(ctx context.Context, worker, i int, repo string) RepositoryReport {
+	repoCtx, span := StartSpan(withProgressIndex(ctx, i+1), "repository.process", attribute.String("repository", repo))
+	vtm.progress.report(repoCtx, ProgressStarted, repo, nil)
+	repoStart := time.Now()
+
+	// Skip oversized repositories before attempting the clone
+	if reason := vtm.checkRepoSize(repo); reason != "" {
+		vtm.Logger.Warnf("Skipping %s: %s", repo, reason)
+		span.SetAttributes(attribute.String("status", RepositoryStatusSkipped))
+		span.End()
+		vtm.progress.report(repoCtx, ProgressSkipped, repo, nil)
+		return RepositoryReport{
+			Repository: repo,
+			Status:     RepositoryStatusSkipped,
+			SkipReason: reason,
+			Labels:     vtm.repositoryLabels(repo),
+			duration:   time.Since(repoStart),
+		}
+	}
+
+	// Use retry mechanism for repository processing
+	repoPath := filepath.Join(vtm.WorkDir, fmt.Sprintf("worker-%d", worker), vtm.extractRepoName(repo))
+	var notes RepositoryNotes
+	err := vtm.ErrorHandler.HandleWithRetry(func() error {
+		var err error
+		notes, err = vtm.generateReleaseNotes(repoCtx, repo, repoPath)
+		return err
+	}, fmt.Sprintf("process repository %s", repo))
+	EndSpan(span, err)
+
+	if err != nil {
+		vtm.Logger.Errorf("Failed to generate release notes for %s: %v", repo, err)
+		vtm.progress.report(repoCtx, ProgressFailed, repo, err)
+		return RepositoryReport{
+			Repository:  repo,
+			Status:      RepositoryStatusFailed,
+			Error:       err.Error(),
+			Remediation: ErrorRemediation(err),
+			err:         err,
+			Labels:      vtm.repositoryLabels(repo),
+			duration:    time.Since(repoStart),
+		}
+	}
+	vtm.progress.report(repoCtx, ProgressSucceeded, repo, nil)
+	return RepositoryReport{
+		Repository:   repo,
+		Status:       RepositoryStatusSuccess,
+		ReleaseNotes: notes.Format,
+		Text:         notes.Text,
+		Branches:     notes.Branches,
+		LowActivity:  vtm.isLowActivity(notes.Format),
+		StaleRelease: vtm.isStaleRelease(notes.Format),
+		Labels:       vtm.repositoryLabels(repo),
+		duration:     time.Since(repoStart),
+	}
+}
+
+// generateReleaseNotes generates release notes for a single repository. When analysis fails
+// because the cloned object database is corrupt, the repository is cloned afresh and analyzed
+// once more before giving up.
+func (vtm *VibeToolsManager) generateReleaseNotes(ctx context.Context, repoURL, repoPath string) (RepositoryNotes, error) {
+	for attempt := 0; ; attempt++ {
+		if err := vtm.cloneRepository(ctx, repoURL, repoPath); err != nil {
+			if vtm.TarballFallback && tarballFallbackApplies(repoURL, err) {
+				return vtm.snapshotFromTarball(ctx, repoURL, repoPath, err)
+			}
+			return RepositoryNotes{}, err
+		}
+		vtm.progress.report(ctx, ProgressCloned, repoURL, nil)
+
+		analyzeCtx, span := StartSpan(ctx, "repository.analyze", attribute.String("repository", repoURL))
+		notes, err := vtm.analyzeRepository(analyzeCtx, repoPath, repoURL)
+		EndSpan(span, err)
+		if err == nil {
+			vtm.progress.report(ctx, ProgressAnalyzed, repoURL, nil)
+		}
+		if err == nil || !IsCorruptObjectError(err) {
+			return notes, err
+		}
+		if attempt > 0 {
+			return RepositoryNotes{}, WrapError(err, ErrorTypeCorruptRepo, "object database still corrupt after a fresh clone", map[string]interface{}{
+				"repository": repoURL,
+				"repo_path":  repoPath,
+			})
+		}
+
+		vtm.Logger.Warnf("Object database of %s looks corrupt (%v), re-cloning from scratch", repoURL, err)
+		vtm.mu.Lock()
+		vtm.recloned = append(vtm.recloned, repoURL)
+		vtm.mu.Unlock()
+	}
+}
+
+// addCloneStats adds the data pulled by one clone to the run's totals
+func (vtm *VibeToolsManager) addCloneStats(stats CloneStats) {
+	vtm.mu.Lock()
+	defer vtm.mu.Unlock()
+	vtm.cloneTotals.Add(stats)
+}
+
+// wasRecloned reports whether the repository already needed a fresh clone in this run
+func (vtm *VibeToolsManager) wasRecloned(repoURL string) bool {
+	vtm.mu.Lock()
+	defer vtm.mu.Unlock()
+	return containsString(vtm.recloned, repoURL)
+}
+
+// cloneFromObjectCache fetches the repository into the shared object cache and checks out a
+// working clone that borrows the cached objects
+func (vtm *VibeToolsManager) cloneFromObjectCache(ctx context.Context, repoURL, repoPath string) error {
+	branches, stats, err := vtm.objectCache.fetch(ctx, repoURL, cloneProgress(vtm.Logger, vtm.NoCloneProgress), cloneAuth(repoURL, vtm.Credentials))
+	if err != nil {
+		return err
+	}
+	if err := vtm.objectCache.checkout(repoURL, repoPath, branches); err != nil {
+		return err
+	}
+	if vtm.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		vtm.Logger.Debugf("Repository %s: %s through the object cache", repoURL, stats)
+		vtm.addCloneStats(stats)
+	}
+	return nil
+}
+
+// cloneRepository clones the repository into repoPath, removing anything already there
+func (vtm *VibeToolsManager) cloneRepository(ctx context.Context, repoURL, repoPath string) (err error) {
+	_, span := StartSpan(ctx, "git.clone", attribute.String("repository", repoURL))
+	defer func() { EndSpan(span, err) }()
+
 	// Remove existing directory if it exists
 	if err := os.RemoveAll(repoPath); err != nil {
 		vtm.Logger.Warnf("Failed to remove existing directory %s: %v", repoPath, err)
 	}
 	
 	vtm.Logger.Infof("Cloning repository: %s", repoURL)
-	_, err := git.PlainClone(repoPath, false, &git.CloneOptions{
+
+	// Bundles are cloned from the local file, without any network access
+	if IsBundlePath(repoURL) {
+		if err := cloneFromBundle(repoURL, repoPath); err != nil {
+			return WrapError(err, ErrorTypeGit, "failed to clone repository from bundle", map[string]interface{}{
+				"repository": repoURL,
+				"repo_path":  repoPath,
+			})
+		}
+		return nil
+	}
+
+	// A re-clone after corruption bypasses the cache, which may hold the corrupt objects
+	if vtm.objectCache != nil && !vtm.wasRecloned(repoURL) {
+		cacheErr := vtm.cloneFromObjectCache(ctx, repoURL, repoPath)
+		if cacheErr == nil {
+			return nil
+		}
+		vtm.Logger.Warnf("Failed to clone %s through the object cache, falling back to a full clone: %v", repoURL, cacheErr)
+		if err := os.RemoveAll(repoPath); err != nil {
+			vtm.Logger.Warnf("Failed to remove existing directory %s: %v", repoPath, err)
+		}
+	}
+
+	_, err = git.PlainClone(repoPath, false, &git.CloneOptions{
 		URL:      repoURL,
-		Progress: os.Stdout,
+		Auth:     cloneAuth(repoURL, vtm.Credentials),
+		Depth:    vtm.CloneDepth,
+		Tags:     git.AllTags, // With a depth, each tag is fetched with its commit, so older release tags still resolve
+		Progress: cloneProgress(vtm.Logger, vtm.NoCloneProgress),
 	})
 	if err != nil {
-		return "", WrapError(err, ErrorTypeGit, "failed to clone repository", map[string]interface{}{
+		if IsAuthError(err) {
+			return NewAuthRequiredError(repoURL, err)
+		}
+		return WrapError(err, ErrorTypeGit, "failed to clone repository", map[string]interface{}{
 			"repository": repoURL,
 			"repo_path":  repoPath,
 		})
 	}
+	if stats, ok := logCloneStats(vtm.Logger, repoURL, repoPath); ok {
+		vtm.addCloneStats(stats)
+	}
+	return nil
+}
+
+// analyzeRepository generates release notes from an existing clone
+func (vtm *VibeToolsManager) analyzeRepository(ctx context.Context, repoPath, repoURL string) (RepositoryNotes, error) {
+	// vibe-tools only reports on main, so selected branches use the built-in analysis
+	if len(vtm.Branches) > 0 {
+		return vtm.generateBasicReleaseNotes(ctx, repoPath, repoURL)
+	}
 
 	// Check if we should use cursor-agent or regular vibe-tools
 	if vtm.UseCursorAgent {
 		if !vtm.isCursorAgentAvailable() {
 			vtm.Logger.Info("cursor-agent not found, falling back to basic release notes")
-			return vtm.generateBasicReleaseNotes(repoPath, repoURL)
+			return vtm.generateBasicReleaseNotes(ctx, repoPath, repoURL)
 		}
-		return vtm.generateCursorAgentReleaseNotes(repoPath, repoURL)
+		return vtm.generateCursorAgentReleaseNotes(ctx, repoPath, repoURL)
 	} else if vtm.isVibeToolsAvailable() {
-		return vtm.generateVibeToolsReleaseNotes(repoPath, repoURL)
+		return vtm.generateVibeToolsReleaseNotes(ctx, repoPath, repoURL)
 	} else {
 		// No vibe-tools available, use basic release notes
-		return vtm.generateBasicReleaseNotes(repoPath, repoURL)
+		return vtm.generateBasicReleaseNotes(ctx, repoPath, repoURL)
 	}
 }
 
 // isVibeToolsAvailable checks if vibe-tools is available in PATH or .bin/
 func (vtm *VibeToolsManager) isVibeToolsAvailable() bool {
+	vtm.toolsMu.Lock()
+	defer vtm.toolsMu.Unlock()
 	dm := NewDependencyManager(".bin", vtm.Logger)
 	_, err := dm.FindOrDownloadTool("vibe-tools")
 	return err == nil
@@ -206,20 +606,19 @@ func (vtm *VibeToolsManager) isCursorAgentAvailable() bool {
 }
 
 // generateCursorAgentReleaseNotes generates release notes using cursor-agent vibe-tools
-func (vtm *VibeToolsManager) generateCursorAgentReleaseNotes(repoPath, repoURL string) (string, error) {
+func (vtm *VibeToolsManager) generateCursorAgentReleaseNotes(ctx context.Context, repoPath, repoURL string) (RepositoryNotes, error) {
 	vtm.Logger.Infof("Running cursor-agent vibe-tools on: %s", repoPath)
 	
 	// Find cursor-agent (cannot be auto-downloaded, must be in PATH)
 	cursorAgentPath, err := exec.LookPath("cursor-agent")
 	if err != nil {
 		vtm.Logger.Warnf("cursor-agent not found in PATH, falling back to basic notes")
-		return vtm.generateBasicReleaseNotes(repoPath, repoURL)
+		return vtm.generateBasicReleaseNotes(ctx, repoPath, repoURL)
 	}
 	
-	// Calculate date range for last week
-	now := time.Now()
-	oneWeekAgo := now.AddDate(0, 0, -7)
-	sinceDate := oneWeekAgo.Format("2006-01-02")
+	// Calculate date range for the analysis window
+	since := vtm.analysisWindowFor(repoURL).since
+	sinceDate := since.Format("2006-01-02")
 	
 	// Try cursor-agent with date range first
 	cmd := exec.Command(cursorAgentPath, "vibe-tools", "release-notes", "--repo", repoPath, "--branch", "main", "--since", sinceDate)
@@ -235,7 +634,7 @@ func (vtm *VibeToolsManager) generateCursorAgentReleaseNotes(repoPath, repoURL s
 		output, err = cmd.CombinedOutput()
 		if err != nil {
 			vtm.Logger.Infof("cursor-agent failed for %s, falling back to basic notes: %v", repoURL, err)
-			return vtm.generateBasicReleaseNotes(repoPath, repoURL)
+			return vtm.generateBasicReleaseNotes(ctx, repoPath, repoURL)
 		}
 	}
 
@@ -244,11 +643,11 @@ func (vtm *VibeToolsManager) generateCursorAgentReleaseNotes(repoPath, repoURL s
 		vtm.Logger.Warnf("Failed to clean up repository directory %s: %v", repoPath, err)
 	}
 	
-	return string(output), nil
+	return RepositoryNotes{Text: string(output)}, nil
 }
 
 // generateVibeToolsReleaseNotes generates release notes using regular vibe-tools
-func (vtm *VibeToolsManager) generateVibeToolsReleaseNotes(repoPath, repoURL string) (string, error) {
+func (vtm *VibeToolsManager) generateVibeToolsReleaseNotes(ctx context.Context, repoPath, repoURL string) (RepositoryNotes, error) {
 	vtm.Logger.Infof("Running vibe-tools on: %s", repoPath)
 	
 	// Find or download vibe-tools
@@ -256,13 +655,12 @@ func (vtm *VibeToolsManager) generateVibeToolsReleaseNotes(repoPath, repoURL str
 	vibeToolsPath, err := dm.FindOrDownloadTool("vibe-tools")
 	if err != nil {
 		vtm.Logger.Warnf("vibe-tools not available and could not be downloaded, falling back to basic notes: %v", err)
-		return vtm.generateBasicReleaseNotes(repoPath, repoURL)
+		return vtm.generateBasicReleaseNotes(ctx, repoPath, repoURL)
 	}
 	
-	// Calculate date range for last week
-	now := time.Now()
-	oneWeekAgo := now.AddDate(0, 0, -7)
-	sinceDate := oneWeekAgo.Format("2006-01-02")
+	// Calculate date range for the analysis window
+	since := vtm.analysisWindowFor(repoURL).since
+	sinceDate := since.Format("2006-01-02")
 	
 	// Try vibe-tools with date range first
 	cmd := exec.Command(vibeToolsPath, "release-notes", "--repo", repoPath, "--branch", "main", "--since", sinceDate)
@@ -278,7 +676,7 @@ func (vtm *VibeToolsManager) generateVibeToolsReleaseNotes(repoPath, repoURL str
 		output, err = cmd.CombinedOutput()
 		if err != nil {
 			vtm.Logger.Infof("vibe-tools failed for %s, falling back to basic notes: %v", repoURL, err)
-			return vtm.generateBasicReleaseNotes(repoPath, repoURL)
+			return vtm.generateBasicReleaseNotes(ctx, repoPath, repoURL)
 		}
 	}
 
@@ -287,161 +685,288 @@ func (vtm *VibeToolsManager) generateVibeToolsReleaseNotes(repoPath, repoURL str
 		vtm.Logger.Warnf("Failed to clean up repository directory %s: %v", repoPath, err)
 	}
 	
-	return string(output), nil
+	return RepositoryNotes{Text: string(output)}, nil
 }
 
 // generateBasicReleaseNotes generates basic release notes when vibe-tools is not available
-func (vtm *VibeToolsManager) generateBasicReleaseNotes(repoPath, repoURL string) (string, error) {
+func (vtm *VibeToolsManager) generateBasicReleaseNotes(ctx context.Context, repoPath, repoURL string) (RepositoryNotes, error) {
 	// Get basic repository information
 	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
-		return "", WrapError(err, ErrorTypeGit, "failed to open repository", map[string]interface{}{
+		return RepositoryNotes{}, WrapError(err, ErrorTypeGit, "failed to open repository", map[string]interface{}{
 			"repo_path": repoPath,
 		})
 	}
 
+	if len(vtm.Branches) > 0 {
+		return vtm.generateBranchesReleaseNotes(ctx, repo, repoPath, repoURL)
+	}
+
 	// Get main branch reference
 	ref, err := repo.Reference("refs/heads/main", true)
 	if err != nil {
 		// Try master branch if main doesn't exist
 		ref, err = repo.Reference("refs/heads/master", true)
 		if err != nil {
-			return "", WrapError(err, ErrorTypeGit, "failed to get main/master branch reference", map[string]interface{}{
+			return RepositoryNotes{}, WrapError(err, ErrorTypeGit, "failed to get main/master branch reference", map[string]interface{}{
 				"repo_path": repoPath,
 			})
 		}
 	}
 
+	notes, err := vtm.analyzeBranch(ctx, repo, repoPath, repoURL, branchTip{Name: ref.Name().Short(), Hash: ref.Hash()}, true)
+	if err != nil {
+		return RepositoryNotes{}, err
+	}
+
+	// Clean up cloned repository
+	if err := os.RemoveAll(repoPath); err != nil {
+		vtm.Logger.Warnf("Failed to clean up repository directory %s: %v", repoPath, err)
+	}
+	return notes, nil
+}
+
+// generateBranchesReleaseNotes generates the notes of every branch matching Branches from the
+// single clone in repoPath, one section per branch
+func (vtm *VibeToolsManager) generateBranchesReleaseNotes(ctx context.Context, repo *git.Repository, repoPath, repoURL string) (RepositoryNotes, error) {
+	tips, err := matchBranches(repo, vtm.Branches)
+	if err != nil {
+		return RepositoryNotes{}, WrapError(err, ErrorTypeGit, "failed to list branches", map[string]interface{}{
+			"repo_path": repoPath,
+		})
+	}
+	if len(tips) == 0 {
+		return RepositoryNotes{}, WrapError(nil, ErrorTypeGit, "no branch matches the selected branches", map[string]interface{}{
+			"repository":  repoURL,
+			"branches":    strings.Join(vtm.Branches, ","),
+			"remediation": "check the --branches patterns against the repository's branch names",
+		})
+	}
+
+	var branches []BranchNotes
+	for i, tip := range tips {
+		vtm.Logger.Infof("Analyzing branch %s of %s", tip.Name, repoURL)
+		// Run state, identities and submodules follow the first selected branch only
+		notes, err := vtm.analyzeBranch(ctx, repo, repoPath, repoURL, tip, i == 0)
+		if err != nil {
+			return RepositoryNotes{}, err
+		}
+		branches = append(branches, BranchNotes{Branch: tip.Name, ReleaseNotes: notes.Format, Text: notes.Text})
+	}
+
+	// Clean up cloned repository
+	if err := os.RemoveAll(repoPath); err != nil {
+		vtm.Logger.Warnf("Failed to clean up repository directory %s: %v", repoPath, err)
+	}
+	return RepositoryNotes{
+		Format:   branches[0].ReleaseNotes,
+		Text:     formatTextBranches(repoURL, branches),
+		Branches: branches,
+	}, nil
+}
+
+// analyzeBranch analyzes the window of one branch of an open clone. The primary branch is the
+// one whose tip is recorded in the state file, whose authors feed the identities file and whose
+// checked-out submodules are analyzed.
+func (vtm *VibeToolsManager) analyzeBranch(ctx context.Context, repo *git.Repository, repoPath, repoURL string, tip branchTip, primary bool) (RepositoryNotes, error) {
 	// Get commit information
-	commit, err := repo.CommitObject(ref.Hash())
+	commit, err := repo.CommitObject(tip.Hash)
 	if err != nil {
-		return "", WrapError(err, ErrorTypeGit, "failed to get commit object", map[string]interface{}{
+		return RepositoryNotes{}, WrapError(err, ErrorTypeGit, "failed to get commit object", map[string]interface{}{
 			"repo_path": repoPath,
 		})
 	}
 
-	// Calculate date range for last week
-	now := time.Now()
-	oneWeekAgo := now.AddDate(0, 0, -7)
+	// Calculate date range for the analysis window, which the overrides file may set per repository
+	window := vtm.analysisWindowFor(repoURL)
+	days, since, now := window.days, window.since, window.until
 	
-	vtm.Logger.Infof("Analyzing commits from the last week (since %s)", oneWeekAgo.Format("2006-01-02 15:04:05"))
+	if window.absolute {
+		vtm.Logger.Infof("Analyzing commits from %s to %s", since.Format("2006-01-02"), now.Format("2006-01-02"))
+	} else {
+		vtm.Logger.Infof("Analyzing commits from the last %d days (since %s)", days, since.Format("2006-01-02 15:04:05"))
+	}
 
-	// Get commits from the last week
-	commitIter, err := repo.Log(&git.LogOptions{
-		From: ref.Hash(),
-		All:  false,
-		Since: &oneWeekAgo,
-	})
+	// A historical report shows the branch as it was at the end of the window
+	if window.historical {
+		commit, err = latestCommitAsOf(repo, tip.Hash, now)
+		if err != nil {
+			return RepositoryNotes{}, WrapError(err, ErrorTypeGit, "failed to find the latest commit as of the analysis end", map[string]interface{}{
+				"repo_path": repoPath,
+				"as_of":     now.Format("2006-01-02"),
+			})
+		}
+	}
+
+	// Get commits from the analysis window
+	commits, err := logWindowCommits(repo, tip.Hash, since, now, vtm.DateMode)
 	if err != nil {
-		return "", WrapError(err, ErrorTypeGit, "failed to get commit log", map[string]interface{}{
+		return RepositoryNotes{}, WrapError(err, ErrorTypeGit, "failed to walk commit history", map[string]interface{}{
 			"repo_path": repoPath,
 		})
 	}
+	if vtm.CloneDepth > 0 {
+		warnTruncatedHistory(vtm.Logger, repo, repoURL, vtm.CloneDepth, since)
+	}
+	// Identities are collected before the author filter so every variant of a person shows up
+	if primary && vtm.IdentitiesFile != "" && vtm.identities != nil {
+		identities := collectIdentities(commits)
+		vtm.mu.Lock()
+		vtm.identities[repoURL] = identities
+		vtm.mu.Unlock()
+	}
+	commits = filterCommitsByAuthor(commits, vtm.OnlyAuthors)
 
 	var commitDetails []CommitDetail
-	var commitCount int
-	var authorStats = make(map[string]int)
-	var totalChanges int
-	
-	commitIter.ForEach(func(c *object.Commit) error {
-		commitCount++
-		
-		// Count changes in this commit with panic recovery
-		// Some commits with very large diffs can cause panics in the diff library
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					vtm.Logger.Warnf("Failed to calculate stats for commit %s (panic recovered): %v", c.Hash.String()[:8], r)
-				}
-			}()
-			
-			stats, err := c.Stats()
-			if err == nil {
-				for _, stat := range stats {
-					totalChanges += stat.Addition + stat.Deletion
-				}
-			} else {
-				vtm.Logger.Debugf("Failed to get stats for commit %s: %v", c.Hash.String()[:8], err)
+	var breaking []BreakingChange
+	var merged []MergedPullRequest
+	commitCount := len(commits)
+	authorStats := make(contributorTally)
+	var totalChanges, statsSkipped int
+
+	// Count changes in each commit, spreading the diffs over the stats workers
+	for i, stats := range computeCommitChanges(repoPath, commits, newChurnOptions(vtm.IgnoreWhitespace, vtm.ExcludeExtensions), vtm.StatsWorkers) {
+		c := commits[i]
+		if stats.err != nil {
+			if IsCorruptObjectError(stats.err) {
+				return RepositoryNotes{}, WrapError(stats.err, ErrorTypeGit, "failed to walk commit history", map[string]interface{}{
+					"repo_path": repoPath,
+				})
 			}
-		}()
+			vtm.Logger.Warnf("Failed to calculate stats for commit %s: %v", c.Hash.String()[:8], stats.err)
+			statsSkipped++
+		}
+		totalChanges += stats.lines
 		
 		// Track author activity
-		authorStats[c.Author.Name]++
+		authorStats.add(c, stats.lines)
 		
 		// Add commit detail
-		commitDetails = append(commitDetails, CommitDetail{
+		detail := CommitDetail{
 			Hash:    c.Hash.String()[:8],
 			Message: strings.TrimSpace(c.Message),
 			Author:  c.Author.Name,
-			Date:    c.Author.When,
-		})
-		
-		return nil
-	})
-
-	// Clean up cloned repository
-	if err := os.RemoveAll(repoPath); err != nil {
-		vtm.Logger.Warnf("Failed to clean up repository directory %s: %v", repoPath, err)
+			Date:    vtm.DateMode.commitDate(c),
+			Files:   stats.files,
+		}
+		commitDetails = append(commitDetails, detail)
+		if description, ok := breakingChangeDescription(c.Message); ok {
+			breaking = append(breaking, BreakingChange{Commit: detail, Description: description})
+		}
+		if pr, ok := mergedPullRequest(c, repoURL, detail, vtm.CommitURLs); ok {
+			merged = append(merged, pr)
+		}
 	}
 
-	// Create contributors list
-	var contributors []Contributor
-	type authorCommit struct {
-		author string
-		count  int
+	// Check the previous run's commit is still on the branch before the clone is removed
+	var rewrite *HistoryRewrite
+	if primary && vtm.state != nil && !window.historical {
+		branch := tip.Name
+		rewrite, err = detectHistoryRewrite(repo, branch, commit, vtm.state.Repositories[repoURL])
+		if err != nil {
+			vtm.Logger.Warnf("Failed to check %s for rewritten history: %v", repoURL, err)
+		} else if rewrite != nil {
+			vtm.Logger.Warnf("%s: %s", repoURL, rewrite.Message())
+		}
+		vtm.mu.Lock()
+		vtm.analyzed[repoURL] = RepositoryState{Branch: branch, Commit: commit.Hash.String(), AnalyzedAt: time.Now()}
+		vtm.mu.Unlock()
 	}
-	var sortedAuthors []authorCommit
-	for author, count := range authorStats {
-		sortedAuthors = append(sortedAuthors, authorCommit{author, count})
+
+	// Compare the fork against its upstream before the clone is removed
+	var upstream *UpstreamComparison
+	if upstreamURL, ok := vtm.Upstreams[repoURL]; ok {
+		upstream, err = compareWithUpstream(repo, tip.Hash, upstreamURL, tip.Name, cloneAuth(upstreamURL, vtm.Credentials))
+		if err != nil {
+			vtm.Logger.Warnf("Failed to compare %s with upstream %s: %v", repoURL, upstreamURL, err)
+		}
 	}
-	
-	// Simple sort by count (descending)
-	for i := 0; i < len(sortedAuthors); i++ {
-		for j := i + 1; j < len(sortedAuthors); j++ {
-			if sortedAuthors[i].count < sortedAuthors[j].count {
-				sortedAuthors[i], sortedAuthors[j] = sortedAuthors[j], sortedAuthors[i]
+
+	// Submodules are fetched into the clone, so they are analyzed before it is removed
+	var submodules []SubmoduleChanges
+	if primary && vtm.IncludeSubmodules {
+		submodules, err = analyzeSubmodules(ctx, repo, since, now, vtm.DateMode, vtm.AllowedHosts)
+		if err != nil {
+			vtm.Logger.Warnf("Failed to analyze submodules of %s: %v", repoURL, err)
+		}
+		for _, sub := range submodules {
+			if sub.Error != "" {
+				vtm.Logger.Warnf("Submodule %s of %s not analyzed: %s", sub.Path, repoURL, sub.Error)
 			}
 		}
 	}
-	
-	// Convert to contributors
-	for i, author := range sortedAuthors {
-		contributors = append(contributors, Contributor{
-			Name:        author.author,
-			CommitCount: author.count,
-			Rank:        i + 1,
-		})
-	}
+
+	// Create contributors list
+	contributors := authorStats.ranked(vtm.RankBy)
+	firstParty, vendored := splitVendoredCommits(commitDetails, vtm.VendorPatterns)
 
 	// Create standard format using formatter
-	format := vtm.Formatter.CreateStandardFormat(
+	format := vtm.Formatter.CreateStandardFormatWithDays(
 		repoURL,
-		oneWeekAgo,
+		days,
+		since,
 		now,
 		CommitInfo{
-			Hash:    commit.Hash.String()[:8],
-			Message: commit.Message,
-			Author:  commit.Author.Name,
-			Date:    commit.Author.When,
+			Hash:     commit.Hash.String()[:8],
+			Message:  commit.Message,
+			Author:   commit.Author.Name,
+			Date:     vtm.DateMode.commitDate(commit),
+			fullHash: commit.Hash.String(),
 		},
 		WeeklySummary{
 			TotalCommits:      commitCount,
 			TotalLinesChanged: totalChanges,
 			ActiveContributors: len(authorStats),
-			AnalysisStart:     oneWeekAgo,
+			AnalysisStart:     since,
 			AnalysisEnd:       now,
+			StatsSkipped:      statsSkipped,
+			MergedPRs:         len(merged),
 		},
 		contributors,
-		commitDetails,
+		firstParty,
 	)
+	format.VendoredCommits = vendored
+	if window.absolute {
+		vtm.Formatter.SetAbsoluteRange(&format)
+	}
+	if len(vtm.Branches) > 0 {
+		format.Branch = tip.Name
+	}
+	format.Submodules = submodules
+	format.Upstream = upstream
+	format.HistoryRewrite = rewrite
+	if vtm.StaleReleaseDays > 0 {
+		release, err := latestReleaseTag(repo, now)
+		if err != nil {
+			vtm.Logger.Warnf("Failed to read the release tags of %s: %v", repoURL, err)
+		}
+		format.LatestRelease = release
+	}
+	format.RankBy = vtm.RankBy
+	format.OnlyAuthors = vtm.OnlyAuthors
+	if vtm.ShowAvatars {
+		resolveContributorAvatars(repoURL, format.Contributors)
+	}
+	format.NotableChanges = findNotableChanges(commitDetails, vtm.NotablePatterns)
+	format.BreakingChanges = breaking
+	format.MergedPullRequests = merged
+	if vtm.ActivityHeatmap {
+		format.Activity = newCommitActivity(commitDetails, nil)
+	}
+	if info, ok := vtm.CatalogInfo[repoURL]; ok {
+		format.RepositoryInfo.applyCatalog(info)
+	}
+	if vtm.ReportOpenPRs {
+		format.RepositoryInfo.OpenPullRequests = openPullRequestCount(repoURL)
+	}
 
-	return vtm.Formatter.FormatReleaseNote(format), nil
+	return RepositoryNotes{Format: &format, Text: vtm.Formatter.FormatReleaseNote(format)}, nil
 }
 
 // extractRepoName extracts repository name from URL
 func (vtm *VibeToolsManager) extractRepoName(repoURL string) string {
-	// Remove .git suffix if present
-	repoURL = strings.TrimSuffix(repoURL, ".git")
+	// Remove .git or .bundle suffix if present
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(repoURL, ".git"), bundleExtension)
 	
 	// Extract name from URL
 	parts := strings.Split(repoURL, "/")
@@ -593,8 +1118,26 @@ func (vtm *VibeToolsManager) generateHTMLHeader() string {
             color: var(--accent-secondary);
             min-width: 24px;
         }
+        .contributor .avatar { width: 24px; height: 24px; border-radius: 50%; }
         .contributor .name { flex: 1; font-weight: 500; }
         .contributor .count { color: var(--text-muted); font-size: 13px; }
+        .history-rewrite, .stats-unavailable { padding: 12px 16px; border: 1px solid var(--warning); border-radius: 8px; color: var(--warning); }
+        .breaking-changes { padding: 16px; border: 1px solid var(--error); border-radius: 8px; background: rgba(255, 85, 85, 0.08); }
+        .breaking-changes h3 { color: var(--error); }
+        .breaking-list { list-style: none; display: flex; flex-direction: column; gap: 8px; }
+        .breaking-list .breaking-author { color: var(--text-muted); font-size: 13px; }
+        .activity-table { border-collapse: collapse; font-size: 12px; font-family: 'JetBrains Mono', monospace; }
+        .activity-table caption { text-align: left; color: var(--text-muted); margin-bottom: 6px; }
+        .activity-table th { color: var(--text-muted); font-weight: 500; padding: 4px 8px; }
+        .activity-table td { min-width: 44px; padding: 4px 8px; text-align: center; border: 1px solid var(--border-color); }
+        .activity-table .activity-total { color: var(--text-secondary); font-weight: 600; }
+        .notes-text {
+            font-family: 'JetBrains Mono', monospace;
+            font-size: 13px;
+            white-space: pre-wrap;
+            word-break: break-word;
+            color: var(--text-secondary);
+        }
         .error-card {
             background: rgba(255, 85, 85, 0.1);
             border-color: var(--error);
@@ -609,6 +1152,31 @@ func (vtm *VibeToolsManager) generateHTMLHeader() string {
             margin-top: 40px;
         }
         .summary-card h2 { font-size: 24px; margin-bottom: 24px; }
+        .label-index {
+            background: var(--bg-secondary);
+            border: 1px solid var(--border-color);
+            border-radius: 16px;
+            padding: 24px;
+            margin-bottom: 32px;
+        }
+        .label-index h2 { font-size: 20px; margin-bottom: 12px; }
+        .label-index ul { list-style: none; }
+        .label-index li { padding: 6px 0; }
+        .label-index a { color: var(--accent-primary); font-weight: 600; text-decoration: none; }
+        .label-index .label-repos { color: var(--text-muted); font-size: 13px; }
+        .label-heading {
+            font-size: 24px;
+            margin: 40px 0 16px;
+            padding-bottom: 8px;
+            border-bottom: 1px solid var(--border-color);
+        }
+        .label-count {
+            font-size: 12px;
+            color: var(--text-muted);
+            background: var(--bg-tertiary);
+            border-radius: 10px;
+            padding: 2px 8px;
+        }
         .footer {
             text-align: center;
             margin-top: 40px;
@@ -623,11 +1191,60 @@ func (vtm *VibeToolsManager) generateHTMLHeader() string {
     <div class="container">
         <div class="header">
             <h1>🔍 Prega Operator Release Notes</h1>
-            <p>Generated on ` + time.Now().Format("January 02, 2006 at 15:04:05") + `</p>
+            ` + vtm.generatedOnHTML() + vtm.asOfHTML() + vtm.catalogSchemaHTML() + `
         </div>
 `
 }
 
+// catalogSchemaLine returns the "Catalog schema" line for the text output, or nothing when unknown
+func (vtm *VibeToolsManager) catalogSchemaLine() string {
+	if len(vtm.CatalogSchemas) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Catalog schema: %s\n", strings.Join(vtm.CatalogSchemas, ", "))
+}
+
+// generatedOnHTML returns the generation timestamp paragraph for the HTML header,
+// or nothing when timestamps are omitted
+func (vtm *VibeToolsManager) generatedOnHTML() string {
+	if vtm.Formatter.OmitTimestamp {
+		return ""
+	}
+	return "<p>Generated on " + time.Now().Format("January 02, 2006 at 15:04:05") + "</p>"
+}
+
+// asOfHTML returns the paragraph naming the date range of the report, or the end of a
+// historical report's window, or nothing for a report of the last days ending now
+func (vtm *VibeToolsManager) asOfHTML() string {
+	if !vtm.Since.IsZero() {
+		return "<p>Report from " + vtm.Since.Format("January 02, 2006") + " to " + vtm.analysisEnd().Format("January 02, 2006") + "</p>"
+	}
+	if vtm.AsOf.IsZero() {
+		return ""
+	}
+	return "<p>Report as of " + vtm.AsOf.Format("January 02, 2006") + "</p>"
+}
+
+// asOfLine returns the "Date Range" or "As of" line for the text output, or nothing for a
+// report of the last days ending now
+func (vtm *VibeToolsManager) asOfLine() string {
+	if !vtm.Since.IsZero() {
+		return fmt.Sprintf("Date Range: %s..%s\n", vtm.Since.Format("2006-01-02"), vtm.analysisEnd().Format("2006-01-02"))
+	}
+	if vtm.AsOf.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("As of: %s\n", vtm.AsOf.Format("2006-01-02"))
+}
+
+// catalogSchemaHTML returns the catalog schema paragraph for the HTML header, or nothing when unknown
+func (vtm *VibeToolsManager) catalogSchemaHTML() string {
+	if len(vtm.CatalogSchemas) == 0 {
+		return ""
+	}
+	return "<p>Catalog schema: " + html.EscapeString(strings.Join(vtm.CatalogSchemas, ", ")) + "</p>"
+}
+
 // generateHTMLFooter generates the HTML document footer
 func (vtm *VibeToolsManager) generateHTMLFooter() string {
 	return `
@@ -641,8 +1258,7 @@ func (vtm *VibeToolsManager) generateHTMLFooter() string {
 }
 
 // generateHTMLSummary generates an HTML summary section
-func (vtm *VibeToolsManager) generateHTMLSummary(total, success, failed int) string {
-	successRate := float64(success) / float64(total) * 100
+func (vtm *VibeToolsManager) generateHTMLSummary(report *RunReport) string {
 	return fmt.Sprintf(`
         <div class="summary-card">
             <h2>📊 Processing Summary</h2>
@@ -659,13 +1275,113 @@ func (vtm *VibeToolsManager) generateHTMLSummary(total, success, failed int) str
                     <span class="stat-value" style="color: var(--error)">%d</span>
                     <span class="stat-label">Failed</span>
                 </div>
+                <div class="stat-card">
+                    <span class="stat-value" style="color: var(--warning)">%d</span>
+                    <span class="stat-label">Skipped</span>
+                </div>
                 <div class="stat-card">
                     <span class="stat-value">%.1f%%</span>
                     <span class="stat-label">Success Rate</span>
                 </div>
             </div>
         </div>
-`, total, success, failed, successRate)
+`, report.Total, report.Successful, report.Failed, report.Skipped, report.SuccessRate())
+}
+
+// formatHTMLRepoSection formats a successfully processed repository's release notes in HTML
+func (vtm *VibeToolsManager) formatHTMLRepoSection(repoURL, releaseNotes string, format *ReleaseNoteFormat) string {
+	repoName := vtm.extractRepoName(repoURL)
+	return fmt.Sprintf(`
+        <div class="repo-card">
+            <div class="repo-header">
+                <h2>📦 %s</h2>
+                <div class="repo-url">%s</div>
+            </div>
+            <div class="repo-body">%s%s%s%s%s
+                <div class="section">
+                    <div class="notes-text">%s</div>
+                </div>
+            </div>
+        </div>
+`, html.EscapeString(repoName), html.EscapeString(repoURL), formatHTMLHistoryRewrite(format), formatHTMLStatsUnavailable(format), formatHTMLRepoBreakingChanges(format), formatHTMLAvatarContributors(format), formatHTMLActivity(format), html.EscapeString(strings.TrimSpace(releaseNotes)))
+}
+
+// formatHTMLHistoryRewrite warns that the branch was force-pushed since the previous run
+func formatHTMLHistoryRewrite(format *ReleaseNoteFormat) string {
+	if format == nil || format.HistoryRewrite == nil {
+		return ""
+	}
+	return fmt.Sprintf(`
+                <div class="section history-rewrite">⚠️ %s</div>`, html.EscapeString(format.HistoryRewrite.Message()))
+}
+
+// formatHTMLStatsUnavailable warns that the line counts in the notes are not meaningful
+func formatHTMLStatsUnavailable(format *ReleaseNoteFormat) string {
+	if format == nil || !format.WeeklySummary.StatsUnavailable() {
+		return ""
+	}
+	return fmt.Sprintf(`
+                <div class="section stats-unavailable">⚠️ %s</div>`, html.EscapeString(format.WeeklySummary.statsUnavailableNote()))
+}
+
+// formatHTMLRepoBreakingChanges highlights the breaking changes above the rest of the notes
+func formatHTMLRepoBreakingChanges(format *ReleaseNoteFormat) string {
+	if format == nil || len(format.BreakingChanges) == 0 {
+		return ""
+	}
+	return `
+                <div class="section breaking-changes">
+                    <h3>⚠️ Breaking Changes</h3>
+` + formatHTMLBreakingChanges(format.BreakingChanges) + `
+                </div>`
+}
+
+// formatHTMLActivity renders the commit activity table when the heatmap is enabled
+func formatHTMLActivity(format *ReleaseNoteFormat) string {
+	if format == nil || format.Activity == nil {
+		return ""
+	}
+	return `
+                <div class="section">
+                    <h3>Commit Activity</h3>
+` + format.Activity.FormatHTML() + `
+                </div>`
+}
+
+// formatHTMLAvatarContributors lists the top contributors with their avatars when any were
+// resolved; the text notes already cover contributors otherwise
+func formatHTMLAvatarContributors(format *ReleaseNoteFormat) string {
+	if format == nil {
+		return ""
+	}
+	hasAvatar := false
+	for _, c := range format.Contributors {
+		hasAvatar = hasAvatar || c.AvatarURL != ""
+	}
+	if !hasAvatar {
+		return ""
+	}
+
+	var section strings.Builder
+	section.WriteString(`
+                <div class="section">`)
+	for _, c := range format.Contributors {
+		section.WriteString(fmt.Sprintf(`
+                    <div class="contributor"><span class="rank">#%d</span>%s<span class="name">%s</span><span class="count">%s</span></div>`,
+			c.Rank, contributorAvatarHTML(c), html.EscapeString(c.Name), html.EscapeString(c.Activity(format.RankBy))))
+	}
+	section.WriteString(`
+                </div>`)
+	return section.String()
+}
+
+// errorHint returns the remediation for an error, or the generic advice to check the
+// repository URL and connectivity
+func errorHint(err error) string {
+	if remediation := ErrorRemediation(err); remediation != "" {
+		return "To fix: " + remediation + "."
+	}
+	return "This repository could not be processed. Please check the repository URL and network connectivity."
 }
 
 // formatHTMLErrorSection formats an error section in HTML
@@ -682,10 +1398,29 @@ func (vtm *VibeToolsManager) formatHTMLErrorSection(repoURL string, err error) s
                     <h3>Error Details</h3>
                     <p style="color: var(--error);">%v</p>
                     <p style="color: var(--text-muted); margin-top: 8px;">
-                        This repository could not be processed. Please check the repository URL and network connectivity.
+                        %s
                     </p>
                 </div>
             </div>
         </div>
-`, repoName, repoURL, err)
-}
\ No newline at end of file
+`, repoName, repoURL, err, html.EscapeString(errorHint(err)))
+}
+
+// formatHTMLSkippedSection formats a skipped repository section in HTML
+func (vtm *VibeToolsManager) formatHTMLSkippedSection(repoURL, reason string) string {
+	repoName := vtm.extractRepoName(repoURL)
+	return fmt.Sprintf(`
+        <div class="repo-card">
+            <div class="repo-header">
+                <h2>⏭️ %s</h2>
+                <div class="repo-url">%s</div>
+            </div>
+            <div class="repo-body">
+                <div class="section">
+                    <h3>Skipped</h3>
+                    <p style="color: var(--warning);">%s</p>
+                </div>
+            </div>
+        </div>
+`, html.EscapeString(repoName), html.EscapeString(repoURL), html.EscapeString(reason))
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// ensureWritableDir creates dir if needed and checks that files can be created in it by
+// creating and removing a probe file, so an unwritable work or output directory fails before
+// the first clone instead of deep inside go-git
+func ensureWritableDir(dir string) error {
+	if dir == "" {
+		dir = "."
+	}
+	errContext := map[string]interface{}{
+		"directory":   dir,
+		"remediation": "make the directory writable by the user running the analyzer, or choose another directory",
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return WrapError(err, ErrorTypeFileSystem, writableDirMessage("cannot create directory "+dir, err), errContext)
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return WrapError(err, ErrorTypeFileSystem, writableDirMessage("directory "+dir+" is not writable", err), errContext)
+	}
+	probe.Close()
+	if err := os.Remove(probe.Name()); err != nil {
+		return WrapError(err, ErrorTypeFileSystem, writableDirMessage("cannot remove files from directory "+dir, err), errContext)
+	}
+	return nil
+}
+
+// writableDirMessage names a permission problem explicitly, since that is the usual cause
+func writableDirMessage(message string, err error) string {
+	if errors.Is(err, fs.ErrPermission) {
+		return message + " (permission denied)"
+	}
+	return message
+}
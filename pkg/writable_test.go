@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEnsureWritableDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "work", "repos")
+	if err := ensureWritableDir(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected the directory to be created: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected the probe file to be removed, found %d entries", len(entries))
+	}
+
+	// A directory below a regular file can never be created
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	err = ensureWritableDir(filepath.Join(file, "work"))
+	var analyzerErr *AnalyzerError
+	if !errors.As(err, &analyzerErr) || analyzerErr.Type != ErrorTypeFileSystem {
+		t.Fatalf("Expected a file system error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), filepath.Join(file, "work")) || ErrorRemediation(err) == "" {
+		t.Errorf("Expected the error to name the directory with a remediation, got %v", err)
+	}
+}
+
+func TestEnsureWritableDirPermissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for this user")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatalf("Failed to make directory read-only: %v", err)
+	}
+	defer os.Chmod(dir, 0755)
+
+	err := ensureWritableDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "is not writable (permission denied)") {
+		t.Errorf("Expected a permission error naming the directory, got %v", err)
+	}
+}
+
+func TestProcessRepositoriesUnwritableWorkDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	vtm := NewVibeToolsManager(filepath.Join(file, "work"), filepath.Join(t.TempDir(), "notes.txt"), false)
+
+	results, err := vtm.ProcessRepositories(context.Background(), []string{"https://github.com/test/repo"})
+	var analyzerErr *AnalyzerError
+	if !errors.As(err, &analyzerErr) || analyzerErr.Type != ErrorTypeFileSystem {
+		t.Fatalf("Expected a file system error, got %v", err)
+	}
+	if results != nil {
+		t.Errorf("Expected no repository to be processed, got %+v", results)
+	}
+}